@@ -0,0 +1,860 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/junlapong/coronavirus/covid"
+)
+
+// handleChanges returns the changes to the dataset since a given version
+// e.g. /api/changes?since=12
+func handleChanges(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	since, err := strconv.Atoi(r.URL.Query().Get("since"))
+	if err != nil {
+		since = 0
+	}
+
+	changes, version, complete := covid.Changes(since)
+	if changes == nil {
+		changes = []covid.Change{}
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"version":  version,
+		"since":    since,
+		"complete": complete,
+		"changes":  changes,
+	})
+}
+
+// writeJSON writes v to w as json
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	output, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("json render error:%s", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(output)
+}
+
+// maxBatch is the maximum number of queries accepted in one batch request
+const maxBatch = 100
+
+// batchQuery is one query within a batch request
+type batchQuery struct {
+	Country  string `json:"country"`
+	Province string `json:"province"`
+	Metric   string `json:"metric"`
+	Days     int    `json:"days"`
+}
+
+// batchResult is the result for one query within a batch request
+type batchResult struct {
+	batchQuery
+	Dates  []string `json:"dates,omitempty"`
+	Values []int    `json:"values,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// handleBatch accepts a POST of a json list of queries and returns the results for each
+// e.g. [{"country":"italy","metric":"deaths_daily","days":28}]
+func handleBatch(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "batch requests must be POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var queries []batchQuery
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&queries)
+	if err != nil {
+		http.Error(w, "invalid batch request:"+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(queries) > maxBatch {
+		http.Error(w, "too many queries in batch request", http.StatusBadRequest)
+		return
+	}
+
+	// Errors for individual queries are reported in the results
+	results := make([]batchResult, len(queries))
+	for i, q := range queries {
+		results[i].batchQuery = q
+
+		days := q.Days
+		if days < 0 {
+			days = 0
+		}
+		view, err := covid.FetchView(q.Country, q.Province, days, q.Metric)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Dates = view.Dates
+		results[i].Values = view.Metric(q.Metric).Values
+	}
+
+	writeJSON(w, results)
+}
+
+// Defaults for listing endpoints
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// handleSeriesList returns a page of series with selected fields
+// e.g. /api/series?type=countries&offset=50&limit=50&fields=country,total_deaths
+// or /api/series?tag=g7 for the series in a curated group, income=low-income or who_region=european-region for classifications
+func handleSeriesList(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	writeSeriesList(w, r, covid.ListSeries)
+}
+
+// writeSeriesList writes a page of series from list using the query params for filters and fields
+func writeSeriesList(w http.ResponseWriter, r *http.Request, list func(covid.SeriesFilter, int, int) (covid.SeriesSlice, int)) {
+	query := r.URL.Query()
+	offset, err := strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	// Parse the fields requested, if none we return all
+	var fields []string
+	if query.Get("fields") != "" {
+		fields = strings.Split(query.Get("fields"), ",")
+		for _, f := range fields {
+			if !validSeriesField(f) {
+				http.Error(w, "invalid field:"+f, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	filter := covid.SeriesFilter{
+		Countries: query.Get("type") == "countries",
+		Tag:       query.Get("tag"),
+		Income:    query.Get("income"),
+		WHORegion: query.Get("who_region"),
+	}
+	page, total := list(filter, offset, limit)
+
+	results := make([]map[string]interface{}, len(page))
+	for i, s := range page {
+		results[i] = seriesFields(s.View(), fields)
+	}
+
+	response := map[string]interface{}{
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+		"series": results,
+	}
+	if offset+len(page) < total {
+		response["next_offset"] = offset + len(page)
+	}
+
+	writeJSON(w, response)
+}
+
+// seriesFieldNames lists the fields available for series in listings
+var seriesFieldNames = []string{
+	"key", "country", "province", "title", "updated_at", "starts_at", "stale_since", "data_age", "cadence", "tags", "income_group", "who_region",
+	"total_deaths", "total_confirmed", "incidence_14d", "acceleration",
+	"dates", "deaths", "confirmed", "deaths_daily", "confirmed_daily", "positivity_rate", "auxiliary",
+	"hospital_occupancy", "icu_occupancy", "variants", "dominant_variants",
+}
+
+// validSeriesField returns true if f is a valid series field name
+func validSeriesField(f string) bool {
+	for _, n := range seriesFieldNames {
+		if n == f {
+			return true
+		}
+	}
+	return false
+}
+
+// seriesFields returns the requested fields from a read only view of a series, or all fields if fields is empty
+func seriesFields(s covid.SeriesView, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		fields = seriesFieldNames
+	}
+
+	key := s.Key(s.Country())
+	if s.Province() != "" {
+		key = key + "/" + s.Key(s.Province())
+	}
+
+	result := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		switch f {
+		case "key":
+			result[f] = key
+		case "country":
+			result[f] = s.Country()
+		case "province":
+			result[f] = s.Province()
+		case "title":
+			result[f] = s.Title()
+		case "updated_at":
+			result[f] = s.UpdatedAt()
+		case "stale_since":
+			result[f] = s.StaleSince()
+		case "data_age":
+			result[f] = s.DataAge()
+		case "cadence":
+			result[f] = s.Cadence()
+		case "tags":
+			result[f] = s.Tags()
+		case "income_group":
+			result[f] = s.IncomeGroup()
+		case "who_region":
+			result[f] = s.WHORegion()
+		case "starts_at":
+			result[f] = s.StartsAt()
+		case "total_deaths":
+			result[f] = s.TotalDeaths()
+		case "total_confirmed":
+			result[f] = s.TotalConfirmed()
+		case "incidence_14d":
+			result[f] = s.Incidence14Per100k()
+		case "acceleration":
+			result[f] = s.Acceleration()
+		case "dates":
+			result[f] = s.Dates()
+		case "deaths":
+			result[f] = s.Deaths()
+		case "confirmed":
+			result[f] = s.Confirmed()
+		case "deaths_daily":
+			result[f] = s.DeathsDaily()
+		case "confirmed_daily":
+			result[f] = s.ConfirmedDaily()
+		case "positivity_rate":
+			result[f] = s.PositivityRate()
+		case "auxiliary":
+			result[f] = s.Auxiliary()
+		case "hospital_occupancy":
+			result[f] = s.HospitalOccupancy()
+		case "icu_occupancy":
+			result[f] = s.ICUOccupancy()
+		case "variants":
+			result[f] = s.VariantShares()
+		case "dominant_variants":
+			result[f] = s.DominantVariants()
+		}
+	}
+	return result
+}
+
+// handleExportXLSX serves an Excel workbook of all data
+func handleExportXLSX(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"covid.xlsx\"")
+	err := covid.WriteXLSX(w)
+	if err != nil {
+		log.Printf("xlsx export error:%s", err)
+	}
+}
+
+// handleSeriesDownload serves the full history of one country and its provinces as csv in long format
+// at /api/series/{country}/download.csv, so that one country can be downloaded without the full export
+func handleSeriesDownload(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/series/"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "download.csv" {
+		http.NotFound(w, r)
+		return
+	}
+	list, err := covid.FetchCountrySeries(parts[0])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+list[0].Key(list[0].Country)+".csv\"")
+	err = list.WriteHistoryCSV(w)
+	if err != nil {
+		log.Printf("series download error:%s", err)
+	}
+}
+
+// handleExportParquet serves a parquet file of all data in long format
+func handleExportParquet(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"covid.parquet\"")
+	err := covid.WriteParquet(w)
+	if err != nil {
+		log.Printf("parquet export error:%s", err)
+	}
+}
+
+// handleLeaderboard returns countries ranked by a per capita metric over a window
+// e.g. /api/leaderboard?metric=cases_per_100k&window=14d&min_population=1000000&n=20
+func handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	query := r.URL.Query()
+	metric, err := covid.ParseMetricFilter(query.Get("metric"), covid.LeaderboardDeathsPerMillion,
+		covid.LeaderboardDeathsPerMillion, covid.LeaderboardCasesPer100k)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Window is a number of days e.g. 7d or all for all time
+	window, err := covid.ParsePeriodFilter(query.Get("window"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	minPopulation := covid.DefaultMinPopulation
+	if v, err := strconv.Atoi(query.Get("min_population")); err == nil && v >= 0 {
+		minPopulation = v
+	}
+	n := 20
+	if v, err := strconv.Atoi(query.Get("n")); err == nil && v > 0 {
+		n = v
+	}
+
+	entries, err := covid.Leaderboard(metric.Metric, window.Days, minPopulation, n)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"metric":         metric.Metric,
+		"window":         window.Days,
+		"min_population": minPopulation,
+		"entries":        entries,
+	})
+}
+
+// handleBreakdown returns the breakdown of a series by age or sex, with the strata summed to totals
+// e.g. /api/breakdown?country=italy&dimension=age
+func handleBreakdown(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	query := r.URL.Query()
+	series, err := covid.FetchSeries(query.Get("country"), query.Get("province"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	dimension := query.Get("dimension")
+	if dimension == "" {
+		dimension = covid.DimensionAge
+	}
+	strata := series.Breakdown(dimension)
+	if strata == nil {
+		strata = []*covid.Stratum{}
+	}
+	deaths, confirmed := series.AggregateStrata(dimension)
+
+	writeJSON(w, map[string]interface{}{
+		"country":    series.Country,
+		"province":   series.Province,
+		"dimension":  dimension,
+		"dimensions": series.Dimensions(),
+		"dates":      series.Dates(),
+		"strata":     strata,
+		"totals": map[string]interface{}{
+			"deaths":    deaths,
+			"confirmed": confirmed,
+		},
+	})
+}
+
+// handleCountry serves api requests for a country at /api/country/{key}/meta
+// or /api/country/{key}/projection?metric=deaths&threshold=100000
+// or /api/country/{key}/stats?metric=confirmed_daily&period=28
+// or /api/country/{key}/calendar?metric=confirmed_daily
+// or /api/country/{key}/transforms?metric=deaths_daily&transform=raw,smoothed,redistributed,per_capita&smooth=7
+func handleCountry(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/country/"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch parts[1] {
+	case "meta":
+		meta, err := covid.FetchMeta(parts[0])
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, meta)
+
+	case "projection":
+		series, err := covid.FetchSeries(parts[0], "")
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		metric, err := covid.ParseMetricFilter(r.URL.Query().Get("metric"), "")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		threshold, err := strconv.Atoi(r.URL.Query().Get("threshold"))
+		if err != nil || threshold <= 0 {
+			http.Error(w, "invalid threshold", http.StatusBadRequest)
+			return
+		}
+		days, err := series.DaysUntil(metric.Metric, threshold)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rate, _, _ := series.GrowthRate(metric.Metric)
+		result := map[string]interface{}{
+			"metric":      metric.Metric,
+			"threshold":   threshold,
+			"growth_rate": rate,
+			"days":        days,
+		}
+		switch days {
+		case covid.ProjectionNever:
+			result["status"] = "never"
+		case covid.ProjectionUnknown:
+			result["status"] = "unknown"
+		default:
+			result["status"] = "projected"
+			result["date"] = series.StartsAt.AddDate(0, 0, len(series.Deaths)-1+days).Format("2006-01-02")
+		}
+		writeJSON(w, result)
+
+	case "stats":
+		series, err := covid.FetchSeries(parts[0], "")
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		metric, err := covid.ParseMetricFilter(r.URL.Query().Get("metric"), covid.MetricConfirmedDaily)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		period, err := covid.ParsePeriodFilter(r.URL.Query().Get("period"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stats, err := series.Stats(metric.Metric, period.Days)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, stats)
+
+	case "calendar":
+		series, err := covid.FetchSeries(parts[0], "")
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		metric, err := covid.ParseMetricFilter(r.URL.Query().Get("metric"), covid.MetricConfirmedDaily)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		calendar, err := series.Calendar(metric.Metric)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, calendar)
+
+	case "transforms":
+		series, err := covid.FetchSeries(parts[0], "")
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		metric, err := covid.ParseMetricFilter(r.URL.Query().Get("metric"), covid.MetricConfirmedDaily)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		transforms, err := covid.ParseTransforms(r.URL.Query().Get("transform"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		days := 0
+		if r.URL.Query().Get("smooth") != "" {
+			days, err = strconv.Atoi(r.URL.Query().Get("smooth"))
+			if err != nil || days <= 0 {
+				http.Error(w, "invalid smooth", http.StatusBadRequest)
+				return
+			}
+		}
+		adjusted, err := series.Adjusted(metric.Metric, transforms, days)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, adjusted)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleSummary returns the global summary shown in the dashboard header
+func handleSummary(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	writeJSON(w, covid.FetchSummary())
+}
+
+// handleLoadReport returns the report for the last load of data, with the files read, rows skipped and timings
+func handleLoadReport(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	writeJSON(w, covid.FetchLoadReport())
+}
+
+// handleOptions returns the country options grouped by continent with the provinces of each country nested,
+// for pickers which show the hierarchy rather than one long list
+func handleOptions(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	writeJSON(w, covid.OptionTree())
+}
+
+// handleStats returns counts for the dataset as a whole: series, countries and provinces, the dates covered,
+// global totals and the last update of each source
+func handleStats(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	writeJSON(w, covid.FetchDataStats())
+}
+
+// handleSnapshots lists the stored daily snapshots at /api/snapshots
+// or serves the data as it was on one day at /api/snapshots/{date} as json, or csv or parquet by extension
+// e.g. /api/snapshots/2020-03-24.csv
+func handleSnapshots(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/snapshots"), "/")
+	if name == "" {
+		list, err := covid.Snapshots()
+		if err != nil {
+			log.Printf("snapshot error:%s", err)
+			http.Error(w, "error listing snapshots", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, list)
+		return
+	}
+
+	ext := path.Ext(name)
+	slice, err := covid.FetchSnapshot(strings.TrimSuffix(name, ext))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	switch ext {
+	case "", ".json":
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err = slice.WriteJSON(w)
+	case ".csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = slice.WriteCSV(w)
+	case ".parquet":
+		w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+		err = slice.WriteParquet(w)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("snapshot render error:%s", err)
+	}
+}
+
+// handleMovers returns the countries with the biggest changes compared with the prior period
+// e.g. /api/movers?metric=deaths_daily&window=7&n=10
+func handleMovers(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	query := r.URL.Query()
+	metric, err := covid.ParseMetricFilter(query.Get("metric"), covid.MetricConfirmedDaily)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	window, err := covid.ParsePeriodFilter(query.Get("window"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if window.AllTime() {
+		window.Days = 1
+	}
+	n, err := strconv.Atoi(query.Get("n"))
+	if err != nil || n < 1 {
+		n = 10
+	}
+
+	movers, err := covid.FetchMovers(metric.Metric, window.Days, n)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, movers)
+}
+
+// handleDatasets lists the datasets available at /api/datasets
+// or returns series from one dataset at /api/datasets/{name}/series with the same params as /api/series
+// or /api/datasets/{name}/series/{slug}?fields=dates,deaths_daily for one series
+func handleDatasets(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/datasets"), "/")
+	if path == "" {
+		var list []map[string]interface{}
+		for _, name := range covid.Datasets() {
+			item := map[string]interface{}{"name": name}
+			if d, err := covid.FetchDataset(name); err == nil {
+				item["source"] = d.Source
+				item["format"] = d.Format
+				item["updated_at"] = d.UpdatedAt()
+			}
+			list = append(list, item)
+		}
+		writeJSON(w, list)
+		return
+	}
+
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 2 || parts[1] != "series" {
+		http.NotFound(w, r)
+		return
+	}
+	list, fetch := covid.ListSeries, covid.FetchSlug
+	if parts[0] != covid.DefaultDataset {
+		d, err := covid.FetchDataset(parts[0])
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		list, fetch = d.ListSeries, d.FetchSlug
+	}
+
+	if len(parts) == 2 {
+		writeSeriesList(w, r, list)
+		return
+	}
+
+	series, err := fetch(parts[2])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	var fields []string
+	if f := r.URL.Query().Get("fields"); f != "" {
+		fields = strings.Split(f, ",")
+	}
+	writeJSON(w, seriesFields(series.View(), fields))
+}
+
+// handleReconcile compares the same series in two datasets and returns those which diverge
+// e.g. /api/reconcile?dataset=jhu&other=ecdc&tolerance=0.05
+// this is an admin endpoint, so it requires the header Authorization: Bearer $COVID_ADMIN_TOKEN
+func handleReconcile(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	if !adminAuthorized(r) {
+		adminUnauthorized(w)
+		return
+	}
+
+	query := r.URL.Query()
+	dataset := query.Get("dataset")
+	if dataset == "" {
+		dataset = covid.DefaultDataset
+	}
+	tolerance, err := strconv.ParseFloat(query.Get("tolerance"), 64)
+	if err != nil || tolerance <= 0 {
+		tolerance = covid.DefaultTolerance
+	}
+
+	report, err := covid.Reconcile(dataset, query.Get("other"), tolerance)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, report)
+}
+
+// handleRollups lists countries whose series differ from the sum of their provinces
+// e.g. /api/rollups?tolerance=0.1 for a difference of over 10%
+func handleRollups(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	tolerance, err := strconv.ParseFloat(r.URL.Query().Get("tolerance"), 64)
+	if err != nil || tolerance <= 0 {
+		tolerance = covid.DefaultTolerance
+	}
+	writeJSON(w, covid.FetchRollups(tolerance))
+}
+
+// handleRestOfWorld serves a series summing all countries except those excluded
+// e.g. /api/rest_of_world?excluding=china,italy or /api/rest_of_world?top=5 to exclude the top 5 countries by deaths
+func handleRestOfWorld(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	query := r.URL.Query()
+	var excluded []string
+	if query.Get("excluding") != "" {
+		excluded = strings.Split(query.Get("excluding"), ",")
+	}
+	top := 0
+	if query.Get("top") != "" {
+		var err error
+		top, err = strconv.Atoi(query.Get("top"))
+		if err != nil {
+			http.Error(w, "invalid top", http.StatusBadRequest)
+			return
+		}
+	}
+
+	series, names, err := covid.FetchRestOfWorld(excluded, top)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"name":                  series.Country,
+		"excluded":              names,
+		"population":            series.Population(),
+		"starts_at":             series.StartsAt,
+		"updated_at":            series.UpdatedAt,
+		"deaths":                series.Deaths,
+		"confirmed":             series.Confirmed,
+		"deaths_per_million":    series.DeathsPerMillion(),
+		"confirmed_per_million": series.ConfirmedPerMillion(),
+	})
+}
+
+// adminAuthorized returns true if the request has the header Authorization: Bearer $COVID_ADMIN_TOKEN
+// admin requests are disabled if it is not set
+func adminAuthorized(r *http.Request) bool {
+	token := os.Getenv("COVID_ADMIN_TOKEN")
+	return token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
+}
+
+// adminUnauthorized responds to an admin request without a valid token, asking for a bearer token
+func adminUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// handleHidden lists hidden series with GET, hides a series with POST /admin/hidden?series=china/hubei
+// and restores it with DELETE, hidden series are left out of options, global totals and country lists
+// requests must have the admin token as for imports
+func handleHidden(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	if !adminAuthorized(r) {
+		adminUnauthorized(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodDelete:
+		err := covid.SetHidden(r.URL.Query().Get("series"), r.Method == http.MethodPost)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, covid.Hidden())
+}
+
+// maxImportSize is the maximum size of a json import
+const maxImportSize = 32 << 20
+
+// handleImport accepts a POST of a json list of observations and applies them to our data
+// e.g. [{"country":"Thailand","date":"2020-04-01","metric":"confirmed","value":1771}]
+// requests must have the header Authorization: Bearer $COVID_ADMIN_TOKEN, imports are disabled if it is not set
+func handleImport(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	if !adminAuthorized(r) {
+		adminUnauthorized(w)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	list, err := covid.ReadObservations(io.LimitReader(r.Body, maxImportSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, covid.Import(list))
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junlapong/coronavirus/covid"
+)
+
+func TestAdminUnauthorized(t *testing.T) {
+	previous := os.Getenv("COVID_ADMIN_TOKEN")
+	os.Setenv("COVID_ADMIN_TOKEN", "secret")
+	defer os.Setenv("COVID_ADMIN_TOKEN", previous)
+
+	handlers := map[string]http.HandlerFunc{
+		"/api/reconcile?dataset=jhu&other=ecdc": handleReconcile,
+		"/admin/hidden":                         handleHidden,
+		"/admin/import":                         handleImport,
+	}
+	for path, handler := range handlers {
+		for _, header := range []string{"", "Bearer wrong", "secret"} {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			if header != "" {
+				r.Header.Set("Authorization", header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+			if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Bearer realm="admin"` {
+				t.Fatalf("test: %s wanted:%d with challenge got:%d %q for header:%q", path, http.StatusUnauthorized, w.Code, w.Header().Get("WWW-Authenticate"), header)
+			}
+		}
+
+		// With the token the request is handled, here failing for reconcile as the other dataset is not loaded
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code == http.StatusUnauthorized {
+			t.Fatalf("test: %s wanted authorized got:%d", path, w.Code)
+		}
+	}
+}
+
+// loadData ensures the dev data in ./data is loaded only once for handler tests
+var loadData sync.Once
+
+func loadTestData(t *testing.T) {
+	loadData.Do(func() {
+		err := covid.LoadData()
+		if err != nil {
+			t.Fatalf("test: load data error:%s", err)
+		}
+	})
+}
+
+func TestHandleBatch(t *testing.T) {
+	loadTestData(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/batch", nil)
+	w := httptest.NewRecorder()
+	handleBatch(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("test: batch GET wanted:%d got:%d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	// Errors for individual queries are reported in their results
+	body := `[{"country":"italy","metric":"deaths","days":3},{"country":"atlantis","metric":"deaths"},{"country":"italy","metric":"nope"}]`
+	r = httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	handleBatch(w, r)
+	var results []batchResult
+	err := json.Unmarshal(w.Body.Bytes(), &results)
+	if w.Code != http.StatusOK || err != nil || len(results) != 3 {
+		t.Fatalf("test: batch wanted:3 results got:%d %s", w.Code, w.Body.String())
+	}
+	if results[0].Error != "" || len(results[0].Values) != 3 || len(results[0].Dates) != 3 || results[0].Country != "italy" {
+		t.Fatalf("test: batch wanted italy deaths got:%+v", results[0])
+	}
+	if results[1].Error == "" || results[2].Error == "" || len(results[1].Values) != 0 {
+		t.Fatalf("test: batch wanted errors for invalid queries got:%+v %+v", results[1], results[2])
+	}
+
+	// Batches which are too large are rejected as a whole
+	queries := make([]string, maxBatch+1)
+	for i := range queries {
+		queries[i] = `{"country":"italy"}`
+	}
+	tests := map[string]string{
+		"too many queries": "[" + strings.Join(queries, ",") + "]",
+		"too long body":    `[{"country":"` + strings.Repeat("a", 1<<20) + `"}]`,
+		"invalid json":     `[{"country":`,
+	}
+	for name, body := range tests {
+		r = httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(body))
+		w = httptest.NewRecorder()
+		handleBatch(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("test: batch %s wanted:%d got:%d", name, http.StatusBadRequest, w.Code)
+		}
+	}
+
+	// The limit is inclusive
+	r = httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader("["+strings.Join(queries[:maxBatch], ",")+"]"))
+	w = httptest.NewRecorder()
+	handleBatch(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("test: batch of %d wanted:%d got:%d", maxBatch, http.StatusOK, w.Code)
+	}
+}
+
+func TestWriteSeriesList(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	var slice covid.SeriesSlice
+	for _, country := range []string{"Italy", "Spain", "France"} {
+		s := &covid.Series{Country: country, StartsAt: start, Deaths: []int{1, 2}, Confirmed: []int{10, 20}}
+		s.UpdateDaily()
+		slice = append(slice, s)
+	}
+	list := func(filter covid.SeriesFilter, offset, limit int) (covid.SeriesSlice, int) {
+		return slice.List(filter, offset, limit)
+	}
+
+	tests := []struct {
+		query     string
+		countries string
+		offset    int
+		limit     int
+		next      int
+	}{
+		{"", "Italy,Spain,France", 0, defaultListLimit, -1},
+		{"?offset=1&limit=1", "Spain", 1, 1, 2},
+		{"?offset=-5&limit=-1", "Italy,Spain,France", 0, defaultListLimit, -1},
+		{"?offset=3", "", 3, defaultListLimit, -1},
+		{"?offset=100", "", 100, defaultListLimit, -1},
+		{"?limit=0", "Italy,Spain,France", 0, defaultListLimit, -1},
+		{"?limit=100000", "Italy,Spain,France", 0, maxListLimit, -1},
+		{"?offset=1&limit=nope", "Spain,France", 1, defaultListLimit, -1},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/series"+tt.query, nil)
+		w := httptest.NewRecorder()
+		writeSeriesList(w, r, list)
+		var response struct {
+			Total      int                      `json:"total"`
+			Offset     int                      `json:"offset"`
+			Limit      int                      `json:"limit"`
+			NextOffset *int                     `json:"next_offset"`
+			Series     []map[string]interface{} `json:"series"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusOK || err != nil {
+			t.Fatalf("test: series list %s wanted:%d got:%d %s", tt.query, http.StatusOK, w.Code, w.Body.String())
+		}
+		var countries []string
+		for _, s := range response.Series {
+			countries = append(countries, s["country"].(string))
+		}
+		next := -1
+		if response.NextOffset != nil {
+			next = *response.NextOffset
+		}
+		if strings.Join(countries, ",") != tt.countries || response.Total != 3 || response.Offset != tt.offset || response.Limit != tt.limit || next != tt.next {
+			t.Fatalf("test: series list %s wanted:%s offset:%d limit:%d next:%d got:%s", tt.query, tt.countries, tt.offset, tt.limit, tt.next, w.Body.String())
+		}
+	}
+
+	// Only the fields requested are included
+	r := httptest.NewRequest(http.MethodGet, "/api/series?fields=country,total_deaths&limit=1", nil)
+	w := httptest.NewRecorder()
+	writeSeriesList(w, r, list)
+	var response struct {
+		Series []map[string]interface{} `json:"series"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Series) != 1 || len(response.Series[0]) != 2 || response.Series[0]["country"] != "Italy" || response.Series[0]["total_deaths"] != 2.0 {
+		t.Fatalf("test: series list fields wanted country and total_deaths got:%s", w.Body.String())
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/series?fields=country,nope", nil)
+	w = httptest.NewRecorder()
+	writeSeriesList(w, r, list)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("test: series list invalid field wanted:%d got:%d", http.StatusBadRequest, w.Code)
+	}
+}
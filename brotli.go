@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+// brotliWriter compresses data in the brotli format (RFC 7932) using only the standard library
+// it finds matches with a single hash table and uses one set of prefix codes per meta-block,
+// so compresses less than the reference encoder, but well enough for json and html responses
+type brotliWriter struct {
+	w   io.Writer
+	err error
+
+	// buf holds data not yet compressed, which is compressed a block at a time
+	buf []byte
+
+	// bits pending output, written least significant bit first
+	bits  uint64
+	nbits uint
+	out   []byte
+
+	table []int32
+}
+
+const (
+	// brotliBlockSize is the most data in one meta-block, so that MLEN always fits in 4 nibbles
+	brotliBlockSize = 1 << 16
+	// brotliMaxDistance is the furthest back we look for matches, within the 64KB window of WBITS 16
+	brotliMaxDistance = 1 << 15
+	brotliMinMatch    = 4
+	brotliHashBits    = 15
+)
+
+// Lengths and bases of the insert and copy length codes
+var (
+	brotliInsertBase  = []int{0, 1, 2, 3, 4, 5, 6, 8, 10, 14, 18, 26, 34, 50, 66, 98, 130, 194, 322, 578, 1090, 2114, 6210, 22594}
+	brotliInsertExtra = []uint{0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 7, 8, 9, 10, 12, 14, 24}
+	brotliCopyBase    = []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 14, 18, 22, 30, 38, 54, 70, 102, 134, 198, 326, 582, 1094, 2118}
+	brotliCopyExtra   = []uint{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 7, 8, 9, 10, 24}
+)
+
+// The order code length code lengths are stored in, and the static code used to store them
+var (
+	brotliCodeLengthOrder = []int{1, 2, 3, 4, 0, 5, 17, 6, 16, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	brotliCodeLengthBits  = []uint{2, 4, 3, 2, 2, 4}
+	brotliCodeLengthCodes = []uint64{0, 7, 3, 2, 1, 15}
+)
+
+// brotliCommand inserts literals then copies bytes from distance back, copy is 0 for the literals ending a block
+type brotliCommand struct {
+	literals []byte
+	copy     int
+	distance int
+}
+
+// newBrotliWriter returns a writer which compresses to w, Close must be called to finish the stream
+func newBrotliWriter(w io.Writer) *brotliWriter {
+	b := &brotliWriter{table: make([]int32, 1<<brotliHashBits)}
+	b.Reset(w)
+	return b
+}
+
+// Reset discards any state and starts a new stream to w
+func (b *brotliWriter) Reset(w io.Writer) {
+	b.w, b.err = w, nil
+	b.buf, b.out = b.buf[:0], b.out[:0]
+
+	// The stream header is a single 0 bit for a window of WBITS 16
+	b.bits, b.nbits = 0, 1
+}
+
+// Write compresses p, buffering any data short of a full block
+func (b *brotliWriter) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	b.buf = append(b.buf, p...)
+	for len(b.buf) >= brotliBlockSize {
+		b.writeBlock(b.buf[:brotliBlockSize])
+		b.buf = b.buf[:copy(b.buf, b.buf[brotliBlockSize:])]
+		b.flush()
+	}
+	return len(p), b.err
+}
+
+// Close compresses any buffered data and ends the stream with an empty last meta-block
+func (b *brotliWriter) Close() error {
+	if b.err != nil {
+		return b.err
+	}
+	if len(b.buf) > 0 {
+		b.writeBlock(b.buf)
+		b.buf = b.buf[:0]
+	}
+	// ISLAST and ISLASTEMPTY, then pad to a byte
+	b.writeBits(2, 3)
+	b.writeBits((8-b.nbits%8)%8, 0)
+	b.flush()
+	return b.err
+}
+
+// writeBits appends the low n bits of v to the output
+func (b *brotliWriter) writeBits(n uint, v uint64) {
+	b.bits |= v << b.nbits
+	b.nbits += n
+	for b.nbits >= 8 {
+		b.out = append(b.out, byte(b.bits))
+		b.bits >>= 8
+		b.nbits -= 8
+	}
+}
+
+// flush writes complete bytes of output to the underlying writer
+func (b *brotliWriter) flush() {
+	if b.err == nil && len(b.out) > 0 {
+		_, b.err = b.w.Write(b.out)
+	}
+	b.out = b.out[:0]
+}
+
+// writeBlock writes data as one compressed meta-block, which is not the last
+func (b *brotliWriter) writeBlock(data []byte) {
+	commands := b.commands(data)
+
+	literalFreqs := make([]int, 256)
+	commandFreqs := make([]int, 704)
+	distanceFreqs := make([]int, 64)
+	for _, c := range commands {
+		for _, l := range c.literals {
+			literalFreqs[l]++
+		}
+		commandFreqs[brotliCommandSymbol(c)]++
+		if c.copy > 0 {
+			symbol, _, _ := brotliDistanceCode(c.distance)
+			distanceFreqs[symbol]++
+		}
+	}
+
+	// ISLAST 0, MNIBBLES 4, MLEN-1 and ISUNCOMPRESSED 0
+	b.writeBits(1, 0)
+	b.writeBits(2, 0)
+	b.writeBits(16, uint64(len(data)-1))
+	b.writeBits(1, 0)
+
+	// One block type of each kind, NPOSTFIX and NDIRECT 0, literal context mode LSB6, and one prefix code of each kind
+	b.writeBits(3, 0)
+	b.writeBits(6, 0)
+	b.writeBits(2, 0)
+	b.writeBits(2, 0)
+
+	literalLengths, literalCodes := b.writePrefixCode(literalFreqs, 8)
+	commandLengths, commandCodes := b.writePrefixCode(commandFreqs, 10)
+	distanceLengths, distanceCodes := b.writePrefixCode(distanceFreqs, 6)
+
+	for _, c := range commands {
+		symbol := brotliCommandSymbol(c)
+		b.writeBits(uint(commandLengths[symbol]), uint64(commandCodes[symbol]))
+		code := brotliLengthCode(brotliInsertBase, len(c.literals))
+		b.writeBits(brotliInsertExtra[code], uint64(len(c.literals)-brotliInsertBase[code]))
+		if c.copy > 0 {
+			code = brotliLengthCode(brotliCopyBase, c.copy)
+			b.writeBits(brotliCopyExtra[code], uint64(c.copy-brotliCopyBase[code]))
+		}
+		for _, l := range c.literals {
+			b.writeBits(uint(literalLengths[l]), uint64(literalCodes[l]))
+		}
+		// The block ends after the literals of the last command, so it has no distance
+		if c.copy > 0 {
+			symbol, n, extra := brotliDistanceCode(c.distance)
+			b.writeBits(uint(distanceLengths[symbol]), uint64(distanceCodes[symbol]))
+			b.writeBits(n, uint64(extra))
+		}
+	}
+}
+
+// commands splits data into literals and copies of earlier data, using greedy matching
+func (b *brotliWriter) commands(data []byte) []brotliCommand {
+	for i := range b.table {
+		b.table[i] = 0
+	}
+	var commands []brotliCommand
+	start := 0
+	for i := 0; i+brotliMinMatch <= len(data); {
+		h := binary.LittleEndian.Uint32(data[i:]) * 0x1e35a7bd >> (32 - brotliHashBits)
+		candidate := int(b.table[h]) - 1
+		b.table[h] = int32(i + 1)
+		if candidate < 0 || i-candidate > brotliMaxDistance || binary.LittleEndian.Uint32(data[candidate:]) != binary.LittleEndian.Uint32(data[i:]) {
+			i++
+			continue
+		}
+		n := brotliMinMatch
+		for i+n < len(data) && data[candidate+n] == data[i+n] {
+			n++
+		}
+		commands = append(commands, brotliCommand{literals: data[start:i], copy: n, distance: i - candidate})
+		i += n
+		start = i
+	}
+	if start < len(data) {
+		commands = append(commands, brotliCommand{literals: data[start:]})
+	}
+	return commands
+}
+
+// writePrefixCode writes a prefix code for symbols with freqs, and returns the code lengths and codes to write symbols with
+// a code with one symbol is written as a simple prefix code, whose symbol takes no bits
+func (b *brotliWriter) writePrefixCode(freqs []int, alphabetBits uint) ([]uint8, []uint64) {
+	lengths := brotliCodeLengths(freqs, 15)
+	last, used := -1, 0
+	for i, l := range lengths {
+		if l > 0 {
+			last = i
+			used++
+		}
+	}
+	if used < 2 {
+		// HSKIP 1 for a simple code, NSYM-1 0 and the symbol
+		symbol := 0
+		if last > 0 {
+			symbol = last
+		}
+		b.writeBits(2, 1)
+		b.writeBits(2, 0)
+		b.writeBits(alphabetBits, uint64(symbol))
+		return make([]uint8, len(freqs)), make([]uint64, len(freqs))
+	}
+
+	// The code lengths are written with a code of their own, without repeat codes
+	codeLengthFreqs := make([]int, 18)
+	for _, l := range lengths[:last+1] {
+		codeLengthFreqs[l]++
+	}
+	codeLengthLengths := brotliCodeLengths(codeLengthFreqs, 5)
+	count := len(brotliCodeLengthOrder)
+	codeLengthsUsed := 0
+	for _, l := range codeLengthLengths {
+		if l > 0 {
+			codeLengthsUsed++
+		}
+	}
+	if codeLengthsUsed > 1 {
+		for codeLengthLengths[brotliCodeLengthOrder[count-1]] == 0 {
+			count--
+		}
+	}
+
+	// HSKIP 0, then the code length code lengths with their static code
+	b.writeBits(2, 0)
+	for _, symbol := range brotliCodeLengthOrder[:count] {
+		l := codeLengthLengths[symbol]
+		b.writeBits(brotliCodeLengthBits[l], brotliCodeLengthCodes[l])
+	}
+
+	// A code length code with one symbol takes no bits to write each length
+	codeLengthCodes := brotliCodes(codeLengthLengths)
+	if codeLengthsUsed == 1 {
+		codeLengthLengths = make([]uint8, len(codeLengthLengths))
+	}
+	for _, l := range lengths[:last+1] {
+		b.writeBits(uint(codeLengthLengths[l]), codeLengthCodes[l])
+	}
+	return lengths, brotliCodes(lengths)
+}
+
+// brotliCodeLengths returns huffman code lengths of at most limit bits for symbols with freqs
+// a single symbol has length 1, unused symbols have length 0
+func brotliCodeLengths(freqs []int, limit uint8) []uint8 {
+	lengths := make([]uint8, len(freqs))
+	var symbols []int
+	for i, f := range freqs {
+		if f > 0 {
+			symbols = append(symbols, i)
+		}
+	}
+	if len(symbols) == 1 {
+		lengths[symbols[0]] = 1
+	}
+	if len(symbols) < 2 {
+		return lengths
+	}
+
+	weights := make([]int, len(freqs))
+	copy(weights, freqs)
+	for {
+		// Build the tree from leaves sorted by weight and internal nodes, which are created in order of weight
+		sort.SliceStable(symbols, func(i, j int) bool { return weights[symbols[i]] < weights[symbols[j]] })
+		type node struct{ weight, left, right int }
+		nodes := make([]node, 0, 2*len(symbols))
+		for _, s := range symbols {
+			nodes = append(nodes, node{weight: weights[s], left: -1, right: s})
+		}
+		leaf, internal := 0, len(symbols)
+		smallest := func() int {
+			if leaf < len(symbols) && (internal >= len(nodes) || nodes[leaf].weight <= nodes[internal].weight) {
+				leaf++
+				return leaf - 1
+			}
+			internal++
+			return internal - 1
+		}
+		for i := 1; i < len(symbols); i++ {
+			l, r := smallest(), smallest()
+			nodes = append(nodes, node{weight: nodes[l].weight + nodes[r].weight, left: l, right: r})
+		}
+
+		// Depths are set from the root down, as children are always before their parents
+		depths := make([]uint8, len(nodes))
+		longest := uint8(0)
+		for i := len(nodes) - 1; i >= len(symbols); i-- {
+			depths[nodes[i].left] = depths[i] + 1
+			depths[nodes[i].right] = depths[i] + 1
+		}
+		for i, s := range symbols {
+			lengths[s] = depths[i]
+			if depths[i] > longest {
+				longest = depths[i]
+			}
+		}
+		if longest <= limit {
+			return lengths
+		}
+
+		// Flatten the weights and try again, until the code is short enough
+		for _, s := range symbols {
+			weights[s] = (weights[s] + 1) / 2
+		}
+	}
+}
+
+// brotliCodes returns the canonical codes for lengths, with their bits reversed for writing least significant bit first
+func brotliCodes(lengths []uint8) []uint64 {
+	var counts [16]int
+	for _, l := range lengths {
+		if l > 0 {
+			counts[l]++
+		}
+	}
+	var next [16]int
+	code := 0
+	for bits := 1; bits < 16; bits++ {
+		code = (code + counts[bits-1]) << 1
+		next[bits] = code
+	}
+	codes := make([]uint64, len(lengths))
+	for i, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		for j := uint8(0); j < l; j++ {
+			codes[i] |= uint64(next[l]>>j&1) << (l - 1 - j)
+		}
+		next[l]++
+	}
+	return codes
+}
+
+// brotliLengthCode returns the code for n from a table of bases
+func brotliLengthCode(bases []int, n int) int {
+	code := len(bases) - 1
+	for bases[code] > n {
+		code--
+	}
+	return code
+}
+
+// brotliCommandSymbol returns the insert and copy length symbol for c, always with an explicit distance
+func brotliCommandSymbol(c brotliCommand) int {
+	insert := brotliLengthCode(brotliInsertBase, len(c.literals))
+	copyCode := 0
+	if c.copy > 0 {
+		copyCode = brotliLengthCode(brotliCopyBase, c.copy)
+	}
+	offsets := []int{128, 192, 384, 256, 320, 512, 448, 576, 640}
+	return offsets[copyCode>>3+3*(insert>>3)] | (insert&7)<<3 | copyCode&7
+}
+
+// brotliDistanceCode returns the distance symbol and extra bits for distance, with NPOSTFIX and NDIRECT 0
+func brotliDistanceCode(distance int) (int, uint, int) {
+	x := distance + 3
+	n := uint(0)
+	for x>>(n+2) > 0 {
+		n++
+	}
+	high := x >> n & 1
+	return 16 + 2*(int(n)-1) + high, n, x - (2+high)<<n
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipWriters pools gzip writers to avoid allocating one per request
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	},
+}
+
+// brotliWriters pools brotli writers to avoid allocating one per request
+var brotliWriters = sync.Pool{
+	New: func() interface{} {
+		return newBrotliWriter(nil)
+	},
+}
+
+// compressor is a gzip or brotli writer which may be reset for another response
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// encodings are the content encodings we support, in order of preference
+var encodings = []string{"br", "gzip"}
+
+// acceptedEncoding returns the encoding to use for the Accept-Encoding header, or "" to leave the response as it is
+// the encoding with the highest q value is chosen, preferring brotli where q values are equal
+func acceptedEncoding(header string) string {
+	q := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name == "" {
+			continue
+		}
+		q[name] = 1
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				v, err := strconv.ParseFloat(p[2:], 64)
+				if err == nil {
+					q[name] = v
+				}
+			}
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, e := range encodings {
+		v, ok := q[e]
+		if !ok {
+			v = q["*"]
+		}
+		if v > bestQ {
+			best, bestQ = e, v
+		}
+	}
+	return best
+}
+
+// compressResponseWriter compresses the response body, once there is a body to compress
+// responses to HEAD requests, with a status which has no body, or with an empty body are left as they are
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+	head     bool
+
+	// status is the status set by the handler, 0 until set
+	status int
+	// skip is true if the response has no body to compress
+	skip bool
+	// writer is the compressor, nil until the first data is written
+	writer compressor
+}
+
+// WriteHeader records the status, the header is written with the first data as we then know if it should be compressed
+func (w *compressResponseWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if w.head || !bodyAllowed(status) {
+		w.skip = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write compresses the data written, removing any content length set, as it will no longer be correct
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.skip {
+		return w.ResponseWriter.Write(b)
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if w.writer == nil {
+		// Detect the content type from the data, as the server would otherwise sniff compressed data
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Encoding", w.encoding)
+		w.ResponseWriter.WriteHeader(w.status)
+		if w.encoding == "br" {
+			w.writer = brotliWriters.Get().(*brotliWriter)
+		} else {
+			w.writer = gzipWriters.Get().(*gzip.Writer)
+		}
+		w.writer.Reset(w.ResponseWriter)
+	}
+	return w.writer.Write(b)
+}
+
+// close ends the compressed stream, or writes the header for a response with an empty body
+func (w *compressResponseWriter) close() {
+	if w.writer == nil {
+		if w.status != 0 && !w.skip {
+			w.ResponseWriter.WriteHeader(w.status)
+		}
+		return
+	}
+	w.writer.Close()
+	if w.encoding == "br" {
+		brotliWriters.Put(w.writer)
+	} else {
+		gzipWriters.Put(w.writer)
+	}
+}
+
+// bodyAllowed returns true if a response with status may have a body
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// compressHandler wraps a handler to compress responses with brotli or gzip for clients which accept them
+func compressHandler(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			h(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding, head: r.Method == http.MethodHead}
+		defer cw.close()
+		h(cw, r)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"identity":               "",
+		"gzip":                   "gzip",
+		"gzip, deflate, br":      "br",
+		"br;q=0.5, gzip":         "gzip",
+		"br;q=0, gzip;q=0":       "",
+		"*":                      "br",
+		"*;q=0.1, gzip;q=0.2":    "gzip",
+		"GZIP;q=1.0, BR;q=0.999": "gzip",
+	}
+	for header, want := range tests {
+		if got := acceptedEncoding(header); got != want {
+			t.Fatalf("test: accepted encoding for:%q wanted:%q got:%q", header, want, got)
+		}
+	}
+}
+
+func TestCompressHandler(t *testing.T) {
+	body := strings.Repeat(`{"country":"Korea, South","deaths":[1,2,3]}`, 100)
+	handler := compressHandler(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/empty":
+			w.WriteHeader(http.StatusOK)
+			return
+		case "/not_modified":
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "4400")
+		w.Write([]byte(body))
+	})
+
+	tests := []struct {
+		method, path, accept, encoding string
+	}{
+		{http.MethodGet, "/", "gzip, deflate", "gzip"},
+		{http.MethodGet, "/", "gzip, br", "br"},
+		{http.MethodGet, "/", "", ""},
+		{http.MethodHead, "/", "gzip", ""},
+		{http.MethodGet, "/empty", "gzip", ""},
+		{http.MethodGet, "/not_modified", "br", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		r.Header.Set("Accept-Encoding", tt.accept)
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		if w.Header().Get("Vary") != "Accept-Encoding" || w.Header().Get("Content-Encoding") != tt.encoding {
+			t.Fatalf("test: compress %s %s wanted encoding:%q got:%q vary:%q", tt.method, tt.path, tt.encoding, w.Header().Get("Content-Encoding"), w.Header().Get("Vary"))
+		}
+		if tt.encoding != "" && w.Header().Get("Content-Length") != "" {
+			t.Fatalf("test: compress %s wanted no content length got:%s", tt.path, w.Header().Get("Content-Length"))
+		}
+
+		switch {
+		case tt.path == "/not_modified":
+			if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+				t.Fatalf("test: compress not modified wanted no body got:%d %q", w.Code, w.Body.String())
+			}
+		case tt.path == "/empty":
+			if w.Code != http.StatusOK || w.Body.Len() != 0 {
+				t.Fatalf("test: compress empty wanted no body got:%d %q", w.Code, w.Body.String())
+			}
+		case tt.encoding == "gzip":
+			gz, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatalf("test: compress gzip invalid:%s", err)
+			}
+			got, err := ioutil.ReadAll(gz)
+			if err != nil || string(got) != body {
+				t.Fatalf("test: compress gzip wrong body error:%v", err)
+			}
+		case tt.encoding == "br":
+			if w.Body.Len() == 0 || w.Body.Len() >= len(body) {
+				t.Fatalf("test: compress brotli wanted compressed body got:%d bytes", w.Body.Len())
+			}
+		default:
+			if w.Body.String() != body || w.Header().Get("Content-Length") != "4400" {
+				t.Fatalf("test: compress %s %s wanted body unchanged", tt.method, tt.path)
+			}
+		}
+	}
+}
+
+func TestBrotliWriter(t *testing.T) {
+	// An empty stream is a window of WBITS 16 and an empty last meta-block
+	b := &bytes.Buffer{}
+	w := newBrotliWriter(b)
+	w.Close()
+	if !bytes.Equal(b.Bytes(), []byte{0x06}) {
+		t.Fatalf("test: brotli empty stream wanted:06 got:%x", b.Bytes())
+	}
+
+	// The standard library has no brotli decoder, so check the output with curl if it supports brotli
+	version, err := exec.Command("curl", "-V").Output()
+	if err != nil || !strings.Contains(string(version), "brotli") {
+		t.Skip("test: curl with brotli not available")
+	}
+	random := make([]byte, 70000)
+	for i := range random {
+		random[i] = byte(i * 7919 >> 3)
+	}
+	inputs := [][]byte{
+		[]byte("a"),
+		bytes.Repeat([]byte("covid "), 30000),
+		[]byte(strings.Repeat(`{"country":"Korea, South","deaths":[1,2,3]},`, 3000)),
+		random,
+	}
+	for _, input := range inputs {
+		// Reuse the writer, as the pool does, writing in chunks which cross blocks
+		b.Reset()
+		w.Reset(b)
+		for i := 0; i < len(input); i += 5000 {
+			end := i + 5000
+			if end > len(input) {
+				end = len(input)
+			}
+			w.Write(input[i:end])
+		}
+		w.Close()
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("Content-Encoding", "br")
+			rw.Write(b.Bytes())
+		}))
+		got, err := exec.Command("curl", "-s", "--fail", "--compressed", server.URL).Output()
+		server.Close()
+		if err != nil || !bytes.Equal(got, input) {
+			t.Fatalf("test: brotli round trip of %d bytes failed got:%d bytes error:%v", len(input), len(got), err)
+		}
+	}
+}
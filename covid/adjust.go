@@ -0,0 +1,197 @@
+package covid
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Transforms of metric values available from the api, so that consumers can choose their preprocessing
+const (
+	TransformRaw           = "raw"
+	TransformSmoothed      = "smoothed"
+	TransformRedistributed = "redistributed"
+	TransformPerCapita     = "per_capita"
+)
+
+// Transforms returns the list of transform names accepted by AdjustedValues
+func Transforms() []string {
+	return []string{TransformRaw, TransformSmoothed, TransformRedistributed, TransformPerCapita}
+}
+
+// ParseTransforms parses a comma separated list of transforms, blank means raw values only
+func ParseTransforms(v string) ([]string, error) {
+	if strings.TrimSpace(v) == "" {
+		return []string{TransformRaw}, nil
+	}
+	var transforms []string
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		known := false
+		for _, k := range Transforms() {
+			if k == name {
+				known = true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("filter: unknown transform:%q wanted one of:%s", name, strings.Join(Transforms(), ", "))
+		}
+		transforms = append(transforms, name)
+	}
+	return transforms, nil
+}
+
+// perCapitaUnit is the population unit for per capita transforms, as used for totals per million
+const perCapitaUnit = 1000000
+
+// Adjusted holds the transforms requested for one metric of a series
+type Adjusted struct {
+	Metric     string               `json:"metric"`
+	StartsAt   string               `json:"starts_at"`
+	Smooth     int                  `json:"smooth_days,omitempty"`
+	Transforms map[string][]float64 `json:"transforms"`
+}
+
+// Adjusted returns the transforms of metric for this series, smoothing over days for the smoothed transform
+func (s *Series) Adjusted(metric string, transforms []string, days int) (*Adjusted, error) {
+	if days <= 0 {
+		days = trendDays
+	}
+	a := &Adjusted{Metric: metric, StartsAt: isoDate(s.StartsAt), Transforms: make(map[string][]float64, len(transforms))}
+	for _, transform := range transforms {
+		values, err := s.AdjustedValues(metric, transform, days)
+		if err != nil {
+			return nil, err
+		}
+		if transform == TransformSmoothed {
+			a.Smooth = days
+		}
+		a.Transforms[transform] = values
+	}
+	return a, nil
+}
+
+// AdjustedValues returns the values of metric for transform, smoothing over days for the smoothed transform
+// corrections can only be redistributed, and values made per capita, for deaths and confirmed
+func (s *Series) AdjustedValues(metric, transform string, days int) ([]float64, error) {
+	switch transform {
+	case TransformRaw:
+		return s.metricFloats(metric)
+	case TransformSmoothed:
+		values, err := s.metricFloats(metric)
+		if err != nil {
+			return nil, err
+		}
+		return smoothFloats(values, days), nil
+	case TransformRedistributed:
+		switch metric {
+		case MetricDeathsDaily:
+			return floatValues(RedistributeCorrections(s.DeathsDaily)), nil
+		case MetricConfirmedDaily:
+			return floatValues(RedistributeCorrections(s.ConfirmedDaily)), nil
+		case MetricDeaths:
+			return floatValues(cumulativeInts(RedistributeCorrections(s.DeathsDaily))), nil
+		case MetricConfirmed:
+			return floatValues(cumulativeInts(RedistributeCorrections(s.ConfirmedDaily))), nil
+		}
+	case TransformPerCapita:
+		switch metric {
+		case MetricDeaths, MetricConfirmed, MetricDeathsDaily, MetricConfirmedDaily:
+			values, _ := s.MetricValues(metric)
+			population := s.Population()
+			if population <= 0 {
+				return nil, fmt.Errorf("series: no population for per capita values:%s", s.Title())
+			}
+			adjusted := make([]float64, len(values))
+			for i, v := range values {
+				adjusted[i] = perCapita(v, population, perCapitaUnit)
+			}
+			return adjusted, nil
+		}
+	default:
+		return nil, fmt.Errorf("series: unknown transform:%s", transform)
+	}
+	return nil, fmt.Errorf("series: transform:%s not available for metric:%s", transform, metric)
+}
+
+// smoothFloats returns the trailing average of values over days for each day, as SmoothFloat does for ints
+func smoothFloats(values []float64, days int) []float64 {
+	smoothed := make([]float64, len(values))
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= days {
+			sum -= values[i-days]
+		}
+		n := days
+		if i+1 < days {
+			n = i + 1
+		}
+		smoothed[i] = sum / float64(n)
+	}
+	return smoothed
+}
+
+// RedistributeCorrections returns a copy of daily values with each negative value, usually a correction
+// to earlier reports, taken from the days before it in proportion to their values, so that no day is negative
+// and the total is unchanged, corrections larger than the total before them are left as they are
+func RedistributeCorrections(daily []int) []int {
+	values := append([]int(nil), daily...)
+	for i, v := range values {
+		if v >= 0 {
+			continue
+		}
+		total := 0
+		for _, p := range values[:i] {
+			if p > 0 {
+				total += p
+			}
+		}
+		if total < -v {
+			continue
+		}
+
+		// Take a share from each earlier day, and the remainder from the latest days which have any left
+		correction := -v
+		remaining := correction
+		for j := 0; j < i; j++ {
+			if values[j] > 0 {
+				share := int(math.Floor(float64(values[j]) * float64(correction) / float64(total)))
+				values[j] -= share
+				remaining -= share
+			}
+		}
+		for j := i - 1; j >= 0 && remaining > 0; j-- {
+			if values[j] > 0 {
+				take := values[j]
+				if take > remaining {
+					take = remaining
+				}
+				values[j] -= take
+				remaining -= take
+			}
+		}
+		values[i] = 0
+	}
+	return values
+}
+
+// cumulativeInts returns the running totals of daily values
+func cumulativeInts(daily []int) []int {
+	values := make([]int, len(daily))
+	total := 0
+	for i, v := range daily {
+		total += v
+		values[i] = total
+	}
+	return values
+}
+
+// floatValues returns values as floats, for transforms which mix whole and fractional values
+func floatValues(values []int) []float64 {
+	floats := make([]float64, len(values))
+	for i, v := range values {
+		floats[i] = float64(v)
+	}
+	return floats
+}
@@ -0,0 +1,57 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedistributeCorrections(t *testing.T) {
+	values := RedistributeCorrections([]int{10, 20, 30, -12, 5})
+	want := []int{8, 16, 24, 0, 5}
+	for i, v := range want {
+		if values[i] != v {
+			t.Fatalf("test: redistribute wanted:%v got:%v", want, values)
+		}
+	}
+	if sumInts(values) != 53 {
+		t.Fatalf("test: redistribute wanted total:53 got:%d", sumInts(values))
+	}
+
+	// Corrections larger than the total before them are left alone
+	values = RedistributeCorrections([]int{1, -5, 3})
+	if values[1] != -5 || values[0] != 1 {
+		t.Fatalf("test: redistribute wanted large correction kept got:%v", values)
+	}
+}
+
+func TestAdjusted(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start, Deaths: []int{10, 30, 60, 48}, Confirmed: []int{100, 200, 300, 400}}
+	s.UpdateDaily()
+
+	a, err := s.Adjusted(MetricDeaths, []string{TransformRaw, TransformRedistributed, TransformSmoothed}, 2)
+	if err != nil {
+		t.Fatalf("test: adjusted error:%s", err)
+	}
+	if a.Transforms[TransformRaw][3] != 48 || a.Transforms[TransformRedistributed][2] != 48 || a.Transforms[TransformRedistributed][3] != 48 {
+		t.Fatalf("test: adjusted wanted redistributed totals got:%v", a.Transforms[TransformRedistributed])
+	}
+	if a.Smooth != 2 || a.Transforms[TransformSmoothed][1] != 20 {
+		t.Fatalf("test: adjusted wanted smoothed:20 got:%v", a.Transforms[TransformSmoothed])
+	}
+
+	// Per capita values need a population, and only apply to deaths and confirmed
+	if _, err = s.AdjustedValues(MetricDeaths, TransformPerCapita, 0); err != nil {
+		t.Fatalf("test: adjusted per capita error:%s", err)
+	}
+	if _, err = s.AdjustedValues(MetricIncidence14, TransformPerCapita, 0); err == nil {
+		t.Fatalf("test: adjusted wanted error for per capita incidence")
+	}
+
+	if _, err = ParseTransforms("raw,nope"); err == nil {
+		t.Fatalf("test: parse transforms wanted error for unknown transform")
+	}
+	if v, err := ParseTransforms(""); err != nil || len(v) != 1 || v[0] != TransformRaw {
+		t.Fatalf("test: parse transforms wanted raw got:%v", v)
+	}
+}
@@ -0,0 +1,71 @@
+package covid
+
+// sum adds the deaths and confirmed values of each series in list to s, then calculates daily values once
+// this is the fast path for aggregates such as global and regional totals, which are the most requested
+// if s is empty it is sized to the longest series, series longer than s or with inconsistent lengths are skipped
+func (s *Series) sum(list SeriesSlice) {
+	if len(s.Deaths) == 0 {
+		days := 0
+		for _, series := range list {
+			if len(series.Deaths) > days {
+				days = len(series.Deaths)
+			}
+		}
+		s.Deaths = make([]int, days)
+		s.Confirmed = make([]int, days)
+	}
+	days := len(s.Deaths)
+	for _, series := range list {
+		if len(series.Deaths) > days || len(series.Confirmed) != len(series.Deaths) {
+			continue
+		}
+		addInts(s.Deaths, series.Deaths)
+		addInts(s.Confirmed, series.Confirmed)
+		if !series.UpdatedAt.IsZero() && series.UpdatedAt.After(s.UpdatedAt) {
+			s.UpdatedAt = series.UpdatedAt
+		}
+		s.mergeUpdates(series)
+	}
+	s.DeathsDaily = dailyInts(s.DeathsDaily, s.Deaths)
+	s.ConfirmedDaily = dailyInts(s.ConfirmedDaily, s.Confirmed)
+}
+
+// addInts adds each value in src to the value at the same index in dst
+// the loop is unrolled in chunks of 8, which lets the compiler drop bounds checks and pipeline the adds
+func addInts(dst, src []int) {
+	n := len(src)
+	if len(dst) < n {
+		n = len(dst)
+	}
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		d := dst[i : i+8 : i+8]
+		v := src[i : i+8 : i+8]
+		d[0] += v[0]
+		d[1] += v[1]
+		d[2] += v[2]
+		d[3] += v[3]
+		d[4] += v[4]
+		d[5] += v[5]
+		d[6] += v[6]
+		d[7] += v[7]
+	}
+	for ; i < n; i++ {
+		dst[i] += src[i]
+	}
+}
+
+// dailyInts sets dst to the daily changes in cumulative values, reusing dst if it has room
+// the first day is the first value
+func dailyInts(dst, values []int) []int {
+	if cap(dst) < len(values) {
+		dst = make([]int, len(values))
+	}
+	dst = dst[:len(values)]
+	previous := 0
+	for i, v := range values {
+		dst[i] = v - previous
+		previous = v
+	}
+	return dst
+}
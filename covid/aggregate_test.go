@@ -0,0 +1,30 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSum(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	var list SeriesSlice
+	for i := 1; i <= 3; i++ {
+		s := &Series{StartsAt: start, Deaths: make([]int, 11), Confirmed: make([]int, 11)}
+		for d := range s.Deaths {
+			s.Deaths[d] = d * i
+			s.Confirmed[d] = d * i * 10
+		}
+		list = append(list, s)
+	}
+	// Series with inconsistent lengths are skipped
+	list = append(list, &Series{Deaths: make([]int, 11), Confirmed: make([]int, 3)})
+
+	global := &Series{}
+	global.sum(list)
+	if len(global.Deaths) != 11 || global.Deaths[10] != 60 || global.Confirmed[9] != 540 {
+		t.Fatalf("test: sum wanted deaths:60 confirmed:540 got:%v %v", global.Deaths, global.Confirmed)
+	}
+	if global.DeathsDaily[0] != 0 || global.DeathsDaily[10] != 6 || global.ConfirmedDaily[10] != 60 {
+		t.Fatalf("test: sum wanted daily deaths:6 got:%v", global.DeathsDaily)
+	}
+}
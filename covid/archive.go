@@ -0,0 +1,90 @@
+package covid
+
+import (
+	"log"
+	"time"
+)
+
+// archiveDate is the last day of data served in archive mode, zero if not archived
+var archiveDate time.Time
+
+// SetArchiveDate freezes our data at the end of the given date, for archival deployments
+// or reproducing analyses, data is truncated after this date on load and never refreshed
+// this must be called before ScheduleDataFetch and LoadData
+func SetArchiveDate(date time.Time) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	archiveDate = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ArchiveDate returns the date our data is frozen at, zero if not archived
+func ArchiveDate() time.Time {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return archiveDate
+}
+
+// Archived returns true if our data is frozen at an archive date
+func Archived() bool {
+	return !ArchiveDate().IsZero()
+}
+
+// Until truncates this series in place so that the last day is date
+// series which start after date are left empty
+func (s *Series) Until(date time.Time) {
+	n := daysBetween(s.StartsAt, date) + 1
+	if n < 0 {
+		n = 0
+	}
+	if n >= len(s.Deaths) && n >= len(s.Confirmed) {
+		return
+	}
+
+	s.Deaths = truncateInts(s.Deaths, n)
+	s.Confirmed = truncateInts(s.Confirmed, n)
+	s.Tests = truncateInts(s.Tests, n)
+	for name, values := range s.Auxiliary {
+		if len(values) > n {
+			s.Auxiliary[name] = values[:n]
+		}
+	}
+	for _, st := range s.Strata {
+		st.Deaths = truncateInts(st.Deaths, n)
+		st.Confirmed = truncateInts(st.Confirmed, n)
+	}
+	s.UpdateDaily()
+
+	// The data was last updated at the end of the archive date at the latest
+	end := date.AddDate(0, 0, 1)
+	if s.UpdatedAt.After(end) {
+		s.UpdatedAt = end
+	}
+	for _, u := range s.Updates {
+		if u.UpdatedAt.After(end) {
+			s.setUpdate(u.Metric, u.Source, end)
+		}
+	}
+	if s.StaleSince.After(end) {
+		s.StaleSince = time.Time{}
+	}
+}
+
+// truncateInts returns the first n values of ints
+func truncateInts(ints []int, n int) []int {
+	if len(ints) > n {
+		return ints[:n]
+	}
+	return ints
+}
+
+// archive truncates all series to the archive date if we are archived
+// the caller must hold the lock
+func (slice SeriesSlice) archive() {
+	if archiveDate.IsZero() {
+		return
+	}
+	for _, s := range slice {
+		s.Until(archiveDate)
+	}
+	log.Printf("data: archived data at %s", archiveDate.Format("2006-01-02"))
+}
@@ -0,0 +1,37 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUntil(t *testing.T) {
+	s := &Series{
+		UpdatedAt: time.Date(2020, 3, 10, 4, 0, 0, 0, time.UTC),
+		StartsAt:  time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths:    []int{0, 1, 2, 3, 4},
+		Confirmed: []int{10, 20, 30, 40, 50},
+		Tests:     []int{100, 200, 300, 400, 500},
+		Auxiliary: map[string][]float64{"stringency": {1, 2, 3, 4, 5}},
+		Strata:    []*Stratum{{Dimension: "sex", Group: "male", Deaths: []int{0, 1, 1, 2, 2}, Confirmed: []int{5, 10, 15, 20, 25}}},
+	}
+	s.UpdateDaily()
+
+	date := time.Date(2020, 3, 3, 0, 0, 0, 0, time.UTC)
+	s.Until(date)
+	if len(s.Deaths) != 3 || len(s.ConfirmedDaily) != 3 || s.TotalConfirmed() != 30 {
+		t.Fatalf("test: until wanted:3 days got:%v", s.Confirmed)
+	}
+	if len(s.Tests) != 3 || len(s.Auxiliary["stringency"]) != 3 || len(s.Strata[0].Deaths) != 3 {
+		t.Fatalf("test: until wanted:3 days of tests auxiliary and strata")
+	}
+	if !s.UpdatedAt.Equal(date.AddDate(0, 0, 1)) {
+		t.Fatalf("test: until wanted updated:%s got:%s", date.AddDate(0, 0, 1), s.UpdatedAt)
+	}
+
+	// Series starting after the date are left empty
+	s.Until(date.AddDate(0, 0, -5))
+	if len(s.Deaths) != 0 || len(s.Confirmed) != 0 {
+		t.Fatalf("test: until wanted empty got:%v", s.Confirmed)
+	}
+}
@@ -0,0 +1,313 @@
+package covid
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Names of auxiliary series
+// mobility series are percentage changes from a pre-pandemic baseline
+const (
+	AuxiliaryStringency = "stringency"
+
+	AuxiliaryMobilityRetail      = "mobility_retail"
+	AuxiliaryMobilityGrocery     = "mobility_grocery"
+	AuxiliaryMobilityParks       = "mobility_parks"
+	AuxiliaryMobilityTransit     = "mobility_transit"
+	AuxiliaryMobilityWorkplaces  = "mobility_workplaces"
+	AuxiliaryMobilityResidential = "mobility_residential"
+
+	AuxiliaryAppleDriving = "apple_driving"
+	AuxiliaryAppleWalking = "apple_walking"
+	AuxiliaryAppleTransit = "apple_transit"
+)
+
+// AuxiliaryValues returns the values for the named auxiliary series, or nil if we have none
+func (s *Series) AuxiliaryValues(name string) []float64 {
+	return s.Auxiliary[name]
+}
+
+// SetAuxiliaryValues sets the values for the named auxiliary series
+func (s *Series) SetAuxiliaryValues(name string, values []float64) {
+	if s.Auxiliary == nil {
+		s.Auxiliary = make(map[string][]float64)
+	}
+	s.Auxiliary[name] = values
+}
+
+// setAuxiliary sets the value for the named auxiliary series on date, ignoring dates outside the series
+// days which are not set are filled by fillAuxiliary after loading
+func (s *Series) setAuxiliary(name string, date time.Time, value float64) {
+	day := daysBetween(s.StartsAt, date)
+	if day < 0 || day >= len(s.Deaths) {
+		return
+	}
+	values := s.Auxiliary[name]
+	if values == nil {
+		values = make([]float64, len(s.Deaths))
+		for i := range values {
+			values[i] = math.NaN()
+		}
+		s.SetAuxiliaryValues(name, values)
+	}
+	values[day] = value
+}
+
+// fillAuxiliary fills days missing from auxiliary series with the value from the day before
+// days before the first value are set to 0
+func (slice SeriesSlice) fillAuxiliary() {
+	for _, s := range slice {
+		for _, values := range s.Auxiliary {
+			previous := 0.0
+			for i, v := range values {
+				if math.IsNaN(v) {
+					values[i] = previous
+				} else {
+					previous = v
+				}
+			}
+		}
+	}
+}
+
+// roundValues returns values rounded to the nearest int
+func roundValues(values []float64) []int {
+	rounded := make([]int, len(values))
+	for i, v := range values {
+		rounded[i] = int(math.Round(v))
+	}
+	return rounded
+}
+
+// seriesForISO returns the country series with the given ISO 3166-1 alpha-2 or alpha-3 code
+func (slice SeriesSlice) seriesForISO(code string) *Series {
+	for _, s := range slice {
+		if s.Province != "" || s.Country == "" {
+			continue
+		}
+		if c := s.Meta(); c != nil && (c.ISO2 == code || c.ISO3 == code) {
+			return s
+		}
+	}
+	return nil
+}
+
+// csvColumns returns the index of each named column in a csv header row
+func csvColumns(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	return columns
+}
+
+// mergeStringencyCSV merges the Oxford COVID-19 Government Response Tracker stringency index
+// into country series, matching countries by their ISO code as names differ from our dataset
+func (slice SeriesSlice) mergeStringencyCSV(records [][]string) (SeriesSlice, error) {
+	if len(records) == 0 {
+		return slice, fmt.Errorf("load: empty stringency csv")
+	}
+
+	// Find the columns we need, as the file has many columns which change over time
+	columns := csvColumns(records[0])
+	code, okCode := columns["CountryCode"]
+	region, okRegion := columns["RegionName"]
+	date, okDate := columns["Date"]
+	index, okIndex := columns["StringencyIndex"]
+	if !okIndex {
+		index, okIndex = columns["StringencyIndex_Average"]
+	}
+	if !okCode || !okRegion || !okDate || !okIndex {
+		return slice, fmt.Errorf("load: invalid stringency csv header")
+	}
+
+	// Cache series by code as the file has one row per country per day
+	series := make(map[string]*Series)
+	for i, row := range records[1:] {
+		if len(row) != len(records[0]) {
+			return slice, fmt.Errorf("load: invalid stringency csv row:%d", i+1)
+		}
+		// Use national figures only, and skip days with no index
+		if row[region] != "" || row[index] == "" {
+			continue
+		}
+
+		s, ok := series[row[code]]
+		if !ok {
+			s = slice.seriesForISO(row[code])
+			series[row[code]] = s
+		}
+		if s == nil {
+			continue
+		}
+
+		d, err := time.Parse("20060102", row[date])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid stringency date row:%d:%s", i+1, err)
+		}
+		v, err := strconv.ParseFloat(row[index], 64)
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid stringency index row:%d:%s", i+1, err)
+		}
+		s.setAuxiliary(AuxiliaryStringency, d, v)
+	}
+
+	return slice, nil
+}
+
+// googleMobilityColumns maps columns in the Google Community Mobility Report to auxiliary series
+var googleMobilityColumns = map[string]string{
+	"retail_and_recreation_percent_change_from_baseline": AuxiliaryMobilityRetail,
+	"grocery_and_pharmacy_percent_change_from_baseline":  AuxiliaryMobilityGrocery,
+	"parks_percent_change_from_baseline":                 AuxiliaryMobilityParks,
+	"transit_stations_percent_change_from_baseline":      AuxiliaryMobilityTransit,
+	"workplaces_percent_change_from_baseline":            AuxiliaryMobilityWorkplaces,
+	"residential_percent_change_from_baseline":           AuxiliaryMobilityResidential,
+}
+
+// mergeGoogleMobilityCSV merges national figures from the Google Community Mobility Report into country series
+func (slice SeriesSlice) mergeGoogleMobilityCSV(records [][]string) (SeriesSlice, error) {
+	if len(records) == 0 {
+		return slice, fmt.Errorf("load: empty mobility csv")
+	}
+
+	columns := csvColumns(records[0])
+	code, okCode := columns["country_region_code"]
+	region, okRegion := columns["sub_region_1"]
+	date, okDate := columns["date"]
+	metro, okMetro := columns["metro_area"]
+	if !okCode || !okRegion || !okDate {
+		return slice, fmt.Errorf("load: invalid mobility csv header")
+	}
+
+	series := make(map[string]*Series)
+	for i, row := range records[1:] {
+		if len(row) != len(records[0]) {
+			return slice, fmt.Errorf("load: invalid mobility csv row:%d", i+1)
+		}
+		// Use national figures only, which have no sub region or metro area
+		if row[region] != "" || (okMetro && row[metro] != "") {
+			continue
+		}
+
+		s, ok := series[row[code]]
+		if !ok {
+			s = slice.seriesForISO(row[code])
+			series[row[code]] = s
+		}
+		if s == nil {
+			continue
+		}
+
+		d, err := time.Parse("2006-01-02", row[date])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid mobility date row:%d:%s", i+1, err)
+		}
+		for column, name := range googleMobilityColumns {
+			c, ok := columns[column]
+			if !ok || row[c] == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(row[c], 64)
+			if err != nil {
+				return slice, fmt.Errorf("load: invalid mobility value row:%d:%s", i+1, err)
+			}
+			s.setAuxiliary(name, d, v)
+		}
+	}
+
+	return slice, nil
+}
+
+// appleMobilityTypes maps transportation types in the Apple Mobility Trends to auxiliary series
+var appleMobilityTypes = map[string]string{
+	"driving": AuxiliaryAppleDriving,
+	"walking": AuxiliaryAppleWalking,
+	"transit": AuxiliaryAppleTransit,
+}
+
+// countryAliases maps country names used by other sources to the names used in our dataset where they differ
+var countryAliases = map[string]string{
+	"United States":     "US",
+	"UK":                "United Kingdom",
+	"Republic of Korea": "Korea, South",
+	"South Korea":       "Korea, South",
+	"Czech Republic":    "Czechia",
+	"Taiwan":            "Taiwan*",
+}
+
+// seriesForName returns the country series for a name used by another source, or nil if none is found
+func (slice SeriesSlice) seriesForName(name string) *Series {
+	if n, ok := countryAliases[name]; ok {
+		name = n
+	}
+	s, err := slice.FetchSeries(name, "")
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// mergeAppleMobilityCSV merges national figures from the Apple Mobility Trends into country series
+// Apple reports an index with a baseline of 100, which is stored as a percentage change to match Google
+func (slice SeriesSlice) mergeAppleMobilityCSV(records [][]string) (SeriesSlice, error) {
+	if len(records) == 0 {
+		return slice, fmt.Errorf("load: empty mobility csv")
+	}
+
+	columns := csvColumns(records[0])
+	geo, okGeo := columns["geo_type"]
+	region, okRegion := columns["region"]
+	kind, okKind := columns["transportation_type"]
+	if !okGeo || !okRegion || !okKind {
+		return slice, fmt.Errorf("load: invalid mobility csv header")
+	}
+
+	// Dates are columns following the descriptive columns
+	var dates []time.Time
+	first := -1
+	for i, name := range records[0] {
+		d, err := time.Parse("2006-01-02", name)
+		if err != nil {
+			continue
+		}
+		if first == -1 {
+			first = i
+		}
+		dates = append(dates, d)
+	}
+	if first == -1 {
+		return slice, fmt.Errorf("load: no dates in mobility csv header")
+	}
+
+	for i, row := range records[1:] {
+		if len(row) != len(records[0]) {
+			return slice, fmt.Errorf("load: invalid mobility csv row:%d", i+1)
+		}
+		name, ok := appleMobilityTypes[row[kind]]
+		if row[geo] != "country/region" || !ok {
+			continue
+		}
+
+		s := slice.seriesForName(row[region])
+		if s == nil {
+			continue
+		}
+
+		for j, d := range dates {
+			value := row[first+j]
+			if value == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return slice, fmt.Errorf("load: invalid mobility value row:%d:%s", i+1, err)
+			}
+			s.setAuxiliary(name, d, v-100)
+		}
+	}
+
+	return slice, nil
+}
@@ -0,0 +1,149 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStringency(t *testing.T) {
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "US", StartsAt: start, Deaths: make([]int, 4), Confirmed: make([]int, 4)},
+		{Country: "US", Province: "New York", StartsAt: start, Deaths: make([]int, 4), Confirmed: make([]int, 4)},
+	}
+	records := [][]string{
+		{"CountryName", "CountryCode", "RegionName", "Date", "StringencyIndex"},
+		{"United States", "USA", "", "20200302", "11.11"},
+		{"United States", "USA", "New York", "20200303", "50"},
+		{"United States", "USA", "", "20200304", "72.5"},
+		{"Unknown", "XXX", "", "20200304", "10"},
+	}
+	slice, err := slice.MergeCSV(records, DataStringency)
+	if err != nil {
+		t.Fatalf("test: merge stringency error:%s", err)
+	}
+	slice.fillAuxiliary()
+
+	// Regional rows are ignored and missing days carried forward
+	values := slice[0].AuxiliaryValues(AuxiliaryStringency)
+	wanted := []float64{0, 11.11, 11.11, 72.5}
+	for i, v := range wanted {
+		if len(values) != len(wanted) || values[i] != v {
+			t.Fatalf("test: stringency wrong wanted:%v got:%v", wanted, values)
+		}
+	}
+	if slice[1].AuxiliaryValues(AuxiliaryStringency) != nil {
+		t.Fatalf("test: stringency set for province")
+	}
+
+	chart := ChartData([]*Series{slice[0]}, ChartSettings{Datum: DataConfirmed, Overlay: AuxiliaryStringency})
+	if len(chart.Data.Datasets) != 2 || chart.Data.Datasets[1].Data[3] != 73 {
+		t.Fatalf("test: chart overlay wrong:%v", chart.Data.Datasets)
+	}
+}
+
+func TestMobility(t *testing.T) {
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "United Kingdom", StartsAt: start, Deaths: make([]int, 3), Confirmed: make([]int, 3)},
+	}
+	google := [][]string{
+		{"country_region_code", "country_region", "sub_region_1", "sub_region_2", "metro_area", "date", "retail_and_recreation_percent_change_from_baseline", "residential_percent_change_from_baseline"},
+		{"GB", "United Kingdom", "", "", "", "2020-03-02", "-10", "5"},
+		{"GB", "United Kingdom", "Greater London", "", "", "2020-03-02", "-50", "20"},
+	}
+	apple := [][]string{
+		{"geo_type", "region", "transportation_type", "alternative_name", "2020-03-01", "2020-03-02"},
+		{"country/region", "UK", "driving", "", "100", "62.5"},
+		{"city", "London", "driving", "", "100", "10"},
+	}
+	slice, err := slice.MergeCSV(google, DataGoogleMobility)
+	if err != nil {
+		t.Fatalf("test: merge google mobility error:%s", err)
+	}
+	slice, err = slice.MergeCSV(apple, DataAppleMobility)
+	if err != nil {
+		t.Fatalf("test: merge apple mobility error:%s", err)
+	}
+	slice.fillAuxiliary()
+
+	retail, err := slice[0].MetricValues(AuxiliaryMobilityRetail)
+	if err != nil || retail[1] != -10 || retail[2] != -10 {
+		t.Fatalf("test: google mobility wrong:%v %v", retail, err)
+	}
+	driving, err := slice[0].MetricValues(AuxiliaryAppleDriving)
+	if err != nil || driving[0] != 0 || driving[1] != -38 {
+		t.Fatalf("test: apple mobility wrong:%v %v", driving, err)
+	}
+}
+
+func TestHospital(t *testing.T) {
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: make([]int, 2), Confirmed: make([]int, 2)},
+		{Country: "Spain", StartsAt: start, Deaths: make([]int, 2), Confirmed: make([]int, 2)},
+	}
+	owid := [][]string{
+		{"entity", "iso_code", "date", "indicator", "value"},
+		{"Italy", "ITA", "2020-03-02", "Daily ICU occupancy", "500"},
+		{"Italy", "ITA", "2020-03-02", "Daily ICU occupancy per million", "8.3"},
+	}
+	ecdc := [][]string{
+		{"country", "indicator", "date", "year_week", "value"},
+		{"Spain", "Daily hospital occupancy", "2020-03-01", "2020-W09", "1200"},
+	}
+	slice, err := slice.MergeCSV(owid, DataHospital)
+	if err != nil {
+		t.Fatalf("test: merge owid hospital error:%s", err)
+	}
+	slice, err = slice.MergeCSV(ecdc, DataHospital)
+	if err != nil {
+		t.Fatalf("test: merge ecdc hospital error:%s", err)
+	}
+	slice.fillAuxiliary()
+
+	if p := LatestCapacityPercent(slice[0].ICUOccupancy(), 1000); p != 50 {
+		t.Fatalf("test: icu capacity wanted:%d got:%f", 50, p)
+	}
+	if h := slice[1].HospitalOccupancy(); len(h) != 2 || h[1] != 1200 {
+		t.Fatalf("test: hospital occupancy wrong:%v", h)
+	}
+}
+
+func TestVariants(t *testing.T) {
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "US", StartsAt: start, Deaths: make([]int, 3), Confirmed: []int{100, 300, 700}},
+	}
+	slice[0].UpdateDaily()
+	records := [][]string{
+		{"location", "date", "variant", "num_sequences", "perc_sequences"},
+		{"United States", "2020-03-02", "Alpha", "30", "75"},
+		{"United States", "2020-03-02", "Delta", "10", "25"},
+		{"United States", "2020-03-03", "Delta", "30", "75"},
+		{"United States", "2020-03-03", "Alpha", "10", "25"},
+	}
+	slice, err := slice.MergeCSV(records, DataVariants)
+	if err != nil {
+		t.Fatalf("test: merge variants error:%s", err)
+	}
+	slice.fillAuxiliary()
+
+	s := slice[0]
+	dominant := s.DominantVariants()
+	if len(s.Variants()) != 2 || dominant[0] != "" || dominant[1] != "Alpha" || dominant[2] != "Delta" {
+		t.Fatalf("test: dominant variants wrong:%v", dominant)
+	}
+	if cases := s.VariantCases(); cases["Delta"][2] != 300 || cases["Alpha"][1] != 150 {
+		t.Fatalf("test: variant cases wrong:%v", cases)
+	}
+
+	chart := ChartData([]*Series{s}, ChartSettings{Datum: DataConfirmed, Daily: true, Variants: true})
+	if len(chart.Data.Datasets) != 2 || chart.Data.Datasets[0].Label != "Alpha" {
+		t.Fatalf("test: variant chart wrong:%v", chart.Data.Datasets)
+	}
+}
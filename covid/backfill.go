@@ -0,0 +1,179 @@
+package covid
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dailyReportsURL is where JHU CSSE publish a report of totals for each day, named MM-DD-YYYY.csv
+const dailyReportsURL = "https://raw.githubusercontent.com/CSSEGISandData/COVID-19/master/csse_covid_19_data/csse_covid_19_daily_reports/"
+
+// dailyReportName returns the file name of the daily report for date e.g. 03-24-2020.csv
+func dailyReportName(date time.Time) string {
+	return date.Format("01-02-2006") + ".csv"
+}
+
+// timeSeriesDays returns the days of data in the global series, which is the length of the time series
+// until daily data is merged
+func (slice SeriesSlice) timeSeriesDays() int {
+	global, err := slice.FetchSeries("", "")
+	if err != nil {
+		return 0
+	}
+	return len(global.Deaths)
+}
+
+// backfillGaps fills the days missed between the end of the time series (from days) and today
+// with values from the daily report for each day in our data path, replacing values carried forward by daily data
+// it returns the dates filled and the dates with no report, the caller must hold the lock
+func (slice SeriesSlice) backfillGaps(from int) (filled, missing []string) {
+	global, err := slice.FetchSeries("", "")
+	if err != nil || from == 0 {
+		return nil, nil
+	}
+	to := daysBetween(global.StartsAt, Today())
+	if to > from+maxDayGap {
+		to = from + maxDayGap
+	}
+	for day := from; day < to; day++ {
+		date := global.StartsAt.AddDate(0, 0, day)
+		name := dailyReportName(date)
+		records, err := readCSV(filepath.Join(dataPath, name))
+		if err == nil {
+			err = slice.mergeDailyReport(records, day)
+		}
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("load: error filling gap from daily report:%s error:%s", name, err)
+			}
+			missing = append(missing, date.Format("2006-01-02"))
+			continue
+		}
+		log.Printf("load: filled gap from daily report:%s", name)
+		filled = append(filled, date.Format("2006-01-02"))
+	}
+	return filled, missing
+}
+
+// readCSV reads all records from the csv file at path
+func readCSV(path string) ([][]string, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return csv.NewReader(r).ReadAll()
+}
+
+// mergeDailyReport sets the values at day for existing series from a daily report
+// country totals are the rows without a province if there are any, as for the time series, otherwise all rows
+// columns are found by name, as the format of daily reports has changed over time
+func (slice SeriesSlice) mergeDailyReport(records [][]string, day int) error {
+	if len(records) < 2 {
+		return fmt.Errorf("load: empty daily report")
+	}
+	cols := make(map[string]int)
+	for i, name := range records[0] {
+		cols[strings.TrimPrefix(name, "\ufeff")] = i
+	}
+	column := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := cols[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	country := column("Country_Region", "Country/Region")
+	province := column("Province_State", "Province/State")
+	confirmed := column("Confirmed")
+	deaths := column("Deaths")
+	if country < 0 || confirmed < 0 || deaths < 0 {
+		return fmt.Errorf("load: daily report format invalid")
+	}
+
+	type total struct{ confirmed, deaths int }
+	totals := make(map[[2]string]*total)
+	whole := make(map[string]bool)
+	for _, row := range records[1:] {
+		if len(row) <= country || len(row) <= confirmed || len(row) <= deaths {
+			continue
+		}
+		p := ""
+		if province >= 0 && province < len(row) {
+			p = row[province]
+		}
+		c, p := canonicalName(row[country], p)
+		var t total
+		var err error
+		if row[confirmed] != "" {
+			if t.confirmed, err = strconv.Atoi(row[confirmed]); err != nil {
+				continue
+			}
+		}
+		if row[deaths] != "" {
+			if t.deaths, err = strconv.Atoi(row[deaths]); err != nil {
+				continue
+			}
+		}
+
+		// Sum counties into provinces, and provinces into countries until we see a row for the whole country
+		keys := [][2]string{{c, p}}
+		if p != "" && !whole[c] {
+			keys = append(keys, [2]string{c, ""})
+		} else if p == "" && !whole[c] {
+			whole[c] = true
+			totals[[2]string{c, ""}] = &total{}
+		}
+		for _, k := range keys {
+			if totals[k] == nil {
+				totals[k] = &total{}
+			}
+			totals[k].confirmed += t.confirmed
+			totals[k].deaths += t.deaths
+		}
+	}
+
+	for k, t := range totals {
+		s, err := slice.FetchSeries(k[0], k[1])
+		if err != nil || s.Global() {
+			continue
+		}
+		err = s.AddDayData(day, s.UpdatedAt, t.confirmed, t.deaths)
+		if err != nil {
+			continue
+		}
+		s.UpdateDaily()
+	}
+	return nil
+}
+
+// FetchGaps downloads the daily reports for days missing from the time series at the last load,
+// and returns the number downloaded, data should be loaded again to fill the gaps if any were
+func FetchGaps() int {
+	mutex.RLock()
+	gaps := append([]string(nil), report.Gaps...)
+	mutex.RUnlock()
+
+	downloaded := 0
+	for _, d := range gaps {
+		date, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			continue
+		}
+		// Reports are only published once the day is over, so skip those which are not found yet
+		n, err := DownloadFiles([]string{dailyReportsURL + dailyReportName(date)}, dataPath)
+		if err != nil {
+			log.Printf("schedule: no daily report for gap:%s error:%s", d, err)
+			continue
+		}
+		downloaded += n
+	}
+	return downloaded
+}
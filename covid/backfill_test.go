@@ -0,0 +1,64 @@
+package covid
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackfillGaps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backfill")
+	if err != nil {
+		t.Fatalf("test: temp dir error:%s", err)
+	}
+	defer os.RemoveAll(dir)
+	previous := dataPath
+	dataPath = dir
+	defer func() { dataPath = previous }()
+
+	// The time series ends three days before today, and daily data for today carried the last values forward
+	today := Today()
+	start := today.AddDate(0, 0, -4)
+	us := &Series{Country: "US", StartsAt: start, Deaths: []int{1, 1, 1, 1, 9}, Confirmed: []int{10, 10, 10, 10, 90}}
+	ny := &Series{Country: "US", Province: "New York", StartsAt: start, Deaths: []int{1, 1, 1, 1, 7}, Confirmed: []int{10, 10, 10, 10, 70}, excludeGlobal: true}
+	uk := &Series{Country: "United Kingdom", StartsAt: start, Deaths: []int{2, 2}, Confirmed: []int{20, 20}}
+	global := &Series{StartsAt: start, Deaths: []int{3, 3}, Confirmed: []int{30, 30}}
+	slice := SeriesSlice{us, ny, uk, global}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	report := "FIPS,Admin2,Province_State,Country_Region,Last_Update,Lat,Long_,Confirmed,Deaths,Recovered,Active,Combined_Key\n" +
+		"36061,New York City,New York,US,2020-03-24 23:37:31,0,0,30,3,0,0,\"New York City, New York, US\"\n" +
+		"36059,Nassau,New York,US,2020-03-24 23:37:31,0,0,20,1,0,0,\"Nassau, New York, US\"\n" +
+		"53033,King,Washington,US,2020-03-24 23:37:31,0,0,5,1,0,0,\"King, Washington, US\"\n" +
+		",,Bermuda,United Kingdom,2020-03-24 23:37:31,0,0,100,10,0,0,\"Bermuda, United Kingdom\"\n" +
+		",,,United Kingdom,2020-03-24 23:37:31,0,0,40,4,0,0,United Kingdom\n"
+	name := dailyReportName(start.AddDate(0, 0, 2))
+	err = ioutil.WriteFile(filepath.Join(dir, name), []byte(report), 0644)
+	if err != nil {
+		t.Fatalf("test: write report error:%s", err)
+	}
+
+	filled, missing := slice.backfillGaps(2)
+	if len(filled) != 1 || len(missing) != 1 || missing[0] != start.AddDate(0, 0, 3).Format("2006-01-02") {
+		t.Fatalf("test: backfill wanted 1 filled 1 missing got:%v %v", filled, missing)
+	}
+	if ny.Confirmed[2] != 50 || ny.Deaths[2] != 4 || ny.Confirmed[3] != 10 {
+		t.Fatalf("test: backfill wanted counties summed got:%v", ny.Confirmed)
+	}
+	if us.Confirmed[2] != 55 || us.ConfirmedDaily[2] != 45 {
+		t.Fatalf("test: backfill wanted states summed got:%v", us.Confirmed)
+	}
+	// Territories are not added to the country, as in the time series
+	if len(uk.Confirmed) != 3 || uk.Confirmed[2] != 40 {
+		t.Fatalf("test: backfill wanted mainland only got:%v", uk.Confirmed)
+	}
+
+	// Global gains a day for each day filled and today, from series with data for those days
+	updateGlobal(slice)
+	if len(global.Deaths) != 5 || global.Confirmed[2] != 95 || global.Confirmed[4] != 90 {
+		t.Fatalf("test: update global wanted filled days got:%v", global.Confirmed)
+	}
+}
@@ -0,0 +1,151 @@
+package covid
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// backupManifest records what a backup contains, stored as manifest.json in the archive
+type backupManifest struct {
+	CreatedAt   time.Time `json:"created_at"`
+	Version     int       `json:"version"`
+	ArchiveDate string    `json:"archive_date,omitempty"`
+}
+
+// Names of the files stored in a backup archive
+const (
+	backupManifestFile = "manifest.json"
+	backupDataFile     = "covid.parquet"
+	backupTagsFile     = "tags.json"
+	backupChecksFile   = "file_checks.json"
+	backupChangesFile  = "changes.json"
+)
+
+// WriteBackup writes a gzipped tar archive of our stored data, tags, file checks,
+// dataset version and recent changes to w, which can be restored with RestoreBackup
+// the data is stored in our parquet export format so auxiliary data is not included
+func WriteBackup(w io.Writer) error {
+	checksumMutex.Lock()
+	checks, err := json.Marshal(fileChecks)
+	checksumMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("backup: error writing file checks:%s", err)
+	}
+
+	mutex.RLock()
+	manifest := backupManifest{CreatedAt: time.Now().UTC(), Version: version}
+	if !archiveDate.IsZero() {
+		manifest.ArchiveDate = archiveDate.Format("2006-01-02")
+	}
+	files := make(map[string][]byte)
+	b := &bytes.Buffer{}
+	err = data.WriteParquet(b)
+	if err == nil {
+		files[backupDataFile] = b.Bytes()
+		files[backupTagsFile], err = json.Marshal(tags)
+	}
+	if err == nil {
+		files[backupChangesFile], err = json.Marshal(changes)
+	}
+	mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("backup: error writing data:%s", err)
+	}
+	files[backupChecksFile] = checks
+	files[backupManifestFile], err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("backup: error writing manifest:%s", err)
+	}
+
+	z := gzip.NewWriter(w)
+	t := tar.NewWriter(z)
+	for _, name := range []string{backupManifestFile, backupDataFile, backupTagsFile, backupChecksFile, backupChangesFile} {
+		err = t.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), ModTime: manifest.CreatedAt})
+		if err != nil {
+			return fmt.Errorf("backup: error writing %s:%s", name, err)
+		}
+		_, err = t.Write(files[name])
+		if err != nil {
+			return fmt.Errorf("backup: error writing %s:%s", name, err)
+		}
+	}
+	err = t.Close()
+	if err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// RestoreBackup replaces our stored data, tags, file checks, archive date and dataset version
+// with those from a backup archive written by WriteBackup
+func RestoreBackup(r io.Reader) error {
+	z, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("backup: invalid archive:%s", err)
+	}
+	files := make(map[string][]byte)
+	t := tar.NewReader(z)
+	for {
+		h, err := t.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("backup: invalid archive:%s", err)
+		}
+		files[h.Name], err = ioutil.ReadAll(t)
+		if err != nil {
+			return fmt.Errorf("backup: error reading %s:%s", h.Name, err)
+		}
+	}
+
+	var manifest backupManifest
+	var archived time.Time
+	var restoredTags map[string][]string
+	var restoredChecks map[string]FileCheck
+	var restoredChanges []Change
+	for name, v := range map[string]interface{}{
+		backupManifestFile: &manifest,
+		backupTagsFile:     &restoredTags,
+		backupChecksFile:   &restoredChecks,
+		backupChangesFile:  &restoredChanges,
+	} {
+		b, ok := files[name]
+		if !ok {
+			return fmt.Errorf("backup: missing %s", name)
+		}
+		err = json.Unmarshal(b, v)
+		if err != nil {
+			return fmt.Errorf("backup: error reading %s:%s", name, err)
+		}
+	}
+	if manifest.ArchiveDate != "" {
+		archived, err = time.Parse("2006-01-02", manifest.ArchiveDate)
+		if err != nil {
+			return fmt.Errorf("backup: invalid archive date:%s", err)
+		}
+	}
+	loaded, err := ReadParquet(bytes.NewReader(files[backupDataFile]))
+	if err != nil {
+		return fmt.Errorf("backup: error reading data:%s", err)
+	}
+
+	for name, check := range restoredChecks {
+		SetFileCheck(name, check)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	tags = restoredTags
+	archiveDate = archived
+	replaceData(loaded)
+	version = manifest.Version
+	changes = restoredChanges
+	return nil
+}
@@ -0,0 +1,53 @@
+package covid
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBackup(t *testing.T) {
+	mutex.Lock()
+	previousData, previousTags, previousVersion := data, tags, version
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	data = addGlobal(SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3}, Confirmed: []int{2, 5, 10}},
+	})
+	tags = map[string][]string{"EU": {"Italy"}}
+	version = 7
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data, tags, version = previousData, previousTags, previousVersion
+		mutex.Unlock()
+	}()
+
+	b := &bytes.Buffer{}
+	err := WriteBackup(b)
+	if err != nil {
+		t.Fatalf("test: backup error:%s", err)
+	}
+
+	mutex.Lock()
+	data, tags, version = nil, nil, 0
+	mutex.Unlock()
+
+	err = RestoreBackup(b)
+	if err != nil {
+		t.Fatalf("test: restore error:%s", err)
+	}
+	if Version() != 7 {
+		t.Fatalf("test: restore wanted version:7 got:%d", Version())
+	}
+	s, err := FetchSeries("Italy", "")
+	if err != nil {
+		t.Fatalf("test: restore missing series:%s", err)
+	}
+	if s.TotalDeaths() != 3 || !s.HasTag("EU") {
+		t.Fatalf("test: restore wanted deaths:3 tag:EU got:%d %v", s.TotalDeaths(), s.Tags)
+	}
+
+	if err := RestoreBackup(bytes.NewBufferString("invalid")); err == nil {
+		t.Fatalf("test: restore wanted error for invalid archive")
+	}
+}
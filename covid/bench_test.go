@@ -0,0 +1,132 @@
+package covid
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Benchmarks for the core paths on a realistic dataset of 4000 series over 500 days
+// run with go test ./covid -run none -bench . -benchmem
+//
+// Performance budgets on a typical server, set with headroom above current timings,
+// changes which take a benchmark over budget should be reconsidered:
+//
+//	BenchmarkMergeCSV     < 5s per load of the deaths and confirmed time series (currently ~3.6s)
+//	BenchmarkFetchSeries  < 500µs per lookup (currently ~370µs)
+//	BenchmarkSort         < 50ms per sort (currently ~36ms)
+//	BenchmarkChartData    < 20µs per chart for one series (currently ~2µs)
+//	BenchmarkAggregate    < 5ms per global total of all series (currently ~2ms, ~10ms with Merge as in BenchmarkAggregateMerge)
+const (
+	benchmarkSeries = 4000
+	benchmarkDays   = 500
+)
+
+// benchmarkRecords returns time series csv records for benchmarkSeries series, 200 countries with 20 provinces each
+func benchmarkRecords() [][]string {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	header := []string{"Province/State", "Country/Region", "Lat", "Long"}
+	for d := 0; d < benchmarkDays; d++ {
+		header = append(header, start.AddDate(0, 0, d).Format("1/2/06"))
+	}
+	records := [][]string{header}
+	for i := 0; i < benchmarkSeries; i++ {
+		record := []string{fmt.Sprintf("Province %d", i%20), fmt.Sprintf("Country %d", i/20), "0", "0"}
+		for d := 0; d < benchmarkDays; d++ {
+			record = append(record, fmt.Sprintf("%d", d*(i%97+1)))
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// benchmarkData returns a loaded dataset of benchmarkSeries series
+func benchmarkData(b *testing.B) SeriesSlice {
+	records := benchmarkRecords()
+	slice, err := SeriesSlice{}.MergeCSV(records, DataDeaths)
+	if err != nil {
+		b.Fatalf("bench: merge error:%s", err)
+	}
+	slice, err = slice.MergeCSV(records, DataConfirmed)
+	if err != nil {
+		b.Fatalf("bench: merge error:%s", err)
+	}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+	return slice
+}
+
+func BenchmarkMergeCSV(b *testing.B) {
+	defer func() { report = &LoadReport{} }()
+	records := benchmarkRecords()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		slice, err := SeriesSlice{}.MergeCSV(records, DataDeaths)
+		if err != nil {
+			b.Fatalf("bench: merge error:%s", err)
+		}
+		_, err = slice.MergeCSV(records, DataConfirmed)
+		if err != nil {
+			b.Fatalf("bench: merge error:%s", err)
+		}
+	}
+}
+
+func BenchmarkFetchSeries(b *testing.B) {
+	slice := benchmarkData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Look up series spread across the dataset, including the last
+		n := (i * 397) % benchmarkSeries
+		_, err := slice.FetchSeries(fmt.Sprintf("Country %d", n/20), fmt.Sprintf("Province %d", n%20))
+		if err != nil {
+			b.Fatalf("bench: fetch error:%s", err)
+		}
+	}
+}
+
+func BenchmarkSort(b *testing.B) {
+	slice := benchmarkData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		shuffled := make(SeriesSlice, len(slice))
+		for j, s := range slice {
+			shuffled[(j*7919)%len(slice)] = s
+		}
+		b.StartTimer()
+		sort.Stable(shuffled)
+	}
+}
+
+func BenchmarkChartData(b *testing.B) {
+	slice := benchmarkData(b)
+	s := slice[len(slice)/2]
+	settings := ChartSettings{Datum: DataConfirmed, Daily: true}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ChartData([]*Series{s}, settings)
+	}
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	slice := benchmarkData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		global := &Series{}
+		global.sum(slice)
+	}
+}
+
+func BenchmarkAggregateMerge(b *testing.B) {
+	slice := benchmarkData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		global := &Series{}
+		for _, s := range slice {
+			global.Merge(s)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package covid
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BucketPublisher uploads exports to an S3 compatible bucket on each refresh
+// Google Cloud Storage can be used with HMAC keys and the endpoint https://storage.googleapis.com
+type BucketPublisher struct {
+	// The endpoint for the storage service e.g. https://s3.eu-west-1.amazonaws.com
+	Endpoint string
+	// The region used for signing requests (auto for GCS)
+	Region string
+	Bucket string
+	// Prefix for keys, exports are stored at prefix/2006-01-02/name and prefix/latest/name
+	Prefix    string
+	AccessKey string
+	SecretKey string
+}
+
+// artifact is one file uploaded by the publisher
+type artifact struct {
+	name        string
+	contentType string
+	write       func(w io.Writer) error
+}
+
+// Publish uploads exports of our stored data to the bucket under a date stamped key and latest
+func (p *BucketPublisher) Publish() error {
+
+	artifacts := []artifact{
+		{"covid.csv", "text/csv", func(w io.Writer) error { return data.WriteCSV(w) }},
+		{"covid.json", "application/json", func(w io.Writer) error { return data.WriteJSON(w) }},
+		{"covid.parquet", "application/vnd.apache.parquet", func(w io.Writer) error { return data.WriteParquet(w) }},
+		{"global.png", "image/png", func(w io.Writer) error {
+			global, err := data.FetchSeries("", "")
+			if err != nil {
+				return err
+			}
+			return global.WriteCard(w)
+		}},
+	}
+
+	date := time.Now().UTC().Format("2006-01-02")
+	for _, a := range artifacts {
+		b := &bytes.Buffer{}
+		mutex.RLock()
+		err := a.write(b)
+		mutex.RUnlock()
+		if err != nil {
+			return fmt.Errorf("bucket: error writing %s:%s", a.name, err)
+		}
+
+		for _, dir := range []string{date, "latest"} {
+			err = p.Put(strings.TrimPrefix(p.Prefix+"/"+dir+"/"+a.name, "/"), b.Bytes(), a.contentType)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Put uploads body to the bucket at key, signing the request with AWS signature v4
+func (p *BucketPublisher) Put(key string, body []byte, contentType string) error {
+	endpoint, err := url.Parse(p.Endpoint)
+	if err != nil {
+		return fmt.Errorf("bucket: invalid endpoint:%s", err)
+	}
+
+	// Use path style urls, which work for all S3 compatible services
+	path := "/" + p.Bucket + "/" + key
+	u := endpoint.Scheme + "://" + endpoint.Host + escapeS3Path(path)
+	req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("bucket: error creating request:%s", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	p.sign(req, body, time.Now().UTC())
+
+	client := &http.Client{Timeout: 120 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bucket: error uploading %s:%s", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("bucket: error uploading %s status:%d %s", key, resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// sign adds an AWS signature v4 authorization header to req
+func (p *BucketPublisher) sign(req *http.Request, body []byte, now time.Time) {
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	headers := map[string]string{
+		"content-type":         req.Header.Get("Content-Type"),
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           req.Header.Get("X-Amz-Date"),
+	}
+	req.Header.Set("Authorization", signV4(req.Method, req.URL.EscapedPath(), req.URL.RawQuery, headers, payloadHash, now, p.Region, "s3", p.AccessKey, p.SecretKey))
+}
+
+// signV4 returns the AWS signature v4 authorization header for a request, headers are keyed by lower case name
+// path and query must already be escaped as they are in the request
+func signV4(method, path, query string, headers map[string]string, payloadHash string, now time.Time, region, service, accessKey, secretKey string) string {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + strings.TrimSpace(headers[name]) + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		query,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature)
+}
+
+// hmacSHA256 returns the hmac of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapeS3Path escapes each segment of path as required for S3 signing
+func escapeS3Path(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		parts[i] = strings.Replace(url.PathEscape(p), "+", "%2B", -1)
+	}
+	return strings.Join(parts, "/")
+}
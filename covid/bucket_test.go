@@ -0,0 +1,46 @@
+package covid
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Credentials and date used by the AWS signature v4 test suite
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+var testSignDate = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSignV4(t *testing.T) {
+	// get-vanilla from the AWS signature v4 test suite
+	emptyHash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	headers := map[string]string{"host": "example.amazonaws.com", "x-amz-date": "20150830T123600Z"}
+	got := signV4("GET", "/", "", headers, emptyHash, testSignDate, "us-east-1", "service", testAccessKey, testSecretKey)
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got != want {
+		t.Fatalf("test: sign get-vanilla wanted:%s got:%s", want, got)
+	}
+}
+
+func TestBucketSign(t *testing.T) {
+	p := &BucketPublisher{Region: "us-east-1", AccessKey: testAccessKey, SecretKey: testSecretKey}
+	body := []byte("a,b\n")
+	req, err := http.NewRequest(http.MethodPut, "https://s3.amazonaws.com/covid/latest/covid.csv", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("test: sign request error:%s", err)
+	}
+	req.Header.Set("Content-Type", "text/csv")
+	p.sign(req, body, testSignDate)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=12a72cbf9b83580507f821fe5d77ffe4e26e96acf716c985c42df665b9037396"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("test: bucket sign wanted:%s got:%s", want, got)
+	}
+	if req.Header.Get("X-Amz-Date") != "20150830T123600Z" {
+		t.Fatalf("test: bucket sign wrong date header:%s", req.Header.Get("X-Amz-Date"))
+	}
+}
@@ -0,0 +1,84 @@
+package covid
+
+import (
+	"time"
+)
+
+// Reporting cadences for series
+const (
+	CadenceDaily     = "daily"
+	CadenceWeekdays  = "weekdays"
+	CadenceWeekly    = "weekly"
+	CadenceIrregular = "irregular"
+)
+
+// cadenceDays is the number of recent days used to detect the reporting cadence
+const cadenceDays = 56
+
+// Cadence returns how often this series reports new cases, detected from the recent history
+// series with too little data to tell are assumed to report daily
+func (s *Series) Cadence() string {
+	daily := s.ConfirmedDaily
+	start := len(daily) - cadenceDays
+	if start < 0 {
+		start = 0
+	}
+
+	// Don't count days before the first case as days without a report
+	first := s.FirstDate(DataConfirmed)
+	if !first.IsZero() {
+		i := daysBetween(s.StartsAt, first)
+		if i > start {
+			start = i
+		}
+	}
+	days := len(daily) - start
+	if days < 14 {
+		return CadenceDaily
+	}
+
+	reported, weekends, weekendReported, weekdayReported := 0, 0, 0, 0
+	for i := start; i < len(daily); i++ {
+		weekday := s.StartsAt.AddDate(0, 0, i).Weekday()
+		weekend := weekday == time.Saturday || weekday == time.Sunday
+		if weekend {
+			weekends++
+		}
+		if daily[i] == 0 {
+			continue
+		}
+		reported++
+		if weekend {
+			weekendReported++
+		} else {
+			weekdayReported++
+		}
+	}
+
+	switch {
+	case float64(reported) >= float64(days)*0.85:
+		return CadenceDaily
+	case float64(weekendReported) <= float64(weekends)*0.15 && float64(weekdayReported) >= float64(days-weekends)*0.7:
+		return CadenceWeekdays
+	case float64(reported)*7 <= float64(days)*1.5:
+		return CadenceWeekly
+	}
+	return CadenceIrregular
+}
+
+// CadenceNote returns a note to show with values for today if this series does not report daily
+// and has no report today, so that a zero is not mistaken for no new cases
+func (s *Series) CadenceNote() string {
+	if len(s.ConfirmedDaily) == 0 || s.ConfirmedDaily[len(s.ConfirmedDaily)-1] != 0 {
+		return ""
+	}
+	switch s.Cadence() {
+	case CadenceWeekdays:
+		return " (reports on weekdays)"
+	case CadenceWeekly:
+		return " (reports weekly)"
+	case CadenceIrregular:
+		return " (reports irregularly)"
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+// cadenceSeries returns a series of 56 days starting on a Monday which reports on days where report returns true
+func cadenceSeries(report func(i int) bool) *Series {
+	s := &Series{StartsAt: time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)}
+	total := 0
+	for i := 0; i < 56; i++ {
+		if report(i) {
+			total += 10
+		}
+		s.Confirmed = append(s.Confirmed, total)
+		s.Deaths = append(s.Deaths, 0)
+	}
+	s.UpdateDaily()
+	return s
+}
+
+func TestCadence(t *testing.T) {
+	tests := []struct {
+		report  func(i int) bool
+		cadence string
+	}{
+		{func(i int) bool { return true }, CadenceDaily},
+		{func(i int) bool { return i%7 < 5 }, CadenceWeekdays},
+		{func(i int) bool { return i%7 == 0 }, CadenceWeekly},
+		{func(i int) bool { return i%2 == 0 }, CadenceIrregular},
+	}
+	for _, test := range tests {
+		s := cadenceSeries(test.report)
+		if s.Cadence() != test.cadence {
+			t.Fatalf("test: cadence wanted:%s got:%s", test.cadence, s.Cadence())
+		}
+	}
+
+	// The last day is a Sunday, which a weekly series does not report on
+	s := cadenceSeries(func(i int) bool { return i%7 == 0 })
+	if s.CadenceNote() != " (reports weekly)" {
+		t.Fatalf("test: cadence note wanted:(reports weekly) got:%s", s.CadenceNote())
+	}
+}
@@ -0,0 +1,65 @@
+package covid
+
+import (
+	"math"
+	"time"
+)
+
+// calendarLevels is the number of shades used for non-zero values in a calendar heatmap
+const calendarLevels = 4
+
+// Calendar holds the values for a metric by week and weekday to draw a calendar heatmap
+type Calendar struct {
+	Metric string         `json:"metric"`
+	Max    int            `json:"max"`
+	Weeks  []CalendarWeek `json:"weeks"`
+}
+
+// CalendarWeek is one column of a calendar heatmap, starting on a Sunday
+type CalendarWeek struct {
+	StartsAt string `json:"starts_at"`
+	// Days indexed by weekday from Sunday, nil for days outside the series
+	Days [7]*CalendarDay `json:"days"`
+}
+
+// CalendarDay is one cell of a calendar heatmap
+// Level is 0 for values of 0 or less, otherwise 1 to 4 in proportion to the max value
+type CalendarDay struct {
+	Date  string `json:"date"`
+	Value int    `json:"value"`
+	Level int    `json:"level"`
+}
+
+// Calendar returns the values of metric for this series arranged by week and weekday
+func (s *Series) Calendar(metric string) (*Calendar, error) {
+	stats, err := s.Stats(metric, 0)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := s.MetricValues(metric)
+
+	c := &Calendar{Metric: metric, Max: stats.Max, Weeks: []CalendarWeek{}}
+	var week *CalendarWeek
+	for i, v := range values {
+		date := s.StartsAt.AddDate(0, 0, i)
+		if week == nil || date.Weekday() == time.Sunday {
+			start := date.AddDate(0, 0, -int(date.Weekday()))
+			c.Weeks = append(c.Weeks, CalendarWeek{StartsAt: isoDate(start)})
+			week = &c.Weeks[len(c.Weeks)-1]
+		}
+		week.Days[date.Weekday()] = &CalendarDay{Date: isoDate(date), Value: v, Level: calendarLevel(v, stats.Max)}
+	}
+	return c, nil
+}
+
+// calendarLevel returns the shade for value v given the max value
+func calendarLevel(v, max int) int {
+	if v <= 0 || max <= 0 {
+		return 0
+	}
+	level := int(math.Ceil(float64(v) / float64(max) * calendarLevels))
+	if level > calendarLevels {
+		level = calendarLevels
+	}
+	return level
+}
@@ -0,0 +1,31 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendar(t *testing.T) {
+	// Starts on Wednesday 4 March 2020
+	s := &Series{
+		StartsAt:  time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC),
+		Deaths:    []int{0, 0, 0, 0, 0},
+		Confirmed: []int{0, 10, 20, 60, 100},
+	}
+	s.UpdateDaily()
+
+	c, err := s.Calendar(MetricConfirmedDaily)
+	if err != nil {
+		t.Fatalf("test: calendar error:%s", err)
+	}
+	if len(c.Weeks) != 2 || c.Weeks[0].StartsAt != "2020-03-01" || c.Weeks[0].Days[0] != nil {
+		t.Fatalf("test: calendar wanted 2 weeks from 2020-03-01 got:%d", len(c.Weeks))
+	}
+	// Saturday has the max value of 40, Friday 10 is a quarter of that
+	if c.Max != 40 || c.Weeks[0].Days[6].Level != 4 || c.Weeks[0].Days[5].Level != 1 || c.Weeks[0].Days[3].Level != 0 {
+		t.Fatalf("test: calendar levels wanted:4,1,0 got:%v", c.Weeks[0].Days)
+	}
+	if c.Weeks[1].Days[0].Date != "2020-03-08" || c.Weeks[1].Days[1] != nil {
+		t.Fatalf("test: calendar wanted Sunday 2020-03-08 got:%v", c.Weeks[1].Days[0])
+	}
+}
@@ -0,0 +1,163 @@
+package covid
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// Share card dimensions, as recommended for og:image and twitter:image
+const (
+	cardWidth  = 1200
+	cardHeight = 630
+	cardMargin = 60
+	cardDays   = 28
+)
+
+// Colours used on share cards (matching the charts on the site)
+var (
+	cardBackground = color.RGBA{255, 255, 255, 255}
+	cardText       = color.RGBA{51, 51, 51, 255}
+	cardMuted      = color.RGBA{150, 150, 150, 255}
+	cardDeaths     = color.RGBA{70, 30, 30, 255}
+	cardConfirmed  = color.RGBA{163, 32, 32, 255}
+)
+
+// WriteCard writes a png share card for this series to w
+// with title, headline numbers, a chart of the last 28 days and the updated at date
+func (s *Series) WriteCard(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{cardBackground}, image.Point{}, draw.Src)
+
+	// Title
+	drawText(img, s.Title(), cardMargin, cardMargin, 8, cardText)
+
+	// Headline numbers
+	y := cardMargin + 100
+	drawText(img, "DEATHS "+s.DeathsDisplay(), cardMargin, y, 5, cardDeaths)
+	drawText(img, "CONFIRMED "+s.ConfirmedDisplay(), cardWidth/2, y, 5, cardConfirmed)
+	y += 55
+	drawText(img, "TODAY "+s.DeathsToday(), cardMargin, y, 3, cardMuted)
+	drawText(img, "TODAY "+s.ConfirmedToday(), cardWidth/2, y, 3, cardMuted)
+
+	// Chart of daily confirmed for the last 28 days
+	chart := image.Rect(cardMargin, y+60, cardWidth-cardMargin, cardHeight-cardMargin-50)
+	drawBars(img, chart, s.Days(cardDays).ConfirmedDaily, cardConfirmed)
+
+	// Updated at and source
+	footer := "DAILY CASES, LAST 28 DAYS"
+	if !s.UpdatedAt.IsZero() {
+		footer += " - UPDATED " + s.UpdatedAt.Format("2006-01-02 15:04")
+	}
+	drawText(img, footer, cardMargin, cardHeight-cardMargin-21, 3, cardMuted)
+
+	return png.Encode(w, img)
+}
+
+// drawBars draws a bar chart of values filling rect
+func drawBars(img *image.RGBA, rect image.Rectangle, values []int, c color.Color) {
+	if len(values) == 0 {
+		return
+	}
+
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+
+	// Draw a baseline then the bars with a small gap between them
+	draw.Draw(img, image.Rect(rect.Min.X, rect.Max.Y, rect.Max.X, rect.Max.Y+2), &image.Uniform{cardMuted}, image.Point{}, draw.Src)
+	barWidth := rect.Dx() / len(values)
+	gap := barWidth / 5
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		x := rect.Min.X + i*barWidth
+		h := v * rect.Dy() / max
+		bar := image.Rect(x+gap, rect.Max.Y-h, x+barWidth-gap, rect.Max.Y)
+		draw.Draw(img, bar, &image.Uniform{c}, image.Point{}, draw.Src)
+	}
+}
+
+// drawText draws text in our bitmap font at x,y (top left) scaled by scale
+// text is drawn in upper case, unknown characters are left blank
+func drawText(img *image.RGBA, text string, x, y, scale int, c color.Color) {
+	u := &image.Uniform{c}
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := cardFont[r]
+		if ok {
+			for row, line := range glyph {
+				for col, p := range line {
+					if p != '#' {
+						continue
+					}
+					px := x + col*scale
+					py := y + row*scale
+					draw.Draw(img, image.Rect(px, py, px+scale, py+scale), u, image.Point{}, draw.Src)
+				}
+			}
+		}
+		// Each glyph is 5 wide with a one pixel gap
+		x += 6 * scale
+	}
+}
+
+// cardFont is a minimal 5x7 bitmap font for share cards
+var cardFont = map[rune][7]string{
+	'A':  {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B':  {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C':  {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D':  {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+	'E':  {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F':  {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G':  {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H':  {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I':  {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J':  {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K':  {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L':  {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N':  {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O':  {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P':  {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q':  {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R':  {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S':  {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V':  {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X':  {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y':  {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z':  {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'0':  {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1':  {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2':  {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3':  {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4':  {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5':  {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6':  {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7':  {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8':  {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9':  {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'.':  {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	',':  {".....", ".....", ".....", ".....", ".##..", "..#..", ".#..."},
+	':':  {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	'-':  {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'+':  {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'/':  {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+	'(':  {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')':  {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'%':  {"##...", "##..#", "...#.", "..#..", ".#...", "#..##", "...##"},
+	'\'': {".##..", "..#..", ".#...", ".....", ".....", ".....", "....."},
+	'*':  {".....", "..#..", "#.#.#", ".###.", "#.#.#", "..#..", "....."},
+}
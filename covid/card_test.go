@@ -0,0 +1,32 @@
+package covid
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestWriteCard(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	series := []*Series{
+		{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3, 6}, Confirmed: []int{1, 5, 10, 20}},
+		// Series with no data still produce a card
+		{Country: "Atlantis", StartsAt: start},
+	}
+	for _, s := range series {
+		s.UpdateDaily()
+		b := &bytes.Buffer{}
+		err := s.WriteCard(b)
+		if err != nil {
+			t.Fatalf("test: write card %s error:%s", s.Country, err)
+		}
+		img, err := png.Decode(b)
+		if err != nil {
+			t.Fatalf("test: write card %s invalid png:%s", s.Country, err)
+		}
+		if img.Bounds().Dx() != cardWidth || img.Bounds().Dy() != cardHeight {
+			t.Fatalf("test: write card %s wanted:%dx%d got:%dx%d", s.Country, cardWidth, cardHeight, img.Bounds().Dx(), img.Bounds().Dy())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package covid
+
+import (
+	"fmt"
+	"math"
+)
+
+// PeriodChange compares the sum of a metric over the last window days with the window before
+type PeriodChange struct {
+	Metric   string  `json:"metric"`
+	Window   int     `json:"window"`
+	Value    int     `json:"value"`
+	Previous int     `json:"previous"`
+	Change   int     `json:"change"`
+	Percent  float64 `json:"percent"`
+}
+
+// Change returns the change in the sum of metric (e.g. confirmed_daily) over the last window days
+// compared with the window before, the percent is 0 if the previous sum was 0
+func (s *Series) Change(metric string, window int) (*PeriodChange, error) {
+	if window < 1 {
+		window = 1
+	}
+	values, err := s.MetricValues(metric)
+	if err != nil {
+		return nil, err
+	}
+	c := &PeriodChange{Metric: metric, Window: window}
+	windows := Windows(values, window, window)
+	if len(windows) < 2 {
+		return c, fmt.Errorf("series: not enough data for change over %d days", window)
+	}
+	c.Value = windows[len(windows)-1].Sum
+	c.Previous = windows[len(windows)-2].Sum
+	c.Change = c.Value - c.Previous
+	if c.Previous != 0 {
+		c.Percent = float64(c.Change) / float64(abs(c.Previous)) * 100
+	}
+	return c, nil
+}
+
+// Arrow returns an arrow for the direction of the change
+func (c *PeriodChange) Arrow() string {
+	switch {
+	case c.Change > 0:
+		return "▲"
+	case c.Change < 0:
+		return "▼"
+	}
+	return "▶"
+}
+
+// Display returns a description of the change e.g. ▲ 23% vs prior 14 days
+// or the absolute change if there is no percentage as the previous value was 0
+func (c *PeriodChange) Display() string {
+	period := "day"
+	if c.Window > 1 {
+		period = fmt.Sprintf("%d days", c.Window)
+	}
+	if c.Previous == 0 {
+		return fmt.Sprintf("%s %s vs prior %s", c.Arrow(), formatThousands(abs(c.Change)), period)
+	}
+	return fmt.Sprintf("%s %.0f%% vs prior %s", c.Arrow(), math.Abs(c.Percent), period)
+}
+
+// ChangeDisplay returns the display for the change in metric over window days
+// or an empty string if there is not enough data
+func (s *Series) ChangeDisplay(metric string, window int) string {
+	c, err := s.Change(metric, window)
+	if err != nil {
+		return ""
+	}
+	return c.Display()
+}
@@ -0,0 +1,42 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChange(t *testing.T) {
+	s := &Series{
+		StartsAt:  time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths:    []int{0, 0, 0, 0, 0},
+		Confirmed: []int{0, 10, 20, 30, 60},
+	}
+	s.UpdateDaily()
+
+	// daily values are 0,10,10,10,30 so the last 2 days are 40 against 20 before
+	c, err := s.Change(MetricConfirmedDaily, 2)
+	if err != nil {
+		t.Fatalf("test: change error:%s", err)
+	}
+	if c.Value != 40 || c.Previous != 20 || c.Change != 20 || c.Percent != 100 {
+		t.Fatalf("test: change wanted value:40 previous:20 change:20 percent:100 got:%v", c)
+	}
+	if d := c.Display(); d != "▲ 100% vs prior 2 days" {
+		t.Fatalf("test: change display wanted:▲ 100%% vs prior 2 days got:%s", d)
+	}
+
+	c, err = s.Change(MetricDeathsDaily, 1)
+	if err != nil {
+		t.Fatalf("test: change error:%s", err)
+	}
+	if d := c.Display(); d != "▶ 0 vs prior day" {
+		t.Fatalf("test: change display wanted:▶ 0 vs prior day got:%s", d)
+	}
+
+	if _, err := s.Change(MetricConfirmedDaily, 3); err == nil {
+		t.Fatalf("test: change wanted error for short series")
+	}
+	if d := s.ChangeDisplay(MetricConfirmedDaily, 3); d != "" {
+		t.Fatalf("test: change display wanted empty got:%s", d)
+	}
+}
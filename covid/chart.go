@@ -0,0 +1,380 @@
+package covid
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChartEvent is an event drawn as a vertical line on charts (e.g. lockdown start)
+type ChartEvent struct {
+	Date  time.Time
+	Label string
+}
+
+// ChartSettings sets which data is included in a chart and how it is displayed
+type ChartSettings struct {
+	// The datum to chart - DataDeaths, DataConfirmed or DataIncidence14
+	Datum int
+	// Chart daily values instead of cumulative totals
+	Daily bool
+	// Use a logarithmic y axis
+	Logarithmic bool
+	// Limit the chart to the last days, 0 for all data
+	// values are calculated before limiting, so windowed values like incidence are complete
+	Days int
+	// Events to annotate on the chart
+	Events []ChartEvent
+	// The name of an auxiliary series (e.g. stringency) for the first series to overlay on a second axis
+	Overlay string
+	// Split daily confirmed cases for the first series into stacked datasets by variant, if we have variant data
+	Variants bool
+	// Flag values with a z-score above this threshold as outliers, 0 to disable
+	OutlierThreshold float64
+	// For daily charts, add a 7 day average line for each series over the raw bars
+	Smoothed bool
+	// Chart daily confirmed and deaths for the first series together, with deaths on a second axis
+	Dual bool
+	// For dual charts, shift deaths this many days earlier to line up with the cases which led to them
+	DeathsLag int
+	// Drop leading days with zero values in every dataset, so late outbreaks don't start with a long flat line
+	TrimZeros bool
+}
+
+// Chart is a complete Chart.js (2.x) config which can be passed straight to new Chart(ctx, config)
+type Chart struct {
+	Type    string                 `json:"type"`
+	Data    ChartDataSets          `json:"data"`
+	Options map[string]interface{} `json:"options"`
+	// The first date charted if leading zero days were trimmed, e.g. 2020-03-01
+	TrimmedStart string `json:"trimmed_start,omitempty"`
+}
+
+// ChartDataSets holds the labels and datasets for a chart
+type ChartDataSets struct {
+	Labels   []string       `json:"labels"`
+	Datasets []ChartDataset `json:"datasets"`
+}
+
+// ChartDataset is one dataset within a chart
+type ChartDataset struct {
+	Label           string  `json:"label"`
+	Data            []int   `json:"data"`
+	Type            string  `json:"type,omitempty"`
+	Fill            bool    `json:"fill"`
+	BorderWidth     int     `json:"borderWidth"`
+	BorderColor     string  `json:"borderColor,omitempty"`
+	BackgroundColor string  `json:"backgroundColor,omitempty"`
+	LineTension     float64 `json:"lineTension"`
+	YAxisID         string  `json:"yAxisID,omitempty"`
+	// Indexes into Data of values which are statistical outliers, if requested
+	Outliers []int `json:"outliers,omitempty"`
+}
+
+// Colours used for chart datasets, the first matches the single series charts on the site
+var chartColors = []string{
+	"rgba(163,32,32,0.7)",
+	"rgba(32,96,163,0.7)",
+	"rgba(40,140,60,0.7)",
+	"rgba(200,130,20,0.7)",
+	"rgba(110,50,150,0.7)",
+	"rgba(20,150,150,0.7)",
+	"rgba(90,90,90,0.7)",
+}
+
+// chartColor returns the colour for dataset i for the given datum
+func chartColor(datum, i int) string {
+	if i == 0 && datum == DataDeaths {
+		return "rgba(70,30,30,0.7)"
+	}
+	return chartColors[i%len(chartColors)]
+}
+
+// smoothedColor returns an opaque version of a dataset colour for smoothed lines drawn over bars
+func smoothedColor(color string) string {
+	return strings.Replace(color, "0.7)", "1)", 1)
+}
+
+// ChartData builds a Chart.js config for one or more series with the given settings
+// all series are assumed to share the same dates, labels are taken from the longest series
+func ChartData(series []*Series, settings ChartSettings) *Chart {
+	chart := &Chart{Type: "line"}
+	if settings.Daily {
+		chart.Type = "bar"
+	}
+
+	// Use the dates from the longest series for labels
+	var longest *Series
+	for _, s := range series {
+		if longest == nil || len(s.Deaths) > len(longest.Deaths) {
+			longest = s
+		}
+	}
+	chart.Data.Labels = []string{}
+	if longest != nil {
+		chart.Data.Labels = lastStrings(longest.Dates(), settings.Days)
+	}
+
+	chart.Data.Datasets = []ChartDataset{}
+	for i, s := range series {
+		color := chartColor(settings.Datum, i)
+		dataset := ChartDataset{
+			Label:           s.Title(),
+			Data:            lastInts(s.chartValues(settings.Datum, settings.Daily), settings.Days),
+			Fill:            len(series) == 1,
+			BorderColor:     color,
+			BackgroundColor: color,
+			LineTension:     0.1,
+		}
+		if len(series) > 1 && !settings.Daily {
+			dataset.BorderWidth = 2
+			dataset.BackgroundColor = ""
+		}
+		chart.Data.Datasets = append(chart.Data.Datasets, dataset)
+
+		// Add the smoothed line computed on all values, before limiting days
+		if settings.Smoothed && settings.Daily {
+			chart.Data.Datasets = append(chart.Data.Datasets, ChartDataset{
+				Label:       fmt.Sprintf("%s (%d day average)", s.Title(), trendDays),
+				Data:        lastInts(Smooth(s.chartValues(settings.Datum, true), trendDays), settings.Days),
+				Type:        "line",
+				BorderWidth: 2,
+				BorderColor: smoothedColor(color),
+				LineTension: 0.4,
+			})
+		}
+	}
+
+	// Replace the datasets with daily cases by variant for the first series
+	if settings.Variants && len(series) > 0 && series[0].VariantShares() != nil {
+		chart.Type = "bar"
+		chart.Data.Datasets = []ChartDataset{}
+		cases := series[0].VariantCases()
+		for i, name := range series[0].Variants() {
+			color := chartColors[i%len(chartColors)]
+			chart.Data.Datasets = append(chart.Data.Datasets, ChartDataset{
+				Label:           name,
+				Data:            lastInts(cases[name], settings.Days),
+				BorderColor:     color,
+				BackgroundColor: color,
+			})
+		}
+	} else {
+		settings.Variants = false
+	}
+
+	// Replace the datasets with daily confirmed and lagged deaths for the first series
+	if settings.Dual && len(series) > 0 {
+		chart.Data.Datasets = dualDatasets(series[0], settings)
+		settings.Overlay = ""
+	}
+
+	// Overlay auxiliary values for the first series if we have them
+	if settings.Overlay != "" && len(series) > 0 {
+		values := series[0].AuxiliaryValues(settings.Overlay)
+		if values != nil {
+			chart.Data.Datasets = append(chart.Data.Datasets, ChartDataset{
+				Label:       fmt.Sprintf("%s %s", series[0].Title(), settings.Overlay),
+				Data:        lastInts(roundValues(values), settings.Days),
+				Type:        "line",
+				BorderWidth: 2,
+				BorderColor: "rgba(0,0,0,0.5)",
+				LineTension: 0.1,
+				YAxisID:     "y-axis-1",
+			})
+		} else {
+			settings.Overlay = ""
+		}
+	}
+
+	// Trim leading zero days before flagging outliers, so that outlier indexes match the data
+	if settings.TrimZeros && longest != nil {
+		chart.trimZeros(longest.StartsAt.AddDate(0, 0, len(longest.Deaths)-len(chart.Data.Labels)))
+	}
+
+	// Flag outliers in each dataset, except overlays which are on a different scale
+	// for cumulative values outliers are found in the daily changes
+	if settings.OutlierThreshold > 0 {
+		for i, d := range chart.Data.Datasets {
+			if d.YAxisID != "" {
+				continue
+			}
+			values := d.Data
+			if !settings.Daily && settings.Datum != DataIncidence14 {
+				values = make([]int, len(d.Data))
+				for j := 1; j < len(d.Data); j++ {
+					values[j] = d.Data[j] - d.Data[j-1]
+				}
+			}
+			chart.Data.Datasets[i].Outliers = Outliers(values, settings.OutlierThreshold)
+		}
+	}
+
+	chart.Options = chartOptions(settings, chart.Data.Labels)
+	return chart
+}
+
+// dualDatasets returns datasets for daily confirmed and deaths for s, with deaths on a second axis
+// deaths are shifted earlier by the lag, so the deaths line ends lag days before the last day
+func dualDatasets(s *Series, settings ChartSettings) []ChartDataset {
+	lag := settings.DeathsLag
+	if lag < 0 || lag >= len(s.DeathsDaily) {
+		lag = 0
+	}
+	confirmed := lastInts(s.ConfirmedDaily, settings.Days)
+	deaths := s.DeathsDaily[lag:]
+	if start := len(s.ConfirmedDaily) - len(confirmed); start < len(deaths) {
+		deaths = deaths[start:]
+	} else {
+		deaths = []int{}
+	}
+
+	label := "Deaths"
+	if lag > 0 {
+		label = fmt.Sprintf("Deaths (%d days later)", lag)
+	}
+	return []ChartDataset{
+		{
+			Label:           "Confirmed",
+			Data:            confirmed,
+			BorderColor:     chartColor(DataConfirmed, 0),
+			BackgroundColor: chartColor(DataConfirmed, 0),
+			LineTension:     0.1,
+		},
+		{
+			Label:       label,
+			Data:        deaths,
+			Type:        "line",
+			BorderWidth: 2,
+			BorderColor: chartColor(DataDeaths, 0),
+			LineTension: 0.1,
+			YAxisID:     "y-axis-1",
+		},
+	}
+}
+
+// JSON returns the json encoding of this chart config
+func (c *Chart) JSON() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// chartValues returns the values for datum, either daily or cumulative
+// incidence is already a rate over a window, so is never shown as daily values
+func (s *Series) chartValues(datum int, daily bool) []int {
+	if datum == DataIncidence14 {
+		return s.Incidence14Values()
+	}
+	if daily {
+		return s.DailyValues(datum)
+	}
+	switch datum {
+	case DataDeaths:
+		return s.Deaths
+	case DataConfirmed:
+		return s.Confirmed
+	}
+	return nil
+}
+
+// chartOptions returns the options for a chart, including axes and annotations
+func chartOptions(settings ChartSettings, labels []string) map[string]interface{} {
+	yAxis := map[string]interface{}{
+		"id":       "y-axis-0",
+		"position": "right",
+		"type":     "linear",
+		"ticks":    map[string]interface{}{"beginAtZero": true, "maxTicksLimit": 5},
+	}
+	if settings.Logarithmic {
+		yAxis["type"] = "logarithmic"
+	}
+
+	options := map[string]interface{}{
+		"maintainAspectRatio": false,
+		"legend":              map[string]interface{}{"display": true},
+		"scales": map[string]interface{}{
+			"xAxes": []interface{}{
+				map[string]interface{}{
+					"id":    "x-axis-0",
+					"ticks": map[string]interface{}{"autoSkip": true},
+				},
+			},
+			"yAxes": []interface{}{yAxis},
+		},
+	}
+
+	// Stack datasets for variants so that the bars show total cases
+	if settings.Variants {
+		scales := options["scales"].(map[string]interface{})
+		for _, axis := range []string{"xAxes", "yAxes"} {
+			for _, a := range scales[axis].([]interface{}) {
+				a.(map[string]interface{})["stacked"] = true
+			}
+		}
+	}
+
+	// Add a second axis on the left for overlays or deaths on dual charts
+	if settings.Overlay != "" || settings.Dual {
+		options["scales"].(map[string]interface{})["yAxes"] = []interface{}{yAxis, map[string]interface{}{
+			"id":        "y-axis-1",
+			"position":  "left",
+			"type":      "linear",
+			"gridLines": map[string]interface{}{"drawOnChartArea": false},
+			"ticks":     map[string]interface{}{"beginAtZero": true, "maxTicksLimit": 5},
+		}}
+	}
+
+	// Add events as vertical lines using chartjs-plugin-annotation
+	// events outside the range of the chart labels are ignored
+	var annotations []interface{}
+	for _, e := range settings.Events {
+		label := e.Date.Format("Jan 2")
+		if !containsString(labels, label) {
+			continue
+		}
+		annotations = append(annotations, map[string]interface{}{
+			"type":        "line",
+			"mode":        "vertical",
+			"scaleID":     "x-axis-0",
+			"value":       label,
+			"borderColor": "rgba(0,0,0,0.4)",
+			"borderWidth": 1,
+			"label": map[string]interface{}{
+				"enabled":  true,
+				"content":  e.Label,
+				"position": "top",
+			},
+		})
+	}
+	if len(annotations) > 0 {
+		options["annotation"] = map[string]interface{}{"annotations": annotations}
+	}
+
+	return options
+}
+
+// lastStrings returns the last n values of list, or all values if n is 0
+func lastStrings(list []string, n int) []string {
+	if n <= 0 || n >= len(list) {
+		return list
+	}
+	return list[len(list)-n:]
+}
+
+// lastInts returns the last n values of list, or all values if n is 0
+func lastInts(list []int, n int) []int {
+	if n <= 0 || n >= len(list) {
+		return list
+	}
+	return list[len(list)-n:]
+}
+
+// containsString returns true if v is in list
+func containsString(list []string, v string) bool {
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
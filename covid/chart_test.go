@@ -0,0 +1,91 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChartData(t *testing.T) {
+
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	series := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3, 6}, Confirmed: []int{1, 5, 10, 20}}
+	series.UpdateDaily()
+
+	settings := ChartSettings{
+		Datum:  DataDeaths,
+		Daily:  true,
+		Events: []ChartEvent{{Date: start.AddDate(0, 0, 2), Label: "Lockdown"}, {Date: start.AddDate(0, 1, 0), Label: "Out of range"}},
+	}
+	chart := ChartData([]*Series{series}, settings)
+
+	if chart.Type != "bar" || len(chart.Data.Labels) != 4 || len(chart.Data.Datasets) != 1 {
+		t.Fatalf("test: chart data wrong type:%s labels:%d", chart.Type, len(chart.Data.Labels))
+	}
+	if chart.Data.Datasets[0].Data[3] != 3 {
+		t.Errorf("test: chart data wrong daily deaths wanted:%d got:%d", 3, chart.Data.Datasets[0].Data[3])
+	}
+
+	annotation, ok := chart.Options["annotation"].(map[string]interface{})
+	if !ok || len(annotation["annotations"].([]interface{})) != 1 {
+		t.Errorf("test: chart data wrong annotations:%v", chart.Options["annotation"])
+	}
+}
+
+func TestOutliers(t *testing.T) {
+
+	// A reporting dump on day 10 should be flagged, the steady growth and small counts should not
+	values := []int{0, 0, 1, 0, 2, 100, 110, 120, 130, 140, 2000, 160, 170, 180, 190, 200}
+	outliers := Outliers(values, DefaultOutlierThreshold)
+	if len(outliers) != 1 || outliers[0] != 10 {
+		t.Fatalf("test: outliers wrong:%v", outliers)
+	}
+
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	series := &Series{Country: "Italy", StartsAt: start, Deaths: make([]int, len(values)), Confirmed: make([]int, len(values))}
+	total := 0
+	for i, v := range values {
+		total += v
+		series.Confirmed[i] = total
+	}
+	series.UpdateDaily()
+	chart := ChartData([]*Series{series}, ChartSettings{Datum: DataConfirmed, OutlierThreshold: DefaultOutlierThreshold})
+	if len(chart.Data.Datasets[0].Outliers) != 1 || chart.Data.Datasets[0].Outliers[0] != 10 {
+		t.Fatalf("test: chart outliers wrong:%v", chart.Data.Datasets[0].Outliers)
+	}
+}
+
+func TestChartSmoothed(t *testing.T) {
+
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	series := &Series{Country: "Italy", StartsAt: start, Deaths: make([]int, 10), Confirmed: []int{7, 14, 21, 28, 35, 42, 49, 56, 63, 140}}
+	series.UpdateDaily()
+
+	chart := ChartData([]*Series{series}, ChartSettings{Datum: DataConfirmed, Daily: true, Smoothed: true, Days: 3})
+	if len(chart.Data.Datasets) != 2 || chart.Data.Datasets[1].Type != "line" {
+		t.Fatalf("test: smoothed chart wrong datasets:%v", chart.Data.Datasets)
+	}
+	smoothed := chart.Data.Datasets[1].Data
+	if len(smoothed) != 3 || smoothed[0] != 7 || smoothed[2] != 17 {
+		t.Fatalf("test: smoothed chart wrong values:%v", smoothed)
+	}
+}
+
+func TestChartDual(t *testing.T) {
+	s := &Series{
+		Country:   "Italy",
+		StartsAt:  time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths:    []int{0, 1, 3, 6, 10},
+		Confirmed: []int{10, 20, 40, 70, 110},
+	}
+	s.UpdateDaily()
+
+	chart := ChartData([]*Series{s}, ChartSettings{Daily: true, Dual: true, DeathsLag: 1, Days: 3})
+	if len(chart.Data.Datasets) != 2 || len(chart.Data.Labels) != 3 {
+		t.Fatalf("test: dual wanted 2 datasets 3 labels got:%d %d", len(chart.Data.Datasets), len(chart.Data.Labels))
+	}
+	confirmed, deaths := chart.Data.Datasets[0], chart.Data.Datasets[1]
+	// Confirmed daily for the last 3 days are 20,30,40 and deaths a day later are 3,4
+	if confirmed.Data[0] != 20 || len(deaths.Data) != 2 || deaths.Data[0] != 3 || deaths.YAxisID != "y-axis-1" {
+		t.Fatalf("test: dual wanted:[20 30 40] [3 4] got:%v %v", confirmed.Data, deaths.Data)
+	}
+}
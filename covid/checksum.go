@@ -0,0 +1,107 @@
+package covid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// FileCheck is an optional check of a downloaded file before it replaces the file we have
+// to avoid merging a truncated download or an error page
+type FileCheck struct {
+	// The expected hex SHA-256 of the file, if known
+	SHA256 string `json:"sha256"`
+	// The minimum number of csv rows, including the header
+	MinRows int `json:"min_rows"`
+}
+
+var (
+	checksumMutex sync.Mutex
+	// fileChecks are the checks for downloaded files by file name
+	fileChecks = make(map[string]FileCheck)
+	// checksums are the SHA-256 of the files we last downloaded by file name
+	checksums = make(map[string]string)
+)
+
+// SetFileCheck sets the check used for downloads of the file with this name, e.g. cases_country.csv
+func SetFileCheck(name string, check FileCheck) {
+	checksumMutex.Lock()
+	defer checksumMutex.Unlock()
+	fileChecks[name] = check
+}
+
+// LoadFileChecks sets the checks for downloaded files from a json config at path
+// which is an object of checks keyed by file name
+func LoadFileChecks(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("data: error reading file checks:%s", err)
+	}
+	var checks map[string]FileCheck
+	err = json.Unmarshal(b, &checks)
+	if err != nil {
+		return fmt.Errorf("data: error parsing file checks:%s", err)
+	}
+	for name, check := range checks {
+		SetFileCheck(name, check)
+	}
+	return nil
+}
+
+// Checksums returns the SHA-256 of each file downloaded by file name
+func Checksums() map[string]string {
+	checksumMutex.Lock()
+	defer checksumMutex.Unlock()
+	list := make(map[string]string, len(checksums))
+	for k, v := range checksums {
+		list[k] = v
+	}
+	return list
+}
+
+// checksum returns the hex SHA-256 of b
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkFile checks the downloaded contents of the file name against any check configured
+// returns the checksum of the contents and whether they differ from the file at path
+func checkFile(name, path string, b []byte) (string, bool, error) {
+	sum := checksum(b)
+
+	checksumMutex.Lock()
+	check := fileChecks[name]
+	checksumMutex.Unlock()
+
+	if check.SHA256 != "" && check.SHA256 != sum {
+		return sum, false, fmt.Errorf("data: checksum mismatch file:%s wanted:%s got:%s", name, check.SHA256, sum)
+	}
+	if check.MinRows > 0 {
+		rows, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+		if err != nil {
+			return sum, false, fmt.Errorf("data: invalid csv file:%s error:%s", name, err)
+		}
+		if len(rows) < check.MinRows {
+			return sum, false, fmt.Errorf("data: too few rows file:%s wanted:%d got:%d", name, check.MinRows, len(rows))
+		}
+	}
+
+	// Compare with the file we have, which may not have been downloaded by this process
+	existing, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return sum, false, fmt.Errorf("data: error reading file:%s", err)
+	}
+	changed := err != nil || checksum(existing) != sum
+
+	checksumMutex.Lock()
+	checksums[name] = sum
+	checksumMutex.Unlock()
+	return sum, changed, nil
+}
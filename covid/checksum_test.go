@@ -0,0 +1,46 @@
+package covid
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "covid")
+	if err != nil {
+		t.Fatalf("test: temp dir error:%s", err)
+	}
+	defer os.RemoveAll(dir)
+	defer SetFileCheck("test.csv", FileCheck{})
+
+	path := filepath.Join(dir, "test.csv")
+	b := []byte("a,b\n1,2\n")
+
+	// A new file is changed, the same contents again are not
+	sum, changed, err := checkFile("test.csv", path, b)
+	if err != nil || !changed {
+		t.Fatalf("test: check new file wanted changed got:%t %v", changed, err)
+	}
+	ioutil.WriteFile(path, b, 0600)
+	_, changed, err = checkFile("test.csv", path, b)
+	if err != nil || changed {
+		t.Fatalf("test: check same file wanted unchanged got:%t %v", changed, err)
+	}
+	if Checksums()["test.csv"] != sum {
+		t.Fatalf("test: checksum wanted:%s got:%s", sum, Checksums()["test.csv"])
+	}
+
+	SetFileCheck("test.csv", FileCheck{MinRows: 3})
+	_, _, err = checkFile("test.csv", path, b)
+	if err == nil {
+		t.Fatalf("test: check min rows wanted error")
+	}
+
+	SetFileCheck("test.csv", FileCheck{SHA256: sum})
+	_, _, err = checkFile("test.csv", path, []byte("<html>error</html>"))
+	if err == nil {
+		t.Fatalf("test: check checksum wanted error")
+	}
+}
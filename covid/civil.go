@@ -0,0 +1,57 @@
+package covid
+
+import (
+	"time"
+)
+
+// secondsPerDay is the length of a day in UTC, which has no DST changes
+const secondsPerDay = 24 * 60 * 60
+
+// Date is a civil date without a time of day or zone, used for day arithmetic on series
+// so that the time of day, zone or a DST change in an input never shifts a day index
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the civil date of t, as read in the location of t
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// Time returns the date as midnight UTC, the form of dates stored in series
+func (d Date) Time() time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// String returns the date formatted as 2006-01-02
+func (d Date) String() string {
+	return d.Time().Format("2006-01-02")
+}
+
+// AddDays returns the date n days after d, or before if n is negative
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.Time().AddDate(0, 0, n))
+}
+
+// DaysSince returns the number of days from o to d, negative if o is after d
+func (d Date) DaysSince(o Date) int {
+	return d.epochDays() - o.epochDays()
+}
+
+// epochDays returns the number of days since 1970-01-01, midnight UTC is always a whole number of days from it
+func (d Date) epochDays() int {
+	return int(d.Time().Unix() / secondsPerDay)
+}
+
+// StartDate returns the civil date of the first day of this series
+func (s *Series) StartDate() Date {
+	return DateOf(s.StartsAt)
+}
+
+// daysBetween returns the number of calendar days from from to to, negative if to is before from
+func daysBetween(from, to time.Time) int {
+	return DateOf(to).DaysSince(DateOf(from))
+}
@@ -0,0 +1,34 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDate(t *testing.T) {
+	// The day clocks go forward in New York is only 23 hours long, which duration maths rounds down
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("test: no zone data:%s", err)
+	}
+	from := time.Date(2020, 3, 8, 0, 0, 0, 0, ny)
+	to := time.Date(2020, 3, 9, 0, 0, 0, 0, ny)
+	if int(to.Sub(from).Hours()/24) != 0 || daysBetween(from, to) != 1 {
+		t.Fatalf("test: days between wanted:1 got:%d", daysBetween(from, to))
+	}
+
+	// Dates in other zones are read as written, not shifted to the UTC date
+	bangkok := time.FixedZone("ICT", 7*60*60)
+	d := DateOf(time.Date(2020, 3, 1, 1, 0, 0, 0, bangkok))
+	if d.String() != "2020-03-01" || !d.Time().Equal(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("test: date of wanted 2020-03-01 got:%s", d)
+	}
+
+	// Leap days are counted
+	if d.DaysSince(Date{2020, time.February, 28}) != 2 || d.AddDays(-1).String() != "2020-02-29" {
+		t.Fatalf("test: leap day wanted 2 days got:%d", d.DaysSince(Date{2020, time.February, 28}))
+	}
+	if daysBetween(to, from) != -1 {
+		t.Fatalf("test: days between wanted:-1 got:%d", daysBetween(to, from))
+	}
+}
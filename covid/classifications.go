@@ -0,0 +1,281 @@
+package covid
+
+import (
+	"fmt"
+)
+
+// World Bank income groups (FY2021)
+const (
+	IncomeLow         = "Low income"
+	IncomeLowerMiddle = "Lower middle income"
+	IncomeUpperMiddle = "Upper middle income"
+	IncomeHigh        = "High income"
+)
+
+// WHO regions
+const (
+	WHOAfrica               = "African Region"
+	WHOAmericas             = "Region of the Americas"
+	WHOSouthEastAsia        = "South-East Asia Region"
+	WHOEurope               = "European Region"
+	WHOEasternMediterranean = "Eastern Mediterranean Region"
+	WHOWesternPacific       = "Western Pacific Region"
+)
+
+// Kinds of classification for countries
+const (
+	ClassificationIncome = "income"
+	ClassificationWHO    = "who"
+)
+
+// classification holds the classifications for a country
+type classification struct {
+	Income string
+	WHO    string
+}
+
+// classifications are the World Bank income group and WHO region for countries keyed by ISO3 code
+// the Holy See is not classified, and Liechtenstein and Taiwan are not WHO members
+var classifications = map[string]classification{
+	"AFG": {IncomeLow, WHOEasternMediterranean},
+	"ALB": {IncomeUpperMiddle, WHOEurope},
+	"DZA": {IncomeLowerMiddle, WHOAfrica},
+	"AND": {IncomeHigh, WHOEurope},
+	"AGO": {IncomeLowerMiddle, WHOAfrica},
+	"ATG": {IncomeHigh, WHOAmericas},
+	"ARG": {IncomeUpperMiddle, WHOAmericas},
+	"ARM": {IncomeUpperMiddle, WHOEurope},
+	"AUS": {IncomeHigh, WHOWesternPacific},
+	"AUT": {IncomeHigh, WHOEurope},
+	"AZE": {IncomeUpperMiddle, WHOEurope},
+	"BHS": {IncomeHigh, WHOAmericas},
+	"BHR": {IncomeHigh, WHOEasternMediterranean},
+	"BGD": {IncomeLowerMiddle, WHOSouthEastAsia},
+	"BRB": {IncomeHigh, WHOAmericas},
+	"BLR": {IncomeUpperMiddle, WHOEurope},
+	"BEL": {IncomeHigh, WHOEurope},
+	"BLZ": {IncomeUpperMiddle, WHOAmericas},
+	"BEN": {IncomeLowerMiddle, WHOAfrica},
+	"BTN": {IncomeLowerMiddle, WHOSouthEastAsia},
+	"BOL": {IncomeLowerMiddle, WHOAmericas},
+	"BIH": {IncomeUpperMiddle, WHOEurope},
+	"BRA": {IncomeUpperMiddle, WHOAmericas},
+	"BRN": {IncomeHigh, WHOWesternPacific},
+	"BGR": {IncomeUpperMiddle, WHOEurope},
+	"BFA": {IncomeLow, WHOAfrica},
+	"CPV": {IncomeLowerMiddle, WHOAfrica},
+	"KHM": {IncomeLowerMiddle, WHOWesternPacific},
+	"CMR": {IncomeLowerMiddle, WHOAfrica},
+	"CAN": {IncomeHigh, WHOAmericas},
+	"CAF": {IncomeLow, WHOAfrica},
+	"TCD": {IncomeLow, WHOAfrica},
+	"CHL": {IncomeHigh, WHOAmericas},
+	"CHN": {IncomeUpperMiddle, WHOWesternPacific},
+	"COL": {IncomeUpperMiddle, WHOAmericas},
+	"COG": {IncomeLowerMiddle, WHOAfrica},
+	"COD": {IncomeLow, WHOAfrica},
+	"CRI": {IncomeUpperMiddle, WHOAmericas},
+	"CIV": {IncomeLowerMiddle, WHOAfrica},
+	"HRV": {IncomeHigh, WHOEurope},
+	"CUB": {IncomeUpperMiddle, WHOAmericas},
+	"CYP": {IncomeHigh, WHOEurope},
+	"CZE": {IncomeHigh, WHOEurope},
+	"DNK": {IncomeHigh, WHOEurope},
+	"DJI": {IncomeLowerMiddle, WHOEasternMediterranean},
+	"DMA": {IncomeUpperMiddle, WHOAmericas},
+	"DOM": {IncomeUpperMiddle, WHOAmericas},
+	"ECU": {IncomeUpperMiddle, WHOAmericas},
+	"EGY": {IncomeLowerMiddle, WHOEasternMediterranean},
+	"SLV": {IncomeLowerMiddle, WHOAmericas},
+	"GNQ": {IncomeUpperMiddle, WHOAfrica},
+	"ERI": {IncomeLow, WHOAfrica},
+	"EST": {IncomeHigh, WHOEurope},
+	"SWZ": {IncomeLowerMiddle, WHOAfrica},
+	"ETH": {IncomeLow, WHOAfrica},
+	"FJI": {IncomeUpperMiddle, WHOWesternPacific},
+	"FIN": {IncomeHigh, WHOEurope},
+	"FRA": {IncomeHigh, WHOEurope},
+	"GAB": {IncomeUpperMiddle, WHOAfrica},
+	"GMB": {IncomeLow, WHOAfrica},
+	"GEO": {IncomeUpperMiddle, WHOEurope},
+	"DEU": {IncomeHigh, WHOEurope},
+	"GHA": {IncomeLowerMiddle, WHOAfrica},
+	"GRC": {IncomeHigh, WHOEurope},
+	"GRD": {IncomeUpperMiddle, WHOAmericas},
+	"GTM": {IncomeUpperMiddle, WHOAmericas},
+	"GIN": {IncomeLow, WHOAfrica},
+	"GUY": {IncomeUpperMiddle, WHOAmericas},
+	"HTI": {IncomeLow, WHOAmericas},
+	"HND": {IncomeLowerMiddle, WHOAmericas},
+	"HUN": {IncomeHigh, WHOEurope},
+	"ISL": {IncomeHigh, WHOEurope},
+	"IND": {IncomeLowerMiddle, WHOSouthEastAsia},
+	"IDN": {IncomeUpperMiddle, WHOSouthEastAsia},
+	"IRN": {IncomeUpperMiddle, WHOEasternMediterranean},
+	"IRQ": {IncomeUpperMiddle, WHOEasternMediterranean},
+	"IRL": {IncomeHigh, WHOEurope},
+	"ISR": {IncomeHigh, WHOEurope},
+	"ITA": {IncomeHigh, WHOEurope},
+	"JAM": {IncomeUpperMiddle, WHOAmericas},
+	"JPN": {IncomeHigh, WHOWesternPacific},
+	"JOR": {IncomeUpperMiddle, WHOEasternMediterranean},
+	"KAZ": {IncomeUpperMiddle, WHOEurope},
+	"KEN": {IncomeLowerMiddle, WHOAfrica},
+	"KOR": {IncomeHigh, WHOWesternPacific},
+	"KWT": {IncomeHigh, WHOEasternMediterranean},
+	"KGZ": {IncomeLowerMiddle, WHOEurope},
+	"LVA": {IncomeHigh, WHOEurope},
+	"LBN": {IncomeUpperMiddle, WHOEasternMediterranean},
+	"LBR": {IncomeLow, WHOAfrica},
+	"LIE": {IncomeHigh, ""},
+	"LTU": {IncomeHigh, WHOEurope},
+	"LUX": {IncomeHigh, WHOEurope},
+	"MDG": {IncomeLow, WHOAfrica},
+	"MYS": {IncomeUpperMiddle, WHOWesternPacific},
+	"MDV": {IncomeUpperMiddle, WHOSouthEastAsia},
+	"MLT": {IncomeHigh, WHOEurope},
+	"MRT": {IncomeLowerMiddle, WHOAfrica},
+	"MUS": {IncomeHigh, WHOAfrica},
+	"MEX": {IncomeUpperMiddle, WHOAmericas},
+	"MDA": {IncomeLowerMiddle, WHOEurope},
+	"MCO": {IncomeHigh, WHOEurope},
+	"MNG": {IncomeLowerMiddle, WHOWesternPacific},
+	"MNE": {IncomeUpperMiddle, WHOEurope},
+	"MAR": {IncomeLowerMiddle, WHOEasternMediterranean},
+	"MOZ": {IncomeLow, WHOAfrica},
+	"NAM": {IncomeUpperMiddle, WHOAfrica},
+	"NPL": {IncomeLowerMiddle, WHOSouthEastAsia},
+	"NLD": {IncomeHigh, WHOEurope},
+	"NZL": {IncomeHigh, WHOWesternPacific},
+	"NIC": {IncomeLowerMiddle, WHOAmericas},
+	"NER": {IncomeLow, WHOAfrica},
+	"NGA": {IncomeLowerMiddle, WHOAfrica},
+	"MKD": {IncomeUpperMiddle, WHOEurope},
+	"NOR": {IncomeHigh, WHOEurope},
+	"OMN": {IncomeHigh, WHOEasternMediterranean},
+	"PAK": {IncomeLowerMiddle, WHOEasternMediterranean},
+	"PAN": {IncomeHigh, WHOAmericas},
+	"PNG": {IncomeLowerMiddle, WHOWesternPacific},
+	"PRY": {IncomeUpperMiddle, WHOAmericas},
+	"PER": {IncomeUpperMiddle, WHOAmericas},
+	"PHL": {IncomeLowerMiddle, WHOWesternPacific},
+	"POL": {IncomeHigh, WHOEurope},
+	"PRT": {IncomeHigh, WHOEurope},
+	"QAT": {IncomeHigh, WHOEasternMediterranean},
+	"ROU": {IncomeHigh, WHOEurope},
+	"RUS": {IncomeUpperMiddle, WHOEurope},
+	"RWA": {IncomeLow, WHOAfrica},
+	"LCA": {IncomeUpperMiddle, WHOAmericas},
+	"VCT": {IncomeUpperMiddle, WHOAmericas},
+	"SMR": {IncomeHigh, WHOEurope},
+	"SAU": {IncomeHigh, WHOEasternMediterranean},
+	"SEN": {IncomeLowerMiddle, WHOAfrica},
+	"SRB": {IncomeUpperMiddle, WHOEurope},
+	"SYC": {IncomeHigh, WHOAfrica},
+	"SGP": {IncomeHigh, WHOWesternPacific},
+	"SVK": {IncomeHigh, WHOEurope},
+	"SVN": {IncomeHigh, WHOEurope},
+	"SOM": {IncomeLow, WHOEasternMediterranean},
+	"ZAF": {IncomeUpperMiddle, WHOAfrica},
+	"ESP": {IncomeHigh, WHOEurope},
+	"LKA": {IncomeLowerMiddle, WHOSouthEastAsia},
+	"SDN": {IncomeLow, WHOEasternMediterranean},
+	"SUR": {IncomeUpperMiddle, WHOAmericas},
+	"SWE": {IncomeHigh, WHOEurope},
+	"CHE": {IncomeHigh, WHOEurope},
+	"SYR": {IncomeLow, WHOEasternMediterranean},
+	"TWN": {IncomeHigh, ""},
+	"TZA": {IncomeLowerMiddle, WHOAfrica},
+	"THA": {IncomeUpperMiddle, WHOSouthEastAsia},
+	"TLS": {IncomeLowerMiddle, WHOSouthEastAsia},
+	"TGO": {IncomeLow, WHOAfrica},
+	"TTO": {IncomeHigh, WHOAmericas},
+	"TUN": {IncomeLowerMiddle, WHOEasternMediterranean},
+	"TUR": {IncomeUpperMiddle, WHOEurope},
+	"USA": {IncomeHigh, WHOAmericas},
+	"UGA": {IncomeLow, WHOAfrica},
+	"UKR": {IncomeLowerMiddle, WHOEurope},
+	"ARE": {IncomeHigh, WHOEasternMediterranean},
+	"GBR": {IncomeHigh, WHOEurope},
+	"URY": {IncomeHigh, WHOAmericas},
+	"UZB": {IncomeLowerMiddle, WHOEurope},
+	"VEN": {IncomeUpperMiddle, WHOAmericas},
+	"VNM": {IncomeLowerMiddle, WHOWesternPacific},
+	"ZMB": {IncomeLowerMiddle, WHOAfrica},
+	"ZWE": {IncomeLowerMiddle, WHOAfrica},
+}
+
+// IncomeGroup returns the World Bank income group for this country, or a blank string if unknown
+func (c *Country) IncomeGroup() string {
+	return classifications[c.ISO3].Income
+}
+
+// WHORegion returns the WHO region for this country, or a blank string if unknown
+func (c *Country) WHORegion() string {
+	return classifications[c.ISO3].WHO
+}
+
+// IncomeGroup returns the World Bank income group for the country of this series (if known)
+func (s *Series) IncomeGroup() string {
+	c := s.Meta()
+	if c == nil {
+		return ""
+	}
+	return c.IncomeGroup()
+}
+
+// WHORegion returns the WHO region for the country of this series (if known)
+func (s *Series) WHORegion() string {
+	c := s.Meta()
+	if c == nil {
+		return ""
+	}
+	return c.WHORegion()
+}
+
+// Classification returns the classification of kind for the country of this series (if known)
+func (s *Series) Classification(kind string) string {
+	switch kind {
+	case ClassificationIncome:
+		return s.IncomeGroup()
+	case ClassificationWHO:
+		return s.WHORegion()
+	}
+	return ""
+}
+
+// Classified returns the country series in slice with the classification of kind given (matched by name or key)
+func (slice SeriesSlice) Classified(kind, value string) SeriesSlice {
+	var list SeriesSlice
+	for _, s := range slice.Countries() {
+		if c := s.Classification(kind); c != "" && Slug(c) == Slug(value) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// ClassificationGroups returns a series for each classification of kind summing the countries in it
+// only countries with a known population are included, so per capita values are population-weighted
+func (slice SeriesSlice) ClassificationGroups(kind string) SeriesSlice {
+	return slice.Countries().GroupBy(func(s *Series) string {
+		if s.Population() == 0 {
+			return ""
+		}
+		return s.Classification(kind)
+	})
+}
+
+// FetchClassification uses our stored data to build the series for an income group or WHO region (name or key)
+func FetchClassification(name string) (*Series, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, kind := range []string{ClassificationIncome, ClassificationWHO} {
+		for _, g := range data.ClassificationGroups(kind) {
+			if Slug(g.Country) == Slug(name) {
+				return g, nil
+			}
+		}
+	}
+	return &Series{}, fmt.Errorf("series: no data for classification:%s", name)
+}
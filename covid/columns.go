@@ -0,0 +1,92 @@
+package covid
+
+import (
+	"time"
+)
+
+// DateAxis is the dates shared by most series in our stored data, whose values are stored in shared columns
+// columnar storage covers values only: dates are not dropped from series, so each series keeps its own StartsAt,
+// as copies from Days, regions, datasets and snapshots start on other dates and StartsAt is part of our exports,
+// compact guarantees that the StartsAt of every series in the columns is the axis start, so loops across them can use the axis
+type DateAxis struct {
+	StartsAt time.Time `json:"starts_at"`
+	Days     int       `json:"days"`
+}
+
+// axis is the date axis of our stored data, protected by mutex
+var axis DateAxis
+
+// Axis returns the date axis shared by series in our stored data
+func Axis() DateAxis {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return axis
+}
+
+// Index returns the index of date on the axis, or -1 if it is outside the axis
+func (a DateAxis) Index(date time.Time) int {
+	i := daysBetween(a.StartsAt, date)
+	if i < 0 || i >= a.Days {
+		return -1
+	}
+	return i
+}
+
+// Date returns the date of day i on the axis
+func (a DateAxis) Date(i int) time.Time {
+	return a.StartsAt.AddDate(0, 0, i)
+}
+
+// Shares returns true if s has the dates of this axis
+func (a DateAxis) Shares(s *Series) bool {
+	return s.StartsAt.Equal(a.StartsAt) && len(s.Deaths) == a.Days && len(s.Confirmed) == a.Days &&
+		len(s.DeathsDaily) == a.Days && len(s.ConfirmedDaily) == a.Days
+}
+
+// commonAxis returns the dates shared by most series in slice
+func (slice SeriesSlice) commonAxis() DateAxis {
+	counts := make(map[DateAxis]int)
+	var common DateAxis
+	for _, s := range slice {
+		a := DateAxis{StartsAt: s.StartsAt.UTC(), Days: len(s.Deaths)}
+		counts[a]++
+		if counts[a] > counts[common] {
+			common = a
+		}
+	}
+	return common
+}
+
+// compact moves the values of series sharing the common axis into one contiguous column per metric,
+// so that loops across series read memory in order, and returns the axis, the series keep their StartsAt (see DateAxis)
+// other series e.g. the global series which has an extra day are left as they are
+// each series keeps a slice of the column with capacity limited to its own values,
+// so that appending a day to one series copies its values rather than overwriting the next
+func (slice SeriesSlice) compact() DateAxis {
+	a := slice.commonAxis()
+	var shared SeriesSlice
+	for _, s := range slice {
+		if a.Shares(s) {
+			shared = append(shared, s)
+		}
+	}
+	if a.Days == 0 || len(shared) == 0 {
+		return a
+	}
+
+	n := len(shared) * a.Days
+	deaths, confirmed := make([]int, n), make([]int, n)
+	deathsDaily, confirmedDaily := make([]int, n), make([]int, n)
+	for i, s := range shared {
+		start, end := i*a.Days, (i+1)*a.Days
+		copy(deaths[start:end], s.Deaths)
+		copy(confirmed[start:end], s.Confirmed)
+		copy(deathsDaily[start:end], s.DeathsDaily)
+		copy(confirmedDaily[start:end], s.ConfirmedDaily)
+		s.Deaths = deaths[start:end:end]
+		s.Confirmed = confirmed[start:end:end]
+		s.DeathsDaily = deathsDaily[start:end:end]
+		s.ConfirmedDaily = confirmedDaily[start:end:end]
+	}
+	return a
+}
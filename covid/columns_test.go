@@ -0,0 +1,52 @@
+package covid
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestCompact(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	italy := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3}, Confirmed: []int{1, 5, 10}}
+	spain := &Series{Country: "Spain", StartsAt: start, Deaths: []int{0, 2, 4}, Confirmed: []int{2, 6, 12}}
+	global := &Series{StartsAt: start, Deaths: []int{0, 3, 7, 7}, Confirmed: []int{3, 11, 22, 22}}
+	slice := SeriesSlice{italy, spain, global}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	a := slice.compact()
+	if !a.StartsAt.Equal(start) || a.Days != 3 || a.Index(start.AddDate(0, 0, 2)) != 2 || a.Index(start.AddDate(0, 0, 3)) != -1 {
+		t.Fatalf("test: compact wanted axis of 3 days got:%v", a)
+	}
+
+	// Series sharing the axis are stored in one column, in order
+	next := uintptr(unsafe.Pointer(&italy.Deaths[2])) + unsafe.Sizeof(italy.Deaths[2])
+	if cap(italy.Deaths) != 3 || next != uintptr(unsafe.Pointer(&spain.Deaths[0])) {
+		t.Fatalf("test: compact wanted contiguous columns")
+	}
+	if spain.Deaths[2] != 4 || spain.ConfirmedDaily[2] != 6 || global.Deaths[3] != 7 {
+		t.Fatalf("test: compact wanted values kept got:%v %v", spain.Deaths, spain.ConfirmedDaily)
+	}
+
+	// Only values are stored in columns, series in them keep the axis start and others keep their own
+	later := &Series{Country: "France", StartsAt: start.AddDate(0, 0, 1), Deaths: []int{1, 2, 3}, Confirmed: []int{1, 2, 3}}
+	later.UpdateDaily()
+	slice = append(slice, later)
+	a = slice.compact()
+	for _, s := range slice {
+		if a.Shares(s) != (s == italy || s == spain) {
+			t.Fatalf("test: compact wrong series in columns:%s", s.Country)
+		}
+	}
+	if !italy.StartsAt.Equal(a.StartsAt) || !later.StartsAt.Equal(start.AddDate(0, 0, 1)) || a.Date(1) != start.AddDate(0, 0, 1) {
+		t.Fatalf("test: compact wanted start dates kept got:%s %s", italy.StartsAt, later.StartsAt)
+	}
+
+	// Adding a day to a series must not alter the next series in the column
+	italy.AddDayData(3, time.Now(), 20, 5)
+	if spain.Deaths[0] != 0 || spain.Confirmed[0] != 2 || len(italy.Deaths) != 4 {
+		t.Fatalf("test: compact append altered next series:%v", spain.Deaths)
+	}
+}
@@ -0,0 +1,62 @@
+package covid
+
+// copyInts returns a copy of values, or nil if values is nil
+func copyInts(values []int) []int {
+	if values == nil {
+		return nil
+	}
+	return append([]int(nil), values...)
+}
+
+// Copy returns a copy of s with its own values, so that the copy can be changed without changing s
+// strata and tags are shared, as they are replaced rather than changed
+func (s *Series) Copy() *Series {
+	c := *s
+	c.Deaths = copyInts(s.Deaths)
+	c.Confirmed = copyInts(s.Confirmed)
+	c.DeathsDaily = copyInts(s.DeathsDaily)
+	c.ConfirmedDaily = copyInts(s.ConfirmedDaily)
+	c.Tests = copyInts(s.Tests)
+	if s.Auxiliary != nil {
+		c.Auxiliary = make(map[string][]float64, len(s.Auxiliary))
+		for name, values := range s.Auxiliary {
+			c.Auxiliary[name] = append([]float64(nil), values...)
+		}
+	}
+	return &c
+}
+
+// copyAll returns a copy of slice with a copy of every series, for changes to all of our stored data
+// which must not change series that callers may still be reading
+func (slice SeriesSlice) copyAll() SeriesSlice {
+	c := make(SeriesSlice, len(slice))
+	for i, s := range slice {
+		c[i] = s.Copy()
+	}
+	return c
+}
+
+// copyOnWrite replaces each series in list within slice by a copy, and returns the copies in the same order
+// so that a few series can be changed without changing series that callers may still be reading
+// series in list which are not in slice are copied but not stored
+func (slice SeriesSlice) copyOnWrite(list SeriesSlice) SeriesSlice {
+	index := make(map[*Series]int, len(slice))
+	for i, s := range slice {
+		index[s] = i
+	}
+	copies := make(SeriesSlice, len(list))
+	done := make(map[*Series]*Series, len(list))
+	for i, s := range list {
+		if c, ok := done[s]; ok {
+			copies[i] = c
+			continue
+		}
+		c := s.Copy()
+		if j, ok := index[s]; ok {
+			slice[j] = c
+		}
+		copies[i] = c
+		done[s] = c
+	}
+	return copies
+}
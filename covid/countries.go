@@ -0,0 +1,265 @@
+package covid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Country stores metadata for one country in the dataset
+type Country struct {
+	// The name as used in the dataset
+	Name string
+	// ISO 3166-1 alpha-2 and alpha-3 codes
+	ISO2 string
+	ISO3 string
+	// The continent this country belongs to
+	Continent string
+	// Approximate population (2020 estimates)
+	Population int
+}
+
+// Flag returns the emoji flag for this country, built from the ISO2 code
+func (c *Country) Flag() string {
+	if len(c.ISO2) != 2 {
+		return ""
+	}
+	// Flags are a pair of regional indicator symbols, offset from A-Z
+	flag := ""
+	for _, r := range strings.ToUpper(c.ISO2) {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+		flag += string(r - 'A' + 0x1F1E6)
+	}
+	return flag
+}
+
+// PopulationDisplay returns a short string representation of population e.g. 69.8M
+func (c *Country) PopulationDisplay() string {
+	p := c.Population
+	switch {
+	case p <= 0:
+		return ""
+	case p < 1000:
+		return fmt.Sprintf("%d", p)
+	case p < 1000000:
+		return fmt.Sprintf("%.1fK", float64(p)/1000)
+	case p < 1000000000:
+		return fmt.Sprintf("%.1fM", float64(p)/1000000)
+	}
+	return fmt.Sprintf("%.2fB", float64(p)/1000000000)
+}
+
+// Display returns a display name including flag, continent and population
+// e.g. 🇹🇭 Thailand (Asia, 69.8M)
+func (c *Country) Display() string {
+	return countryLabel(c.Name, c.Flag(), c.Continent, c.Population)
+}
+
+// countryLabel returns name with a flag, continent and population if we have them, for country displays and options
+func countryLabel(name, flag, continent string, population int) string {
+	if flag != "" {
+		name = flag + " " + name
+	}
+	if continent == "" || population == 0 {
+		return name
+	}
+	c := &Country{Population: population}
+	return fmt.Sprintf("%s (%s, %s)", name, continent, c.PopulationDisplay())
+}
+
+// FetchCountry returns the metadata for a country name (or key), if not found it returns nil
+func FetchCountry(name string) *Country {
+	return countries[strings.Replace(strings.ToLower(name), " ", "-", -1)]
+}
+
+// Continents for country metadata
+const (
+	Africa       = "Africa"
+	Asia         = "Asia"
+	Europe       = "Europe"
+	NorthAmerica = "North America"
+	Oceania      = "Oceania"
+	SouthAmerica = "South America"
+)
+
+// countries is our table of country metadata indexed by key
+var countries = make(map[string]*Country)
+
+// Build an index of countries by key (see Series.Key)
+func init() {
+	for _, c := range countryData {
+		countries[strings.Replace(strings.ToLower(c.Name), " ", "-", -1)] = c
+	}
+}
+
+// countryData is the embedded table of country metadata, names match those used in the dataset
+var countryData = []*Country{
+	{Name: "Afghanistan", ISO2: "AF", ISO3: "AFG", Continent: Asia, Population: 38928346},
+	{Name: "Albania", ISO2: "AL", ISO3: "ALB", Continent: Europe, Population: 2877797},
+	{Name: "Algeria", ISO2: "DZ", ISO3: "DZA", Continent: Africa, Population: 43851044},
+	{Name: "Andorra", ISO2: "AD", ISO3: "AND", Continent: Europe, Population: 77265},
+	{Name: "Angola", ISO2: "AO", ISO3: "AGO", Continent: Africa, Population: 32866272},
+	{Name: "Antigua and Barbuda", ISO2: "AG", ISO3: "ATG", Continent: NorthAmerica, Population: 97929},
+	{Name: "Argentina", ISO2: "AR", ISO3: "ARG", Continent: SouthAmerica, Population: 45195774},
+	{Name: "Armenia", ISO2: "AM", ISO3: "ARM", Continent: Asia, Population: 2963243},
+	{Name: "Australia", ISO2: "AU", ISO3: "AUS", Continent: Oceania, Population: 25499884},
+	{Name: "Austria", ISO2: "AT", ISO3: "AUT", Continent: Europe, Population: 9006398},
+	{Name: "Azerbaijan", ISO2: "AZ", ISO3: "AZE", Continent: Asia, Population: 10139177},
+	{Name: "Bahamas", ISO2: "BS", ISO3: "BHS", Continent: NorthAmerica, Population: 393244},
+	{Name: "Bahrain", ISO2: "BH", ISO3: "BHR", Continent: Asia, Population: 1701575},
+	{Name: "Bangladesh", ISO2: "BD", ISO3: "BGD", Continent: Asia, Population: 164689383},
+	{Name: "Barbados", ISO2: "BB", ISO3: "BRB", Continent: NorthAmerica, Population: 287375},
+	{Name: "Belarus", ISO2: "BY", ISO3: "BLR", Continent: Europe, Population: 9449323},
+	{Name: "Belgium", ISO2: "BE", ISO3: "BEL", Continent: Europe, Population: 11589623},
+	{Name: "Belize", ISO2: "BZ", ISO3: "BLZ", Continent: NorthAmerica, Population: 397628},
+	{Name: "Benin", ISO2: "BJ", ISO3: "BEN", Continent: Africa, Population: 12123200},
+	{Name: "Bhutan", ISO2: "BT", ISO3: "BTN", Continent: Asia, Population: 771608},
+	{Name: "Bolivia", ISO2: "BO", ISO3: "BOL", Continent: SouthAmerica, Population: 11673021},
+	{Name: "Bosnia and Herzegovina", ISO2: "BA", ISO3: "BIH", Continent: Europe, Population: 3280819},
+	{Name: "Brazil", ISO2: "BR", ISO3: "BRA", Continent: SouthAmerica, Population: 212559417},
+	{Name: "Brunei", ISO2: "BN", ISO3: "BRN", Continent: Asia, Population: 437479},
+	{Name: "Bulgaria", ISO2: "BG", ISO3: "BGR", Continent: Europe, Population: 6948445},
+	{Name: "Burkina Faso", ISO2: "BF", ISO3: "BFA", Continent: Africa, Population: 20903273},
+	{Name: "Cabo Verde", ISO2: "CV", ISO3: "CPV", Continent: Africa, Population: 555987},
+	{Name: "Cambodia", ISO2: "KH", ISO3: "KHM", Continent: Asia, Population: 16718965},
+	{Name: "Cameroon", ISO2: "CM", ISO3: "CMR", Continent: Africa, Population: 26545863},
+	{Name: "Canada", ISO2: "CA", ISO3: "CAN", Continent: NorthAmerica, Population: 37742154},
+	{Name: "Central African Republic", ISO2: "CF", ISO3: "CAF", Continent: Africa, Population: 4829767},
+	{Name: "Chad", ISO2: "TD", ISO3: "TCD", Continent: Africa, Population: 16425864},
+	{Name: "Chile", ISO2: "CL", ISO3: "CHL", Continent: SouthAmerica, Population: 19116201},
+	{Name: "China", ISO2: "CN", ISO3: "CHN", Continent: Asia, Population: 1439323776},
+	{Name: "Colombia", ISO2: "CO", ISO3: "COL", Continent: SouthAmerica, Population: 50882891},
+	{Name: "Congo (Brazzaville)", ISO2: "CG", ISO3: "COG", Continent: Africa, Population: 5518087},
+	{Name: "Congo (Kinshasa)", ISO2: "CD", ISO3: "COD", Continent: Africa, Population: 89561403},
+	{Name: "Costa Rica", ISO2: "CR", ISO3: "CRI", Continent: NorthAmerica, Population: 5094118},
+	{Name: "Cote d'Ivoire", ISO2: "CI", ISO3: "CIV", Continent: Africa, Population: 26378274},
+	{Name: "Croatia", ISO2: "HR", ISO3: "HRV", Continent: Europe, Population: 4105267},
+	{Name: "Cuba", ISO2: "CU", ISO3: "CUB", Continent: NorthAmerica, Population: 11326616},
+	{Name: "Cyprus", ISO2: "CY", ISO3: "CYP", Continent: Europe, Population: 1207359},
+	{Name: "Czechia", ISO2: "CZ", ISO3: "CZE", Continent: Europe, Population: 10708981},
+	{Name: "Denmark", ISO2: "DK", ISO3: "DNK", Continent: Europe, Population: 5792202},
+	{Name: "Djibouti", ISO2: "DJ", ISO3: "DJI", Continent: Africa, Population: 988000},
+	{Name: "Dominica", ISO2: "DM", ISO3: "DMA", Continent: NorthAmerica, Population: 71986},
+	{Name: "Dominican Republic", ISO2: "DO", ISO3: "DOM", Continent: NorthAmerica, Population: 10847910},
+	{Name: "Ecuador", ISO2: "EC", ISO3: "ECU", Continent: SouthAmerica, Population: 17643054},
+	{Name: "Egypt", ISO2: "EG", ISO3: "EGY", Continent: Africa, Population: 102334404},
+	{Name: "El Salvador", ISO2: "SV", ISO3: "SLV", Continent: NorthAmerica, Population: 6486205},
+	{Name: "Equatorial Guinea", ISO2: "GQ", ISO3: "GNQ", Continent: Africa, Population: 1402985},
+	{Name: "Eritrea", ISO2: "ER", ISO3: "ERI", Continent: Africa, Population: 3546421},
+	{Name: "Estonia", ISO2: "EE", ISO3: "EST", Continent: Europe, Population: 1326535},
+	{Name: "Eswatini", ISO2: "SZ", ISO3: "SWZ", Continent: Africa, Population: 1160164},
+	{Name: "Ethiopia", ISO2: "ET", ISO3: "ETH", Continent: Africa, Population: 114963588},
+	{Name: "Fiji", ISO2: "FJ", ISO3: "FJI", Continent: Oceania, Population: 896445},
+	{Name: "Finland", ISO2: "FI", ISO3: "FIN", Continent: Europe, Population: 5540720},
+	{Name: "France", ISO2: "FR", ISO3: "FRA", Continent: Europe, Population: 65273511},
+	{Name: "Gabon", ISO2: "GA", ISO3: "GAB", Continent: Africa, Population: 2225734},
+	{Name: "Gambia", ISO2: "GM", ISO3: "GMB", Continent: Africa, Population: 2416668},
+	{Name: "Georgia", ISO2: "GE", ISO3: "GEO", Continent: Asia, Population: 3989167},
+	{Name: "Germany", ISO2: "DE", ISO3: "DEU", Continent: Europe, Population: 83783942},
+	{Name: "Ghana", ISO2: "GH", ISO3: "GHA", Continent: Africa, Population: 31072940},
+	{Name: "Greece", ISO2: "GR", ISO3: "GRC", Continent: Europe, Population: 10423054},
+	{Name: "Grenada", ISO2: "GD", ISO3: "GRD", Continent: NorthAmerica, Population: 112523},
+	{Name: "Guatemala", ISO2: "GT", ISO3: "GTM", Continent: NorthAmerica, Population: 17915568},
+	{Name: "Guinea", ISO2: "GN", ISO3: "GIN", Continent: Africa, Population: 13132795},
+	{Name: "Guyana", ISO2: "GY", ISO3: "GUY", Continent: SouthAmerica, Population: 786552},
+	{Name: "Haiti", ISO2: "HT", ISO3: "HTI", Continent: NorthAmerica, Population: 11402528},
+	{Name: "Holy See", ISO2: "VA", ISO3: "VAT", Continent: Europe, Population: 801},
+	{Name: "Honduras", ISO2: "HN", ISO3: "HND", Continent: NorthAmerica, Population: 9904607},
+	{Name: "Hungary", ISO2: "HU", ISO3: "HUN", Continent: Europe, Population: 9660351},
+	{Name: "Iceland", ISO2: "IS", ISO3: "ISL", Continent: Europe, Population: 341243},
+	{Name: "India", ISO2: "IN", ISO3: "IND", Continent: Asia, Population: 1380004385},
+	{Name: "Indonesia", ISO2: "ID", ISO3: "IDN", Continent: Asia, Population: 273523615},
+	{Name: "Iran", ISO2: "IR", ISO3: "IRN", Continent: Asia, Population: 83992949},
+	{Name: "Iraq", ISO2: "IQ", ISO3: "IRQ", Continent: Asia, Population: 40222493},
+	{Name: "Ireland", ISO2: "IE", ISO3: "IRL", Continent: Europe, Population: 4937786},
+	{Name: "Israel", ISO2: "IL", ISO3: "ISR", Continent: Asia, Population: 8655535},
+	{Name: "Italy", ISO2: "IT", ISO3: "ITA", Continent: Europe, Population: 60461826},
+	{Name: "Jamaica", ISO2: "JM", ISO3: "JAM", Continent: NorthAmerica, Population: 2961167},
+	{Name: "Japan", ISO2: "JP", ISO3: "JPN", Continent: Asia, Population: 126476461},
+	{Name: "Jordan", ISO2: "JO", ISO3: "JOR", Continent: Asia, Population: 10203134},
+	{Name: "Kazakhstan", ISO2: "KZ", ISO3: "KAZ", Continent: Asia, Population: 18776707},
+	{Name: "Kenya", ISO2: "KE", ISO3: "KEN", Continent: Africa, Population: 53771296},
+	{Name: "Korea, South", ISO2: "KR", ISO3: "KOR", Continent: Asia, Population: 51269185},
+	{Name: "Kuwait", ISO2: "KW", ISO3: "KWT", Continent: Asia, Population: 4270571},
+	{Name: "Kyrgyzstan", ISO2: "KG", ISO3: "KGZ", Continent: Asia, Population: 6524195},
+	{Name: "Latvia", ISO2: "LV", ISO3: "LVA", Continent: Europe, Population: 1886198},
+	{Name: "Lebanon", ISO2: "LB", ISO3: "LBN", Continent: Asia, Population: 6825445},
+	{Name: "Liberia", ISO2: "LR", ISO3: "LBR", Continent: Africa, Population: 5057681},
+	{Name: "Liechtenstein", ISO2: "LI", ISO3: "LIE", Continent: Europe, Population: 38128},
+	{Name: "Lithuania", ISO2: "LT", ISO3: "LTU", Continent: Europe, Population: 2722289},
+	{Name: "Luxembourg", ISO2: "LU", ISO3: "LUX", Continent: Europe, Population: 625978},
+	{Name: "Madagascar", ISO2: "MG", ISO3: "MDG", Continent: Africa, Population: 27691018},
+	{Name: "Malaysia", ISO2: "MY", ISO3: "MYS", Continent: Asia, Population: 32365999},
+	{Name: "Maldives", ISO2: "MV", ISO3: "MDV", Continent: Asia, Population: 540544},
+	{Name: "Malta", ISO2: "MT", ISO3: "MLT", Continent: Europe, Population: 441543},
+	{Name: "Mauritania", ISO2: "MR", ISO3: "MRT", Continent: Africa, Population: 4649658},
+	{Name: "Mauritius", ISO2: "MU", ISO3: "MUS", Continent: Africa, Population: 1271768},
+	{Name: "Mexico", ISO2: "MX", ISO3: "MEX", Continent: NorthAmerica, Population: 128932753},
+	{Name: "Moldova", ISO2: "MD", ISO3: "MDA", Continent: Europe, Population: 4033963},
+	{Name: "Monaco", ISO2: "MC", ISO3: "MCO", Continent: Europe, Population: 39242},
+	{Name: "Mongolia", ISO2: "MN", ISO3: "MNG", Continent: Asia, Population: 3278290},
+	{Name: "Montenegro", ISO2: "ME", ISO3: "MNE", Continent: Europe, Population: 628066},
+	{Name: "Morocco", ISO2: "MA", ISO3: "MAR", Continent: Africa, Population: 36910560},
+	{Name: "Mozambique", ISO2: "MZ", ISO3: "MOZ", Continent: Africa, Population: 31255435},
+	{Name: "Namibia", ISO2: "NA", ISO3: "NAM", Continent: Africa, Population: 2540905},
+	{Name: "Nepal", ISO2: "NP", ISO3: "NPL", Continent: Asia, Population: 29136808},
+	{Name: "Netherlands", ISO2: "NL", ISO3: "NLD", Continent: Europe, Population: 17134872},
+	{Name: "New Zealand", ISO2: "NZ", ISO3: "NZL", Continent: Oceania, Population: 4822233},
+	{Name: "Nicaragua", ISO2: "NI", ISO3: "NIC", Continent: NorthAmerica, Population: 6624554},
+	{Name: "Niger", ISO2: "NE", ISO3: "NER", Continent: Africa, Population: 24206644},
+	{Name: "Nigeria", ISO2: "NG", ISO3: "NGA", Continent: Africa, Population: 206139589},
+	{Name: "North Macedonia", ISO2: "MK", ISO3: "MKD", Continent: Europe, Population: 2083374},
+	{Name: "Norway", ISO2: "NO", ISO3: "NOR", Continent: Europe, Population: 5421241},
+	{Name: "Oman", ISO2: "OM", ISO3: "OMN", Continent: Asia, Population: 5106626},
+	{Name: "Pakistan", ISO2: "PK", ISO3: "PAK", Continent: Asia, Population: 220892340},
+	{Name: "Panama", ISO2: "PA", ISO3: "PAN", Continent: NorthAmerica, Population: 4314767},
+	{Name: "Papua New Guinea", ISO2: "PG", ISO3: "PNG", Continent: Oceania, Population: 8947024},
+	{Name: "Paraguay", ISO2: "PY", ISO3: "PRY", Continent: SouthAmerica, Population: 7132538},
+	{Name: "Peru", ISO2: "PE", ISO3: "PER", Continent: SouthAmerica, Population: 32971854},
+	{Name: "Philippines", ISO2: "PH", ISO3: "PHL", Continent: Asia, Population: 109581078},
+	{Name: "Poland", ISO2: "PL", ISO3: "POL", Continent: Europe, Population: 37846611},
+	{Name: "Portugal", ISO2: "PT", ISO3: "PRT", Continent: Europe, Population: 10196709},
+	{Name: "Qatar", ISO2: "QA", ISO3: "QAT", Continent: Asia, Population: 2881053},
+	{Name: "Romania", ISO2: "RO", ISO3: "ROU", Continent: Europe, Population: 19237691},
+	{Name: "Russia", ISO2: "RU", ISO3: "RUS", Continent: Europe, Population: 145934462},
+	{Name: "Rwanda", ISO2: "RW", ISO3: "RWA", Continent: Africa, Population: 12952218},
+	{Name: "Saint Lucia", ISO2: "LC", ISO3: "LCA", Continent: NorthAmerica, Population: 183627},
+	{Name: "Saint Vincent and the Grenadines", ISO2: "VC", ISO3: "VCT", Continent: NorthAmerica, Population: 110940},
+	{Name: "San Marino", ISO2: "SM", ISO3: "SMR", Continent: Europe, Population: 33931},
+	{Name: "Saudi Arabia", ISO2: "SA", ISO3: "SAU", Continent: Asia, Population: 34813871},
+	{Name: "Senegal", ISO2: "SN", ISO3: "SEN", Continent: Africa, Population: 16743927},
+	{Name: "Serbia", ISO2: "RS", ISO3: "SRB", Continent: Europe, Population: 8737371},
+	{Name: "Seychelles", ISO2: "SC", ISO3: "SYC", Continent: Africa, Population: 98347},
+	{Name: "Singapore", ISO2: "SG", ISO3: "SGP", Continent: Asia, Population: 5850342},
+	{Name: "Slovakia", ISO2: "SK", ISO3: "SVK", Continent: Europe, Population: 5459642},
+	{Name: "Slovenia", ISO2: "SI", ISO3: "SVN", Continent: Europe, Population: 2078938},
+	{Name: "Somalia", ISO2: "SO", ISO3: "SOM", Continent: Africa, Population: 15893222},
+	{Name: "South Africa", ISO2: "ZA", ISO3: "ZAF", Continent: Africa, Population: 59308690},
+	{Name: "Spain", ISO2: "ES", ISO3: "ESP", Continent: Europe, Population: 46754778},
+	{Name: "Sri Lanka", ISO2: "LK", ISO3: "LKA", Continent: Asia, Population: 21413249},
+	{Name: "Sudan", ISO2: "SD", ISO3: "SDN", Continent: Africa, Population: 43849260},
+	{Name: "Suriname", ISO2: "SR", ISO3: "SUR", Continent: SouthAmerica, Population: 586632},
+	{Name: "Sweden", ISO2: "SE", ISO3: "SWE", Continent: Europe, Population: 10099265},
+	{Name: "Switzerland", ISO2: "CH", ISO3: "CHE", Continent: Europe, Population: 8654622},
+	{Name: "Syria", ISO2: "SY", ISO3: "SYR", Continent: Asia, Population: 17500658},
+	{Name: "Taiwan*", ISO2: "TW", ISO3: "TWN", Continent: Asia, Population: 23816775},
+	{Name: "Tanzania", ISO2: "TZ", ISO3: "TZA", Continent: Africa, Population: 59734218},
+	{Name: "Thailand", ISO2: "TH", ISO3: "THA", Continent: Asia, Population: 69799978},
+	{Name: "Timor-Leste", ISO2: "TL", ISO3: "TLS", Continent: Asia, Population: 1318445},
+	{Name: "Togo", ISO2: "TG", ISO3: "TGO", Continent: Africa, Population: 8278724},
+	{Name: "Trinidad and Tobago", ISO2: "TT", ISO3: "TTO", Continent: NorthAmerica, Population: 1399488},
+	{Name: "Tunisia", ISO2: "TN", ISO3: "TUN", Continent: Africa, Population: 11818619},
+	{Name: "Turkey", ISO2: "TR", ISO3: "TUR", Continent: Asia, Population: 84339067},
+	{Name: "US", ISO2: "US", ISO3: "USA", Continent: NorthAmerica, Population: 331002651},
+	{Name: "Uganda", ISO2: "UG", ISO3: "UGA", Continent: Africa, Population: 45741007},
+	{Name: "Ukraine", ISO2: "UA", ISO3: "UKR", Continent: Europe, Population: 43733762},
+	{Name: "United Arab Emirates", ISO2: "AE", ISO3: "ARE", Continent: Asia, Population: 9890402},
+	{Name: "United Kingdom", ISO2: "GB", ISO3: "GBR", Continent: Europe, Population: 67886011},
+	{Name: "Uruguay", ISO2: "UY", ISO3: "URY", Continent: SouthAmerica, Population: 3473730},
+	{Name: "Uzbekistan", ISO2: "UZ", ISO3: "UZB", Continent: Asia, Population: 33469203},
+	{Name: "Venezuela", ISO2: "VE", ISO3: "VEN", Continent: SouthAmerica, Population: 28435940},
+	{Name: "Vietnam", ISO2: "VN", ISO3: "VNM", Continent: Asia, Population: 97338579},
+	{Name: "Zambia", ISO2: "ZM", ISO3: "ZMB", Continent: Africa, Population: 18383955},
+	{Name: "Zimbabwe", ISO2: "ZW", ISO3: "ZWE", Continent: Africa, Population: 14862924},
+}
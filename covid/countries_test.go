@@ -0,0 +1,78 @@
+package covid
+
+import (
+	"testing"
+)
+
+func TestCountryMeta(t *testing.T) {
+
+	c := FetchCountry("thailand")
+	if c == nil {
+		t.Fatalf("test: failed fetching country metadata for Thailand")
+	}
+
+	want := "🇹🇭 Thailand (Asia, 69.8M)"
+	if c.Display() != want {
+		t.Errorf("test: country display wanted:%s got:%s", want, c.Display())
+	}
+
+	// Options for countries are labelled the same way, leaving out what we don't know
+	o := Option{Name: c.Name, Flag: c.Flag(), Continent: c.Continent, Population: c.Population}
+	if o.Label() != want || (Option{Name: "Atlantis", Continent: "Asia"}).Label() != "Atlantis" {
+		t.Errorf("test: option label wanted:%s got:%s", want, o.Label())
+	}
+
+	series := &Series{Country: "United Kingdom"}
+	if series.ISO() != "GB" || series.Flag() != "🇬🇧" {
+		t.Errorf("test: series metadata wrong for UK got:%s %s", series.ISO(), series.Flag())
+	}
+
+	// Unknown countries should have no metadata
+	series = &Series{Country: "Cruise Ship"}
+	if series.Meta() != nil || series.Population() != 0 {
+		t.Errorf("test: unexpected metadata for Cruise Ship")
+	}
+}
+
+func TestClassifications(t *testing.T) {
+	for _, c := range countryData {
+		if c.ISO3 == "VAT" {
+			continue
+		}
+		if c.IncomeGroup() == "" {
+			t.Fatalf("test: income group missing for:%s", c.Name)
+		}
+	}
+
+	slice := SeriesSlice{
+		&Series{Country: "Thailand", Deaths: []int{1}, Confirmed: []int{10}},
+		&Series{Country: "Japan", Deaths: []int{2}, Confirmed: []int{20}},
+		&Series{Country: "Germany", Deaths: []int{3}, Confirmed: []int{30}},
+	}
+	if len(slice.Classified(ClassificationIncome, "high-income")) != 2 {
+		t.Fatalf("test: classified wanted:2 got:%d", len(slice.Classified(ClassificationIncome, "high-income")))
+	}
+	groups := slice.ClassificationGroups(ClassificationWHO)
+	if len(groups) != 3 || groups[0].Country != WHOEurope {
+		t.Fatalf("test: classification groups wanted:3 got:%d", len(groups))
+	}
+}
+
+func TestProvinceOptions(t *testing.T) {
+	slice := SeriesSlice{
+		&Series{Country: "France"},
+		&Series{Country: "France", Province: "Reunion"},
+		&Series{Country: "France", Province: "Ile-de-France"},
+		&Series{Country: "Australia", Province: "Victoria"},
+	}
+	options := slice.ProvinceOptions("France")
+	if len(options) != 3 {
+		t.Fatalf("test: province options wrong wanted:3 got:%d", len(options))
+	}
+	if options[1].Value != "ile-de-france" || options[1].Group != "" {
+		t.Errorf("test: province option wrong got:%s %s", options[1].Value, options[1].Group)
+	}
+	if options[2].Value != "reunion" || options[2].Group != GroupTerritories {
+		t.Errorf("test: territory option wrong got:%s %s", options[2].Value, options[2].Group)
+	}
+}
@@ -2,6 +2,7 @@ package covid
 
 import (
 	"fmt"
+	"html/template"
 	"log"
 	"strconv"
 	"strings"
@@ -10,6 +11,12 @@ import (
 )
 
 // Data mutex to protect access to data
+//
+// The concurrency contract for our stored data is:
+//   - functions reading data hold mutex.RLock, functions replacing or changing it hold mutex.Lock
+//   - series are never changed once stored, as callers keep series after the lock is released,
+//     so changes to stored data replace the series changed with copies (see copyAll and copyOnWrite)
+//   - series returned by package functions are shared, and must not be modified by callers
 var mutex sync.RWMutex
 
 // Store our data globally, use mutex to access
@@ -22,26 +29,62 @@ const (
 	DataRecovered // No longer active
 	DataTodayState
 	DataTodayCountry
+	DataIncidence14 // Calculated from confirmed, not imported
+	DataTests
+	DataStringency
+	DataGoogleMobility
+	DataAppleMobility
+	DataHospital
+	DataStrata
+	DataVariants
+	DataTracking
 )
 
 // Series stores data for one country or province within a country
 type Series struct {
 	// UTC Date data last updated
 	UpdatedAt time.Time
+	// UTC Dates each metric was last updated by each source, nil if unknown
+	Updates []Update
+	// UTC Date from which data is stale because a source failed to load, zero if not stale
+	StaleSince time.Time
 	// The Country or Region
 	Country string
 	// The Province or State - may be blank for countries
 	Province string
 	// The date at which the series starts - all datasets must be the same length
+	// most series share their dates with others, see DateAxis, and store their values in shared columns
 	StartsAt time.Time
+	// The location given for this series in the source data, 0 if unknown
+	Latitude  float64
+	Longitude float64
 	// Total Deaths, Confirmed or Recovered by day (cumulative)
 	Deaths    []int
 	Confirmed []int
 	//	Recovered []int
+	// Total tests by day (cumulative) - nil unless testing data is loaded, 0 where unknown
+	Tests []int
+	// Additional values by day from other sources keyed by name e.g. stringency
+	Auxiliary map[string][]float64
+	// Breakdowns of deaths and confirmed by age or sex, for sources that provide them
+	Strata []*Stratum
+	// Curated groupings this series belongs to e.g. G7, set on load from config
+	Tags []string
+	// True if hidden by an admin, hidden series keep their data but are left out of options, global totals and country lists
+	Hidden bool
+	// Values for today from daily data not yet in the official time series, nil if there are none
+	Provisional *Provisional
 
 	// Daily totals
 	DeathsDaily    []int
 	ConfirmedDaily []int
+
+	// The unique slug for this series, set on load
+	slug string
+	// True for provinces only found in daily state data, as their country totals already include them
+	excludeGlobal bool
+	// The population of aggregate series such as regions, which have no country metadata
+	population int
 }
 
 // UpdatedAtDisplay retuns a string to display updated at date (if we have a date)
@@ -49,7 +92,7 @@ func (s *Series) UpdatedAtDisplay() string {
 	if s.UpdatedAt.IsZero() {
 		return ""
 	}
-	return fmt.Sprintf("Data last updated at %s", s.UpdatedAt.Format("2006-01-02 15:04 MST"))
+	return fmt.Sprintf("Data last updated at %s%s", s.UpdatedAt.In(dayLocation).Format("2006-01-02 15:04 MST"), s.laggingDisplay())
 }
 
 // Title returns a display title for this series
@@ -64,38 +107,72 @@ func (s *Series) Title() string {
 }
 
 // Dates returns a set of date labels as an array of strings
-// for every datapoint in this series
-func (s *Series) Dates() (dates []string) {
-	d := s.StartsAt
-	for range s.Deaths {
-		dates = append(dates, d.Format("Jan 2"))
-		d = d.AddDate(0, 0, 1)
-	}
-	return dates
+// for every datapoint in this series, the labels are shared with other series and must not be modified
+func (s *Series) Dates() []string {
+	return dateLabels(s.StartsAt, len(s.Deaths), "Jan 2")
 }
 
 // FetchDate retuns the data for the given date from datum
 func (s *Series) FetchDate(datum int, date time.Time) int {
-
-	// Calculate index in series given StartsAt
-	days := date.Sub(s.StartsAt)
-	i := int(days.Hours() / 24)
-
-	// Bounds check index
-	if i < 0 || i > len(s.Deaths)-1 {
+	p, ok := s.At(date)
+	if !ok {
 		return 0
 	}
-
-	// Fetch the data at index
 	switch datum {
 	case DataDeaths:
-		return s.Deaths[i]
+		return p.Deaths
 	case DataConfirmed:
-		return s.Confirmed[i]
+		return p.Confirmed
 	}
 	return 0
 }
 
+// Meta returns the country metadata for this series, or nil if none is found
+// provinces share the metadata of their country
+func (s *Series) Meta() *Country {
+	return FetchCountry(s.Country)
+}
+
+// Flag returns the emoji flag for the country of this series (if known)
+func (s *Series) Flag() string {
+	c := s.Meta()
+	if c == nil {
+		return ""
+	}
+	return c.Flag()
+}
+
+// ISO returns the ISO 3166-1 alpha-2 code for the country of this series (if known)
+func (s *Series) ISO() string {
+	c := s.Meta()
+	if c == nil {
+		return ""
+	}
+	return c.ISO2
+}
+
+// Continent returns the continent for the country of this series (if known)
+func (s *Series) Continent() string {
+	c := s.Meta()
+	if c == nil {
+		return ""
+	}
+	return c.Continent
+}
+
+// Population returns the population of the country for this series
+// we don't have population data for provinces, so they return 0
+func (s *Series) Population() int {
+	if s.population > 0 {
+		return s.population
+	}
+	c := s.Meta()
+	if c == nil || s.Province != "" {
+		return 0
+	}
+	return c.Population
+}
+
 // Valid returns true if this series is valid
 // a series without a start date set is considered invalid
 func (s *Series) Valid() bool {
@@ -153,6 +230,7 @@ func (s *Series) Merge(series *Series) {
 	if !series.UpdatedAt.IsZero() && (s.UpdatedAt.IsZero() || series.UpdatedAt.After(s.UpdatedAt)) {
 		s.UpdatedAt = series.UpdatedAt
 	}
+	s.mergeUpdates(series)
 
 }
 
@@ -175,10 +253,26 @@ func (s *Series) MergeFinalDay(series *Series) error {
 	if !series.UpdatedAt.IsZero() && (s.UpdatedAt.IsZero() || series.UpdatedAt.After(s.UpdatedAt)) {
 		s.UpdatedAt = series.UpdatedAt
 	}
+	s.mergeUpdates(series)
 
 	return nil
 }
 
+// mergeDay adds the values of series for day i to ours, if series has data for that day
+func (s *Series) mergeDay(series *Series, i int) {
+	if i >= len(series.Deaths) || i >= len(series.Confirmed) || i >= len(series.DeathsDaily) || i >= len(series.ConfirmedDaily) || i >= len(s.Deaths) {
+		return
+	}
+	s.Confirmed[i] += series.Confirmed[i]
+	s.Deaths[i] += series.Deaths[i]
+	s.DeathsDaily[i] += series.DeathsDaily[i]
+	s.ConfirmedDaily[i] += series.ConfirmedDaily[i]
+	if !series.UpdatedAt.IsZero() && (s.UpdatedAt.IsZero() || series.UpdatedAt.After(s.UpdatedAt)) {
+		s.UpdatedAt = series.UpdatedAt
+	}
+	s.mergeUpdates(series)
+}
+
 // Global returns true if this is the global series
 func (s *Series) Global() bool {
 	return s.Country == "" && s.Province == ""
@@ -186,6 +280,10 @@ func (s *Series) Global() bool {
 
 // AddToGlobal returns true if this is the global series
 func (s *Series) AddToGlobal() bool {
+	if s.excludeGlobal || s.Hidden {
+		return false
+	}
+
 	// For provinces - add all
 	if s.Province != "" {
 		return true
@@ -196,6 +294,9 @@ func (s *Series) AddToGlobal() bool {
 	switch s.Country {
 	case "":
 		return false
+	case "Cruise Ship":
+		// cruise ship cases are reported as a country in daily data only, without the history to add to global
+		return false
 	case "US":
 		// the US province data is now missing - when added in perhaps remove it here?
 	//	return false
@@ -212,6 +313,19 @@ func (s *Series) AddToGlobal() bool {
 	return true
 }
 
+// summedFromProvinces returns true if this is a country series we build from the sum of its provinces,
+// because the time series has no country level data for it
+func (s *Series) summedFromProvinces() bool {
+	if s.Province != "" {
+		return false
+	}
+	switch s.Country {
+	case "China", "Australia", "Canada":
+		return true
+	}
+	return false
+}
+
 // Format formats a given number for display and returns a string
 func (s *Series) Format(i int) string {
 	if i < 10000 {
@@ -234,14 +348,14 @@ func (s *Series) ConfirmedDisplay() string {
 	return s.Format(s.TotalConfirmed())
 }
 
-// ConfirmedToday returns a string representation of confirmed for last data in series
+// ConfirmedToday returns a string representation of confirmed for last data in series, or 0 if there is none
 func (s *Series) ConfirmedToday() string {
-	return s.Format(s.ConfirmedDaily[len(s.ConfirmedDaily)-1])
+	return s.Format(todayValue(s.ConfirmedDaily))
 }
 
-// DeathsToday returns a string representation of deaths for last data in series
+// DeathsToday returns a string representation of deaths for last data in series, or 0 if there is none
 func (s *Series) DeathsToday() string {
-	return s.Format(s.DeathsDaily[len(s.DeathsDaily)-1])
+	return s.Format(todayValue(s.DeathsDaily))
 }
 
 // DailyData - for a given series of cumulative total ints,
@@ -258,26 +372,64 @@ func (s *Series) DailyData(start int, ints []int) (daily []int) {
 	return daily
 }
 
-// TotalDeaths returns the cumulative death due to COVID-19 for this series
+// TotalDeaths returns the deaths due to COVID-19 over the days in this series
+// this is the cumulative total unless the series has been limited with Days
 func (s *Series) TotalDeaths() int {
-	if len(s.Deaths) == 0 {
+	return s.TotalIn(DataDeaths, 0)
+}
+
+// TotalConfirmed returns the confirmed cases of COVID-19 over the days in this series
+// this is the cumulative total unless the series has been limited with Days
+func (s *Series) TotalConfirmed() int {
+	return s.TotalIn(DataConfirmed, 0)
+}
+
+// TotalIn returns the total for datum over the last days of this series, or all days if days is 0
+// the daily value of the first day is used where available, so a series limited with Days gives totals within those days
+// this is called for every comparison when sorting, so it must not loop over the days
+func (s *Series) TotalIn(datum, days int) int {
+	var values, daily []int
+	switch datum {
+	case DataDeaths:
+		values, daily = s.Deaths, s.DeathsDaily
+	case DataConfirmed:
+		values, daily = s.Confirmed, s.ConfirmedDaily
+	}
+	n := len(values)
+	if n == 0 {
 		return 0
 	}
-	if len(s.Deaths) < 2 || len(s.Deaths) > 60 {
-		return s.Deaths[len(s.Deaths)-1]
+	if days <= 0 || days > n {
+		days = n
+	}
+
+	if len(daily) == n {
+		return values[n-1] - values[n-days] + daily[n-days]
+	}
+	if days == n {
+		return values[n-1]
 	}
-	return s.Deaths[len(s.Deaths)-1] - s.Deaths[0]
+	return values[n-1] - values[n-1-days]
 }
 
-// TotalConfirmed returns the cumulative confirmed cases of COVID-19 for this series
-func (s *Series) TotalConfirmed() int {
-	if len(s.Confirmed) == 0 {
+// CumulativeAt returns the cumulative value for datum at the end of date
+// 0 before the series starts, and the last value after it ends
+func (s *Series) CumulativeAt(datum int, date time.Time) int {
+	var values []int
+	switch datum {
+	case DataDeaths:
+		values = s.Deaths
+	case DataConfirmed:
+		values = s.Confirmed
+	}
+	i := dayIndex(s.StartsAt, date)
+	if len(values) == 0 || i < 0 {
 		return 0
 	}
-	if len(s.Confirmed) < 2 || len(s.Confirmed) > 60 {
-		return s.Confirmed[len(s.Confirmed)-1]
+	if i >= len(values) {
+		i = len(values) - 1
 	}
-	return s.Confirmed[len(s.Confirmed)-1] - s.Confirmed[0]
+	return values[i]
 }
 
 // Days returns a copy of this series for just the given number of days in the past
@@ -287,15 +439,34 @@ func (s *Series) Days(days int) *Series {
 	}
 
 	i := len(s.Deaths) - days
-	return &Series{
+	series := &Series{
 		Country:        s.Country,
 		Province:       s.Province,
 		StartsAt:       s.StartsAt.AddDate(0, 0, i),
-		Deaths:         s.Deaths[i:],
-		Confirmed:      s.Confirmed[i:],
-		DeathsDaily:    s.DeathsDaily[i:],
-		ConfirmedDaily: s.ConfirmedDaily[i:],
+		Deaths:         copyInts(s.Deaths[i:]),
+		Confirmed:      copyInts(s.Confirmed[i:]),
+		DeathsDaily:    copyInts(s.DeathsDaily[i:]),
+		ConfirmedDaily: copyInts(s.ConfirmedDaily[i:]),
+		StaleSince:     s.StaleSince,
+		Tags:           s.Tags,
+		slug:           s.slug,
+		population:     s.population,
+	}
+	if s.Provisional != nil && !s.Provisional.Date.Before(series.StartsAt) {
+		series.Provisional = s.Provisional
 	}
+	if len(s.Tests) == len(s.Deaths) {
+		series.Tests = copyInts(s.Tests[i:])
+	}
+	for name, values := range s.Auxiliary {
+		if len(values) == len(s.Deaths) {
+			series.SetAuxiliaryValues(name, append([]float64(nil), values[i:]...))
+		}
+	}
+	for _, st := range s.Strata {
+		series.Strata = append(series.Strata, st.days(i))
+	}
+	return series
 }
 
 // UpdateDaily updates the confirmed daily based on a new set of values for Confirmed
@@ -319,21 +490,41 @@ func (s *Series) UpdateDaily() {
 	}
 }
 
+// maxDayGap is the most days daily data may skip past the end of a series, e.g. after missed refreshes
+// indexes further ahead are more likely a bad date than missed days, so they are rejected
+const maxDayGap = 7
+
 // AddDayData sets the data at dayIndex to the supplied data
-// if necessary a day will be added
-func (s *Series) AddDayData(dayIndex int, updated time.Time, confirmed, deaths int) {
-	s.UpdatedAt = updated
+// if necessary days will be added, any days skipped since the end of the series carry forward its last values
+func (s *Series) AddDayData(dayIndex int, updated time.Time, confirmed, deaths int) error {
+	if dayIndex < 0 {
+		return fmt.Errorf("series: invalid day index:%d", dayIndex)
+	}
+	if dayIndex > len(s.Deaths)+maxDayGap {
+		return fmt.Errorf("series: day index:%d too far past end of series:%d", dayIndex, len(s.Deaths))
+	}
+	if len(s.Confirmed) != len(s.Deaths) {
+		return fmt.Errorf("series: inconsistent lengths for %s %s", s.Country, s.Province)
+	}
 
-	if dayIndex > len(s.Deaths)-1 {
-		//	fmt.Printf("dayIndex:%d %d\n", dayIndex, len(s.Deaths))
+	// Carry the last values forward over any missed days, then add the new day
+	for len(s.Deaths) < dayIndex {
+		lastDeaths, lastConfirmed := 0, 0
+		if n := len(s.Deaths); n > 0 {
+			lastDeaths, lastConfirmed = s.Deaths[n-1], s.Confirmed[n-1]
+		}
+		s.Deaths = append(s.Deaths, lastDeaths)
+		s.Confirmed = append(s.Confirmed, lastConfirmed)
+	}
+	if dayIndex == len(s.Deaths) {
 		s.Deaths = append(s.Deaths, deaths)
 		s.Confirmed = append(s.Confirmed, confirmed)
 	} else {
-		//	fmt.Printf("dayIndex exists:%d %d\n", dayIndex, len(s.Deaths))
 		s.Deaths[dayIndex] = deaths
 		s.Confirmed[dayIndex] = confirmed
 	}
-
+	s.UpdatedAt = updated
+	return nil
 }
 
 // SLICE OF Series
@@ -396,6 +587,64 @@ func FetchSeries(country string, province string) (*Series, error) {
 	return data.FetchSeries(country, province)
 }
 
+// SeriesFilter selects series in listings, blank fields match all series
+type SeriesFilter struct {
+	// Only include country level series
+	Countries bool
+	// Only include series with this tag
+	Tag string
+	// Only include countries in this World Bank income group
+	Income string
+	// Only include countries in this WHO region
+	WHORegion string
+}
+
+// Filter returns the series in slice matching filter
+func (slice SeriesSlice) Filter(filter SeriesFilter) SeriesSlice {
+	list := slice
+	if filter.Countries {
+		list = list.Countries()
+	}
+	if filter.Tag != "" {
+		list = list.WithTag(filter.Tag)
+	}
+	if filter.Income != "" {
+		list = list.Classified(ClassificationIncome, filter.Income)
+	}
+	if filter.WHORegion != "" {
+		list = list.Classified(ClassificationWHO, filter.WHORegion)
+	}
+	return list
+}
+
+// ListSeries uses our stored data to return a page of series matching filter starting at offset,
+// and the total available, limit 0 means no limit
+func ListSeries(filter SeriesFilter, offset, limit int) (SeriesSlice, int) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.List(filter, offset, limit)
+}
+
+// List returns a page of series in slice matching filter starting at offset,
+// and the total available, limit 0 means no limit
+func (slice SeriesSlice) List(filter SeriesFilter, offset, limit int) (SeriesSlice, int) {
+	list := slice.Filter(filter)
+	total := len(list)
+
+	if offset < 0 || offset >= total {
+		return SeriesSlice{}, total
+	}
+	list = list[offset:]
+	if limit > 0 && limit < len(list) {
+		list = list[:limit]
+	}
+
+	// Return a copy of the slice so that callers can't alter our data
+	page := make(SeriesSlice, len(list))
+	copy(page, list)
+	return page, total
+}
+
 // CountryOptions uses our stored data to fetch country options
 func CountryOptions() (options []Option) {
 	mutex.RLock()
@@ -410,39 +659,88 @@ func ProvinceOptions(country string) (options []Option) {
 	return data.ProvinceOptions(country)
 }
 
-// PeriodOptions returns a set of options for period filters
-func PeriodOptions() (options []Option) {
+// CountryGroups uses our stored data to fetch country options grouped by continent
+func CountryGroups() []OptionGroup {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.CountryGroups()
+}
 
-	options = append(options, Option{Name: "All Time", Value: "0"})
-	options = append(options, Option{Name: "112 Days", Value: "112"})
-	options = append(options, Option{Name: "56 Days", Value: "56"})
-	options = append(options, Option{Name: "28 Days", Value: "28"})
-	options = append(options, Option{Name: "14 Days", Value: "14"})
-	options = append(options, Option{Name: "7 Days", Value: "7"})
-	options = append(options, Option{Name: "3 Days", Value: "3"})
-	options = append(options, Option{Name: "2 Days", Value: "2"})
+// OptionTree uses our stored data to fetch country options grouped by continent with their provinces
+func OptionTree() []OptionGroup {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.OptionTree()
+}
 
+// Periods are the periods in days offered in period filters, 0 is all time
+var Periods = []int{0, 112, 56, 28, 14, 7, 3, 2}
+
+// PeriodOptions returns a set of options for period filters
+func PeriodOptions() (options []Option) {
+	for _, days := range Periods {
+		name := fmt.Sprintf("%d Days", days)
+		if days == 0 {
+			name = "All Time"
+		}
+		options = append(options, Option{Name: name, Value: strconv.Itoa(days), Number: days})
+	}
 	return options
 }
 
 // Option is used to generate options for selects in the view
 type Option struct {
-	Name  string
-	Value string
+	Name  string `json:"name"`
+	Value string `json:"value"`
+
+	// The numeric value for options with numeric values e.g. period days
+	Number int `json:"number,omitempty"`
+
+	// The group this option is shown under, if any
+	Group string `json:"group,omitempty"`
+
+	// Optional country metadata
+	Flag       string `json:"flag,omitempty"`
+	ISO        string `json:"iso,omitempty"`
+	Continent  string `json:"continent,omitempty"`
+	Population int    `json:"population,omitempty"`
+
+	// An inline svg showing the recent trend in daily deaths, included as markup in json
+	Sparkline template.HTML `json:"sparkline,omitempty"`
+
+	// Optional nested options e.g. the provinces of a country
+	Options []Option `json:"options,omitempty"`
+}
+
+// Label returns the name of this option prefixed with flag and followed by continent and population if available
+// e.g. 🇹🇭 Thailand (Asia, 69.8M)
+func (o Option) Label() string {
+	return countryLabel(o.Name, o.Flag, o.Continent, o.Population)
 }
 
 // CountryOptions returns a set of options for the country dropdown (including a global one)
+// countries are grouped by continent, or GroupOther if we have no metadata for them
 func (slice SeriesSlice) CountryOptions() (options []Option) {
 
 	options = append(options, Option{Name: "Global", Value: ""})
 
 	for _, s := range slice {
-		if s.Province == "" && s.Country != "" {
+		if s.Province == "" && s.Country != "" && !s.Hidden {
 			name := s.Country
 			if s.TotalDeaths() > 0 {
 				name = fmt.Sprintf("%s (%d Deaths)", s.Country, s.TotalDeaths())
 			}
-			options = append(options, Option{Name: name, Value: s.Key(s.Country)})
+			name += s.staleLabel()
+			option := Option{Name: name, Value: s.Key(s.Country), Sparkline: s.Sparkline(DataDeaths, sparklineDays, sparklineWidth, sparklineHeight)}
+			option.Group = GroupOther
+			if c := s.Meta(); c != nil {
+				option.Flag = c.Flag()
+				option.ISO = c.ISO2
+				option.Continent = c.Continent
+				option.Population = c.Population
+				option.Group = c.Continent
+			}
+			options = append(options, option)
 		}
 	}
 
@@ -450,29 +748,31 @@ func (slice SeriesSlice) CountryOptions() (options []Option) {
 }
 
 // ProvinceOptions returns a set of options for the province dropdown
-// this should probably be based on the current country selection, and filtered from there
-// to avoid inconsistency
-// for now just show all which have province filled in.
+// provinces which are overseas territories rather than a breakdown of the country follow the others
+// in the GroupTerritories group
 func (slice SeriesSlice) ProvinceOptions(country string) (options []Option) {
 
 	options = append(options, Option{Name: "All Areas", Value: ""})
 
-	// Ignore UK and France for now as these are just outlying areas, not a breakdown
-	if country == "United Kingdom" || country == "France" {
-		return options
-	}
-
+	var territories []Option
 	for _, s := range slice {
-		if s.Country == country && s.Province != "" {
+		if s.Country == country && s.Province != "" && !s.Hidden {
 			name := s.Province
 			if s.TotalDeaths() > 0 {
 				name = fmt.Sprintf("%s (%d Deaths)", s.Province, s.TotalDeaths())
 			}
-			options = append(options, Option{Name: name, Value: s.Key(s.Province)})
+			name += s.staleLabel()
+			option := Option{Name: name, Value: s.Key(s.Province), Sparkline: s.Sparkline(DataDeaths, sparklineDays, sparklineWidth, sparklineHeight)}
+			if IsTerritory(s.Country, s.Province) {
+				option.Group = GroupTerritories
+				territories = append(territories, option)
+				continue
+			}
+			options = append(options, option)
 		}
 	}
 
-	return options
+	return append(options, territories...)
 }
 
 // MergeCSV merges the data in this CSV with the data we already have in the SeriesSlice
@@ -484,6 +784,22 @@ func (slice SeriesSlice) MergeCSV(records [][]string, dataType int) (SeriesSlice
 		return slice.mergeDailyCountryCSV(records, dataType)
 	case DataTodayState:
 		return slice.mergeDailyStateCSV(records, dataType)
+	case DataTests:
+		return slice.mergeTestsCSV(records)
+	case DataStringency:
+		return slice.mergeStringencyCSV(records)
+	case DataGoogleMobility:
+		return slice.mergeGoogleMobilityCSV(records)
+	case DataAppleMobility:
+		return slice.mergeAppleMobilityCSV(records)
+	case DataHospital:
+		return slice.mergeHospitalCSV(records)
+	case DataStrata:
+		return slice.mergeStrataCSV(records)
+	case DataVariants:
+		return slice.mergeVariantsCSV(records)
+	case DataTracking:
+		return slice.mergeTrackingCSV(records)
 	}
 
 	return slice.mergeTimeSeriesCSV(records, dataType)
@@ -516,9 +832,18 @@ func (slice SeriesSlice) mergeTimeSeriesCSV(records [][]string, dataType int) (S
 				continue
 			}
 
-			// Ignore duplicate Virgin Islands
-			if province == "Virgin Islands, U.S." {
-				continue
+			// Read the days data after col 3 (longitude), recovering from invalid values if we can
+			values, err := readTimeSeriesRow(i, row, country, province)
+			if err != nil {
+				if recoveryMode == RecoverNone {
+					log.Printf("load: error loading series:%s row:%d row:\n%s\nerror:%s", country, i+1, row, err)
+					return slice, err
+				}
+				if recoveryMode == RecoverRow {
+					log.Printf("load: skipping series:%s row:%d error:%s", country, i+1, err)
+					report.skip(i+1, 0, country, province, "", err)
+					continue
+				}
 			}
 
 			// Fetch the series
@@ -532,34 +857,17 @@ func (slice SeriesSlice) mergeTimeSeriesCSV(records [][]string, dataType int) (S
 					Province: province,
 					StartsAt: startDate,
 				}
+				// Locations are approximate, so ignore any we can't read
+				series.Latitude, _ = strconv.ParseFloat(row[2], 64)
+				series.Longitude, _ = strconv.ParseFloat(row[3], 64)
 				slice = append(slice, series)
 			}
 
-			// Walk through row, reading days data after col 3 (longitude)
-			for ii, d := range row {
-				if ii < 4 {
-					continue
-				}
-				var v int
-				var err error
-				if d != "" {
-					v, err = strconv.Atoi(d)
-					if err != nil {
-						log.Printf("load: error loading series:%s row:%d col:%d row:\n%s\nerror:%s", country, i+1, ii+1, row, err)
-						return slice, fmt.Errorf("load: error loading row %d - csv day data invalid:%s", i+1, err)
-					}
-				} else {
-					// This is typically a clerical error - in this case invalid rows ending in ,
-					// So just quietly ignore it
-					log.Printf("load: missing data for series:%s row:%d col:%d", country, i, ii)
-				}
-
-				switch dataType {
-				case DataDeaths:
-					series.Deaths = append(series.Deaths, v)
-				case DataConfirmed:
-					series.Confirmed = append(series.Confirmed, v)
-				}
+			switch dataType {
+			case DataDeaths:
+				series.Deaths = append(series.Deaths, values...)
+			case DataConfirmed:
+				series.Confirmed = append(series.Confirmed, values...)
 			}
 
 			// After reading row data, calculate confirmed daily from confirmed
@@ -572,6 +880,43 @@ func (slice SeriesSlice) mergeTimeSeriesCSV(records [][]string, dataType int) (S
 	return slice, nil
 }
 
+// readTimeSeriesRow reads the days data from a time series row, which starts after col 3 (longitude)
+// invalid values are replaced with the value for the day before and recorded in the load report
+// the error returned is the first invalid value found, values are returned whether or not there is an error
+func readTimeSeriesRow(i int, row []string, country, province string) ([]int, error) {
+	var values []int
+	var rowErr error
+	for ii, d := range row {
+		if ii < 4 {
+			continue
+		}
+		v := 0
+		if d != "" {
+			var err error
+			v, err = strconv.Atoi(d)
+			if err != nil {
+				err = fmt.Errorf("load: error loading row %d col %d - csv day data invalid:%s", i+1, ii+1, err)
+				if rowErr == nil {
+					rowErr = err
+				}
+				if len(values) > 0 {
+					v = values[len(values)-1]
+				}
+				if recoveryMode == RecoverCell {
+					log.Printf("load: skipping value for series:%s row:%d col:%d error:%s", country, i+1, ii+1, err)
+					report.skip(i+1, ii+1, country, province, d, err)
+				}
+			}
+		} else {
+			// This is typically a clerical error - in this case invalid rows ending in ,
+			// So just quietly ignore it
+			log.Printf("load: missing data for series:%s row:%d col:%d", country, i, ii)
+		}
+		values = append(values, v)
+	}
+	return values, rowErr
+}
+
 // mergeDailyCountryCSV merges the data in this country daily series CSV with the data we already have in the SeriesSlice
 func (slice SeriesSlice) mergeDailyCountryCSV(records [][]string, dataType int) (SeriesSlice, error) {
 
@@ -581,8 +926,7 @@ func (slice SeriesSlice) mergeDailyCountryCSV(records [][]string, dataType int)
 	startDate := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
 
 	// Calculate index in series given shared StartsAt vs today (we assume data in these files is for today)
-	days := time.Now().UTC().Sub(startDate)
-	dayIndex := int(days.Hours() / 24)
+	dayIndex := daysBetween(startDate, Today())
 
 	// Bounds check index
 	if dayIndex < 0 {
@@ -607,12 +951,8 @@ func (slice SeriesSlice) mergeDailyCountryCSV(records [][]string, dataType int)
 			// There are several province series with bad names or dates which are duplicated in the state level dataset
 			// we therefore ignore them here as the data seems to be out of date anyway
 
-			// Fetch the series
-			series, err := slice.FetchSeries(country, province)
-			if err != nil {
-				log.Printf("load: warning reading daily series:%s error:%s", row[0], err)
-				//return nil, fmt.Errorf("load: error reading daily series:%s error:%s", row[0], err)
-			}
+			// Fetch the series, or a new one if this country is new
+			series, day, isNew := slice.dailySeries(country, province, startDate, dayIndex)
 
 			// Get the series data from the row
 			updated, confirmed, deaths, err := readCountryRow(row)
@@ -620,7 +960,19 @@ func (slice SeriesSlice) mergeDailyCountryCSV(records [][]string, dataType int)
 				return nil, fmt.Errorf("load: error reading row series:%s error:%s", row[0], err)
 			}
 
-			series.AddDayData(dayIndex, updated, confirmed, deaths)
+			// Skip rows we can't add, such as a bad date, rather than failing the whole file
+			added, err := series.AddProvisional(day, updated, confirmed, deaths)
+			if err != nil {
+				log.Printf("load: skipping daily row for series:%s %s error:%s", series.Country, series.Province, err)
+				continue
+			}
+			if isNew {
+				log.Printf("load: adding series from daily data:%s %s", series.Country, series.Province)
+				slice = append(slice, series)
+			}
+			if added {
+				series.setDailyUpdate(sourceNames[dataType], updated)
+			}
 
 			// After reading row data, recalculate confirmed daily from confirmed
 			// first day is just set to first total after that daily totals are stored
@@ -631,6 +983,34 @@ func (slice SeriesSlice) mergeDailyCountryCSV(records [][]string, dataType int)
 	return slice, nil
 }
 
+// dailySeries returns the series for a row in a daily csv and the index of dayIndex within it, resolving aliases for country names
+// if we have no series a new one is returned which the caller adds once the row is added, so that new countries and states appear
+// new series start on the day they first appear, rather than being padded with zeros before a jump to their cumulative total,
+// and are left out of global, as adding their totals on that day alone would show as a spike in the daily global values
+func (slice SeriesSlice) dailySeries(country, province string, startDate time.Time, dayIndex int) (*Series, int, bool) {
+	if name, ok := countryAliases[country]; ok {
+		country = name
+	}
+	series, err := slice.FetchSeries(country, province)
+	if err == nil {
+		return series, dayIndex - daysBetween(startDate, series.StartsAt), false
+	}
+
+	series = &Series{
+		Country:       country,
+		Province:      province,
+		StartsAt:      startDate.AddDate(0, 0, dayIndex),
+		excludeGlobal: true,
+	}
+	return series, 0, true
+}
+
+// skipDailyProvince returns true for provinces in the daily state csv which we don't load,
+// as they duplicate another series under another name
+func skipDailyProvince(province string) bool {
+	return province == "Virgin Islands, U.S"
+}
+
 func readCountryRow(row []string) (time.Time, int, int, error) {
 
 	// Dates are, remarkably, in two different formats in one file
@@ -671,8 +1051,7 @@ func (slice SeriesSlice) mergeDailyStateCSV(records [][]string, dataType int) (S
 	startDate := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
 
 	// Calculate index in series given shared StartsAt vs today (we assume data in these files is for today)
-	days := time.Now().UTC().Sub(startDate)
-	dayIndex := int(days.Hours() / 24)
+	dayIndex := daysBetween(startDate, Today())
 
 	// Bounds check index
 	if dayIndex < 0 {
@@ -694,19 +1073,15 @@ func (slice SeriesSlice) mergeDailyStateCSV(records [][]string, dataType int) (S
 			country := row[2]
 			province := row[1]
 
-			if province == "Virgin Islands, U.S" {
+			if skipDailyProvince(province) {
 				continue
 			}
 
 			// There are several province series with bad names or dates which are duplicated in the state level dataset
 			// we therefore ignore them here as the data seems to be out of date anyway
 
-			// Fetch the series
-			series, err := slice.FetchSeries(country, province)
-			if err != nil {
-				log.Printf("load: warning reading daily state series:%s error:%s", row[1], err)
-				//return nil, fmt.Errorf("load: error reading daily series:%s error:%s", row[0], err)
-			}
+			// Fetch the series, or a new one if this state is new
+			series, day, isNew := slice.dailySeries(country, province, startDate, dayIndex)
 
 			// Get the series data from the row
 			updated, confirmed, deaths, err := readStateRow(row)
@@ -714,7 +1089,19 @@ func (slice SeriesSlice) mergeDailyStateCSV(records [][]string, dataType int) (S
 				return nil, fmt.Errorf("load: error reading row series:%s error:%s", row[1], err)
 			}
 
-			series.AddDayData(dayIndex, updated, confirmed, deaths)
+			// Skip rows we can't add, such as a bad date, rather than failing the whole file
+			added, err := series.AddProvisional(day, updated, confirmed, deaths)
+			if err != nil {
+				log.Printf("load: skipping daily row for series:%s %s error:%s", series.Country, series.Province, err)
+				continue
+			}
+			if isNew {
+				log.Printf("load: adding series from daily data:%s %s", series.Country, series.Province)
+				slice = append(slice, series)
+			}
+			if added {
+				series.setDailyUpdate(sourceNames[dataType], updated)
+			}
 
 			// After reading row data, recalculate confirmed daily from confirmed
 			// first day is just set to first total after that daily totals are stored
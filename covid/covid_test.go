@@ -2,12 +2,15 @@ package covid
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestFetchData(t *testing.T) {
-	// change dir back to root
+	// change dir back to root, and return afterwards for tests using testdata
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
 	os.Chdir("..")
 	err := FetchData()
 	if err != nil {
@@ -100,3 +103,229 @@ func TestLoadData(t *testing.T) {
 	//t.Logf("data:series:%s %v", series.Country, series.Confirmed)
 
 }
+
+func TestTotalIn(t *testing.T) {
+	s := &Series{
+		StartsAt:  time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths:    []int{1, 3, 6, 10, 15},
+		Confirmed: []int{10, 30, 60, 100, 150},
+	}
+	s.UpdateDaily()
+
+	if s.TotalDeaths() != 15 || s.TotalIn(DataConfirmed, 2) != 90 {
+		t.Fatalf("test: total wanted:15,90 got:%d,%d", s.TotalDeaths(), s.TotalIn(DataConfirmed, 2))
+	}
+
+	// Totals for a limited series are within the days of the series
+	days := s.Days(3)
+	if days.TotalDeaths() != 12 || days.TotalIn(DataDeaths, 1) != 5 {
+		t.Fatalf("test: total days wanted:12,5 got:%d,%d", days.TotalDeaths(), days.TotalIn(DataDeaths, 1))
+	}
+
+	tests := map[string]int{"2020-02-28": 0, "2020-03-02": 30, "2020-03-05": 150, "2020-04-01": 150}
+	for d, want := range tests {
+		date, _ := time.Parse("2006-01-02", d)
+		if v := s.CumulativeAt(DataConfirmed, date); v != want {
+			t.Fatalf("test: cumulative at %s wanted:%d got:%d", d, want, v)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: []int{1}, Confirmed: []int{10}},
+		{Country: "Spain", StartsAt: start, Deaths: []int{1}, Confirmed: []int{10}},
+		{Country: "Spain", Province: "Madrid", StartsAt: start, Deaths: []int{1}, Confirmed: []int{10}},
+		{Country: "France", StartsAt: start, Deaths: []int{1}, Confirmed: []int{10}},
+	}
+
+	tests := []struct {
+		filter        SeriesFilter
+		offset, limit int
+		want          string
+		total         int
+	}{
+		{SeriesFilter{}, 0, 0, "Italy,Spain,Spain,France", 4},
+		{SeriesFilter{}, 1, 2, "Spain,Spain", 4},
+		{SeriesFilter{}, 3, 10, "France", 4},
+		{SeriesFilter{}, 4, 10, "", 4},
+		{SeriesFilter{}, 10, 10, "", 4},
+		{SeriesFilter{}, -1, 10, "", 4},
+		{SeriesFilter{Countries: true}, 1, 0, "Spain,France", 3},
+		{SeriesFilter{Tag: "nope"}, 0, 10, "", 0},
+	}
+	for _, tt := range tests {
+		page, total := slice.List(tt.filter, tt.offset, tt.limit)
+		var names []string
+		for _, s := range page {
+			names = append(names, s.Country)
+		}
+		if strings.Join(names, ",") != tt.want || total != tt.total {
+			t.Fatalf("test: list %+v offset:%d limit:%d wanted:%q %d got:%q %d", tt.filter, tt.offset, tt.limit, tt.want, tt.total, strings.Join(names, ","), total)
+		}
+	}
+
+	// Pages are copies, so changing them doesn't change the slice
+	page, _ := slice.List(SeriesFilter{}, 0, 1)
+	page[0] = nil
+	if slice[0] == nil {
+		t.Fatalf("test: list page shares storage with slice")
+	}
+}
+
+func TestMergeDailyNewSeries(t *testing.T) {
+	// Daily data is for today, so the series end yesterday
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	days := daysBetween(start, Today())
+	korea := &Series{Country: "Korea, South", StartsAt: start, Deaths: make([]int, days), Confirmed: make([]int, days)}
+	global := &Series{StartsAt: start, Deaths: make([]int, days), Confirmed: make([]int, days)}
+	korea.Confirmed[days-1], global.Confirmed[days-1] = 10, 10
+	slice := SeriesSlice{korea, global}
+	records := [][]string{
+		{"Country_Region", "Last_Update", "Lat", "Long_", "Confirmed", "Deaths"},
+		{"South Korea", "2020-01-24 12:00:00", "0", "0", "20", "2"},
+		{"Atlantis", "2020-01-24 12:00:00", "0", "0", "7", "1"},
+	}
+	slice, err := slice.mergeDailyCountryCSV(records, DataTodayCountry)
+	if err != nil {
+		t.Fatalf("test: merge daily error:%s", err)
+	}
+	if len(slice) != 3 {
+		t.Fatalf("test: merge daily wanted:3 series got:%d", len(slice))
+	}
+	if slice[0].TotalConfirmed() != 20 {
+		t.Fatalf("test: merge daily alias wanted:20 got:%d", slice[0].TotalConfirmed())
+	}
+	// New series start today rather than being padded with zeros, and are left out of global
+	s, err := slice.FetchSeries("Atlantis", "")
+	if err != nil || len(s.Confirmed) != 1 || s.TotalConfirmed() != 7 || !s.StartsAt.Equal(Today()) || s.AddToGlobal() {
+		t.Fatalf("test: merge daily new series wanted:7 today got:%d days:%d", s.TotalConfirmed(), len(s.Confirmed))
+	}
+	if (&Series{Country: "Cruise Ship"}).AddToGlobal() {
+		t.Fatalf("test: cruise ship wanted excluded from global")
+	}
+
+	// Incremental merges index new series from their own start
+	records[2][4] = "9"
+	_, err = slice.mergeDailyIncremental(records, DataTodayCountry, days)
+	s, _ = slice.FetchSeries("Atlantis", "")
+	if err != nil || len(s.Confirmed) != 1 || s.Confirmed[0] != 9 {
+		t.Fatalf("test: merge daily incremental new series wanted:[9] got:%v error:%v", s.Confirmed, err)
+	}
+}
+
+func TestAddDayData(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start, Deaths: []int{1, 2}, Confirmed: []int{10, 20}}
+
+	// Missed days carry forward the last values
+	err := s.AddDayData(4, time.Now(), 50, 5)
+	if err != nil {
+		t.Fatalf("test: add day data error:%s", err)
+	}
+	if len(s.Deaths) != 5 || s.Deaths[2] != 2 || s.Confirmed[3] != 20 || s.Confirmed[4] != 50 {
+		t.Fatalf("test: add day data wanted carry forward got:%v %v", s.Deaths, s.Confirmed)
+	}
+
+	// Existing days are replaced
+	err = s.AddDayData(1, time.Now(), 25, 3)
+	if err != nil || s.Deaths[1] != 3 || len(s.Deaths) != 5 {
+		t.Fatalf("test: add day data wanted replace got:%v", s.Deaths)
+	}
+
+	if s.AddDayData(-1, time.Now(), 1, 1) == nil {
+		t.Fatalf("test: add day data accepted negative index")
+	}
+	if s.AddDayData(len(s.Deaths)+maxDayGap+1, time.Now(), 1, 1) == nil || len(s.Deaths) != 5 {
+		t.Fatalf("test: add day data accepted index far in the future")
+	}
+}
+
+func TestMergeTimeSeriesRecovery(t *testing.T) {
+	defer func() { recoveryMode, report = RecoverCell, &LoadReport{} }()
+
+	records := [][]string{
+		{"Province/State", "Country/Region", "Lat", "Long", "1/22/20", "1/23/20", "1/24/20"},
+		{"", "Italy", "0", "0", "1", "x", "3"},
+		{"", "Spain", "0", "0", "1", "2", "3"},
+	}
+
+	report = &LoadReport{}
+	slice, err := SeriesSlice{}.mergeTimeSeriesCSV(records, DataDeaths)
+	if err != nil {
+		t.Fatalf("test: recover cell error:%s", err)
+	}
+	if len(slice) != 2 || slice[0].Deaths[1] != 1 || len(report.Skipped) != 1 || report.Skipped[0].Column != 6 {
+		t.Fatalf("test: recover cell wanted:[1 1 3] got:%v skipped:%v", slice[0].Deaths, report.Skipped)
+	}
+
+	report = &LoadReport{}
+	recoveryMode = RecoverRow
+	slice, err = SeriesSlice{}.mergeTimeSeriesCSV(records, DataDeaths)
+	if err != nil {
+		t.Fatalf("test: recover row error:%s", err)
+	}
+	if len(slice) != 1 || len(report.Skipped) != 1 || report.Skipped[0].Country != "Italy" {
+		t.Fatalf("test: recover row wanted:1 series got:%d skipped:%v", len(slice), report.Skipped)
+	}
+	report.phase("time_series")
+	report.finish(slice)
+	if report.RowsSkipped != 1 || report.SeriesCreated != 1 || len(report.Phases) != 1 {
+		t.Fatalf("test: report wanted 1 row skipped 1 series got:%d %d phases:%v", report.RowsSkipped, report.SeriesCreated, report.Phases)
+	}
+
+	recoveryMode = RecoverNone
+	_, err = SeriesSlice{}.mergeTimeSeriesCSV(records, DataDeaths)
+	if err == nil {
+		t.Fatalf("test: recover none wanted error")
+	}
+}
+
+func TestDates(t *testing.T) {
+	start := time.Date(2020, 1, 30, 0, 0, 0, 0, time.UTC)
+	a := &Series{StartsAt: start, Deaths: make([]int, 3)}
+	b := &Series{StartsAt: start, Deaths: make([]int, 3)}
+
+	dates := a.Dates()
+	if len(dates) != 3 || dates[0] != "Jan 30" || dates[2] != "Feb 1" {
+		t.Fatalf("test: dates wanted:[Jan 30 Jan 31 Feb 1] got:%v", dates)
+	}
+	// Series with the same dates share labels
+	if &b.Dates()[0] != &dates[0] {
+		t.Fatalf("test: dates wanted shared labels")
+	}
+	if len((&Series{StartsAt: start}).Dates()) != 0 {
+		t.Fatalf("test: dates wanted none for empty series")
+	}
+}
+
+func TestLoadDataLengths(t *testing.T) {
+	dataPath = "./testdata/"
+	err := LoadData()
+	if err != nil {
+		t.Fatalf("error loading data:%s", err)
+	}
+
+	// Global must not have days the countries don't, which would show as zero for today
+	global, err := data.FetchSeries("", "")
+	if err != nil {
+		t.Fatalf("test: no global series:%s", err)
+	}
+	for _, s := range data.Countries() {
+		if s.AddToGlobal() && len(s.Deaths) > len(global.Deaths) {
+			t.Fatalf("test: load data global shorter than:%s wanted:%d got:%d", s.Country, len(s.Deaths), len(global.Deaths))
+		}
+	}
+	uk, err := data.FetchSeries("United Kingdom", "")
+	if err != nil || len(uk.Deaths) != len(global.Deaths) {
+		t.Fatalf("test: load data wanted global len:%d got:%d", len(uk.Deaths), len(global.Deaths))
+	}
+	n := len(global.Deaths)
+	if global.Deaths[n-1] == 0 || global.DeathsDaily[n-1] == 0 {
+		t.Fatalf("test: load data wanted global deaths for last day got:%v", global.Deaths[n-3:])
+	}
+	if report.failed("cases_") {
+		t.Fatalf("test: load data wanted daily files to load got failed:%v", report.Failed)
+	}
+}
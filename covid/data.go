@@ -3,9 +3,8 @@ package covid
 import (
 	"encoding/csv"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -25,6 +24,17 @@ var dailyDataFiles = []string{
 	"https://raw.githubusercontent.com/CSSEGISandData/COVID-19/web-data/data/cases_country.csv",
 }
 
+// auxiliaryDataFiles are prefixes for optional files which add data to existing series if present in the data path
+// e.g. OxCGRT_latest.csv from https://github.com/OxCGRT/covid-policy-tracker
+// Global_Mobility_Report.csv from https://www.google.com/covid19/mobility/
+// applemobilitytrends-2020-04-13.csv from https://covid19.apple.com/mobility
+// covid-hospitalizations.csv from https://github.com/owid/covid-19-data
+// ecdc_hospital.csv from https://www.ecdc.europa.eu/en/publications-data/download-data-hospital-and-icu-admission-rates-and-current-occupancy-covid-19
+// strata.csv with cumulative values by age or sex for any source which provides them
+// covid-variants.csv from https://github.com/owid/covid-19-data
+// all-states-history.csv from https://covidtracking.com/data/download (archived March 2021)
+var auxiliaryDataFiles = []string{"tests", "OxCGRT", "Global_Mobility_Report", "applemobilitytrends", "covid-hospitalizations", "ecdc_hospital", "strata", "covid-variants", "all-states-history"}
+
 var hourlyDataFiles = []string{
 	"https://raw.githubusercontent.com/CSSEGISandData/COVID-19/web-data/data/cases_state.csv",
 	"https://raw.githubusercontent.com/CSSEGISandData/COVID-19/web-data/data/cases_country.csv",
@@ -46,40 +56,137 @@ func LoadData() error {
 	defer mutex.Unlock()
 
 	// Need to clear previous data in case we are reloading
+	// keep a reference to the previous data to record changes
+	previous := data
 	data = SeriesSlice{}
+	report = &LoadReport{StartedAt: start}
 
 	// Load all our time series data files - must be loaded and processed first
+	// if one fails we continue with the others, and use previous data for it below
 	for _, fp := range files {
 		name := filepath.Base(fp)
 		if strings.HasPrefix(name, "time_series") {
-			data, err = loadCSVFile(fp, data)
+			loaded, err := loadCSVFile(fp, data)
+			if err != nil {
+				report.fail(name, err)
+				continue
+			}
+			data = loaded
+		}
+	}
+
+	// Recover data for any failed time series from the previous load, keeping the previous data if we can't
+	for _, datum := range []int{DataDeaths, DataConfirmed} {
+		prefix := "time_series_covid19_deaths"
+		if datum == DataConfirmed {
+			prefix = "time_series_covid19_confirmed"
+		}
+		if report.failed(prefix) {
+			err = data.recoverTimeSeries(previous, datum, start)
 			if err != nil {
+				data = previous
 				return err
 			}
 		}
 	}
 
+	// Drop any series with skipped rows, as they would be incomplete
+	if recoveryMode == RecoverRow {
+		data = data.dropSkipped(report)
+	}
+	report.phase("time_series")
+
 	// Process the data after loading (it doesn't include global US counts for example)
 	data = processData(data)
+	timeSeriesDays := data.timeSeriesDays()
+	report.phase("process")
 
 	// Load all our daily data files - must be loaded after main series are inserted for countries
 	for _, fp := range files {
 		name := filepath.Base(fp)
 		if strings.HasPrefix(name, "cases_") {
-			data, err = loadCSVFile(fp, data)
+			loaded, err := loadCSVFile(fp, data)
 			if err != nil {
-				return err
+				report.fail(name, err)
+				continue
 			}
+			data = loaded
+		}
+	}
+
+	// Mark series without today's data from a failed daily file as stale
+	if report.failed("cases_country") {
+		data.markStaleDaily(previous, false, start)
+	}
+	if report.failed("cases_state") {
+		data.markStaleDaily(previous, true, start)
+	}
+
+	// Fill any days missed between the end of the time series and today from daily reports, if we have them
+	report.Backfilled, report.Gaps = data.backfillGaps(timeSeriesDays)
+	report.phase("daily")
+
+	// Load auxiliary data if we have it - must be loaded after all series are complete
+	for _, fp := range files {
+		name := filepath.Base(fp)
+		for _, prefix := range auxiliaryDataFiles {
+			if strings.HasPrefix(name, prefix) {
+				loaded, err := loadCSVFile(fp, data)
+				if err != nil {
+					report.fail(name, err)
+					continue
+				}
+				data = loaded
+			}
+		}
+	}
+	data.fillAuxiliary()
+	report.phase("auxiliary")
+
+	// Apply any imported corrections, as they replace values from the source files
+	data.applyImports(false)
+	report.phase("imports")
+
+	// Check countries against the sum of their provinces, replacing them with the sum if required
+	report.Rollups = data.ValidateRollups(DefaultTolerance)
+	if rollupRecompute {
+		data.recomputeRollups(report.Rollups)
+	}
+
+	// Drop testing data which fails validation, rather than show misleading rates
+	for _, s := range data {
+		err = s.ValidateTests()
+		if err != nil {
+			log.Printf("load: invalid testing data:%s", err)
+			s.Tests = nil
 		}
 	}
 
 	// Update the global dates
 	updateGlobal(data)
 
+	// Freeze the data at the archive date if we have one
+	data.archive()
+
+	// Store values for series sharing the same dates in columns
+	axis = data.compact()
+
 	// Sort the data by deaths, then alphabetically by country
 	sort.Stable(data)
 
-	log.Printf("server: loaded data in %s len:%d", time.Now().Sub(start), len(data))
+	// Assign unique slugs for urls, then tags which use them
+	data.updateSlugs()
+	data.applyTags()
+
+	// Record any changes to the data since the last load
+	updateVersion(previous, data)
+	report.phase("finish")
+	report.finish(data)
+
+	log.Printf("server: loaded data in %s len:%d skipped:%d", time.Now().Sub(start), len(data), len(report.Skipped))
+	if len(report.Failed) > 0 {
+		log.Printf("server: failed to load %d files, data is stale", len(report.Failed))
+	}
 
 	// For Debug, output a series
 	data.PrintSeries("United Kingdom", "")
@@ -92,6 +199,7 @@ func LoadData() error {
 func loadCSVFile(path string, data SeriesSlice) (SeriesSlice, error) {
 
 	log.Printf("load: loading file at path:%v", path)
+	report.setFile(filepath.Base(path))
 
 	// Open the file at path
 	f, err := os.Open(path)
@@ -112,9 +220,42 @@ func loadCSVFile(path string, data SeriesSlice) (SeriesSlice, error) {
 		dataType = DataTodayState
 	} else if strings.HasSuffix(path, "cases_country.csv") {
 		dataType = DataTodayCountry
+	} else if strings.HasPrefix(filepath.Base(path), "tests") {
+		dataType = DataTests
+	} else if strings.HasPrefix(filepath.Base(path), "OxCGRT") {
+		dataType = DataStringency
+	} else if strings.HasPrefix(filepath.Base(path), "Global_Mobility_Report") {
+		dataType = DataGoogleMobility
+	} else if strings.HasPrefix(filepath.Base(path), "applemobilitytrends") {
+		dataType = DataAppleMobility
+	} else if strings.HasPrefix(filepath.Base(path), "covid-hospitalizations") || strings.HasPrefix(filepath.Base(path), "ecdc_hospital") {
+		dataType = DataHospital
+	} else if strings.HasPrefix(filepath.Base(path), "strata") {
+		dataType = DataStrata
+	} else if strings.HasPrefix(filepath.Base(path), "covid-variants") {
+		dataType = DataVariants
+	} else if strings.HasPrefix(filepath.Base(path), "all-states-history") {
+		dataType = DataTracking
 	}
 
-	return data.MergeCSV(csvData, dataType)
+	merged, err := data.MergeCSV(csvData, dataType)
+	if err != nil {
+		return data, err
+	}
+	if len(csvData) > 0 {
+		report.read(len(csvData) - 1)
+	}
+	recordSource(path)
+
+	// Record when time series were updated by metric, so that one stale file shows even if the other is current
+	if info, err := os.Stat(path); err == nil && (dataType == DataDeaths || dataType == DataConfirmed) {
+		metric := MetricDeaths
+		if dataType == DataConfirmed {
+			metric = MetricConfirmed
+		}
+		merged.setUpdates(metric, filepath.Base(path), info.ModTime().UTC())
+	}
+	return merged, nil
 }
 
 // processData post-processes the data
@@ -129,19 +270,14 @@ func processData(data SeriesSlice) SeriesSlice {
 		if s.Province == s.Country {
 			s.Province = ""
 		}
-		// Fix country names - inconsistent between all data sets!
-		if s.Country == "The Bahamas" || s.Country == "Bahamas, The" {
-			s.Country = "Bahamas"
-		}
-		if s.Country == "The Gambia" || s.Country == "Gambia, The" {
-			s.Country = "Gambia"
-		}
-		if s.Country == "East Timor" {
-			s.Country = "Timor-Leste"
-		}
-
 	}
 
+	// Fix names which are inconsistent between data sets, merging any duplicate series
+	data, report.Duplicates = data.dedupe()
+
+	// Mark hidden series now that names are fixed, so that they are left out of global totals
+	data.applyHidden()
+
 	// Generate extra series not include in the data
 	startDate := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
 
@@ -182,11 +318,12 @@ func processData(data SeriesSlice) SeriesSlice {
 
 	// Add global country entries for countries with data broken down at province level
 	// Add a global dataset from all other datasets combined
+	var china, australia, canada SeriesSlice
 	for _, s := range data {
 
 		// Build an overall China series
 		if s.Country == "China" {
-			China.Merge(s)
+			china = append(china, s)
 		}
 
 		// The dataset now includes a US global entry
@@ -201,18 +338,21 @@ func processData(data SeriesSlice) SeriesSlice {
 
 		// Build an overall Australia series
 		if s.Country == "Australia" {
-			Australia.Merge(s)
+			australia = append(australia, s)
 		}
 
 		// Build an overall Canada series
 		if s.Country == "Canada" {
-			Canada.Merge(s)
+			canada = append(canada, s)
 		}
 
-		// Build a global series
-		Global.Merge(s)
-
 	}
+	China.sum(china)
+	Australia.sum(australia)
+	Canada.sum(canada)
+
+	// Build a global series
+	Global.sum(data.visible())
 
 	//	log.Printf("Added China Series:%s %s %v", China.Country, China.Province, China.Confirmed)
 	data = append(data, China)
@@ -247,21 +387,30 @@ func updateGlobal(data SeriesSlice) {
 		return
 	}
 
-	// Add a blank day to global
-	global.Deaths = append(global.Deaths, 0)
-	global.Confirmed = append(global.Confirmed, 0)
-	global.DeathsDaily = append(global.DeathsDaily, 0)
-	global.ConfirmedDaily = append(global.ConfirmedDaily, 0)
+	// Extend global to the longest series, for days added by daily data since the end of the time series
+	first := len(global.Deaths)
+	days := first
+	for _, s := range data {
+		if s.AddToGlobal() && len(s.Deaths) > days {
+			days = len(s.Deaths)
+		}
+	}
+	for len(global.Deaths) < days {
+		global.Deaths = append(global.Deaths, 0)
+		global.Confirmed = append(global.Confirmed, 0)
+		global.DeathsDaily = append(global.DeathsDaily, 0)
+		global.ConfirmedDaily = append(global.ConfirmedDaily, 0)
+	}
 
 	// Add global country entries for countries with data broken down at province level
 	// Add a global dataset from all other datasets combined
 	for _, s := range data {
-		// Add final day for each series to global totals, ignoring our synthetic globals not in orgiginal dataset
+		// Add the new days for each series to global totals, ignoring our synthetic globals not in orgiginal dataset
 		// US, Global etc
 		if s.AddToGlobal() {
-			global.MergeFinalDay(s)
-		} else {
-			//log.Printf("skipping global:%s %d", s.Country, s.TotalDeaths())
+			for i := first; i < days; i++ {
+				global.mergeDay(s, i)
+			}
 		}
 	}
 
@@ -269,6 +418,12 @@ func updateGlobal(data SeriesSlice) {
 
 // ScheduleDataFetch sets up a regular fetch of data from data sources
 func ScheduleDataFetch() {
+	// Archived data is never refreshed
+	if Archived() {
+		log.Printf("schedule: data archived, skipping fetch")
+		return
+	}
+
 	// Set up a scheduled time every day at 8PM UTC
 	now := time.Now()
 	when := time.Date(now.Year(), now.Month(), now.Day(), 3, 33, 0, 0, time.UTC)
@@ -293,11 +448,17 @@ func FetchDataDaily() {
 	log.Printf("schedule: fetching daily data from data source")
 
 	// First download the files we need from github master branch to our data dir
-	err := DownloadFiles(dailyDataFiles, dataPath)
+	changed, err := DownloadFiles(dailyDataFiles, dataPath)
 	if err != nil {
 		log.Printf("schedule: error fetching daily data from data source:%s", err)
 	}
 
+	// Skip merging again if the files are identical to those we have loaded
+	if err == nil && changed == 0 {
+		log.Printf("schedule: daily data unchanged")
+		return
+	}
+
 	// Add a pause after requests
 	time.Sleep(1 * time.Second)
 
@@ -305,7 +466,12 @@ func FetchDataDaily() {
 	err = LoadData()
 	if err != nil {
 		log.Printf("schedule: error loading daily data from data source:%s", err)
+		return
 	}
+	fillGaps()
+
+	// Publish the data to any services registered
+	runLoadHooks()
 }
 
 // FetchDataHourly is called on a schedule
@@ -313,18 +479,49 @@ func FetchDataHourly() {
 	log.Printf("schedule: fetching hourly data from data source")
 
 	// First download hourly data files
-	err := DownloadFiles(hourlyDataFiles, dataPath)
+	changed, err := DownloadFiles(hourlyDataFiles, dataPath)
 	if err != nil {
 		log.Printf("schedule: error fetching daily data from data source:%s", err)
 	}
 
+	// Skip merging again if the files are identical to those we have loaded
+	if err == nil && changed == 0 {
+		log.Printf("schedule: hourly data unchanged")
+		return
+	}
+
 	// Add a pause after requests
 	time.Sleep(1 * time.Second)
 
-	// Trigger a reload of the data from our standard data path
+	// Merge the changed rows into our data if we can, otherwise trigger a reload of the data from our standard data path
+	if err == nil {
+		err = LoadDailyData()
+		if err == nil {
+			runLoadHooks()
+			return
+		}
+		log.Printf("schedule: reloading all data, hourly data not merged:%s", err)
+	}
 	err = LoadData()
 	if err != nil {
 		log.Printf("schedule: error loading daily data from data source:%s", err)
+		return
+	}
+	fillGaps()
+
+	// Publish the data to any services registered
+	runLoadHooks()
+}
+
+// fillGaps downloads daily reports for any days missing from the time series at the last load,
+// and loads the data again to fill them, e.g. after the server has been down for several days
+func fillGaps() {
+	if FetchGaps() == 0 {
+		return
+	}
+	err := LoadData()
+	if err != nil {
+		log.Printf("schedule: error loading data after filling gaps:%s", err)
 	}
 }
 
@@ -333,7 +530,7 @@ func FetchDataHourly() {
 func FetchData() error {
 
 	// First download the 3 x files we need from github master branch to our data dir
-	err := DownloadFiles(dailyDataFiles, dataPath)
+	_, err := DownloadFiles(dailyDataFiles, dataPath)
 	if err != nil {
 		return err
 	}
@@ -342,45 +539,52 @@ func FetchData() error {
 	time.Sleep(1 * time.Second)
 
 	// Trigger a reload of the data from our standard data path
-	return LoadData()
+	err = LoadData()
+	if err != nil {
+		return err
+	}
+	fillGaps()
+	return nil
 }
 
 // DownloadFiles downloads the specified url to the specified file path
-// requires csv files
-func DownloadFiles(urls []string, dataPath string) error {
-
+// requires csv files, files which fail their checks are not saved
+// returns the number of files with contents which changed
+func DownloadFiles(urls []string, dataPath string) (int, error) {
+	changed := 0
 	for _, url := range urls {
 		log.Printf("schedule: downloading file %s", url)
 
-		// Get the data
-		resp, err := http.Get(url)
-		if err != nil {
-			return fmt.Errorf("data: error fetching data url:%s error:%s", url, err)
-		}
-		defer resp.Body.Close()
-
 		name := filepath.Clean(filepath.Base(url))
 		if !strings.HasSuffix(name, ".csv") {
-			return fmt.Errorf("data: error csv not supplied:%s", name)
+			return changed, fmt.Errorf("data: error csv not supplied:%s", name)
 		}
 		path := filepath.Join(dataPath, name)
-		// Open or Create the file locally if required
-		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0700)
+
+		// Get the data, retrying transient failures
+		b, err := fetch(url)
 		if err != nil {
-			return fmt.Errorf("data: error opening file:%s", err)
+			return changed, fmt.Errorf("data: error fetching data url:%s error:%s", url, err)
 		}
-		defer f.Close()
 
-		// Write the body to file
-		_, err = io.Copy(f, resp.Body)
+		// Check the file before replacing ours, and skip writing it if unchanged
+		sum, fileChanged, err := checkFile(name, path, b)
 		if err != nil {
-			return fmt.Errorf("data: error copying data url:%s path:%s error:%s", url, path, err)
+			return changed, err
+		}
+		log.Printf("schedule: downloaded file %s sha256:%s changed:%t", name, sum, fileChanged)
+		if fileChanged {
+			err = ioutil.WriteFile(path, b, 0700)
+			if err != nil {
+				return changed, fmt.Errorf("data: error writing data url:%s path:%s error:%s", url, path, err)
+			}
+			changed++
 		}
 
 		// Allow a pause between requests
 		time.Sleep(1 * time.Second)
 	}
-	return nil
+	return changed, nil
 }
 
 // ScheduleAt schedules execution for a particular time and at intervals thereafter.
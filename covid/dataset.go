@@ -0,0 +1,221 @@
+package covid
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDataset is the name of our stored data, loaded from the JHU csv files
+const DefaultDataset = "jhu"
+
+// Dataset is an additional named dataset from another provider, e.g. ecdc
+// loaded from one of our export formats or a provider format so that providers can be compared side by side
+type Dataset struct {
+	// The name used in api paths, e.g. /api/datasets/ecdc/series
+	Name string `json:"name"`
+	// The url or file path of the source
+	Source string `json:"source"`
+	// The format of the source e.g. who, if blank an export format is chosen by extension
+	Format string `json:"format"`
+	// The columns used for the mapped and xlsx formats, and the sheet name for xlsx (the first if blank)
+	Columns *ColumnMapping `json:"columns,omitempty"`
+	Sheet   string         `json:"sheet,omitempty"`
+	// Apply the deaths and confirmed values from this dataset to our stored data, replacing values for the same days
+	// so that official figures can replace or supplement those from JHU
+	Apply bool `json:"apply"`
+	// How often to refresh the dataset in hours, 0 to load once
+	RefreshHours int `json:"refresh_hours"`
+
+	mutex     sync.RWMutex
+	data      SeriesSlice
+	updatedAt time.Time
+	stop      chan struct{}
+}
+
+var (
+	datasetsMutex sync.RWMutex
+	// datasets are the additional datasets by name
+	datasets = make(map[string]*Dataset)
+)
+
+// AddDataset loads a dataset and schedules a refresh if required
+// the dataset is replaced if one with the same name exists
+func AddDataset(d *Dataset) error {
+	if d.Name == "" || d.Name != Slug(d.Name) || d.Name == DefaultDataset {
+		return fmt.Errorf("dataset: invalid name:%s", d.Name)
+	}
+	if d.Source == "" {
+		return fmt.Errorf("dataset: missing source for:%s", d.Name)
+	}
+	err := d.Load()
+	if err != nil {
+		return err
+	}
+
+	datasetsMutex.Lock()
+	defer datasetsMutex.Unlock()
+	if existing, ok := datasets[d.Name]; ok && existing.stop != nil {
+		close(existing.stop)
+	}
+	if d.RefreshHours > 0 {
+		interval := time.Duration(d.RefreshHours) * time.Hour
+		d.stop = ScheduleAt(d.refresh, time.Now().Add(interval), interval)
+	}
+	datasets[d.Name] = d
+	return nil
+}
+
+// LoadDatasets adds the datasets in a json config at path, which is a list of datasets
+func LoadDatasets(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("dataset: error reading config:%s", err)
+	}
+	var config []*Dataset
+	err = json.Unmarshal(b, &config)
+	if err != nil {
+		return fmt.Errorf("dataset: error parsing config:%s", err)
+	}
+	for _, d := range config {
+		err = AddDataset(d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Datasets returns the names of all datasets including our default dataset, sorted
+func Datasets() []string {
+	datasetsMutex.RLock()
+	defer datasetsMutex.RUnlock()
+	list := []string{DefaultDataset}
+	for name := range datasets {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// FetchDataset returns the additional dataset with name
+func FetchDataset(name string) (*Dataset, error) {
+	datasetsMutex.RLock()
+	defer datasetsMutex.RUnlock()
+	d, ok := datasets[name]
+	if !ok {
+		return nil, fmt.Errorf("dataset: not found:%s", name)
+	}
+	return d, nil
+}
+
+// Load reads the dataset from its source and replaces the data we hold for it
+func (d *Dataset) Load() error {
+	var loaded SeriesSlice
+	var err error
+	if d.Format == FormatDiseaseSh {
+		loaded, err = fetchDiseaseSh(d.Source)
+	} else {
+		var b []byte
+		b, err = d.read()
+		if err != nil {
+			return err
+		}
+		loaded, err = d.readSource(b)
+	}
+	if err != nil {
+		return fmt.Errorf("dataset: error loading %s:%s", d.Name, err)
+	}
+	loaded = addGlobal(loaded)
+	loaded.compact()
+	sort.Stable(loaded)
+	loaded.updateSlugs()
+
+	d.mutex.Lock()
+	d.data = loaded
+	d.updatedAt = time.Now().UTC()
+	d.mutex.Unlock()
+	log.Printf("dataset: loaded %s len:%d", d.Name, len(loaded))
+
+	if d.Apply {
+		result := importDataset(d.Name, loaded.observations())
+		log.Printf("dataset: applied %s to data applied:%d skipped:%d", d.Name, result.Applied, result.Skipped)
+	}
+	return nil
+}
+
+// readSource reads series from b in the dataset format, or an export format chosen by the extension of the source
+func (d *Dataset) readSource(b []byte) (SeriesSlice, error) {
+	switch d.Format {
+	case "":
+		return readExport(d.Source, b)
+	case FormatWHO, FormatMapped:
+		records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if d.Format == FormatWHO {
+			return readWHOCSV(records)
+		}
+		return readMappedCSV(records, d.Columns)
+	case FormatXLSX:
+		records, err := readXLSX(b, d.Sheet)
+		if err != nil {
+			return nil, err
+		}
+		return readMappedCSV(records, d.Columns)
+	}
+	return nil, fmt.Errorf("load: unknown format:%s", d.Format)
+}
+
+// read returns the contents of the source, fetching it if it is a url
+func (d *Dataset) read() ([]byte, error) {
+	if !strings.HasPrefix(d.Source, "http://") && !strings.HasPrefix(d.Source, "https://") {
+		return ioutil.ReadFile(d.Source)
+	}
+	return fetch(d.Source)
+}
+
+// refresh is called on a schedule to reload the dataset, keeping the data we have on failure
+func (d *Dataset) refresh() {
+	err := d.Load()
+	if err != nil {
+		log.Printf("schedule: error refreshing dataset:%s", err)
+	}
+}
+
+// UpdatedAt returns the time the dataset was last loaded
+func (d *Dataset) UpdatedAt() time.Time {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.updatedAt
+}
+
+// FetchSeries returns the series for country and province from this dataset
+func (d *Dataset) FetchSeries(country string, province string) (*Series, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.data.FetchSeries(country, province)
+}
+
+// FetchSlug returns the series with slug from this dataset
+func (d *Dataset) FetchSlug(slug string) (*Series, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.data.FetchSlug(slug)
+}
+
+// ListSeries returns a page of series from this dataset matching filter starting at offset,
+// and the total available, limit 0 means no limit
+func (d *Dataset) ListSeries(filter SeriesFilter, offset, limit int) (SeriesSlice, int) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.data.List(filter, offset, limit)
+}
@@ -0,0 +1,57 @@
+package covid
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDataset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "covid")
+	if err != nil {
+		t.Fatalf("test: temp dir error:%s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ecdc.csv")
+	csv := "country,province,date,confirmed,deaths\nItaly,,2020-03-01,10,1\nItaly,,2020-03-02,20,2\nSpain,,2020-03-01,5,0\n"
+	err = ioutil.WriteFile(path, []byte(csv), 0644)
+	if err != nil {
+		t.Fatalf("test: write error:%s", err)
+	}
+
+	err = AddDataset(&Dataset{Name: "ecdc", Source: path})
+	if err != nil {
+		t.Fatalf("test: add dataset error:%s", err)
+	}
+	defer func() {
+		datasetsMutex.Lock()
+		delete(datasets, "ecdc")
+		datasetsMutex.Unlock()
+	}()
+
+	names := Datasets()
+	if len(names) != 2 || names[0] != "ecdc" || names[1] != DefaultDataset {
+		t.Fatalf("test: datasets wanted:[ecdc jhu] got:%v", names)
+	}
+
+	d, err := FetchDataset("ecdc")
+	if err != nil {
+		t.Fatalf("test: fetch dataset error:%s", err)
+	}
+	s, err := d.FetchSlug("italy")
+	if err != nil || s.TotalConfirmed() != 20 {
+		t.Fatalf("test: dataset series wanted confirmed:20 got:%v err:%v", s, err)
+	}
+	list, total := d.ListSeries(SeriesFilter{Countries: true}, 0, 0)
+	if total != 2 || len(list) != 2 {
+		t.Fatalf("test: dataset list wanted:2 got:%d", total)
+	}
+
+	for _, name := range []string{DefaultDataset, "Has Spaces", ""} {
+		if err := AddDataset(&Dataset{Name: name, Source: path}); err == nil {
+			t.Fatalf("test: add dataset wanted error for name:%s", name)
+		}
+	}
+}
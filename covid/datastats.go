@@ -0,0 +1,109 @@
+package covid
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// sourceUpdates records when each source file we loaded was last downloaded, by file name
+// guarded by mutex like our data
+var sourceUpdates = make(map[string]time.Time)
+
+// recordSource records the modification time of the source file at path, which is when it was last downloaded
+func recordSource(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	sourceUpdates[filepath.Base(path)] = info.ModTime().UTC()
+}
+
+// SourceUpdate records when one source of data was last updated
+type SourceUpdate struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// True if the source failed to load last time, so its data is stale
+	Failed bool `json:"failed,omitempty"`
+}
+
+// DataStats holds counts for our dataset as a whole, for monitoring and to describe the data
+type DataStats struct {
+	Series    int `json:"series"`
+	Countries int `json:"countries"`
+	Provinces int `json:"provinces"`
+
+	// The dates covered by the data, and the number of days
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+	Days     int    `json:"days"`
+
+	// Global totals
+	Confirmed int `json:"confirmed"`
+	Deaths    int `json:"deaths"`
+
+	UpdatedAt time.Time      `json:"updated_at"`
+	Sources   []SourceUpdate `json:"sources"`
+	Datasets  []SourceUpdate `json:"datasets"`
+}
+
+// DataStats returns counts of series, the dates covered and global totals for slice
+func (slice SeriesSlice) DataStats() *DataStats {
+	stats := &DataStats{Series: len(slice), Sources: []SourceUpdate{}, Datasets: []SourceUpdate{}}
+	var start, end time.Time
+	for _, s := range slice {
+		switch {
+		case s.Global():
+			stats.Confirmed = s.TotalConfirmed()
+			stats.Deaths = s.TotalDeaths()
+		case s.Province == "":
+			stats.Countries++
+		default:
+			stats.Provinces++
+		}
+		if s.UpdatedAt.After(stats.UpdatedAt) {
+			stats.UpdatedAt = s.UpdatedAt
+		}
+		if len(s.Deaths) == 0 {
+			continue
+		}
+		if start.IsZero() || s.StartsAt.Before(start) {
+			start = s.StartsAt
+		}
+		if last := s.StartsAt.AddDate(0, 0, len(s.Deaths)-1); last.After(end) {
+			end = last
+		}
+	}
+	if !start.IsZero() {
+		stats.StartsAt = start.Format("2006-01-02")
+		stats.EndsAt = end.Format("2006-01-02")
+		stats.Days = daysBetween(start, end) + 1
+	}
+	return stats
+}
+
+// FetchDataStats uses our stored data to return counts for the dataset as a whole,
+// with the last update of each source file and additional dataset
+func FetchDataStats() *DataStats {
+	mutex.RLock()
+	stats := data.DataStats()
+	for name, t := range sourceUpdates {
+		stats.Sources = append(stats.Sources, SourceUpdate{Name: name, UpdatedAt: t, Failed: report.failed(name)})
+	}
+	for _, f := range report.Failed {
+		if _, ok := sourceUpdates[f.File]; !ok {
+			stats.Sources = append(stats.Sources, SourceUpdate{Name: f.File, Failed: true})
+		}
+	}
+	mutex.RUnlock()
+	sort.Slice(stats.Sources, func(i, j int) bool { return stats.Sources[i].Name < stats.Sources[j].Name })
+
+	datasetsMutex.RLock()
+	defer datasetsMutex.RUnlock()
+	for name, d := range datasets {
+		stats.Datasets = append(stats.Datasets, SourceUpdate{Name: name, UpdatedAt: d.UpdatedAt()})
+	}
+	sort.Slice(stats.Datasets, func(i, j int) bool { return stats.Datasets[i].Name < stats.Datasets[j].Name })
+	return stats
+}
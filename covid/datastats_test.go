@@ -0,0 +1,26 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDataStats(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		&Series{StartsAt: start, Deaths: []int{1, 2, 3}, Confirmed: []int{10, 20, 30}},
+		&Series{Country: "China", StartsAt: start, Deaths: []int{1, 2, 3}, Confirmed: []int{10, 20, 30}},
+		&Series{Country: "China", Province: "Hubei", StartsAt: start.AddDate(0, 0, 1), Deaths: []int{1, 2, 3}},
+		&Series{Country: "Italy", StartsAt: start, Deaths: []int{0}},
+	}
+	stats := slice.DataStats()
+	if stats.Series != 4 || stats.Countries != 2 || stats.Provinces != 1 {
+		t.Fatalf("test: stats counts wrong got:%d %d %d", stats.Series, stats.Countries, stats.Provinces)
+	}
+	if stats.StartsAt != "2020-03-01" || stats.EndsAt != "2020-03-04" || stats.Days != 4 {
+		t.Fatalf("test: stats dates wrong got:%s %s %d", stats.StartsAt, stats.EndsAt, stats.Days)
+	}
+	if stats.Deaths != 3 || stats.Confirmed != 30 {
+		t.Fatalf("test: stats totals wrong wanted:3 30 got:%d %d", stats.Deaths, stats.Confirmed)
+	}
+}
@@ -0,0 +1,163 @@
+package covid
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormatDiseaseSh is the disease.sh JSON API, the source is the api base url e.g. https://disease.sh/v3/covid-19
+// history is read from the historical endpoint, and today's figures from the countries endpoint
+const FormatDiseaseSh = "disease.sh"
+
+// diseaseShHistory is one entry from the disease.sh historical endpoint
+type diseaseShHistory struct {
+	Country  string `json:"country"`
+	Province string `json:"province"`
+	Timeline struct {
+		Cases  map[string]int `json:"cases"`
+		Deaths map[string]int `json:"deaths"`
+	} `json:"timeline"`
+}
+
+// diseaseShCountry is one entry from the disease.sh countries endpoint with current totals
+type diseaseShCountry struct {
+	Country     string `json:"country"`
+	Updated     int64  `json:"updated"`
+	Cases       int    `json:"cases"`
+	Deaths      int    `json:"deaths"`
+	CountryInfo struct {
+		ISO2 string `json:"iso2"`
+	} `json:"countryInfo"`
+}
+
+// fetchDiseaseSh fetches all history and current figures from the disease.sh api at base
+func fetchDiseaseSh(base string) (SeriesSlice, error) {
+	base = strings.TrimSuffix(base, "/")
+	history, err := fetch(base + "/historical?lastdays=all")
+	if err != nil {
+		return nil, err
+	}
+	current, err := fetch(base + "/countries")
+	if err != nil {
+		return nil, err
+	}
+	return readDiseaseSh(history, current)
+}
+
+// readDiseaseSh returns series from the json responses of the disease.sh historical and countries endpoints
+// countries with only province history are given a country series summed from their provinces
+func readDiseaseSh(history, current []byte) (SeriesSlice, error) {
+	var entries []diseaseShHistory
+	err := json.Unmarshal(history, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("load: invalid disease.sh history:%s", err)
+	}
+	var countries []diseaseShCountry
+	if current != nil {
+		err = json.Unmarshal(current, &countries)
+		if err != nil {
+			return nil, fmt.Errorf("load: invalid disease.sh countries:%s", err)
+		}
+	}
+
+	// Note which countries have national figures, as some only have provinces
+	national := make(map[string]bool)
+	for _, e := range entries {
+		if e.Province == "" {
+			national[diseaseShName(e.Country)] = true
+		}
+	}
+
+	var rows []Row
+	totals := make(map[string]map[time.Time]*Row)
+	for _, e := range entries {
+		country := diseaseShName(e.Country)
+		province := ""
+		if e.Province != "" {
+			province = strings.Title(e.Province)
+		}
+		for key, confirmed := range e.Timeline.Cases {
+			d, err := time.Parse("1/2/06", key)
+			if err != nil {
+				return nil, fmt.Errorf("load: invalid disease.sh date:%s", key)
+			}
+			r := Row{Country: country, Province: province, Date: d, Confirmed: confirmed, Deaths: e.Timeline.Deaths[key]}
+			rows = append(rows, r)
+
+			if province == "" || national[country] {
+				continue
+			}
+			if totals[country] == nil {
+				totals[country] = make(map[time.Time]*Row)
+			}
+			total, ok := totals[country][d]
+			if !ok {
+				total = &Row{Country: country, Date: d}
+				totals[country][d] = total
+			}
+			total.Confirmed += r.Confirmed
+			total.Deaths += r.Deaths
+		}
+	}
+
+	// Add country totals for countries with only provinces, which are excluded from global as the provinces are included
+	var summed []string
+	for country, days := range totals {
+		summed = append(summed, country)
+		for _, r := range days {
+			rows = append(rows, *r)
+		}
+	}
+	slice := SeriesFromRows(rows)
+	for _, country := range summed {
+		if s, err := slice.FetchSeries(country, ""); err == nil {
+			s.excludeGlobal = true
+		}
+	}
+
+	// Add current totals as provisional values for today, if history doesn't include it yet
+	for _, c := range countries {
+		name := countryForISO(c.CountryInfo.ISO2)
+		if name == "" {
+			name = diseaseShName(c.Country)
+		}
+		s, err := slice.FetchSeries(name, "")
+		if err != nil || c.Updated == 0 {
+			continue
+		}
+		updated := time.Unix(0, c.Updated*int64(time.Millisecond)).UTC()
+		day := daysBetween(s.StartsAt, dayAt(updated))
+		if day < len(s.Deaths)-1 || day > len(s.Deaths) {
+			continue
+		}
+		if ok, err := s.AddProvisional(day, updated, c.Cases, c.Deaths); !ok || err != nil {
+			continue
+		}
+		s.setDailyUpdate("disease.sh", updated)
+		s.UpdateDaily()
+	}
+
+	return slice, nil
+}
+
+// diseaseShAliases maps names used by disease.sh to the names used in our dataset where they differ
+var diseaseShAliases = map[string]string{
+	"USA":      "US",
+	"UK":       "United Kingdom",
+	"S. Korea": "Korea, South",
+	"Taiwan":   "Taiwan*",
+	"Myanmar":  "Burma",
+}
+
+// diseaseShName returns the name used in our dataset for a country name from disease.sh
+func diseaseShName(name string) string {
+	if n, ok := diseaseShAliases[name]; ok {
+		return n
+	}
+	if n, ok := countryAliases[name]; ok {
+		return n
+	}
+	return name
+}
@@ -0,0 +1,47 @@
+package covid
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReadDiseaseSh(t *testing.T) {
+	history := `[
+		{"country":"USA","province":null,"timeline":{"cases":{"3/1/20":10,"3/2/20":20},"deaths":{"3/1/20":1,"3/2/20":2}}},
+		{"country":"Australia","province":"new south wales","timeline":{"cases":{"3/1/20":5,"3/2/20":6},"deaths":{"3/1/20":0,"3/2/20":1}}},
+		{"country":"Australia","province":"victoria","timeline":{"cases":{"3/1/20":3,"3/2/20":4},"deaths":{"3/1/20":0,"3/2/20":0}}}
+	]`
+	updated := time.Date(2020, 3, 3, 12, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	current := `[{"country":"USA","updated":` + strconv.FormatInt(updated, 10) + `,"cases":25,"deaths":3,"countryInfo":{"iso2":"US"}}]`
+
+	slice, err := readDiseaseSh([]byte(history), []byte(current))
+	if err != nil {
+		t.Fatalf("test: disease.sh error:%s", err)
+	}
+
+	us, err := slice.FetchSeries("US", "")
+	if err != nil {
+		t.Fatalf("test: disease.sh missing US:%s", err)
+	}
+	// Current figures are added as a new day
+	if len(us.Confirmed) != 3 || us.TotalConfirmed() != 25 || us.DeathsDaily[2] != 1 {
+		t.Fatalf("test: disease.sh wrong data for US got:%v %v", us.Confirmed, us.Deaths)
+	}
+
+	// Countries with only provinces are summed, and excluded from global
+	australia, err := slice.FetchSeries("Australia", "")
+	if err != nil {
+		t.Fatalf("test: disease.sh missing Australia:%s", err)
+	}
+	if australia.TotalConfirmed() != 10 || australia.AddToGlobal() {
+		t.Fatalf("test: disease.sh wrong data for Australia got:%v", australia.Confirmed)
+	}
+	if _, err := slice.FetchSeries("Australia", "New South Wales"); err != nil {
+		t.Fatalf("test: disease.sh missing province:%s", err)
+	}
+
+	if _, err := readDiseaseSh([]byte(`{}`), nil); err == nil {
+		t.Fatalf("test: disease.sh wanted error for invalid history")
+	}
+}
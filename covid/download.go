@@ -0,0 +1,189 @@
+package covid
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache settings, protected by fetchMutex
+var (
+	// cacheDir is the directory for cached and partial downloads
+	// if blank downloads are held in memory and start again from the beginning on failure
+	cacheDir string
+	// cacheTTL is how long a cached download is used before checking the source again with its etag
+	cacheTTL time.Duration
+	// offline loads from the cache only, and fails for downloads not in the cache
+	offline bool
+)
+
+// SetCacheDir sets the directory used to cache downloads, so that interrupted downloads resume
+// with a range request rather than fetching the whole file again, and unchanged files are not downloaded again
+func SetCacheDir(dir string) error {
+	if dir != "" {
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			return fmt.Errorf("fetch: error creating cache dir:%s", err)
+		}
+	}
+	fetchMutex.Lock()
+	defer fetchMutex.Unlock()
+	cacheDir = dir
+	return nil
+}
+
+// SetCacheTTL sets how long cached downloads are used without checking the source, 0 to check each time
+func SetCacheTTL(ttl time.Duration) {
+	fetchMutex.Lock()
+	defer fetchMutex.Unlock()
+	cacheTTL = ttl
+}
+
+// SetOffline sets whether fetches are loaded from the cache only, for development and tests without network access
+func SetOffline(o bool) {
+	fetchMutex.Lock()
+	defer fetchMutex.Unlock()
+	offline = o
+}
+
+// readCache returns the cached body for u in dir, and whether it was cached within ttl, or nil if not cached
+func readCache(dir, u string, ttl time.Duration) ([]byte, bool) {
+	path := cachePath(dir, u)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return body, time.Since(info.ModTime()) < ttl
+}
+
+// cachePath returns the path in dir for the url u
+func cachePath(dir, u string) string {
+	sum := sha1.Sum([]byte(u))
+	return filepath.Join(dir, hex.EncodeToString(sum[:]))
+}
+
+// fetchResume returns the body of a GET of u, writing it to a partial file in dir as it arrives, then keeping it in the cache
+// if the file is cached the request is conditional on its etag, so unchanged files are not downloaded again
+// if a partial file remains from an earlier attempt, only the rest of the file is requested
+// the validator (etag or last modified) of the partial file is sent with If-Range, so if the file has changed
+// the server sends all of it again, and we start over
+func fetchResume(u, dir string) ([]byte, bool, error) {
+	cached := cachePath(dir, u)
+	path := cached + ".part"
+	validatorPath := path + ".validator"
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch: invalid url %s:%s", u, err)
+	}
+	var offset int64
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		validator, err := ioutil.ReadFile(validatorPath)
+		if err == nil && len(validator) > 0 {
+			offset = info.Size()
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Range", string(validator))
+		}
+	} else if validator, err := ioutil.ReadFile(cached + ".etag"); err == nil && len(validator) > 0 {
+		// Ask for the file only if it has changed since we cached it
+		if strings.HasPrefix(string(validator), `"`) || strings.HasPrefix(string(validator), "W/") {
+			req.Header.Set("If-None-Match", string(validator))
+		} else {
+			req.Header.Set("If-Modified-Since", string(validator))
+		}
+	}
+
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("fetch: error fetching %s:%s", u, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		now := time.Now()
+		os.Chtimes(cached, now, now)
+		body, err := ioutil.ReadFile(cached)
+		if err != nil {
+			return nil, false, fmt.Errorf("fetch: error reading cache:%s", err)
+		}
+		return body, false, nil
+	case http.StatusPartialContent:
+		if contentRangeStart(resp.Header.Get("Content-Range")) != offset {
+			os.Remove(path)
+			return nil, true, fmt.Errorf("fetch: unexpected range from %s:%s", u, resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The whole file, either we asked for it or the server ignored our range
+		flags |= os.O_TRUNC
+		validator := resp.Header.Get("ETag")
+		if validator == "" {
+			validator = resp.Header.Get("Last-Modified")
+		}
+		err = ioutil.WriteFile(validatorPath, []byte(validator), 0600)
+		if err != nil {
+			return nil, false, fmt.Errorf("fetch: error writing cache:%s", err)
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is no longer valid for this url, so start over
+		os.Remove(path)
+		return nil, true, fmt.Errorf("fetch: range not satisfiable for %s", u)
+	default:
+		io.Copy(ioutil.Discard, resp.Body)
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retry, fmt.Errorf("fetch: error fetching %s status:%d", u, resp.StatusCode)
+	}
+
+	f, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch: error writing cache:%s", err)
+	}
+	_, err = io.Copy(f, resp.Body)
+	f.Close()
+	if err != nil {
+		// Keep what we have so the next attempt resumes from here
+		return nil, true, fmt.Errorf("fetch: error reading %s:%s", u, err)
+	}
+
+	// Keep the completed download and its validator in the cache
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch: error reading cache:%s", err)
+	}
+	err = os.Rename(path, cached)
+	if err == nil {
+		err = os.Rename(validatorPath, cached+".etag")
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch: error writing cache:%s", err)
+	}
+	return body, false, nil
+}
+
+// contentRangeStart returns the first byte in a Content-Range header e.g. 100 for bytes 100-199/200, or -1 if invalid
+func contentRangeStart(header string) int64 {
+	header = strings.TrimPrefix(header, "bytes ")
+	i := strings.Index(header, "-")
+	if i < 0 {
+		return -1
+	}
+	start, err := strconv.ParseInt(header[:i], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return start
+}
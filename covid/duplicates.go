@@ -0,0 +1,142 @@
+package covid
+
+import (
+	"log"
+	"strings"
+)
+
+// Kinds of duplicate series found on load
+const (
+	// DuplicateMerged is a series with a known variant name, merged into the series with our name
+	DuplicateMerged = "merged"
+	// DuplicateVariant is a series with a name similar to another series, which is not in the alias table
+	DuplicateVariant = "variant"
+	// DuplicateProvince is a province with the same name as its country, which may duplicate the country series
+	DuplicateProvince = "province"
+)
+
+// seriesAliases maps variant names used upstream for a series to the names we use,
+// as country or country/province, variants are merged into one series on load
+var seriesAliases = map[string]string{
+	"The Bahamas":             "Bahamas",
+	"Bahamas, The":            "Bahamas",
+	"The Gambia":              "Gambia",
+	"Gambia, The":             "Gambia",
+	"East Timor":              "Timor-Leste",
+	"US/Virgin Islands, U.S.": "US/Virgin Islands",
+	"US/Virgin Islands, U.S":  "US/Virgin Islands",
+}
+
+// Duplicate records a likely duplicate series found on load
+type Duplicate struct {
+	Kind string `json:"kind"`
+	// The names of the series as country or country/province, the series kept first
+	Series []string `json:"series"`
+}
+
+// canonicalName returns the country and province we use for a series named country and province upstream
+func canonicalName(country, province string) (string, string) {
+	if province != "" {
+		if name, ok := seriesAliases[country+"/"+province]; ok {
+			parts := strings.SplitN(name, "/", 2)
+			return parts[0], parts[1]
+		}
+	}
+	if name, ok := seriesAliases[country]; ok {
+		return name, province
+	}
+	if name, ok := countryAliases[country]; ok {
+		return name, province
+	}
+	return country, province
+}
+
+// seriesName returns the name of a series as country or country/province
+func seriesName(country, province string) string {
+	if province == "" {
+		return country
+	}
+	return country + "/" + province
+}
+
+// variantKey returns a key for a name which ignores case, punctuation and common variations
+// e.g. Saint Martin and St. Martin have the same key
+func variantKey(name string) string {
+	name = strings.ToLower(name)
+	var words []string
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		switch w {
+		case "the", "and", "of":
+			continue
+		case "saint":
+			w = "st"
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " ")
+}
+
+// dedupe renames series with variant names to our names, merges series which then share a name,
+// and reports series which look like duplicates but are not in the alias table
+func (slice SeriesSlice) dedupe() (SeriesSlice, []Duplicate) {
+	var duplicates []Duplicate
+	kept := make(map[string]*Series)
+	list := make(SeriesSlice, 0, len(slice))
+	for _, s := range slice {
+		original := seriesName(s.Country, s.Province)
+		s.Country, s.Province = canonicalName(s.Country, s.Province)
+		name := seriesName(s.Country, s.Province)
+		existing, ok := kept[name]
+		if !ok {
+			kept[name] = s
+			list = append(list, s)
+			continue
+		}
+		existing.mergeMax(s)
+		duplicates = append(duplicates, Duplicate{Kind: DuplicateMerged, Series: []string{name, original}})
+		log.Printf("load: merged duplicate series:%s into:%s", original, name)
+	}
+
+	// Report series with similar names, and provinces named for their country
+	variants := make(map[string]string)
+	for _, s := range list {
+		name := seriesName(s.Country, s.Province)
+		key := variantKey(s.Country) + "/" + variantKey(s.Province)
+		if other, ok := variants[key]; ok {
+			duplicates = append(duplicates, Duplicate{Kind: DuplicateVariant, Series: []string{other, name}})
+			log.Printf("load: possible duplicate series:%s of:%s", name, other)
+		} else {
+			variants[key] = name
+		}
+		if s.Province != "" && variantKey(s.Province) == variantKey(s.Country) && kept[s.Country] != nil {
+			duplicates = append(duplicates, Duplicate{Kind: DuplicateProvince, Series: []string{s.Country, name}})
+			log.Printf("load: possible duplicate series:%s of:%s", name, s.Country)
+		}
+	}
+	return list, duplicates
+}
+
+// mergeMax merges the values of other into s, taking the larger value for each day
+// as duplicates are usually the same figures reported under two names, one of which may lag the other
+func (s *Series) mergeMax(other *Series) {
+	offset := daysBetween(s.StartsAt, other.StartsAt)
+	for i := range other.Deaths {
+		day := offset + i
+		if day < 0 || day >= len(s.Deaths) || day >= len(s.Confirmed) || i >= len(other.Confirmed) {
+			continue
+		}
+		if other.Deaths[i] > s.Deaths[day] {
+			s.Deaths[day] = other.Deaths[i]
+		}
+		if other.Confirmed[i] > s.Confirmed[day] {
+			s.Confirmed[day] = other.Confirmed[i]
+		}
+	}
+	if other.UpdatedAt.After(s.UpdatedAt) {
+		s.UpdatedAt = other.UpdatedAt
+	}
+	s.mergeUpdates(other)
+	s.UpdateDaily()
+}
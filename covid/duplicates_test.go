@@ -0,0 +1,35 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupe(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		&Series{Country: "Bahamas", StartsAt: start, Deaths: []int{0, 1, 1}, Confirmed: []int{1, 2, 3}},
+		&Series{Country: "Bahamas, The", StartsAt: start, Deaths: []int{0, 0, 2}, Confirmed: []int{1, 2, 2}},
+		&Series{Country: "US", Province: "Virgin Islands, U.S.", StartsAt: start, Deaths: []int{0, 0, 0}, Confirmed: []int{0, 1, 1}},
+		&Series{Country: "France", Province: "St Martin", StartsAt: start, Deaths: []int{0, 0, 0}, Confirmed: []int{0, 0, 1}},
+		&Series{Country: "France", Province: "Saint Martin", StartsAt: start, Deaths: []int{0, 0, 0}, Confirmed: []int{0, 0, 1}},
+	}
+
+	list, duplicates := slice.dedupe()
+	if len(list) != 4 {
+		t.Fatalf("test: dedupe wrong len wanted:4 got:%d", len(list))
+	}
+	bahamas := list[0]
+	if bahamas.TotalDeaths() != 2 || bahamas.TotalConfirmed() != 3 || bahamas.DeathsDaily[2] != 1 {
+		t.Fatalf("test: merged series wrong got:%v %v", bahamas.Deaths, bahamas.Confirmed)
+	}
+	if list[1].Province != "Virgin Islands" {
+		t.Fatalf("test: alias not applied got:%s", list[1].Province)
+	}
+	if len(duplicates) != 2 || duplicates[0].Kind != DuplicateMerged || duplicates[1].Kind != DuplicateVariant {
+		t.Fatalf("test: duplicates wrong got:%v", duplicates)
+	}
+	if duplicates[1].Series[0] != "France/St Martin" || duplicates[1].Series[1] != "France/Saint Martin" {
+		t.Fatalf("test: variant wrong got:%v", duplicates[1].Series)
+	}
+}
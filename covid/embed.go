@@ -0,0 +1,92 @@
+package covid
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Default embed chart settings
+const (
+	EmbedDays    = 28
+	embedWidth   = 400
+	embedHeight  = 120
+	embedBarGap  = 0.2
+	embedMaxDays = 366
+)
+
+// Embed holds the figures shown in an embeddable widget for one series and metric
+type Embed struct {
+	Title  string
+	Metric string
+	Path   string
+	Days   int
+	// The total and latest daily values for the metric
+	Total string
+	Today string
+	// An inline svg bar chart of daily values
+	Chart     template.HTML
+	UpdatedAt string
+}
+
+// Embed returns an embeddable widget for datum (DataDeaths or DataConfirmed)
+// with a bar chart of daily values for the last days
+func (s *Series) Embed(datum int, days int) (*Embed, error) {
+	if days <= 0 || days > embedMaxDays {
+		return nil, fmt.Errorf("embed: invalid days:%d", days)
+	}
+	e := &Embed{Title: s.Title(), Path: s.Path(0), Days: days}
+	switch datum {
+	case DataDeaths:
+		e.Metric = "Deaths"
+		e.Total = s.DeathsDisplay()
+	case DataConfirmed:
+		e.Metric = "Confirmed"
+		e.Total = s.ConfirmedDisplay()
+	default:
+		return nil, fmt.Errorf("embed: invalid datum:%d", datum)
+	}
+	values := lastInts(s.DailyValues(datum), days)
+	if len(values) > 0 {
+		e.Today = s.Format(values[len(values)-1])
+	}
+	if !s.UpdatedAt.IsZero() {
+		e.UpdatedAt = s.UpdatedAt.Format("2006-01-02 15:04")
+	}
+	color := "#a02020"
+	if datum == DataDeaths {
+		color = "#461e1e"
+	}
+	e.Chart = BarChart(values, embedWidth, embedHeight, color)
+	return e, nil
+}
+
+// BarChart returns an inline svg bar chart of values scaled to fit width and height
+// negative values (from corrections) are drawn as empty days
+func BarChart(values []int, width, height int, color string) template.HTML {
+	if len(values) == 0 || width <= 0 || height <= 0 {
+		return ""
+	}
+	max := 1
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	step := float64(width) / float64(len(values))
+	gap := step * embedBarGap
+	bars := make([]string, 0, len(values))
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		h := float64(v) * float64(height) / float64(max)
+		bars = append(bars, fmt.Sprintf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f"/>`,
+			float64(i)*step+gap/2, float64(height)-h, step-gap, h))
+	}
+
+	svg := fmt.Sprintf(`<svg class="chart" xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" preserveAspectRatio="none"><g fill="%s">%s</g></svg>`,
+		width, height, color, strings.Join(bars, ""))
+	return template.HTML(svg)
+}
@@ -0,0 +1,29 @@
+package covid
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbed(t *testing.T) {
+	s := &Series{Country: "Italy", StartsAt: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths: []int{1, 3, 6, 10}, Confirmed: []int{10, 30, 60, 100}}
+	s.UpdateDaily()
+
+	e, err := s.Embed(DataDeaths, 3)
+	if err != nil {
+		t.Fatalf("test: embed failed:%s", err)
+	}
+	if e.Title != "Italy" || e.Metric != "Deaths" || e.Total != "10" || e.Today != "4" || e.Path != "/italy" {
+		t.Fatalf("test: embed wrong got:%s %s %s %s %s", e.Title, e.Metric, e.Total, e.Today, e.Path)
+	}
+	if strings.Count(string(e.Chart), "<rect") != 3 {
+		t.Fatalf("test: embed chart bars wrong wanted:3 got:%d", strings.Count(string(e.Chart), "<rect"))
+	}
+
+	_, err = s.Embed(DataDeaths, 0)
+	if err == nil {
+		t.Fatalf("test: embed accepted invalid days")
+	}
+}
@@ -0,0 +1,74 @@
+package covid
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// LoadExport replaces our stored data with the data from one of our own exports at path
+// the format is chosen by extension: .parquet, .csv or .json
+// so that archives and backups can be loaded without the upstream csv files
+func LoadExport(path string) error {
+	start := time.Now()
+	log.Printf("data: loading export from path %s", path)
+
+	f, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	loaded, err := readExport(path, f)
+	if err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	replaceData(loaded)
+
+	log.Printf("server: loaded export in %s len:%d", time.Now().Sub(start), len(data))
+	return nil
+}
+
+// readExport reads series from b in one of our export formats, chosen by the extension of name
+func readExport(name string, b []byte) (SeriesSlice, error) {
+	var loaded SeriesSlice
+	var err error
+	switch filepath.Ext(name) {
+	case ".parquet":
+		loaded, err = ReadParquet(bytes.NewReader(b))
+	case ".csv":
+		loaded, err = ReadCSV(bytes.NewReader(b))
+	case ".json":
+		loaded, err = ReadJSON(bytes.NewReader(b))
+	default:
+		err = fmt.Errorf("load: unknown export format:%s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(loaded) == 0 {
+		return nil, fmt.Errorf("load: no series in export:%s", name)
+	}
+	return loaded, nil
+}
+
+// replaceData replaces our stored data with series loaded from an export, adding the global series
+// the caller must hold the lock
+func replaceData(loaded SeriesSlice) {
+	previous := data
+	loaded.applyImports(false)
+	loaded.applyHidden()
+	data = addGlobal(loaded)
+	data.archive()
+	axis = data.compact()
+	sort.Stable(data)
+	data.updateSlugs()
+	data.applyTags()
+	updateVersion(previous, data)
+}
@@ -0,0 +1,312 @@
+package covid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expressions combine metrics with + - * / and brackets e.g. deaths/confirmed*100,
+// and apply trailing windows with a suffix e.g. confirmed_daily.avg7, confirmed_daily.sum14 or deaths.lag7
+// names are any metric accepted by MetricValues, so there is no access to anything but series values
+// a + in an expression must be escaped as %2B in urls
+
+// maxWindow is the longest window accepted for avg, sum and lag
+const maxWindow = 365
+
+// Expression is a compiled metric expression, which may be evaluated against any series
+type Expression struct {
+	Source string
+	root   exprNode
+}
+
+// exprNode is one node of a compiled expression, returning a value for each day of s
+type exprNode interface {
+	eval(s *Series, days int) ([]float64, error)
+}
+
+// IsExpression returns true if metric is an expression rather than a metric name
+func IsExpression(metric string) bool {
+	return strings.ContainsAny(metric, "+-*/(). ")
+}
+
+// ParseExpression compiles the expression src, returning an error if it is malformed
+func ParseExpression(src string) (*Expression, error) {
+	if len(src) > maxMetricLength {
+		return nil, fmt.Errorf("expression: too long:%d wanted at most %d", len(src), maxMetricLength)
+	}
+	p := &exprParser{src: src}
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("expression: unexpected:%q at:%d", p.src[p.pos:], p.pos)
+	}
+	return &Expression{Source: src, root: root}, nil
+}
+
+// Values returns the value of the expression for each day of s, days where a divisor is zero are zero
+func (e *Expression) Values(s *Series) ([]float64, error) {
+	return e.root.eval(s, len(s.Deaths))
+}
+
+// metricFloats returns the values of metric, without rounding if it is an expression
+func (s *Series) metricFloats(metric string) ([]float64, error) {
+	if IsExpression(metric) {
+		e, err := ParseExpression(metric)
+		if err != nil {
+			return nil, err
+		}
+		return e.Values(s)
+	}
+	values, err := s.MetricValues(metric)
+	if err != nil {
+		return nil, err
+	}
+	return floatValues(values), nil
+}
+
+// exprParser is a recursive descent parser for expressions
+type exprParser struct {
+	src string
+	pos int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// peek returns the next byte after any spaces, or 0 at the end
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// parseSum parses terms separated by + or -
+func (p *exprParser) parseSum() (exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseProduct parses factors separated by * or /
+func (p *exprParser) parseProduct() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses a negated factor, or a factor with any window suffixes
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode{op: '-', left: constNode(0), right: operand}, nil
+	}
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == '.' {
+		p.pos++
+		name := p.word()
+		node, err = windowFor(name, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// parsePrimary parses a number, a metric name or a bracketed expression
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("expression: missing ) at:%d", p.pos)
+		}
+		p.pos++
+		return node, nil
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || (p.src[p.pos] >= '0' && p.src[p.pos] <= '9')) {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("expression: invalid number:%q", p.src[start:p.pos])
+		}
+		return constNode(v), nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return metricNode(p.word()), nil
+	case c == 0:
+		return nil, fmt.Errorf("expression: unexpected end")
+	}
+	return nil, fmt.Errorf("expression: unexpected:%q at:%d", string(c), p.pos)
+}
+
+// word reads a name of letters, digits and underscores
+func (p *exprParser) word() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := rune(p.src[p.pos])
+		if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// windowFor returns the window node for a suffix such as avg7 applied to node
+func windowFor(name string, node exprNode) (exprNode, error) {
+	for _, fn := range []string{"avg", "sum", "lag"} {
+		if !strings.HasPrefix(name, fn) {
+			continue
+		}
+		days, err := strconv.Atoi(strings.TrimPrefix(name, fn))
+		if err != nil || days < 1 || days > maxWindow {
+			return nil, fmt.Errorf("expression: invalid window:%q wanted e.g. %s7 up to %d days", name, fn, maxWindow)
+		}
+		return windowNode{fn: fn, days: days, operand: node}, nil
+	}
+	return nil, fmt.Errorf("expression: unknown function:%q wanted avg, sum or lag", name)
+}
+
+// constNode is a number, the same on every day
+type constNode float64
+
+func (n constNode) eval(s *Series, days int) ([]float64, error) {
+	values := make([]float64, days)
+	for i := range values {
+		values[i] = float64(n)
+	}
+	return values, nil
+}
+
+// metricNode is the values of a named metric, auxiliary series keep their fractional values
+type metricNode string
+
+func (n metricNode) eval(s *Series, days int) ([]float64, error) {
+	values := make([]float64, days)
+	if aux := s.AuxiliaryValues(string(n)); aux != nil {
+		copy(values, aux)
+		return values, nil
+	}
+	ints, err := s.MetricValues(string(n))
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < days && i < len(ints); i++ {
+		values[i] = float64(ints[i])
+	}
+	return values, nil
+}
+
+// binaryNode applies an arithmetic operator to the values of each day
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+func (n binaryNode) eval(s *Series, days int) ([]float64, error) {
+	left, err := n.left.eval(s, days)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(s, days)
+	if err != nil {
+		return nil, err
+	}
+	for i := range left {
+		switch n.op {
+		case '+':
+			left[i] += right[i]
+		case '-':
+			left[i] -= right[i]
+		case '*':
+			left[i] *= right[i]
+		case '/':
+			if right[i] == 0 {
+				left[i] = 0
+			} else {
+				left[i] /= right[i]
+			}
+		}
+	}
+	return left, nil
+}
+
+// windowNode applies a trailing window to values, the first days use the values available so far
+type windowNode struct {
+	fn      string
+	days    int
+	operand exprNode
+}
+
+func (n windowNode) eval(s *Series, days int) ([]float64, error) {
+	values, err := n.operand.eval(s, days)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]float64, len(values))
+	for i := range values {
+		start := i - n.days + 1
+		if start < 0 {
+			start = 0
+		}
+		switch n.fn {
+		case "lag":
+			if i >= n.days {
+				result[i] = values[i-n.days]
+			}
+		default:
+			var sum float64
+			for _, v := range values[start : i+1] {
+				sum += v
+			}
+			result[i] = sum
+			if n.fn == "avg" {
+				result[i] = sum / float64(i+1-start)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package covid
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExpression(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 2, 6, 12}, Confirmed: []int{0, 100, 200, 300}}
+	s.UpdateDaily()
+	s.SetAuxiliaryValues(AuxiliaryStringency, []float64{10.5, 20.5, 30.5, 40.5})
+
+	tests := []struct {
+		src  string
+		want []float64
+	}{
+		{"deaths/confirmed*100", []float64{0, 2, 3, 4}},
+		{"confirmed_daily.avg2", []float64{0, 50, 100, 100}},
+		{"deaths_daily.sum3", []float64{0, 2, 6, 12}},
+		{"deaths.lag1", []float64{0, 0, 2, 6}},
+		{"-(deaths+1)*2", []float64{-2, -6, -14, -26}},
+		{"stringency/2", []float64{5.25, 10.25, 15.25, 20.25}},
+	}
+	for _, tt := range tests {
+		e, err := ParseExpression(tt.src)
+		if err != nil {
+			t.Fatalf("test: expression:%s error:%s", tt.src, err)
+		}
+		got, err := e.Values(s)
+		if err != nil {
+			t.Fatalf("test: expression:%s error:%s", tt.src, err)
+		}
+		for i, v := range tt.want {
+			if got[i] != v {
+				t.Fatalf("test: expression:%s wanted:%v got:%v", tt.src, tt.want, got)
+			}
+		}
+	}
+
+	// Expressions with whole values are accepted wherever a metric name is
+	values, err := s.MetricValues("stringency*2")
+	if err != nil || values[0] != 21 {
+		t.Fatalf("test: metric values for expression wanted:21 got:%v error:%v", values, err)
+	}
+
+	// Fractional values are kept for transforms, and rejected rather than truncated elsewhere
+	s.Deaths, s.Confirmed = []int{0, 1, 1, 2}, []int{0, 3, 3, 3}
+	floats, err := s.metricFloats("deaths/confirmed*100")
+	if err != nil || math.Abs(floats[1]-100.0/3) > 1e-9 {
+		t.Fatalf("test: metric floats wanted:33.33 got:%v error:%v", floats, err)
+	}
+	for _, metric := range []string{"deaths/confirmed*100", "1/3*100"} {
+		if _, err := s.MetricValues(metric); err == nil {
+			t.Fatalf("test: metric values for expression:%s wanted error for fractional values", metric)
+		}
+		if _, err := s.Stats(metric, 0); err == nil {
+			t.Fatalf("test: stats for expression:%s wanted error for fractional values", metric)
+		}
+	}
+	if a, err := s.Adjusted("1/3*100", []string{TransformRaw}, 0); err != nil || math.Abs(a.Transforms[TransformRaw][0]-100.0/3) > 1e-9 {
+		t.Fatalf("test: adjusted expression wanted:33.33 got:%v error:%v", a, err)
+	}
+
+	for _, src := range []string{"deaths/", "(deaths", "deaths.avg0", "deaths.median7", "deaths$", "nope+1"} {
+		e, err := ParseExpression(src)
+		if err == nil {
+			_, err = e.Values(s)
+		}
+		if err == nil {
+			t.Fatalf("test: expression:%s wanted error", src)
+		}
+	}
+}
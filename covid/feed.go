@@ -0,0 +1,112 @@
+package covid
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// feedDays is the number of daily entries included in a feed
+const feedDays = 14
+
+// atomFeed is the root element of an atom feed
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomLink is a link to a page for a feed or entry
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// atomAuthor is the author of a feed
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// atomEntry is one daily update in a feed
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+// WriteAtom writes an atom feed to w with one entry per day for the most recent days of this series
+// link is the absolute url of the page for this series
+func (s *Series) WriteAtom(w io.Writer, link string) error {
+	updated := s.UpdatedAt
+	if updated.IsZero() {
+		updated = s.StartsAt.AddDate(0, 0, len(s.Deaths))
+	}
+
+	feed := atomFeed{
+		ID:      link,
+		Title:   fmt.Sprintf("Coronavirus - %s", s.Title()),
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: link},
+		Author:  atomAuthor{Name: "coronavirus.projectpage.app"},
+	}
+
+	// Add entries for the most recent days first
+	days := len(s.Deaths)
+	if len(s.Confirmed) < days {
+		days = len(s.Confirmed)
+	}
+	for i := days - 1; i >= 0 && i >= days-feedDays; i-- {
+		date := s.StartsAt.AddDate(0, 0, i)
+		entryUpdated := date.AddDate(0, 0, 1)
+		if i == days-1 {
+			entryUpdated = updated
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      fmt.Sprintf("%s#%s", link, date.Format("2006-01-02")),
+			Title:   fmt.Sprintf("%s %s: %s confirmed, %s deaths", s.Title(), date.Format("Jan 2"), s.Format(s.Confirmed[i]), s.Format(s.Deaths[i])),
+			Updated: entryUpdated.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: link},
+			Summary: s.feedSummary(i),
+		})
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	return e.Encode(feed)
+}
+
+// feedSummary returns a summary of the changes on day i compared to the day before
+func (s *Series) feedSummary(i int) string {
+	confirmed, deaths := s.dayChange(s.Confirmed, i), s.dayChange(s.Deaths, i)
+	return fmt.Sprintf("%s new cases (%s vs yesterday), %s new deaths (%s vs yesterday). Total %s confirmed, %s deaths.",
+		s.Format(confirmed), signed(confirmed-s.dayChange(s.Confirmed, i-1)),
+		s.Format(deaths), signed(deaths-s.dayChange(s.Deaths, i-1)),
+		s.Format(s.Confirmed[i]), s.Format(s.Deaths[i]))
+}
+
+// dayChange returns the change in cumulative values on day i, or 0 if out of range
+func (s *Series) dayChange(ints []int, i int) int {
+	if i < 0 || i >= len(ints) {
+		return 0
+	}
+	if i == 0 {
+		return ints[0]
+	}
+	return ints[i] - ints[i-1]
+}
+
+// signed formats i with a leading sign e.g. +12 or -3
+func signed(i int) string {
+	return fmt.Sprintf("%+d", i)
+}
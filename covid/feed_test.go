@@ -0,0 +1,70 @@
+package covid
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteAtom(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	deaths, confirmed := make([]int, 20), make([]int, 20)
+	for i := range deaths {
+		deaths[i], confirmed[i] = i*i, i*10
+	}
+	updated := time.Date(2020, 3, 20, 18, 30, 0, 0, time.UTC)
+	s := &Series{Country: "Korea, South", StartsAt: start, UpdatedAt: updated, Deaths: deaths, Confirmed: confirmed}
+
+	b := &bytes.Buffer{}
+	err := s.WriteAtom(b, "https://example.com/korea-south")
+	if err != nil {
+		t.Fatalf("test: atom error:%s", err)
+	}
+	if !strings.HasPrefix(b.String(), xml.Header) {
+		t.Fatalf("test: atom wanted xml header got:%s", b.String()[:40])
+	}
+
+	// The feed must be valid xml in the atom namespace
+	var feed atomFeed
+	err = xml.Unmarshal(b.Bytes(), &feed)
+	if err != nil {
+		t.Fatalf("test: atom invalid xml:%s", err)
+	}
+	if feed.XMLName.Space != "http://www.w3.org/2005/Atom" || feed.Updated != "2020-03-20T18:30:00Z" || feed.Title != "Coronavirus - Korea, South" {
+		t.Fatalf("test: atom wrong feed:%+v", feed)
+	}
+
+	// Entries are the most recent days, newest first
+	if len(feed.Entries) != feedDays {
+		t.Fatalf("test: atom wanted:%d entries got:%d", feedDays, len(feed.Entries))
+	}
+	first, last := feed.Entries[0], feed.Entries[len(feed.Entries)-1]
+	if first.ID != "https://example.com/korea-south#2020-03-20" || first.Updated != "2020-03-20T18:30:00Z" || last.ID != "https://example.com/korea-south#2020-03-07" {
+		t.Fatalf("test: atom wrong entries first:%s last:%s", first.ID, last.ID)
+	}
+	for i := 1; i < len(feed.Entries); i++ {
+		if feed.Entries[i].Updated >= feed.Entries[i-1].Updated {
+			t.Fatalf("test: atom entries out of order at:%d %s %s", i, feed.Entries[i-1].Updated, feed.Entries[i].Updated)
+		}
+	}
+	if first.Title != "Korea, South Mar 20: 190 confirmed, 361 deaths" || first.Summary != "10 new cases (+0 vs yesterday), 37 new deaths (+2 vs yesterday). Total 190 confirmed, 361 deaths." {
+		t.Fatalf("test: atom wrong entry:%+v", first)
+	}
+
+	// A series with fewer days has an entry for each, and an empty series has none
+	s = &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 1}, Confirmed: []int{5, 10}}
+	for _, days := range []int{2, 0} {
+		s.Deaths, s.Confirmed = s.Deaths[:days], s.Confirmed[:days]
+		b.Reset()
+		feed = atomFeed{}
+		err = s.WriteAtom(b, "https://example.com/italy")
+		if err == nil {
+			err = xml.Unmarshal(b.Bytes(), &feed)
+		}
+		if err != nil || len(feed.Entries) != days {
+			t.Fatalf("test: atom for %d days wanted:%d entries got:%d error:%v", days, days, len(feed.Entries), err)
+		}
+	}
+}
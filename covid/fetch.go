@@ -0,0 +1,165 @@
+package covid
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures retries of failed fetches from data sources
+type RetryPolicy struct {
+	// The number of attempts, including the first
+	Attempts int
+	// The wait before the first retry, doubled for each retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// The fraction by which each wait is varied at random, e.g. 0.2 for ±20%
+	Jitter float64
+}
+
+// DefaultRetryPolicy retries transient failures twice, over about 6 seconds
+var DefaultRetryPolicy = RetryPolicy{Attempts: 3, Backoff: 2 * time.Second, MaxBackoff: 30 * time.Second, Jitter: 0.2}
+
+// Circuit breaker settings, after this many failed fetches in a row from a host we stop fetching from it for a while
+const (
+	breakerThreshold = 5
+	breakerCooldown  = 10 * time.Minute
+)
+
+// breaker records failed fetches in a row from one host
+type breaker struct {
+	failures  int
+	openUntil time.Time
+}
+
+var (
+	fetchMutex  sync.Mutex
+	retryPolicy = DefaultRetryPolicy
+	// breakers are the circuit breakers for each host
+	breakers = make(map[string]*breaker)
+	// sleep waits between retries, replaced in tests
+	sleep = time.Sleep
+)
+
+// fetchClient is the client used for fetches from data sources
+var fetchClient = &http.Client{Timeout: 120 * time.Second}
+
+// SetRetryPolicy sets the policy for retrying failed fetches from data sources
+func SetRetryPolicy(policy RetryPolicy) {
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+	fetchMutex.Lock()
+	defer fetchMutex.Unlock()
+	retryPolicy = policy
+}
+
+// fetch returns the body of a GET of u, retrying network errors and server errors
+// with exponential backoff, fetches fail immediately while the circuit breaker for the host is open
+// with a cache dir, downloads are cached and recent or unchanged files are read from the cache
+func fetch(u string) ([]byte, error) {
+	host := u
+	if parsed, err := url.Parse(u); err == nil {
+		host = parsed.Host
+	}
+
+	fetchMutex.Lock()
+	policy := retryPolicy
+	dir := cacheDir
+	ttl := cacheTTL
+	local := offline
+	b := breakers[host]
+	if b == nil {
+		b = &breaker{}
+		breakers[host] = b
+	}
+	open := time.Now().Before(b.openUntil)
+	fetchMutex.Unlock()
+
+	// Use the cached copy if recent, or if we are offline
+	if dir != "" {
+		if body, fresh := readCache(dir, u, ttl); body != nil && (fresh || local) {
+			return body, nil
+		}
+	}
+	if local {
+		return nil, fmt.Errorf("fetch: offline and not cached:%s", u)
+	}
+	if open {
+		return nil, fmt.Errorf("fetch: too many failures from %s, skipping:%s", host, u)
+	}
+
+	var body []byte
+	var err error
+	for attempt := 1; attempt <= policy.Attempts; attempt++ {
+		if attempt > 1 {
+			wait := policy.backoff(attempt - 1)
+			log.Printf("fetch: retrying %s in %s after error:%s", u, wait, err)
+			sleep(wait)
+		}
+		var retry bool
+		if dir != "" {
+			body, retry, err = fetchResume(u, dir)
+		} else {
+			body, retry, err = fetchOnce(u)
+		}
+		if err == nil || !retry {
+			break
+		}
+	}
+
+	// Record the result for the circuit breaker
+	fetchMutex.Lock()
+	defer fetchMutex.Unlock()
+	if err == nil {
+		b.failures = 0
+		return body, nil
+	}
+	b.failures++
+	if b.failures >= breakerThreshold {
+		log.Printf("fetch: %d failures in a row from %s, pausing fetches for %s", b.failures, host, breakerCooldown)
+		b.openUntil = time.Now().Add(breakerCooldown)
+		b.failures = 0
+	}
+	return nil, err
+}
+
+// fetchOnce returns the body of a GET of u, and whether the request should be retried on failure
+func fetchOnce(u string) ([]byte, bool, error) {
+	resp, err := fetchClient.Get(u)
+	if err != nil {
+		return nil, true, fmt.Errorf("fetch: error fetching %s:%s", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retry, fmt.Errorf("fetch: error fetching %s status:%d", u, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("fetch: error reading %s:%s", u, err)
+	}
+	return body, false, nil
+}
+
+// backoff returns the wait before retry n (from 1), doubling each time with jitter
+func (p RetryPolicy) backoff(n int) time.Duration {
+	wait := p.Backoff
+	for i := 1; i < n && (p.MaxBackoff == 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		wait += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(wait))
+	}
+	return wait
+}
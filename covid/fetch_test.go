@@ -0,0 +1,167 @@
+package covid
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchRetry(t *testing.T) {
+	sleep = func(time.Duration) {}
+	defer func() {
+		sleep = time.Sleep
+		fetchMutex.Lock()
+		breakers = make(map[string]*breaker)
+		fetchMutex.Unlock()
+	}()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/flaky":
+			if requests < 3 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("ok"))
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			http.Error(w, "error", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	// Transient failures are retried
+	b, err := fetch(server.URL + "/flaky")
+	if err != nil || string(b) != "ok" || requests != 3 {
+		t.Fatalf("test: fetch wanted ok after 3 requests got:%s requests:%d err:%v", b, requests, err)
+	}
+
+	// Client errors are not retried
+	requests = 0
+	if _, err := fetch(server.URL + "/missing"); err == nil || requests != 1 {
+		t.Fatalf("test: fetch wanted 1 request for missing got:%d", requests)
+	}
+
+	// Repeated failures open the circuit breaker, so we stop requesting
+	for i := 0; i < breakerThreshold; i++ {
+		fetch(server.URL + "/broken")
+	}
+	requests = 0
+	if _, err := fetch(server.URL + "/flaky"); err == nil || requests != 0 {
+		t.Fatalf("test: fetch wanted breaker open got requests:%d", requests)
+	}
+
+	if wait := (RetryPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}).backoff(3); wait != 3*time.Second {
+		t.Fatalf("test: backoff wanted:3s got:%s", wait)
+	}
+}
+
+func TestFetchResume(t *testing.T) {
+	sleep = func(time.Duration) {}
+	dir, err := ioutil.TempDir("", "covid-cache")
+	if err != nil {
+		t.Fatalf("test: failed to create cache dir:%s", err)
+	}
+	defer func() {
+		sleep = time.Sleep
+		SetCacheDir("")
+		os.RemoveAll(dir)
+	}()
+	SetCacheDir(dir)
+
+	content := strings.Repeat("Country,Province,Date,Confirmed\n", 1000)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		if len(ranges) == 1 {
+			// Drop the connection halfway through the first download
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Write([]byte(content[:len(content)/2]))
+			return
+		}
+		http.ServeContent(w, r, "data.csv", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	b, err := fetch(server.URL + "/data.csv")
+	if err != nil || string(b) != content {
+		t.Fatalf("test: fetch wanted content len:%d got:%d err:%v", len(content), len(b), err)
+	}
+	want := fmt.Sprintf("bytes=%d-", len(content)/2)
+	if len(ranges) != 2 || ranges[1] != want {
+		t.Fatalf("test: fetch wanted resume with range:%s got:%v", want, ranges)
+	}
+
+	// The partial download is kept in the cache with its etag once complete
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Fatalf("test: cache wanted:2 files got:%d", len(files))
+	}
+}
+
+func TestFetchCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "covid-cache")
+	if err != nil {
+		t.Fatalf("test: failed to create cache dir:%s", err)
+	}
+	defer func() {
+		SetCacheDir("")
+		SetCacheTTL(0)
+		SetOffline(false)
+		os.RemoveAll(dir)
+	}()
+	SetCacheDir(dir)
+
+	content := "Country,Province,Date,Confirmed\n"
+	var statuses []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			statuses = append(statuses, http.StatusNotModified)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		statuses = append(statuses, http.StatusOK)
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	// Unchanged files are checked with the etag and read from the cache
+	for i := 0; i < 2; i++ {
+		b, err := fetch(server.URL + "/data.csv")
+		if err != nil || string(b) != content {
+			t.Fatalf("test: fetch wanted content got:%s err:%v", b, err)
+		}
+	}
+	if len(statuses) != 2 || statuses[1] != http.StatusNotModified {
+		t.Fatalf("test: fetch wanted not modified got:%v", statuses)
+	}
+
+	// Recent files are read from the cache without a request
+	SetCacheTTL(time.Hour)
+	b, err := fetch(server.URL + "/data.csv")
+	if err != nil || string(b) != content || len(statuses) != 2 {
+		t.Fatalf("test: fetch wanted cached content got:%s requests:%d err:%v", b, len(statuses), err)
+	}
+
+	// Offline fetches only read from the cache
+	SetCacheTTL(0)
+	SetOffline(true)
+	b, err = fetch(server.URL + "/data.csv")
+	if err != nil || string(b) != content || len(statuses) != 2 {
+		t.Fatalf("test: fetch wanted cached content offline got:%s requests:%d err:%v", b, len(statuses), err)
+	}
+	if _, err = fetch(server.URL + "/other.csv"); err == nil || len(statuses) != 2 {
+		t.Fatalf("test: fetch wanted error offline for uncached file")
+	}
+}
@@ -0,0 +1,84 @@
+package covid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PeriodFilter limits a request to the last Days days of data, 0 means all time
+type PeriodFilter struct {
+	Days int
+}
+
+// ParsePeriodFilter parses a period param in days e.g. 28 or 28d, blank or all means all time
+func ParsePeriodFilter(v string) (PeriodFilter, error) {
+	v = strings.TrimSpace(strings.ToLower(v))
+	if v == "" || v == "all" {
+		return PeriodFilter{}, nil
+	}
+	days, err := strconv.Atoi(strings.TrimSuffix(v, "d"))
+	if err != nil {
+		return PeriodFilter{}, fmt.Errorf("filter: invalid period:%q wanted days e.g. 28 or all", v)
+	}
+	f := PeriodFilter{Days: days}
+	return f, f.Validate()
+}
+
+// Validate returns an error if the period is negative
+func (f PeriodFilter) Validate() error {
+	if f.Days < 0 {
+		return fmt.Errorf("filter: invalid period:%d days must not be negative", f.Days)
+	}
+	return nil
+}
+
+// AllTime returns true if the filter covers all days
+func (f PeriodFilter) AllTime() bool {
+	return f.Days == 0
+}
+
+// maxMetricLength is the longest metric name accepted in params
+const maxMetricLength = 64
+
+// MetricFilter selects the metric for a request
+type MetricFilter struct {
+	Metric string
+	// The metrics accepted, if empty any well formed name is accepted, as auxiliary series vary by country
+	Allowed []string
+}
+
+// ParseMetricFilter parses a metric param, using fallback if blank
+// if allowed metrics are given the metric must be one of them
+func ParseMetricFilter(v string, fallback string, allowed ...string) (MetricFilter, error) {
+	f := MetricFilter{Metric: strings.TrimSpace(v), Allowed: allowed}
+	if f.Metric == "" {
+		f.Metric = fallback
+	}
+	return f, f.Validate()
+}
+
+// Validate returns an error if the metric is missing, malformed or not one of those allowed
+func (f MetricFilter) Validate() error {
+	if f.Metric == "" {
+		return fmt.Errorf("filter: missing metric")
+	}
+	if len(f.Metric) > maxMetricLength {
+		return fmt.Errorf("filter: invalid metric:%q longer than %d", f.Metric[:maxMetricLength], maxMetricLength)
+	}
+	for _, r := range f.Metric {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return fmt.Errorf("filter: invalid metric:%q", f.Metric)
+		}
+	}
+	if len(f.Allowed) == 0 {
+		return nil
+	}
+	for _, m := range f.Allowed {
+		if m == f.Metric {
+			return nil
+		}
+	}
+	return fmt.Errorf("filter: unknown metric:%s wanted one of:%s", f.Metric, strings.Join(f.Allowed, ", "))
+}
@@ -0,0 +1,52 @@
+package covid
+
+import (
+	"testing"
+)
+
+func TestPeriodFilter(t *testing.T) {
+	tests := []struct {
+		value string
+		days  int
+		valid bool
+	}{
+		{"", 0, true},
+		{"all", 0, true},
+		{"28", 28, true},
+		{"14d", 14, true},
+		{"-1", 0, false},
+		{"week", 0, false},
+	}
+	for _, test := range tests {
+		f, err := ParsePeriodFilter(test.value)
+		if (err == nil) != test.valid {
+			t.Fatalf("test: period %q valid wanted:%t got err:%v", test.value, test.valid, err)
+		}
+		if test.valid && f.Days != test.days {
+			t.Fatalf("test: period %q wanted:%d got:%d", test.value, test.days, f.Days)
+		}
+	}
+}
+
+func TestMetricFilter(t *testing.T) {
+	f, err := ParseMetricFilter("", MetricConfirmedDaily)
+	if err != nil || f.Metric != MetricConfirmedDaily {
+		t.Fatalf("test: metric fallback wrong got:%s %v", f.Metric, err)
+	}
+	f, err = ParseMetricFilter(AuxiliaryStringency, MetricConfirmedDaily)
+	if err != nil || f.Metric != AuxiliaryStringency {
+		t.Fatalf("test: auxiliary metric wrong got:%s %v", f.Metric, err)
+	}
+	_, err = ParseMetricFilter("deaths daily", MetricConfirmedDaily)
+	if err == nil {
+		t.Fatalf("test: accepted malformed metric")
+	}
+	_, err = ParseMetricFilter("", "")
+	if err == nil {
+		t.Fatalf("test: accepted missing metric")
+	}
+	_, err = ParseMetricFilter(MetricDeaths, LeaderboardDeathsPerMillion, LeaderboardDeathsPerMillion, LeaderboardCasesPer100k)
+	if err == nil {
+		t.Fatalf("test: accepted metric not allowed")
+	}
+}
@@ -0,0 +1,59 @@
+package covid
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+)
+
+// FuncMap returns a set of template functions for rendering series data in html/template
+// these wrap Series methods so that templates don't need to reimplement formatting
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"formatNumber": formatNumber,
+		"percent":      percent,
+		"trendArrow":   trendArrow,
+		"chartJSON":    chartJSON,
+		"dateRange":    dateRange,
+	}
+}
+
+// formatNumber formats a number for display e.g. 12.34k
+func formatNumber(i int) string {
+	s := &Series{}
+	return s.Format(i)
+}
+
+// percent returns value as a percentage of total e.g. 12.3%
+func percent(value, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(value)/float64(total)*100)
+}
+
+// trendArrow returns an arrow showing the trend of the series for datum, or "" if there is no series
+func trendArrow(s *Series, datum int) string {
+	if s == nil {
+		return ""
+	}
+	return s.TrendArrow(datum)
+}
+
+// chartJSON returns values encoded as json suitable for use in chart scripts
+func chartJSON(v interface{}) (template.JS, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(b), nil
+}
+
+// dateRange returns a display string for the dates covered by the series e.g. Jan 22 - Mar 23
+func dateRange(s *Series) string {
+	if s == nil || len(s.Deaths) == 0 {
+		return ""
+	}
+	end := s.StartsAt.AddDate(0, 0, len(s.Deaths)-1)
+	return fmt.Sprintf("%s - %s", s.StartsAt.Format("Jan 2"), end.Format("Jan 2"))
+}
@@ -0,0 +1,59 @@
+package covid
+
+import (
+	"html/template"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFuncMap(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	deaths := make([]int, 14)
+	for i := range deaths {
+		deaths[i] = i * i
+	}
+	s := &Series{Country: "Italy", StartsAt: start, Deaths: deaths, Confirmed: make([]int, 14)}
+	s.UpdateDaily()
+
+	type args struct {
+		Series *Series
+		Value  interface{}
+	}
+	tests := []struct {
+		src  string
+		args args
+		want string
+	}{
+		{`{{formatNumber 12345}}`, args{}, "12.35k"},
+		{`{{formatNumber 0}}`, args{}, "0"},
+		{`{{percent 1 8}}`, args{}, "12.5%"},
+		{`{{percent 1 0}}`, args{}, "0%"},
+		{`{{trendArrow .Series 0}}`, args{Series: s}, "▲"},
+		{`{{trendArrow .Series 0}}`, args{Series: &Series{}}, "▶"},
+		{`{{trendArrow .Series 0}}`, args{}, ""},
+		{`<script>var v = {{chartJSON .Value}};</script>`, args{Value: []int{1, 2}}, "<script>var v = [1,2];</script>"},
+		{`<script>var v = {{chartJSON .Value}};</script>`, args{}, "<script>var v = null;</script>"},
+		{`{{dateRange .Series}}`, args{Series: s}, "Mar 1 - Mar 14"},
+		{`{{dateRange .Series}}`, args{Series: &Series{}}, ""},
+		{`{{dateRange .Series}}`, args{}, ""},
+	}
+	for _, tt := range tests {
+		tmpl, err := template.New("test").Funcs(FuncMap()).Parse(tt.src)
+		if err != nil {
+			t.Fatalf("test: funcs parse:%s error:%s", tt.src, err)
+		}
+		b := &strings.Builder{}
+		err = tmpl.Execute(b, tt.args)
+		if err != nil || b.String() != tt.want {
+			t.Fatalf("test: funcs %s wanted:%q got:%q error:%v", tt.src, tt.want, b.String(), err)
+		}
+	}
+
+	// Values which can't be encoded fail the template rather than writing invalid script
+	tmpl := template.Must(template.New("test").Funcs(FuncMap()).Parse(`<script>var v = {{chartJSON .}};</script>`))
+	if err := tmpl.Execute(&strings.Builder{}, math.NaN()); err == nil {
+		t.Fatalf("test: funcs chartJSON wanted error for NaN")
+	}
+}
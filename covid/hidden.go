@@ -0,0 +1,116 @@
+package covid
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// hidden is the set of series hidden by an admin by slug, e.g. junk rows introduced upstream
+// hidden series keep their data and pages, but are left out of options, global totals and country lists
+// guarded by mutex, and applied on each load
+var hidden = make(map[string]bool)
+
+// Hidden returns the slugs of hidden series, sorted
+func Hidden() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	list := []string{}
+	for key := range hidden {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// SetHidden hides or shows the series with slug (e.g. italy or china/hubei), and updates global totals
+// series which are not loaded yet are hidden when they are
+func SetHidden(slug string, hide bool) error {
+	key := tagKey(slug)
+	if key == "" {
+		return fmt.Errorf("hidden: invalid series:%s", slug)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if hide {
+		hidden[key] = true
+	} else {
+		delete(hidden, key)
+	}
+
+	s, err := data.FetchSlug(key)
+	if err != nil || s.Hidden == hide {
+		return nil
+	}
+
+	if hide {
+		log.Printf("hidden: hiding series:%s", key)
+	} else {
+		log.Printf("hidden: restoring series:%s", key)
+	}
+
+	// Replace the series and global with copies, then move the series out of or back into global totals
+	included := s.AddToGlobal()
+	global, err := data.FetchSeries("", "")
+	if err != nil || global == s {
+		s = data.copyOnWrite(SeriesSlice{s})[0]
+		s.Hidden = hide
+		return nil
+	}
+	copies := data.copyOnWrite(SeriesSlice{s, global})
+	s, global = copies[0], copies[1]
+	s.Hidden = hide
+	if included || s.AddToGlobal() {
+		sign := 1
+		if hide {
+			sign = -1
+		}
+		global.addValues(s, sign)
+	}
+	return nil
+}
+
+// applyHidden marks the series in slice which are hidden, this must be called before global totals are calculated
+func (slice SeriesSlice) applyHidden() {
+	for _, s := range slice {
+		s.Hidden = hidden[s.Slug()]
+	}
+}
+
+// visible returns the series in slice which are not hidden
+func (slice SeriesSlice) visible() SeriesSlice {
+	list := make(SeriesSlice, 0, len(slice))
+	for _, s := range slice {
+		if !s.Hidden {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// addValues adds the cumulative deaths and confirmed values of other to s, multiplied by sign
+// for days after the end of other its last values are used, as global has a day for today merged from final values
+func (s *Series) addValues(other *Series, sign int) {
+	n := len(other.Deaths)
+	if len(other.Confirmed) < n {
+		n = len(other.Confirmed)
+	}
+	if n == 0 {
+		return
+	}
+	offset := daysBetween(s.StartsAt, other.StartsAt)
+	for day := range s.Deaths {
+		i := day - offset
+		if i < 0 || day >= len(s.Confirmed) {
+			continue
+		}
+		if i >= n {
+			i = n - 1
+		}
+		s.Deaths[day] += sign * other.Deaths[i]
+		s.Confirmed[day] += sign * other.Confirmed[i]
+	}
+	s.DeathsDaily = dailyInts(s.DeathsDaily, s.Deaths)
+	s.ConfirmedDaily = dailyInts(s.ConfirmedDaily, s.Confirmed)
+}
@@ -0,0 +1,54 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetHidden(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	italy := &Series{Country: "Italy", StartsAt: start, Deaths: []int{1, 2}, Confirmed: []int{10, 20}}
+	junk := &Series{Country: "Others", StartsAt: start.AddDate(0, 0, 1), Deaths: []int{5}, Confirmed: []int{50}}
+	global := &Series{StartsAt: start, Deaths: []int{1, 7}, Confirmed: []int{10, 70}}
+
+	mutex.Lock()
+	previous := data
+	data = SeriesSlice{italy, junk, global}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data = previous
+		hidden = make(map[string]bool)
+		mutex.Unlock()
+	}()
+
+	err := SetHidden("others", true)
+	if err != nil {
+		t.Fatalf("test: hide failed:%s", err)
+	}
+	if junk.Hidden || global.TotalDeaths() != 7 {
+		t.Fatalf("test: hide changed stored series in place")
+	}
+	s, _ := FetchSeries("", "")
+	if s.TotalDeaths() != 2 || s.TotalConfirmed() != 20 || s.DeathsDaily[1] != 1 {
+		t.Fatalf("test: global not updated on hide got:%d %d", s.TotalDeaths(), s.TotalConfirmed())
+	}
+	if len(data.Countries()) != 1 || len(data.CountryOptions()) != 2 {
+		t.Fatalf("test: hidden series listed got:%d %d", len(data.Countries()), len(data.CountryOptions()))
+	}
+	if list := Hidden(); len(list) != 1 || list[0] != "others" {
+		t.Fatalf("test: hidden list wrong got:%v", list)
+	}
+
+	err = SetHidden("others", false)
+	if err != nil {
+		t.Fatalf("test: restore failed:%s", err)
+	}
+	s, _ = FetchSeries("", "")
+	if s.TotalDeaths() != 7 || s.TotalConfirmed() != 70 {
+		t.Fatalf("test: global not updated on restore got:%d %d", s.TotalDeaths(), s.TotalConfirmed())
+	}
+	if len(data.Countries()) != 2 {
+		t.Fatalf("test: restored series not listed")
+	}
+}
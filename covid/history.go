@@ -0,0 +1,84 @@
+package covid
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// FetchCountrySeries returns the series for country followed by its provinces, leaving out hidden provinces
+func FetchCountrySeries(country string) (SeriesSlice, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	s, err := data.FetchSeries(country, "")
+	if err != nil || s.Global() {
+		return nil, fmt.Errorf("series: no country:%s", country)
+	}
+	list := SeriesSlice{s}
+	for _, p := range data {
+		if p.Country == s.Country && p.Province != "" && !p.Hidden {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+// WriteHistoryCSV writes the full history of each series in slice to w as csv in long format, one row per series per day
+// with cumulative and daily values, tests, whether the day is provisional and any auxiliary series
+// rows are written as they are built, so that large downloads are streamed
+func (slice SeriesSlice) WriteHistoryCSV(w io.Writer) error {
+	auxiliary := make(map[string]bool)
+	for _, s := range slice {
+		for name := range s.Auxiliary {
+			auxiliary[name] = true
+		}
+	}
+	var names []string
+	for name := range auxiliary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := csv.NewWriter(w)
+	header := []string{"country", "province", "date", "confirmed", "deaths", "confirmed_daily", "deaths_daily", "tests", "provisional"}
+	c.Write(append(header, names...))
+	for _, s := range slice {
+		start := s.StartDate()
+		for i := range s.Deaths {
+			if i >= len(s.Confirmed) {
+				break
+			}
+			row := []string{s.Country, s.Province, start.AddDays(i).String(), strconv.Itoa(s.Confirmed[i]), strconv.Itoa(s.Deaths[i]),
+				intCell(s.ConfirmedDaily, i), intCell(s.DeathsDaily, i), intCell(s.Tests, i), boolCell(s.IsProvisional(i))}
+			for _, name := range names {
+				values := s.Auxiliary[name]
+				if i < len(values) {
+					row = append(row, strconv.FormatFloat(values[i], 'f', -1, 64))
+				} else {
+					row = append(row, "")
+				}
+			}
+			c.Write(row)
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// intCell returns values[i] formatted for a csv cell, or a blank cell if values has no value for i
+func intCell(values []int, i int) string {
+	if i >= len(values) {
+		return ""
+	}
+	return strconv.Itoa(values[i])
+}
+
+// boolCell returns 1 for true and a blank cell for false
+func boolCell(b bool) string {
+	if b {
+		return "1"
+	}
+	return ""
+}
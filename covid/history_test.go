@@ -0,0 +1,42 @@
+package covid
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+)
+
+func TestWriteHistoryCSV(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	uk := &Series{Country: "United Kingdom", StartsAt: start, Deaths: []int{1, 3}, Confirmed: []int{10, 30}, Tests: []int{100, 200}}
+	uk.SetAuxiliaryValues(AuxiliaryStringency, []float64{12.5})
+	uk.Provisional = &Provisional{Date: start.AddDate(0, 0, 1), Since: start.AddDate(0, 0, 1), Deaths: 3, Confirmed: 30}
+	bermuda := &Series{Country: "United Kingdom", Province: "Bermuda", StartsAt: start, Deaths: []int{0, 1}, Confirmed: []int{2, 4}}
+	slice := SeriesSlice{uk, bermuda}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	var b bytes.Buffer
+	err := slice.WriteHistoryCSV(&b)
+	if err != nil {
+		t.Fatalf("test: write history error:%s", err)
+	}
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("test: read history error:%s", err)
+	}
+	if len(records) != 5 || len(records[0]) != 10 || records[0][9] != AuxiliaryStringency {
+		t.Fatalf("test: write history wanted 5 rows of 10 got:%v", records)
+	}
+	want := []string{"United Kingdom", "", "2020-03-02", "30", "3", "20", "2", "200", "1", ""}
+	for i, v := range want {
+		if records[2][i] != v {
+			t.Fatalf("test: write history wanted:%v got:%v", want, records[2])
+		}
+	}
+	if records[1][9] != "12.5" || records[4][1] != "Bermuda" || records[4][7] != "" {
+		t.Fatalf("test: write history wrong auxiliary or province rows got:%v", records)
+	}
+}
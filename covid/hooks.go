@@ -0,0 +1,35 @@
+package covid
+
+import (
+	"log"
+	"sync"
+)
+
+// hooksMutex protects access to loadHooks
+var hooksMutex sync.Mutex
+
+// loadHooks are called after each scheduled fetch and load of data succeeds
+var loadHooks []func() error
+
+// OnLoad registers f to be called after each scheduled load of data
+// this is used to publish data to other services, errors are logged
+func OnLoad(f func() error) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	loadHooks = append(loadHooks, f)
+}
+
+// runLoadHooks calls each of our load hooks in turn, must be called without mutex held
+func runLoadHooks() {
+	hooksMutex.Lock()
+	hooks := make([]func() error, len(loadHooks))
+	copy(hooks, loadHooks)
+	hooksMutex.Unlock()
+
+	for _, f := range hooks {
+		err := f()
+		if err != nil {
+			log.Printf("publish: error after load:%s", err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package covid
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Names of auxiliary series for hospital data, which are counts of patients in hospital each day
+const (
+	AuxiliaryHospitalOccupancy = "hospital_occupancy"
+	AuxiliaryICUOccupancy      = "icu_occupancy"
+)
+
+// hospitalIndicators maps indicators used by OWID and ECDC to auxiliary series
+var hospitalIndicators = map[string]string{
+	"Daily hospital occupancy": AuxiliaryHospitalOccupancy,
+	"Daily ICU occupancy":      AuxiliaryICUOccupancy,
+}
+
+// HospitalOccupancy returns the number of patients in hospital by day, or nil if we have no data
+func (s *Series) HospitalOccupancy() []float64 {
+	return s.AuxiliaryValues(AuxiliaryHospitalOccupancy)
+}
+
+// ICUOccupancy returns the number of patients in intensive care by day, or nil if we have no data
+func (s *Series) ICUOccupancy() []float64 {
+	return s.AuxiliaryValues(AuxiliaryICUOccupancy)
+}
+
+// CapacityPercent returns occupancy as a percentage of capacity (e.g. the number of ICU beds) for each day
+func CapacityPercent(occupancy []float64, capacity int) []float64 {
+	if occupancy == nil || capacity <= 0 {
+		return nil
+	}
+	percent := make([]float64, len(occupancy))
+	for i, v := range occupancy {
+		percent[i] = v / float64(capacity) * 100
+	}
+	return percent
+}
+
+// LatestCapacityPercent returns the most recent occupancy as a percentage of capacity, or 0 if unknown
+func LatestCapacityPercent(occupancy []float64, capacity int) float64 {
+	percent := CapacityPercent(occupancy, capacity)
+	if len(percent) == 0 {
+		return 0
+	}
+	return percent[len(percent)-1]
+}
+
+// mergeHospitalCSV merges hospital occupancy from the OWID or ECDC hospital datasets into country series
+// OWID has columns entity,iso_code,date,indicator,value and ECDC has country,indicator,date,...,value
+// ECDC files are loaded after OWID, so take precedence for Europe
+func (slice SeriesSlice) mergeHospitalCSV(records [][]string) (SeriesSlice, error) {
+	if len(records) == 0 {
+		return slice, fmt.Errorf("load: empty hospital csv")
+	}
+
+	columns := csvColumns(records[0])
+	indicator, okIndicator := columns["indicator"]
+	date, okDate := columns["date"]
+	value, okValue := columns["value"]
+	code, okCode := columns["iso_code"]
+	country, okCountry := columns["country"]
+	if !okIndicator || !okDate || !okValue || (!okCode && !okCountry) {
+		return slice, fmt.Errorf("load: invalid hospital csv header")
+	}
+
+	series := make(map[string]*Series)
+	for i, row := range records[1:] {
+		if len(row) != len(records[0]) {
+			return slice, fmt.Errorf("load: invalid hospital csv row:%d", i+1)
+		}
+		name, ok := hospitalIndicators[row[indicator]]
+		if !ok || row[value] == "" {
+			continue
+		}
+
+		// OWID identifies countries by code, ECDC by name
+		key := ""
+		if okCode {
+			key = row[code]
+		} else {
+			key = row[country]
+		}
+		s, ok := series[key]
+		if !ok {
+			if okCode {
+				s = slice.seriesForISO(key)
+			} else {
+				s, _ = slice.FetchSeries(key, "")
+			}
+			series[key] = s
+		}
+		if s == nil {
+			continue
+		}
+
+		d, err := time.Parse("2006-01-02", row[date])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid hospital date row:%d:%s", i+1, err)
+		}
+		v, err := strconv.ParseFloat(row[value], 64)
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid hospital value row:%d:%s", i+1, err)
+		}
+		s.setAuxiliary(name, d, v)
+	}
+
+	return slice, nil
+}
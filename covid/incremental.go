@@ -0,0 +1,265 @@
+package covid
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// setDay sets the cumulative confirmed and deaths of s for day, and applies the change to global (which may be nil)
+// so that the global series is maintained by the delta of each row rather than summed from every series again
+// countries summed from their provinces are updated by the caller, and regional series are built on request so need no update
+// returns true if the values of s changed, day must be within s
+func (s *Series) setDay(global *Series, day, confirmed, deaths int) bool {
+	dc, dd := confirmed-s.Confirmed[day], deaths-s.Deaths[day]
+	if dc == 0 && dd == 0 {
+		return false
+	}
+	s.applyDelta(day, dc, dd)
+	if global != nil && global != s && s.AddToGlobal() && day < len(global.Deaths) && day < len(global.Confirmed) {
+		global.applyDelta(day, dc, dd)
+	}
+	return true
+}
+
+// applyDelta adds the changes in confirmed and deaths to the cumulative values of s for day
+// and to the daily values for day and the day after, which are the only daily values affected
+func (s *Series) applyDelta(day, confirmed, deaths int) {
+	s.Confirmed[day] += confirmed
+	s.Deaths[day] += deaths
+	if day < len(s.ConfirmedDaily) {
+		s.ConfirmedDaily[day] += confirmed
+	}
+	if day+1 < len(s.ConfirmedDaily) {
+		s.ConfirmedDaily[day+1] -= confirmed
+	}
+	if day < len(s.DeathsDaily) {
+		s.DeathsDaily[day] += deaths
+	}
+	if day+1 < len(s.DeathsDaily) {
+		s.DeathsDaily[day+1] -= deaths
+	}
+}
+
+// dailyRow is one row read from a daily country or state csv
+type dailyRow struct {
+	series *Series
+	day    int
+	// parent is the country summed from this province, if any, with the index of the day in parent
+	parent    *Series
+	parentDay int
+	updated   time.Time
+	confirmed int
+	deaths    int
+}
+
+// mergeDailyIncremental merges rows from a daily country or state csv into slice for day
+// applying the change in each row to the global series, and to the country for provinces of countries summed from them,
+// and returns the series changed
+// series which change are replaced in slice by copies, so series held by callers are not changed
+// if a row is for a series or day we don't have, an error is returned without changing slice
+func (slice SeriesSlice) mergeDailyIncremental(records [][]string, dataType int, day int) (SeriesSlice, error) {
+	global, err := slice.FetchSeries("", "")
+	if err != nil {
+		global = nil
+	}
+
+	// Make an assumption about the starting date, from which day is counted, series which start later are indexed from their start
+	startDate := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+
+	// Read all rows first, so that we change nothing unless every row can be merged in place
+	var rows []dailyRow
+	for i, row := range records {
+		var country, province string
+		var r dailyRow
+		var err error
+		if dataType == DataTodayState {
+			if i == 0 {
+				if len(row) < 7 || row[0] != "FIPS" || row[1] != "Province_State" || row[2] != "Country_Region" || row[6] != "Confirmed" {
+					return nil, fmt.Errorf("load: error loading file - daily state csv data format invalid")
+				}
+				continue
+			}
+			country, province = row[2], row[1]
+			if skipDailyProvince(province) {
+				continue
+			}
+			r.updated, r.confirmed, r.deaths, err = readStateRow(row)
+		} else {
+			if i == 0 {
+				if len(row) < 5 || row[0] != "Country_Region" || row[1] != "Last_Update" || row[2] != "Lat" || row[4] != "Confirmed" {
+					return nil, fmt.Errorf("load: error loading file - daily country csv data format invalid")
+				}
+				continue
+			}
+			country = row[0]
+			r.updated, r.confirmed, r.deaths, err = readCountryRow(row)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if name, ok := countryAliases[country]; ok {
+			country = name
+		}
+		r.series, err = slice.FetchSeries(country, province)
+		if err != nil {
+			return nil, fmt.Errorf("load: no series for daily row:%s %s", country, province)
+		}
+		r.day = day - daysBetween(startDate, r.series.StartsAt)
+		if r.day < 0 || r.day >= len(r.series.Deaths) || r.day >= len(r.series.Confirmed) {
+			return nil, fmt.Errorf("load: no day %d for daily row:%s %s", day, country, province)
+		}
+		// Countries summed from their provinces are updated by the change in each province, so their own rows are ignored
+		if r.series.summedFromProvinces() {
+			continue
+		}
+		// Official values from the time series are never replaced by daily data, and earlier provisional days are left as they are
+		if !r.series.IsProvisional(r.day) || r.series.provisionalDay() != r.day {
+			continue
+		}
+		if parent, err := slice.FetchSeries(country, ""); province != "" && err == nil && parent.summedFromProvinces() {
+			r.parentDay = day - daysBetween(startDate, parent.StartsAt)
+			if r.parentDay >= 0 && r.parentDay < len(parent.Deaths) && r.parentDay < len(parent.Confirmed) && parent.provisionalDay() == r.parentDay {
+				r.parent = parent
+			}
+		}
+		rows = append(rows, r)
+	}
+
+	// Replace the series which change with copies, as callers may be reading the stored series
+	var touched SeriesSlice
+	parents := make(map[*Series]bool)
+	for _, r := range rows {
+		if r.updated.After(r.series.UpdatedAt) || r.series.Confirmed[r.day] != r.confirmed || r.series.Deaths[r.day] != r.deaths {
+			touched = append(touched, r.series)
+			if r.parent != nil && !parents[r.parent] {
+				parents[r.parent] = true
+				touched = append(touched, r.parent)
+			}
+		}
+	}
+	if len(touched) == 0 {
+		return nil, nil
+	}
+	if global != nil {
+		touched = append(touched, global)
+	}
+	copies := slice.copyOnWrite(touched)
+	replaced := make(map[*Series]*Series, len(touched))
+	for i, s := range touched {
+		replaced[s] = copies[i]
+	}
+	if global != nil {
+		global = replaced[global]
+	}
+
+	var changed SeriesSlice
+	changedParents := make(map[*Series]bool)
+	for _, r := range rows {
+		s, ok := replaced[r.series]
+		if !ok {
+			continue
+		}
+		if r.updated.After(s.UpdatedAt) {
+			s.UpdatedAt = r.updated
+		}
+		dc, dd := r.confirmed-s.Confirmed[r.day], r.deaths-s.Deaths[r.day]
+		if s.setDay(global, r.day, r.confirmed, r.deaths) {
+			changed = append(changed, s)
+			if parent := replaced[r.parent]; parent != nil {
+				parent.applyDelta(r.parentDay, dc, dd)
+				if r.updated.After(parent.UpdatedAt) {
+					parent.UpdatedAt = r.updated
+				}
+				parent.setProvisional(parent.Confirmed[r.parentDay], parent.Deaths[r.parentDay], parent.UpdatedAt)
+				changedParents[parent] = true
+			}
+		}
+		s.setProvisional(r.confirmed, r.deaths, s.UpdatedAt)
+		s.setDailyUpdate(sourceNames[dataType], r.updated)
+	}
+	for _, s := range copies {
+		if changedParents[s] {
+			changed = append(changed, s)
+		}
+	}
+	if len(changed) > 0 && global != nil {
+		global.UpdatedAt = time.Now().UTC()
+		changed = append(changed, global)
+	}
+	return changed, nil
+}
+
+// LoadDailyData merges the daily country and state files in our data path into our stored data in place,
+// so that hourly refreshes only touch the rows which changed rather than loading all data again
+// if the files can't be merged in place, e.g. because a new day or series has started, an error is returned
+// and the caller should call LoadData instead
+func LoadDailyData() error {
+	files := []struct {
+		name     string
+		dataType int
+	}{
+		{"cases_country.csv", DataTodayCountry},
+		{"cases_state.csv", DataTodayState},
+	}
+	records := make([][][]string, len(files))
+	for i, f := range files {
+		r, err := os.Open(filepath.Join(dataPath, f.name))
+		if err != nil {
+			return err
+		}
+		records[i], err = csv.NewReader(r).ReadAll()
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("load: error reading %s:%s", f.name, err)
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// Stale series from a failed load are only cleared by a full load
+	if len(data) == 0 || report.failed("cases_") {
+		return fmt.Errorf("load: daily data requires a full load")
+	}
+
+	startDate := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	day := daysBetween(startDate, Today())
+	changed := make(map[*Series]bool)
+	for i, f := range files {
+		list, err := data.mergeDailyIncremental(records[i], f.dataType, day)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			changed[s] = true
+		}
+		recordSource(filepath.Join(dataPath, f.name))
+	}
+
+	// Imported corrections take precedence over values from the source files
+	data.applyImports(true)
+
+	// Record the changes, and sort again as totals may have changed
+	date := startDate.AddDate(0, 0, day).Format("2006-01-02")
+	now := time.Now().UTC()
+	var updated []Change
+	for s := range changed {
+		updated = append(updated, Change{Country: s.Country, Province: s.Province, Dates: []string{date}, At: now})
+	}
+	sort.Slice(updated, func(i, j int) bool {
+		return updated[i].Country < updated[j].Country || (updated[i].Country == updated[j].Country && updated[i].Province < updated[j].Province)
+	})
+	addChanges(updated)
+	if len(updated) > 0 {
+		sort.Stable(data)
+	}
+
+	log.Printf("load: merged daily data series changed:%d", len(updated))
+	return nil
+}
@@ -0,0 +1,123 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeDailyIncremental(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	italy := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3}, Confirmed: []int{1, 5, 10}}
+	spain := &Series{Country: "Spain", StartsAt: start, Deaths: []int{0, 2, 4}, Confirmed: []int{2, 6, 12}}
+	slice := addGlobal(SeriesSlice{italy, spain})
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+	global := slice[2]
+	// Today is provisional, as it would be after merging daily data
+	italy.Provisional = &Provisional{Date: start.AddDate(0, 0, 2), Since: start.AddDate(0, 0, 2), Deaths: 3, Confirmed: 10}
+	spain.Provisional = &Provisional{Date: start.AddDate(0, 0, 2), Since: start.AddDate(0, 0, 2), Deaths: 4, Confirmed: 12}
+
+	records := [][]string{
+		{"Country_Region", "Last_Update", "Lat", "Long_", "Confirmed", "Deaths"},
+		{"Italy", "2020-01-24 10:00:00", "0", "0", "15", "5"},
+		{"Spain", "2020-01-24 10:00:00", "0", "0", "12", "4"},
+	}
+	changed, err := slice.mergeDailyIncremental(records, DataTodayCountry, 2)
+	if err != nil {
+		t.Fatalf("test: merge incremental error:%s", err)
+	}
+	if len(changed) != 2 || changed[0].Country != "Italy" || !changed[1].Global() {
+		t.Fatalf("test: merge incremental wanted italy and global changed got:%d", len(changed))
+	}
+	if changed[1].Confirmed[2] != 27 || changed[1].Deaths[2] != 9 || changed[1].ConfirmedDaily[2] != 16 || changed[0].DeathsDaily[2] != 4 {
+		t.Fatalf("test: merge incremental wanted global:27 9 got:%v %v", changed[1].Confirmed, changed[1].Deaths)
+	}
+
+	// The series changed are replaced with copies, so the series we held are unchanged
+	if slice[0] != changed[0] || italy.Confirmed[2] != 10 || global.Confirmed[2] != 22 {
+		t.Fatalf("test: merge incremental wanted copies got:%v %v", italy.Confirmed, global.Confirmed)
+	}
+	italy = slice[0]
+
+	// Rows for unknown series or days are not merged, and nothing is changed
+	records[1][4] = "20"
+	records = append(records, []string{"Atlantis", "2020-01-24 10:00:00", "0", "0", "1", "0"})
+	_, err = slice.mergeDailyIncremental(records, DataTodayCountry, 2)
+	if err == nil || italy.Confirmed[2] != 15 {
+		t.Fatalf("test: merge incremental wanted error for new series")
+	}
+	_, err = slice.mergeDailyIncremental(records[:2], DataTodayCountry, 3)
+	if err == nil {
+		t.Fatalf("test: merge incremental wanted error for new day")
+	}
+
+	// Official days from the time series are not changed
+	changed, err = slice.mergeDailyIncremental(records[:2], DataTodayCountry, 1)
+	if err != nil || len(changed) != 0 || italy.Confirmed[1] != 5 {
+		t.Fatalf("test: merge incremental changed official day got:%v", italy.Confirmed)
+	}
+	if italy.Provisional == nil || italy.Provisional.Confirmed != 15 {
+		t.Fatalf("test: merge incremental wanted provisional:15 got:%v", italy.Provisional)
+	}
+}
+
+func TestMergeDailyIncrementalProvinces(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	nsw := &Series{Country: "Australia", Province: "New South Wales", StartsAt: start, Deaths: []int{0, 1, 2}, Confirmed: []int{1, 5, 10}}
+	vic := &Series{Country: "Australia", Province: "Victoria", StartsAt: start, Deaths: []int{0, 0, 1}, Confirmed: []int{0, 2, 4}}
+	australia := &Series{Country: "Australia", StartsAt: start}
+	australia.sum(SeriesSlice{nsw, vic})
+	slice := addGlobal(SeriesSlice{nsw, vic, australia})
+	for _, s := range slice {
+		s.UpdateDaily()
+		s.Provisional = &Provisional{Date: start.AddDate(0, 0, 2), Since: start.AddDate(0, 0, 2), Deaths: s.Deaths[2], Confirmed: s.Confirmed[2]}
+	}
+
+	// The change in a province is applied to the country summed from it, and to global once
+	records := [][]string{
+		{"FIPS", "Province_State", "Country_Region", "Last_Update", "Lat", "Long_", "Confirmed", "Deaths"},
+		{"", "New South Wales", "Australia", "2020-01-24 10:00:00", "0", "0", "16", "3"},
+	}
+	changed, err := slice.mergeDailyIncremental(records, DataTodayState, 2)
+	if err != nil {
+		t.Fatalf("test: merge incremental provinces error:%s", err)
+	}
+	if len(changed) != 3 {
+		t.Fatalf("test: merge incremental provinces wanted 3 changed got:%d", len(changed))
+	}
+	australia, _ = slice.FetchSeries("Australia", "")
+	global, _ := slice.FetchSeries("", "")
+	if australia.Confirmed[2] != 20 || australia.Deaths[2] != 4 || australia.ConfirmedDaily[2] != 13 || australia.Provisional.Confirmed != 20 {
+		t.Fatalf("test: merge incremental provinces wanted australia:20 4 got:%v %v", australia.Confirmed, australia.Deaths)
+	}
+	if global.Confirmed[2] != 20 || global.Deaths[2] != 4 {
+		t.Fatalf("test: merge incremental provinces wanted global:20 4 got:%v %v", global.Confirmed, global.Deaths)
+	}
+
+	// Rows for the country itself are ignored, as it is maintained from its provinces
+	records = [][]string{
+		{"Country_Region", "Last_Update", "Lat", "Long_", "Confirmed", "Deaths"},
+		{"Australia", "2020-01-24 11:00:00", "0", "0", "50", "9"},
+	}
+	changed, err = slice.mergeDailyIncremental(records, DataTodayCountry, 2)
+	australia, _ = slice.FetchSeries("Australia", "")
+	if err != nil || len(changed) != 0 || australia.Confirmed[2] != 20 {
+		t.Fatalf("test: merge incremental wanted country row ignored got:%v error:%v", australia.Confirmed, err)
+	}
+}
+
+func TestApplyObservationsGlobal(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	italy := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3}, Confirmed: []int{1, 5, 10}}
+	slice := addGlobal(SeriesSlice{italy})
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	result, dates := slice.applyObservations([]Observation{{Country: "Italy", Date: "2020-01-23", Metric: MetricDeaths, Value: 2}}, false)
+	global := slice[1]
+	if result.Applied != 1 || global.Deaths[1] != 2 || global.DeathsDaily[2] != 1 || len(dates[global]) != 1 {
+		t.Fatalf("test: apply observations wanted global deaths:2 got:%v", global.Deaths)
+	}
+}
@@ -0,0 +1,86 @@
+package covid
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// influxMeasurement is the measurement name used for points
+const influxMeasurement = "covid"
+
+// influxEscaper escapes tag keys and values in line protocol
+var influxEscaper = strings.NewReplacer(",", "\\,", "=", "\\=", " ", "\\ ")
+
+// WriteLineProtocol writes one point per series per day to w in InfluxDB line protocol
+// e.g. covid,country=United\ Kingdom confirmed=5741i,deaths=282i 1584835200000000000
+func (slice SeriesSlice) WriteLineProtocol(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	for _, r := range slice.Rows() {
+		tags := "country=" + influxEscaper.Replace(r.Country)
+		if r.Province != "" {
+			tags += ",province=" + influxEscaper.Replace(r.Province)
+		}
+		_, err := fmt.Fprintf(b, "%s,%s confirmed=%di,deaths=%di %d\n", influxMeasurement, tags, r.Confirmed, r.Deaths, r.Date.UnixNano())
+		if err != nil {
+			return err
+		}
+	}
+	return b.Flush()
+}
+
+// WriteLineProtocol uses our stored data to write InfluxDB line protocol
+func WriteLineProtocol(w io.Writer) error {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.WriteLineProtocol(w)
+}
+
+// ExportLineProtocol writes our stored data in InfluxDB line protocol to the file at path
+func ExportLineProtocol(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("influx: error opening file:%s", err)
+	}
+	defer f.Close()
+	return WriteLineProtocol(f)
+}
+
+// PushInflux posts our stored data in line protocol to an InfluxDB write endpoint
+// url should be a full write url e.g. http://localhost:8086/api/v2/write?org=o&bucket=covid
+// or http://localhost:8086/write?db=covid, token is optional
+func PushInflux(url, token string) error {
+	body := &bytes.Buffer{}
+	err := WriteLineProtocol(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return fmt.Errorf("influx: error creating request:%s", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("influx: error posting data:%s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx: error posting data status:%d %s", resp.StatusCode, msg)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package covid
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteLineProtocol(t *testing.T) {
+	start := time.Date(2020, 3, 22, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Korea, South", StartsAt: start, Deaths: []int{104, 111}, Confirmed: []int{8897, 8961}},
+		{Country: "United Kingdom", Province: "Isle of Man", StartsAt: start, Deaths: []int{0}, Confirmed: []int{3}},
+		{Country: "a=b", StartsAt: start, Deaths: []int{1}, Confirmed: []int{2}},
+	}
+	b := &bytes.Buffer{}
+	err := slice.WriteLineProtocol(b)
+	if err != nil {
+		t.Fatalf("test: line protocol error:%s", err)
+	}
+	want := `covid,country=Korea\,\ South confirmed=8897i,deaths=104i 1584835200000000000
+covid,country=Korea\,\ South confirmed=8961i,deaths=111i 1584921600000000000
+covid,country=United\ Kingdom,province=Isle\ of\ Man confirmed=3i,deaths=0i 1584835200000000000
+covid,country=a\=b confirmed=2i,deaths=1i 1584835200000000000
+`
+	if b.String() != want {
+		t.Fatalf("test: line protocol wanted:\n%s got:\n%s", want, b.String())
+	}
+}
+
+func TestPushInflux(t *testing.T) {
+	var body, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body, auth = string(b), r.Header.Get("Authorization")
+		if r.URL.Query().Get("bucket") != "covid" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mutex.Lock()
+	previous := data
+	data = SeriesSlice{{Country: "Italy", StartsAt: time.Date(2020, 3, 22, 0, 0, 0, 0, time.UTC), Deaths: []int{5}, Confirmed: []int{50}}}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data = previous
+		mutex.Unlock()
+	}()
+
+	err := PushInflux(server.URL+"/api/v2/write?bucket=covid", "secret")
+	if err != nil || auth != "Token secret" || body != "covid,country=Italy confirmed=50i,deaths=5i 1584835200000000000\n" {
+		t.Fatalf("test: push influx wrong request auth:%q body:%q error:%v", auth, body, err)
+	}
+	if err := PushInflux(server.URL+"/api/v2/write?bucket=nope", ""); err == nil || auth != "" {
+		t.Fatalf("test: push influx wanted error for status:404 auth:%q", auth)
+	}
+}
@@ -0,0 +1,222 @@
+package covid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Observation is one value in our json import format, used to push corrections or local data
+// e.g. {"country":"Thailand","province":"","date":"2020-04-01","metric":"confirmed","value":1771}
+// metric is deaths or confirmed (cumulative), tests (cumulative) or the name of an auxiliary series
+type Observation struct {
+	Country  string  `json:"country"`
+	Province string  `json:"province"`
+	Date     string  `json:"date"`
+	Metric   string  `json:"metric"`
+	Value    float64 `json:"value"`
+}
+
+// ImportResult reports the observations applied to our data by an import
+type ImportResult struct {
+	Applied int `json:"applied"`
+	// Observations for unknown series or dates outside the series
+	Skipped int `json:"skipped"`
+}
+
+// imports are the observations imported since start, reapplied after each load, protected by mutex
+var imports []Observation
+
+// datasetImports are the observations applied from datasets by name, replaced each time the dataset loads
+var datasetImports = make(map[string][]Observation)
+
+// ReadObservations reads a json array of observations from r, checking dates and metrics
+func ReadObservations(r io.Reader) ([]Observation, error) {
+	var list []Observation
+	err := json.NewDecoder(r).Decode(&list)
+	if err != nil {
+		return nil, fmt.Errorf("import: invalid json:%s", err)
+	}
+	for i, o := range list {
+		if o.Metric == "" {
+			return nil, fmt.Errorf("import: missing metric for observation:%d", i)
+		}
+		_, err := time.Parse("2006-01-02", o.Date)
+		if err != nil {
+			return nil, fmt.Errorf("import: invalid date for observation:%d:%s", i, err)
+		}
+	}
+	return list, nil
+}
+
+// Import applies observations to our stored data, they are kept and applied again after each load
+func Import(list []Observation) ImportResult {
+	mutex.Lock()
+	defer mutex.Unlock()
+	imports = append(imports, list...)
+	return applyImport(list)
+}
+
+// importDataset applies observations from a dataset to our stored data, replacing any from its previous load
+func importDataset(name string, list []Observation) ImportResult {
+	mutex.Lock()
+	defer mutex.Unlock()
+	datasetImports[name] = list
+	return applyImport(list)
+}
+
+// applyImport applies observations to our stored data and records the changes, the caller must hold the lock
+func applyImport(list []Observation) ImportResult {
+	result, dates := data.applyObservations(list, true)
+
+	// Record the changes so that clients fetching changes pick up the imported values
+	now := time.Now().UTC()
+	var updated []Change
+	for s, list := range dates {
+		updated = append(updated, Change{Country: s.Country, Province: s.Province, Dates: list, At: now})
+	}
+	addChanges(updated)
+	return result
+}
+
+// ImportFile reads observations from a json file at path and imports them
+func ImportFile(path string) (ImportResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer f.Close()
+	list, err := ReadObservations(f)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return Import(list), nil
+}
+
+// applyImports applies all observations imported so far to slice, the caller must hold the lock
+// observations from datasets are applied first, so that imported corrections take precedence
+// if shared is true slice is our stored data, and series are replaced by copies before they change
+func (slice SeriesSlice) applyImports(shared bool) {
+	var names []string
+	for name := range datasetImports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		slice.applyObservations(datasetImports[name], shared)
+	}
+	if len(imports) > 0 {
+		slice.applyObservations(imports, shared)
+	}
+}
+
+// observations returns the deaths and confirmed values of each series in slice as observations
+// the global series is not included
+func (slice SeriesSlice) observations() (list []Observation) {
+	for _, r := range slice.Rows() {
+		date := r.Date.Format("2006-01-02")
+		list = append(list,
+			Observation{Country: r.Country, Province: r.Province, Date: date, Metric: MetricDeaths, Value: float64(r.Deaths)},
+			Observation{Country: r.Country, Province: r.Province, Date: date, Metric: MetricConfirmed, Value: float64(r.Confirmed)},
+		)
+	}
+	return list
+}
+
+// applyObservations sets the values in list on the matching series in slice, and returns the dates changed by series
+// observations for unknown series or dates outside a series are skipped, the global series is kept up to date
+// if shared is true slice is our stored data which callers may be reading, so series are replaced by copies before they change
+func (slice SeriesSlice) applyObservations(list []Observation, shared bool) (result ImportResult, dates map[*Series][]string) {
+	dates = make(map[*Series][]string)
+	global, err := slice.FetchSeries("", "")
+	if err != nil {
+		global = nil
+	}
+
+	// Find the series and day for each observation before changing anything
+	targets := make(SeriesSlice, len(list))
+	days := make([]int, len(list))
+	var touched SeriesSlice
+	for i, o := range list {
+		s := slice.seriesForName(o.Country)
+		if o.Province != "" {
+			s, _ = slice.FetchSeries(o.Country, o.Province)
+		}
+		date, err := time.Parse("2006-01-02", o.Date)
+		if s == nil || err != nil {
+			continue
+		}
+		day := daysBetween(s.StartsAt, date)
+		if day < 0 || day >= len(s.Deaths) || day >= len(s.Confirmed) {
+			continue
+		}
+		targets[i], days[i] = s, day
+		touched = append(touched, s)
+	}
+	if shared && len(touched) > 0 {
+		if global != nil {
+			touched = append(touched, global)
+		}
+		copies := slice.copyOnWrite(touched)
+		replaced := make(map[*Series]*Series, len(touched))
+		for i, s := range touched {
+			replaced[s] = copies[i]
+		}
+		for i, s := range targets {
+			if s != nil {
+				targets[i] = replaced[s]
+			}
+		}
+		if global != nil {
+			global = replaced[global]
+		}
+	}
+
+	for i, o := range list {
+		s, day := targets[i], days[i]
+		if s == nil {
+			result.Skipped++
+			continue
+		}
+
+		// Changes to deaths and confirmed are applied to the global series too
+		switch o.Metric {
+		case MetricDeaths, MetricConfirmed:
+			confirmed, deaths := s.Confirmed[day], s.Deaths[day]
+			if o.Metric == MetricDeaths {
+				deaths = int(o.Value)
+			} else {
+				confirmed = int(o.Value)
+			}
+			if s.setDay(global, day, confirmed, deaths) && global != nil && global != s && s.AddToGlobal() && day < len(global.Deaths) {
+				if !containsString(dates[global], o.Date) {
+					dates[global] = append(dates[global], o.Date)
+				}
+			}
+		case "tests":
+			if s.Tests == nil {
+				s.Tests = make([]int, len(s.Confirmed))
+			}
+			s.Tests[day] = int(o.Value)
+		default:
+			values := s.AuxiliaryValues(o.Metric)
+			if values == nil {
+				values = make([]float64, len(s.Deaths))
+				s.SetAuxiliaryValues(o.Metric, values)
+			}
+			values[day] = o.Value
+		}
+		result.Applied++
+		if !containsString(dates[s], o.Date) {
+			dates[s] = append(dates[s], o.Date)
+		}
+	}
+
+	for s := range dates {
+		s.UpdateDaily()
+	}
+	return result, dates
+}
@@ -0,0 +1,68 @@
+package covid
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImport(t *testing.T) {
+	list, err := ReadObservations(strings.NewReader(`[
+		{"country":"Thailand","date":"2020-03-02","metric":"confirmed","value":50},
+		{"country":"Thailand","date":"2020-03-02","metric":"stringency","value":40.5},
+		{"country":"Thailand","date":"2020-04-01","metric":"deaths","value":1},
+		{"country":"Nowhere","date":"2020-03-01","metric":"deaths","value":1}
+	]`))
+	if err != nil {
+		t.Fatalf("test: read observations error:%s", err)
+	}
+
+	mutex.Lock()
+	previousData, previousVersion, previousChanges := data, version, changes
+	data = SeriesSlice{
+		{Country: "Thailand", StartsAt: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), Deaths: []int{0, 0, 0}, Confirmed: []int{10, 20, 60}},
+	}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data, version, changes, imports = previousData, previousVersion, previousChanges, nil
+		mutex.Unlock()
+	}()
+
+	result := Import(list)
+	if result.Applied != 2 || result.Skipped != 2 {
+		t.Fatalf("test: import wanted applied:2 skipped:2 got:%v", result)
+	}
+	s, _ := FetchSeries("Thailand", "")
+	if s.Confirmed[1] != 50 || s.ConfirmedDaily[2] != 10 || s.AuxiliaryValues("stringency")[1] != 40.5 {
+		t.Fatalf("test: import wrong data got:%v %v", s.Confirmed, s.Auxiliary)
+	}
+	changes, current, _ := Changes(previousVersion)
+	if current != previousVersion+1 || len(changes) != 1 || changes[0].Dates[0] != "2020-03-02" {
+		t.Fatalf("test: import wanted a change got:%v", changes)
+	}
+
+	if _, err := ReadObservations(strings.NewReader(`[{"country":"Thailand","date":"1/3/20","metric":"deaths"}]`)); err == nil {
+		t.Fatalf("test: read observations wanted error for invalid date")
+	}
+}
+
+func TestApplyImports(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	official := SeriesSlice{{Country: "Thailand", StartsAt: start.AddDate(0, 0, 1), Deaths: []int{2}, Confirmed: []int{30}}}
+
+	mutex.Lock()
+	defer func() {
+		datasetImports, imports = make(map[string][]Observation), nil
+		mutex.Unlock()
+	}()
+	datasetImports["ddc"] = official.observations()
+	imports = []Observation{{Country: "Thailand", Date: "2020-03-02", Metric: MetricDeaths, Value: 3}}
+
+	// Corrections are applied after datasets
+	slice := SeriesSlice{{Country: "Thailand", StartsAt: start, Deaths: []int{0, 1, 1}, Confirmed: []int{10, 20, 40}}}
+	slice.applyImports(false)
+	if slice[0].Confirmed[1] != 30 || slice[0].Deaths[1] != 3 || slice[0].ConfirmedDaily[2] != 10 {
+		t.Fatalf("test: apply imports wrong data got:%v %v", slice[0].Confirmed, slice[0].Deaths)
+	}
+}
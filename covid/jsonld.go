@@ -0,0 +1,72 @@
+package covid
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+)
+
+// JSONLD returns schema.org Dataset structured data for this series as json for a script tag,
+// with observations of the latest totals so that search engines can show and discover the data
+// base is the absolute url of the site, e.g. https://coronavirus.projectpage.app
+func (s *Series) JSONLD(base string) (template.JS, error) {
+	title := s.Title()
+	page := base + s.Path(0)
+	place := map[string]interface{}{
+		"@type": "Place",
+		"name":  title,
+	}
+	download := page + ".json"
+	if s.Global() {
+		place["name"] = "Worldwide"
+		download = base + "/global.json"
+	} else if c := s.Meta(); c != nil && s.Province == "" {
+		place["@type"] = "Country"
+		place["identifier"] = c.ISO3
+	}
+
+	dataset := map[string]interface{}{
+		"@context":            "https://schema.org",
+		"@type":               "Dataset",
+		"name":                fmt.Sprintf("%s COVID-19 Deaths and Confirmed Cases", title),
+		"description":         fmt.Sprintf("Daily time series of COVID-19 deaths and confirmed cases for %s, from Johns Hopkins University CSSE data.", title),
+		"url":                 page,
+		"isAccessibleForFree": true,
+		"license":             "https://creativecommons.org/publicdomain/zero/1.0/",
+		"spatialCoverage":     place,
+		"variableMeasured":    []string{"COVID-19 deaths", "COVID-19 confirmed cases"},
+		"distribution": []map[string]interface{}{
+			{"@type": "DataDownload", "encodingFormat": "application/json", "contentUrl": download},
+		},
+	}
+	if !s.UpdatedAt.IsZero() {
+		dataset["dateModified"] = s.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	if len(s.Deaths) > 0 {
+		end := s.StartsAt.AddDate(0, 0, len(s.Deaths)-1).Format("2006-01-02")
+		dataset["temporalCoverage"] = s.StartsAt.Format("2006-01-02") + "/" + end
+		dataset["mainEntity"] = []map[string]interface{}{
+			observation(place, end, "COVID-19 deaths", s.TotalDeaths()),
+			observation(place, end, "COVID-19 confirmed cases", s.TotalConfirmed()),
+		}
+	}
+
+	b, err := json.Marshal(dataset)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(b), nil
+}
+
+// observation returns a schema.org Observation of a cumulative value at place on date
+func observation(place map[string]interface{}, date, measured string, value int) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":                "Observation",
+		"observationAbout":     place,
+		"observationDate":      date,
+		"variableMeasured":     measured,
+		"measuredValue":        value,
+		"measurementTechnique": "Cumulative total reported",
+	}
+}
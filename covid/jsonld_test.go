@@ -0,0 +1,43 @@
+package covid
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONLD(t *testing.T) {
+	s := &Series{Country: "Italy", StartsAt: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths: []int{1, 3, 6}, Confirmed: []int{10, 30, 60}}
+
+	js, err := s.JSONLD("https://example.com")
+	if err != nil {
+		t.Fatalf("test: json-ld failed:%s", err)
+	}
+	var ld struct {
+		Type             string `json:"@type"`
+		URL              string `json:"url"`
+		TemporalCoverage string `json:"temporalCoverage"`
+		SpatialCoverage  struct {
+			Type       string `json:"@type"`
+			Identifier string `json:"identifier"`
+		} `json:"spatialCoverage"`
+		MainEntity []struct {
+			Type          string `json:"@type"`
+			MeasuredValue int    `json:"measuredValue"`
+		} `json:"mainEntity"`
+	}
+	err = json.Unmarshal([]byte(js), &ld)
+	if err != nil {
+		t.Fatalf("test: json-ld invalid:%s", err)
+	}
+	if ld.Type != "Dataset" || ld.URL != "https://example.com/italy" || ld.TemporalCoverage != "2020-03-01/2020-03-03" {
+		t.Fatalf("test: json-ld dataset wrong got:%s %s %s", ld.Type, ld.URL, ld.TemporalCoverage)
+	}
+	if ld.SpatialCoverage.Type != "Country" || ld.SpatialCoverage.Identifier != "ITA" {
+		t.Fatalf("test: json-ld place wrong got:%s %s", ld.SpatialCoverage.Type, ld.SpatialCoverage.Identifier)
+	}
+	if len(ld.MainEntity) != 2 || ld.MainEntity[0].MeasuredValue != 6 || ld.MainEntity[1].MeasuredValue != 60 {
+		t.Fatalf("test: json-ld observations wrong got:%v", ld.MainEntity)
+	}
+}
@@ -0,0 +1,52 @@
+package covid
+
+import (
+	"sync"
+	"time"
+)
+
+// labelKey identifies a list of date labels by start date, length and format
+type labelKey struct {
+	start  int64
+	length int
+	format string
+}
+
+// maxLabels is the number of label lists kept before the cache is cleared, most series share a few start dates
+const maxLabels = 256
+
+var (
+	labelsMutex sync.RWMutex
+	// labels are the date labels for each key, shared between series, callers must not modify them
+	labels = make(map[labelKey][]string)
+)
+
+// dateLabels returns length date labels in format starting at start, formatting them only on first use
+// the list returned is shared, and must not be modified
+func dateLabels(start time.Time, length int, format string) []string {
+	if length <= 0 {
+		return nil
+	}
+	key := labelKey{start: start.Unix(), length: length, format: format}
+	labelsMutex.RLock()
+	list, ok := labels[key]
+	labelsMutex.RUnlock()
+	if ok {
+		return list
+	}
+
+	list = make([]string, length)
+	d := start
+	for i := range list {
+		list[i] = d.Format(format)
+		d = d.AddDate(0, 0, 1)
+	}
+
+	labelsMutex.Lock()
+	defer labelsMutex.Unlock()
+	if len(labels) >= maxLabels {
+		labels = make(map[labelKey][]string)
+	}
+	labels[key] = list
+	return list
+}
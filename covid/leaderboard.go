@@ -0,0 +1,82 @@
+package covid
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Leaderboard metrics
+const (
+	LeaderboardDeathsPerMillion = "deaths_per_million"
+	LeaderboardCasesPer100k     = "cases_per_100k"
+)
+
+// DefaultMinPopulation excludes microstates from leaderboards, where small numbers give very high rates
+const DefaultMinPopulation = 1000000
+
+// LeaderboardEntry is one country in a per capita leaderboard
+type LeaderboardEntry struct {
+	Rank       int     `json:"rank"`
+	Country    string  `json:"country"`
+	Flag       string  `json:"flag"`
+	Population int     `json:"population"`
+	Value      int     `json:"value"`
+	Rate       float64 `json:"rate"`
+	Change     string  `json:"change,omitempty"`
+}
+
+// Leaderboard returns the top n countries ranked by metric per capita over the last window days
+// (0 for all time), excluding countries with a population less than minPopulation
+// for a window, entries include the change compared with the prior window
+func (slice SeriesSlice) Leaderboard(metric string, window, minPopulation, n int) ([]LeaderboardEntry, error) {
+	var datum, unit int
+	var daily string
+	switch metric {
+	case LeaderboardDeathsPerMillion:
+		datum, unit, daily = DataDeaths, 1000000, MetricDeathsDaily
+	case LeaderboardCasesPer100k:
+		datum, unit, daily = DataConfirmed, 100000, MetricConfirmedDaily
+	default:
+		return nil, fmt.Errorf("leaderboard: unknown metric:%s", metric)
+	}
+
+	var entries []LeaderboardEntry
+	for _, s := range slice.Countries() {
+		population := s.Population()
+		if population == 0 || population < minPopulation {
+			continue
+		}
+		value := s.TotalIn(datum, window)
+		entry := LeaderboardEntry{
+			Country:    s.Country,
+			Flag:       s.Flag(),
+			Population: population,
+			Value:      value,
+			Rate:       perCapita(value, population, unit),
+		}
+		if window > 0 {
+			if c, err := s.Change(daily, window); err == nil {
+				entry.Change = c.Display()
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Rate > entries[j].Rate
+	})
+	if n > 0 && n < len(entries) {
+		entries = entries[:n]
+	}
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries, nil
+}
+
+// Leaderboard uses our stored data to return a per capita leaderboard
+func Leaderboard(metric string, window, minPopulation, n int) ([]LeaderboardEntry, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.Leaderboard(metric, window, minPopulation, n)
+}
@@ -0,0 +1,39 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaderboard(t *testing.T) {
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: []int{100, 200, 600}, Confirmed: []int{1000, 2000, 3000}},
+		{Country: "Spain", StartsAt: start, Deaths: []int{0, 10, 700}, Confirmed: []int{100, 200, 300}},
+		{Country: "San Marino", StartsAt: start, Deaths: []int{10, 20, 30}, Confirmed: []int{100, 200, 300}},
+	}
+
+	// San Marino has the highest rate but is excluded by population
+	entries, err := slice.Leaderboard(LeaderboardDeathsPerMillion, 0, DefaultMinPopulation, 10)
+	if err != nil {
+		t.Fatalf("test: leaderboard error:%s", err)
+	}
+	if len(entries) != 2 || entries[0].Country != "Spain" || entries[0].Rank != 1 {
+		t.Fatalf("test: leaderboard wrong entries:%v", entries)
+	}
+
+	// Without a population filter San Marino leads cases over the last day
+	entries, err = slice.Leaderboard(LeaderboardCasesPer100k, 1, 0, 1)
+	if err != nil {
+		t.Fatalf("test: leaderboard error:%s", err)
+	}
+	if len(entries) != 1 || entries[0].Country != "San Marino" || entries[0].Value != 100 {
+		t.Fatalf("test: leaderboard wrong entries:%v", entries)
+	}
+
+	_, err = slice.Leaderboard("unknown", 0, 0, 10)
+	if err == nil {
+		t.Fatalf("test: leaderboard accepted unknown metric")
+	}
+}
@@ -0,0 +1,144 @@
+package covid
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatMapped is a csv with the columns declared in the dataset config, e.g. a health ministry csv
+// the same mapping is used for spreadsheets in FormatXLSX
+const FormatMapped = "mapped"
+
+// ColumnMapping declares the columns used in a csv by header name, so that new sources can be loaded from config
+type ColumnMapping struct {
+	Country   string `json:"country"`
+	Province  string `json:"province"`
+	Date      string `json:"date"`
+	Confirmed string `json:"confirmed"`
+	Deaths    string `json:"deaths"`
+	// The layout of dates, in Go time format, defaults to 2006-01-02
+	DateFormat string `json:"date_format"`
+	// The country for all rows if the csv has no country column, e.g. Thailand
+	DefaultCountry string `json:"default_country"`
+	// True if values are new cases and deaths each day rather than cumulative totals
+	Daily bool `json:"daily"`
+}
+
+// readMappedCSV reads a csv using the columns declared in mapping and returns the series it contains
+// rows for the same series and date are summed, so case line lists can be loaded as daily data
+func readMappedCSV(records [][]string, mapping *ColumnMapping) (SeriesSlice, error) {
+	if mapping == nil {
+		return nil, fmt.Errorf("load: missing column mapping")
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("load: empty mapped csv")
+	}
+
+	columns := csvColumns(records[0])
+	column := func(name string, required bool) (int, error) {
+		if name == "" && !required {
+			return -1, nil
+		}
+		i, ok := columns[name]
+		if !ok {
+			return -1, fmt.Errorf("load: mapped csv missing column:%s", name)
+		}
+		return i, nil
+	}
+	country, err := column(mapping.Country, mapping.DefaultCountry == "")
+	if err != nil {
+		return nil, err
+	}
+	province, err := column(mapping.Province, false)
+	if err != nil {
+		return nil, err
+	}
+	date, err := column(mapping.Date, true)
+	if err != nil {
+		return nil, err
+	}
+	confirmed, err := column(mapping.Confirmed, false)
+	if err != nil {
+		return nil, err
+	}
+	deaths, err := column(mapping.Deaths, false)
+	if err != nil {
+		return nil, err
+	}
+	layout := mapping.DateFormat
+	if layout == "" {
+		layout = "2006-01-02"
+	}
+
+	// Sum rows by series and date
+	type key struct {
+		country, province string
+		date              time.Time
+	}
+	values := make(map[key]*Row)
+	var keys []key
+	for i, row := range records[1:] {
+		if len(row) != len(records[0]) {
+			return nil, fmt.Errorf("load: invalid mapped csv row:%d", i+1)
+		}
+		k := key{country: mapping.DefaultCountry}
+		if country >= 0 && row[country] != "" {
+			k.country = row[country]
+			if n, ok := countryAliases[k.country]; ok {
+				k.country = n
+			}
+		}
+		if province >= 0 {
+			k.province = row[province]
+		}
+		d, err := time.Parse(layout, strings.TrimSpace(row[date]))
+		if err != nil {
+			// Spreadsheets store dates as serial numbers
+			d, err = excelDate(row[date])
+			if err != nil {
+				return nil, fmt.Errorf("load: invalid mapped date row:%d:%s", i+1, err)
+			}
+		}
+		k.date = dateOnly(d)
+
+		r, ok := values[k]
+		if !ok {
+			r = &Row{Country: k.country, Province: k.province, Date: k.date}
+			values[k] = r
+			keys = append(keys, k)
+		}
+		for _, c := range []struct {
+			index int
+			value *int
+		}{{confirmed, &r.Confirmed}, {deaths, &r.Deaths}} {
+			if c.index < 0 || row[c.index] == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.Replace(row[c.index], ",", "", -1), 64)
+			if err != nil {
+				return nil, fmt.Errorf("load: invalid mapped value row:%d:%s", i+1, err)
+			}
+			*c.value += int(math.Round(v))
+		}
+	}
+
+	// Convert daily values to cumulative totals in date order
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].date.Before(keys[j].date) })
+	rows := make([]Row, 0, len(keys))
+	totals := make(map[[2]string]Row)
+	for _, k := range keys {
+		r := *values[k]
+		if mapping.Daily {
+			t := totals[[2]string{k.country, k.province}]
+			r.Confirmed += t.Confirmed
+			r.Deaths += t.Deaths
+			totals[[2]string{k.country, k.province}] = r
+		}
+		rows = append(rows, r)
+	}
+	return SeriesFromRows(rows), nil
+}
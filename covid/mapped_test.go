@@ -0,0 +1,49 @@
+package covid
+
+import (
+	"testing"
+)
+
+func TestReadMappedCSV(t *testing.T) {
+	records := [][]string{
+		{"announce_date", "province_of_onset", "new_cases", "new_deaths"},
+		{"02/03/2020", "Bangkok", "5", "0"},
+		{"01/03/2020", "Bangkok", "3", "1"},
+		{"02/03/2020", "Bangkok", "2", ""},
+		{"02/03/2020", "Phuket", "1,000", "1"},
+	}
+	mapping := &ColumnMapping{
+		Province:       "province_of_onset",
+		Date:           "announce_date",
+		Confirmed:      "new_cases",
+		Deaths:         "new_deaths",
+		DateFormat:     "02/01/2006",
+		DefaultCountry: "Thailand",
+		Daily:          true,
+	}
+	slice, err := readMappedCSV(records, mapping)
+	if err != nil {
+		t.Fatalf("test: mapped error:%s", err)
+	}
+	if len(slice) != 2 {
+		t.Fatalf("test: mapped wanted len:2 got:%d", len(slice))
+	}
+
+	// Daily values for the same day are summed and made cumulative
+	s, err := slice.FetchSeries("Thailand", "Bangkok")
+	if err != nil {
+		t.Fatalf("test: mapped missing Bangkok:%s", err)
+	}
+	if len(s.Confirmed) != 2 || s.Confirmed[0] != 3 || s.Confirmed[1] != 10 || s.TotalDeaths() != 1 {
+		t.Fatalf("test: mapped wrong data for Bangkok got:%v %v", s.Confirmed, s.Deaths)
+	}
+	s, err = slice.FetchSeries("Thailand", "Phuket")
+	if err != nil || s.TotalConfirmed() != 1000 {
+		t.Fatalf("test: mapped wrong data for Phuket got:%v", s)
+	}
+
+	mapping.Date = "missing"
+	if _, err := readMappedCSV(records, mapping); err == nil {
+		t.Fatalf("test: mapped wanted error for missing column")
+	}
+}
@@ -0,0 +1,168 @@
+package covid
+
+import (
+	"time"
+)
+
+// SeriesMeta holds everything a landing page needs about a country
+type SeriesMeta struct {
+	Key        string `json:"key"`
+	Country    string `json:"country"`
+	Flag       string `json:"flag"`
+	ISO2       string `json:"iso2"`
+	ISO3       string `json:"iso3"`
+	Continent  string `json:"continent"`
+	Income     string `json:"income_group"`
+	WHORegion  string `json:"who_region"`
+	Population int    `json:"population"`
+
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+
+	StartsAt        time.Time `json:"starts_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Updates         []Update  `json:"updates,omitempty"`
+	LastReported    string    `json:"last_reported,omitempty"`
+	DataAge         int       `json:"data_age"`
+	Stale           bool      `json:"stale"`
+	Cadence         string    `json:"cadence"`
+	FirstCase       string    `json:"first_case,omitempty"`
+	FirstDeath      string    `json:"first_death,omitempty"`
+	PeakCases       string    `json:"peak_cases,omitempty"`
+	PeakCasesValue  int       `json:"peak_cases_value"`
+	PeakDeaths      string    `json:"peak_deaths,omitempty"`
+	PeakDeathsValue int       `json:"peak_deaths_value"`
+
+	DaysSinceFirstCase int         `json:"days_since_first_case"`
+	Milestones         []Milestone `json:"milestones"`
+
+	Confirmed           int     `json:"confirmed"`
+	Deaths              int     `json:"deaths"`
+	ConfirmedToday      int     `json:"confirmed_today"`
+	DeathsToday         int     `json:"deaths_today"`
+	ConfirmedPerMillion float64 `json:"confirmed_per_million"`
+	DeathsPerMillion    float64 `json:"deaths_per_million"`
+	Incidence14Per100k  float64 `json:"incidence_14d"`
+	CaseFatalityRate    float64 `json:"case_fatality_rate"`
+	ConfirmedTrend      int     `json:"confirmed_trend"`
+	DeathsTrend         int     `json:"deaths_trend"`
+
+	Provisional *Provisional `json:"provisional,omitempty"`
+}
+
+// FirstDate returns the date of the first non-zero cumulative value for datum, or a zero time if none
+func (s *Series) FirstDate(datum int) time.Time {
+	for i, v := range s.chartValues(datum, false) {
+		if v > 0 {
+			return s.StartsAt.AddDate(0, 0, i)
+		}
+	}
+	return time.Time{}
+}
+
+// PeakDate returns the date and value of the highest daily value for datum, or a zero time if none
+// where the peak value occurs more than once, the first date is returned
+func (s *Series) PeakDate(datum int) (time.Time, int) {
+	peak, value := -1, 0
+	for i, v := range s.DailyValues(datum) {
+		if v > value {
+			peak, value = i, v
+		}
+	}
+	if peak == -1 {
+		return time.Time{}, 0
+	}
+	return s.StartsAt.AddDate(0, 0, peak), value
+}
+
+// CaseFatalityRate returns deaths as a percentage of confirmed cases
+func (s *Series) CaseFatalityRate() float64 {
+	if s.TotalConfirmed() == 0 {
+		return 0
+	}
+	return float64(s.TotalDeaths()) / float64(s.TotalConfirmed()) * 100
+}
+
+// Centroid returns the location of the series s, averaging the locations of its provinces
+// for country series which are built from provinces
+func (slice SeriesSlice) Centroid(s *Series) (latitude, longitude float64) {
+	if s.Latitude != 0 || s.Longitude != 0 || s.Province != "" || s.Country == "" {
+		return s.Latitude, s.Longitude
+	}
+	count := 0
+	for _, p := range slice {
+		if p.Country == s.Country && p.Province != "" && (p.Latitude != 0 || p.Longitude != 0) {
+			latitude += p.Latitude
+			longitude += p.Longitude
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return latitude / float64(count), longitude / float64(count)
+}
+
+// SeriesMeta returns the metadata and summary statistics for the country series s
+func (slice SeriesSlice) SeriesMeta(s *Series) *SeriesMeta {
+	m := &SeriesMeta{
+		Key:                 s.Key(s.Country),
+		Country:             s.Country,
+		Flag:                s.Flag(),
+		Population:          s.Population(),
+		StartsAt:            s.StartsAt,
+		UpdatedAt:           s.UpdatedAt,
+		Updates:             s.Updates,
+		LastReported:        isoDate(s.LastReportedAt()),
+		DataAge:             s.DataAge(),
+		Stale:               s.IsStale(DefaultStaleDays),
+		Cadence:             s.Cadence(),
+		Confirmed:           s.TotalConfirmed(),
+		Deaths:              s.TotalDeaths(),
+		ConfirmedToday:      todayValue(s.ConfirmedDaily),
+		DeathsToday:         todayValue(s.DeathsDaily),
+		ConfirmedPerMillion: s.ConfirmedPerMillion(),
+		DeathsPerMillion:    s.DeathsPerMillion(),
+		Incidence14Per100k:  s.Incidence14Per100k(),
+		CaseFatalityRate:    s.CaseFatalityRate(),
+		ConfirmedTrend:      s.Trend(DataConfirmed),
+		DeathsTrend:         s.Trend(DataDeaths),
+		Provisional:         s.Provisional,
+	}
+	if c := s.Meta(); c != nil {
+		m.ISO2, m.ISO3, m.Continent = c.ISO2, c.ISO3, c.Continent
+		m.Income, m.WHORegion = c.IncomeGroup(), c.WHORegion()
+	}
+	m.Latitude, m.Longitude = slice.Centroid(s)
+
+	m.FirstCase = isoDate(s.FirstDate(DataConfirmed))
+	m.FirstDeath = isoDate(s.FirstDate(DataDeaths))
+	var peak time.Time
+	peak, m.PeakCasesValue = s.PeakDate(DataConfirmed)
+	m.PeakCases = isoDate(peak)
+	peak, m.PeakDeathsValue = s.PeakDate(DataDeaths)
+	m.PeakDeaths = isoDate(peak)
+
+	m.DaysSinceFirstCase = s.DaysSinceFirstCase()
+	m.Milestones = s.Milestones()
+	return m
+}
+
+// FetchMeta uses our stored data to return metadata for the country with the given key
+func FetchMeta(country string) (*SeriesMeta, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	s, err := data.FetchSeries(country, "")
+	if err != nil {
+		return nil, err
+	}
+	return data.SeriesMeta(s), nil
+}
+
+// isoDate formats t as 2006-01-02, or returns blank for a zero time
+func isoDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
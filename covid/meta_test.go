@@ -0,0 +1,50 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFetchMeta(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	italy := &Series{Country: "Italy", StartsAt: start, Latitude: 43, Longitude: 12, Deaths: []int{0, 2, 5, 5}, Confirmed: []int{1, 10, 30, 40}}
+	australia := &Series{Country: "Australia", StartsAt: start, Deaths: []int{0, 0, 1, 1}, Confirmed: []int{0, 3, 5, 9}}
+	nsw := &Series{Country: "Australia", Province: "New South Wales", StartsAt: start, Latitude: -33, Longitude: 151, Deaths: []int{0, 0, 1, 1}, Confirmed: []int{0, 2, 3, 6}}
+	wa := &Series{Country: "Australia", Province: "Western Australia", StartsAt: start, Latitude: -31, Longitude: 115, Deaths: []int{0, 0, 0, 0}, Confirmed: []int{0, 1, 2, 3}}
+	for _, s := range []*Series{italy, australia, nsw, wa} {
+		s.UpdateDaily()
+	}
+	mutex.Lock()
+	previous := data
+	data = SeriesSlice{italy, australia, nsw, wa}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data = previous
+		mutex.Unlock()
+	}()
+
+	m, err := FetchMeta("italy")
+	if err != nil {
+		t.Fatalf("test: meta italy error:%s", err)
+	}
+	if m.Key != "italy" || m.ISO2 != "IT" || m.Continent != Europe || m.Latitude != 43 || m.Deaths != 5 || m.DeathsToday != 0 || m.ConfirmedToday != 10 {
+		t.Fatalf("test: meta italy wrong got:%+v", m)
+	}
+	if m.FirstCase != "2020-03-01" || m.FirstDeath != "2020-03-02" || m.PeakCases != "2020-03-03" || m.PeakCasesValue != 20 || m.DaysSinceFirstCase != 3 {
+		t.Fatalf("test: meta italy wrong dates got:%s %s %s %d", m.FirstCase, m.FirstDeath, m.PeakCases, m.DaysSinceFirstCase)
+	}
+
+	// Countries built from provinces are located at the centre of their provinces
+	m, err = FetchMeta("australia")
+	if err != nil || m.Latitude != -32 || m.Longitude != 133 || m.Confirmed != 9 || m.ISO3 != "AUS" {
+		t.Fatalf("test: meta australia wrong got:%+v error:%v", m, err)
+	}
+
+	// Meta is only for countries, so provinces and unknown countries are not found
+	for _, key := range []string{"new-south-wales", "atlantis", ""} {
+		if _, err := FetchMeta(key); err == nil {
+			t.Fatalf("test: meta %s wanted not found", key)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package covid
+
+import (
+	"fmt"
+	"math"
+)
+
+// Metric names accepted by MetricValues
+const (
+	MetricDeaths         = "deaths"
+	MetricConfirmed      = "confirmed"
+	MetricDeathsDaily    = "deaths_daily"
+	MetricConfirmedDaily = "confirmed_daily"
+	MetricIncidence14    = "incidence_14d"
+)
+
+// Metrics returns the list of metric names available on every series
+func Metrics() []string {
+	return []string{MetricDeaths, MetricConfirmed, MetricDeathsDaily, MetricConfirmedDaily, MetricIncidence14}
+}
+
+// MetricValues returns the values for the named metric, which may be an auxiliary series name or an expression
+func (s *Series) MetricValues(metric string) ([]int, error) {
+	switch metric {
+	case MetricDeaths:
+		return s.Deaths, nil
+	case MetricConfirmed:
+		return s.Confirmed, nil
+	case MetricDeathsDaily:
+		return s.DeathsDaily, nil
+	case MetricConfirmedDaily:
+		return s.ConfirmedDaily, nil
+	case MetricIncidence14:
+		return s.Incidence14Values(), nil
+	}
+
+	// Auxiliary series such as mobility are available for some countries, rounded for comparison
+	if aux := s.AuxiliaryValues(metric); aux != nil {
+		return roundValues(aux), nil
+	}
+
+	// Expressions of other metrics e.g. deaths_daily.sum7 must have whole values to be used as a metric,
+	// ratios such as deaths/confirmed*100 are rejected rather than truncated, and are available from transforms
+	if IsExpression(metric) {
+		values, err := s.metricFloats(metric)
+		if err != nil {
+			return nil, err
+		}
+		ints := make([]int, len(values))
+		for i, v := range values {
+			if math.Abs(v-math.Round(v)) > 1e-9 {
+				return nil, fmt.Errorf("series: expression:%s has fractional values, which are only available from transforms", metric)
+			}
+			ints[i] = int(math.Round(v))
+		}
+		return ints, nil
+	}
+	return nil, fmt.Errorf("series: unknown metric:%s", metric)
+}
@@ -0,0 +1,124 @@
+package covid
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Kinds of milestone
+const (
+	MilestoneFirstCase  = "first_case"
+	MilestoneFirstDeath = "first_death"
+	MilestoneCases      = "cases"
+	MilestoneDeaths     = "deaths"
+	MilestonePeakCases  = "peak_cases"
+	MilestonePeakDeaths = "peak_deaths"
+)
+
+// peakWindow is the number of days either side a local peak must be highest for
+const peakWindow = 14
+
+// Milestone is a notable date in a series, for timelines
+type Milestone struct {
+	Date  time.Time `json:"date"`
+	Kind  string    `json:"kind"`
+	Label string    `json:"label"`
+	Value int       `json:"value"`
+}
+
+// Milestones returns the date of the first case, first death, each power of ten threshold from 100
+// and local peaks in daily cases and deaths, sorted by date
+func (s *Series) Milestones() (milestones []Milestone) {
+	if d := s.FirstDate(DataConfirmed); !d.IsZero() {
+		milestones = append(milestones, Milestone{Date: d, Kind: MilestoneFirstCase, Label: "First case", Value: s.FetchDate(DataConfirmed, d)})
+	}
+	if d := s.FirstDate(DataDeaths); !d.IsZero() {
+		milestones = append(milestones, Milestone{Date: d, Kind: MilestoneFirstDeath, Label: "First death", Value: s.FetchDate(DataDeaths, d)})
+	}
+
+	milestones = append(milestones, s.thresholdMilestones(s.Confirmed, MilestoneCases, "cases")...)
+	milestones = append(milestones, s.thresholdMilestones(s.Deaths, MilestoneDeaths, "deaths")...)
+	milestones = append(milestones, s.peakMilestones(s.ConfirmedDaily, MilestonePeakCases, "Peak in daily cases")...)
+	milestones = append(milestones, s.peakMilestones(s.DeathsDaily, MilestonePeakDeaths, "Peak in daily deaths")...)
+
+	sort.SliceStable(milestones, func(i, j int) bool {
+		return milestones[i].Date.Before(milestones[j].Date)
+	})
+	return milestones
+}
+
+// DaysSinceFirstCase returns the number of days from the first case to the last day of the series, or -1 if none
+func (s *Series) DaysSinceFirstCase() int {
+	first := s.FirstDate(DataConfirmed)
+	if first.IsZero() {
+		return -1
+	}
+	last := s.StartsAt.AddDate(0, 0, len(s.Confirmed)-1)
+	return daysBetween(first, last)
+}
+
+// thresholdMilestones returns the first date cumulative values reached each power of ten from 100
+func (s *Series) thresholdMilestones(values []int, kind, name string) (milestones []Milestone) {
+	threshold := 100
+	for i, v := range values {
+		for v >= threshold {
+			milestones = append(milestones, Milestone{
+				Date:  s.StartsAt.AddDate(0, 0, i),
+				Kind:  kind,
+				Label: fmt.Sprintf("%s %s", formatThousands(threshold), name),
+				Value: threshold,
+			})
+			threshold *= 10
+		}
+	}
+	return milestones
+}
+
+// peakMilestones returns local peaks in the centred 7 day average of daily values
+// a day is a peak if its average is the highest within 14 days either side, so recent days can't be peaks yet
+func (s *Series) peakMilestones(daily []int, kind, label string) (milestones []Milestone) {
+	average := make([]float64, len(daily))
+	for i := range daily {
+		start, end := i-trendDays/2, i+trendDays/2+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(daily) {
+			end = len(daily)
+		}
+		average[i] = float64(sumInts(daily[start:end])) / float64(end-start)
+	}
+
+	for i := peakWindow; i+peakWindow < len(average); i++ {
+		if average[i] <= 0 {
+			continue
+		}
+		peak := true
+		for j := i - peakWindow; j <= i+peakWindow; j++ {
+			// Ties count for the earliest day only
+			if average[j] > average[i] || (j < i && average[j] == average[i]) {
+				peak = false
+				break
+			}
+		}
+		if peak {
+			milestones = append(milestones, Milestone{
+				Date:  s.StartsAt.AddDate(0, 0, i),
+				Kind:  kind,
+				Label: label,
+				Value: daily[i],
+			})
+		}
+	}
+	return milestones
+}
+
+// formatThousands formats i with commas separating thousands e.g. 10,000
+func formatThousands(i int) string {
+	s := fmt.Sprintf("%d", i)
+	for n := len(s) - 3; n > 0; n -= 3 {
+		s = s[:n] + "," + s[n:]
+	}
+	return s
+}
@@ -0,0 +1,44 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMilestones(t *testing.T) {
+
+	// A single wave of cases peaking on day 20, with deaths from day 10
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start}
+	confirmed, deaths := 0, 0
+	for i := 0; i < 50; i++ {
+		daily := 200 - (i-20)*(i-20)
+		if daily < 0 {
+			daily = 0
+		}
+		confirmed += daily
+		if i >= 10 {
+			deaths++
+		}
+		s.Confirmed = append(s.Confirmed, confirmed)
+		s.Deaths = append(s.Deaths, deaths)
+	}
+	s.UpdateDaily()
+
+	kinds := map[string]int{}
+	for _, m := range s.Milestones() {
+		kinds[m.Kind]++
+		if m.Kind == MilestonePeakCases && !m.Date.Equal(start.AddDate(0, 0, 20)) {
+			t.Errorf("test: milestones wrong peak date:%s", m.Date)
+		}
+		if m.Kind == MilestoneCases && m.Value == 1000 && m.Label != "1,000 cases" {
+			t.Errorf("test: milestones wrong label:%s", m.Label)
+		}
+	}
+	if kinds[MilestoneFirstCase] != 1 || kinds[MilestoneFirstDeath] != 1 || kinds[MilestoneCases] != 2 || kinds[MilestonePeakCases] != 1 {
+		t.Fatalf("test: milestones wrong:%v", kinds)
+	}
+	if s.DaysSinceFirstCase() != 43 {
+		t.Fatalf("test: days since first case wanted:%d got:%d", 43, s.DaysSinceFirstCase())
+	}
+}
@@ -0,0 +1,88 @@
+package covid
+
+import (
+	"sort"
+)
+
+// moversMinPrevious is the minimum value in the prior period for a percentage change to be ranked
+// so that countries going from 1 to 5 cases don't dominate
+const moversMinPrevious = 10
+
+// Movers holds the countries with the largest changes in a metric compared with the prior period
+type Movers struct {
+	Metric           string  `json:"metric"`
+	Window           int     `json:"window"`
+	Increases        []Mover `json:"increases"`
+	Decreases        []Mover `json:"decreases"`
+	PercentIncreases []Mover `json:"percent_increases"`
+	PercentDecreases []Mover `json:"percent_decreases"`
+}
+
+// Mover is a country whose figures changed significantly, with the sums of the metric over the window
+// and the period before it
+type Mover struct {
+	Country  string  `json:"country"`
+	Flag     string  `json:"flag"`
+	Value    int     `json:"value"`
+	Previous int     `json:"previous"`
+	Change   int     `json:"change"`
+	Percent  float64 `json:"percent"`
+	Display  string  `json:"display"`
+}
+
+// Movers compares the sum of a daily metric (e.g. confirmed_daily) over the last window days
+// with the window before, and returns the top n countries by absolute and percentage change each way
+func (slice SeriesSlice) Movers(metric string, window, n int) (*Movers, error) {
+	if window < 1 {
+		window = 1
+	}
+	movers := &Movers{Metric: metric, Window: window}
+
+	var all []Mover
+	for _, s := range slice.Countries() {
+		if _, err := s.MetricValues(metric); err != nil {
+			return nil, err
+		}
+		c, err := s.Change(metric, window)
+		if err != nil {
+			continue
+		}
+		all = append(all, Mover{
+			Country:  s.Country,
+			Flag:     s.Flag(),
+			Value:    c.Value,
+			Previous: c.Previous,
+			Change:   c.Change,
+			Percent:  c.Percent,
+			Display:  c.Display(),
+		})
+	}
+
+	movers.Increases = topMovers(all, n, func(m Mover) bool { return m.Change > 0 }, func(a, b Mover) bool { return a.Change > b.Change })
+	movers.Decreases = topMovers(all, n, func(m Mover) bool { return m.Change < 0 }, func(a, b Mover) bool { return a.Change < b.Change })
+	movers.PercentIncreases = topMovers(all, n, func(m Mover) bool { return m.Change > 0 && m.Previous >= moversMinPrevious }, func(a, b Mover) bool { return a.Percent > b.Percent })
+	movers.PercentDecreases = topMovers(all, n, func(m Mover) bool { return m.Change < 0 && m.Previous >= moversMinPrevious }, func(a, b Mover) bool { return a.Percent < b.Percent })
+	return movers, nil
+}
+
+// FetchMovers uses our stored data to return the biggest movers
+func FetchMovers(metric string, window, n int) (*Movers, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.Movers(metric, window, n)
+}
+
+// topMovers returns the first n movers which match include, sorted by less
+func topMovers(all []Mover, n int, include func(Mover) bool, less func(a, b Mover) bool) []Mover {
+	list := []Mover{}
+	for _, m := range all {
+		if include(m) {
+			list = append(list, m)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+	if n > 0 && len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
@@ -0,0 +1,74 @@
+package covid
+
+import (
+	"testing"
+)
+
+func TestMovers(t *testing.T) {
+	// Each series has a week before with previous cases and a week with value cases
+	week := func(country string, previous, value int) *Series {
+		return seriesFromDaily(country, "", 0, 0, 0, 0, 0, 0, previous, 0, 0, 0, 0, 0, 0, value)
+	}
+	slice := SeriesSlice{
+		week("Brazil", 100, 150),
+		week("Austria", 10, 30),
+		week("Egypt", 10, 30),
+		week("Chile", 30, 10),
+		week("Denmark", 5, 20),
+		seriesFromDaily("Fiji", "", 1, 2, 3, 40, 50, 60, 70, 80, 90, 100),
+		seriesFromDaily("Brazil", "Acre", 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1000),
+	}
+
+	m, err := slice.Movers(MetricConfirmedDaily, 7, 2)
+	if err != nil {
+		t.Fatalf("test: movers error:%s", err)
+	}
+	// Ties keep the order of the slice, which is sorted by deaths, and only n are returned
+	tests := map[string]struct {
+		got  []Mover
+		want []string
+	}{
+		"increases":         {m.Increases, []string{"Brazil", "Austria"}},
+		"decreases":         {m.Decreases, []string{"Chile"}},
+		"percent increases": {m.PercentIncreases, []string{"Austria", "Egypt"}},
+		"percent decreases": {m.PercentDecreases, []string{"Chile"}},
+	}
+	for name, tt := range tests {
+		if len(tt.got) != len(tt.want) {
+			t.Fatalf("test: movers %s wanted:%v got:%+v", name, tt.want, tt.got)
+		}
+		for i, country := range tt.want {
+			if tt.got[i].Country != country {
+				t.Fatalf("test: movers %s wanted:%v got:%+v", name, tt.want, tt.got)
+			}
+		}
+	}
+	if b := m.Increases[0]; b.Value != 150 || b.Previous != 100 || b.Change != 50 || b.Percent != 50 {
+		t.Fatalf("test: movers wrong values got:%+v", b)
+	}
+	if c := m.Decreases[0]; c.Change != -20 || int(c.Percent) != -66 {
+		t.Fatalf("test: movers wrong decrease got:%+v", c)
+	}
+
+	// With no limit all movers are returned, countries with a small previous value are left out of percentages
+	m, _ = slice.Movers(MetricConfirmedDaily, 7, 0)
+	if len(m.Increases) != 4 || m.Increases[3].Country != "Denmark" || len(m.PercentIncreases) != 3 {
+		t.Fatalf("test: movers wanted 4 increases, 3 by percent got:%+v %+v", m.Increases, m.PercentIncreases)
+	}
+
+	// Series shorter than two windows are left out, and a window below 1 is treated as 1
+	m, _ = slice.Movers(MetricConfirmedDaily, 6, 0)
+	for _, mover := range m.Increases {
+		if mover.Country == "Fiji" {
+			t.Fatalf("test: movers wanted short series left out got:%+v", mover)
+		}
+	}
+	m, _ = slice.Movers(MetricConfirmedDaily, 0, 0)
+	if m.Window != 1 || len(m.Increases) != 6 {
+		t.Fatalf("test: movers window 0 wanted window 1 with 6 increases got:%d %+v", m.Window, m.Increases)
+	}
+
+	if _, err := slice.Movers("nope", 7, 2); err == nil {
+		t.Fatalf("test: movers wanted error for invalid metric")
+	}
+}
@@ -0,0 +1,81 @@
+package covid
+
+import (
+	"sort"
+)
+
+// GroupOther is the option group for countries without a continent, e.g. cruise ships
+const GroupOther = "Other"
+
+// OptionGroup is a named group of options, for optgroups in selects and for hierarchical pickers
+// options without a group are collected in a group with no name
+type OptionGroup struct {
+	Name    string   `json:"name"`
+	Options []Option `json:"options"`
+}
+
+// GroupOptions returns options collected by group, groups are in the order they first appear
+func GroupOptions(options []Option) []OptionGroup {
+	var groups []OptionGroup
+	index := make(map[string]int)
+	for _, o := range options {
+		i, ok := index[o.Group]
+		if !ok {
+			i = len(groups)
+			index[o.Group] = i
+			groups = append(groups, OptionGroup{Name: o.Group})
+		}
+		groups[i].Options = append(groups[i].Options, o)
+	}
+	return groups
+}
+
+// CountryGroups returns the country options grouped by continent, with ungrouped options like Global first,
+// then continents in alphabetical order and GroupOther last
+func (slice SeriesSlice) CountryGroups() []OptionGroup {
+	groups := GroupOptions(slice.CountryOptions())
+	rank := func(name string) int {
+		switch name {
+		case "":
+			return 0
+		case GroupOther:
+			return 2
+		}
+		return 1
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		ri, rj := rank(groups[i].Name), rank(groups[j].Name)
+		if ri != rj {
+			return ri < rj
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// OptionTree returns the country groups with the provinces of each country nested in its options
+// so that pickers can show continent → country → province without further requests
+func (slice SeriesSlice) OptionTree() []OptionGroup {
+	names := make(map[string]string)
+	for _, s := range slice {
+		if s.Province == "" && s.Country != "" {
+			names[s.Key(s.Country)] = s.Country
+		}
+	}
+
+	groups := slice.CountryGroups()
+	for _, g := range groups {
+		for i, o := range g.Options {
+			country, ok := names[o.Value]
+			if !ok {
+				continue
+			}
+			// Skip the All Areas option, which is the country itself
+			provinces := slice.ProvinceOptions(country)[1:]
+			if len(provinces) > 0 {
+				g.Options[i].Options = provinces
+			}
+		}
+	}
+	return groups
+}
@@ -0,0 +1,41 @@
+package covid
+
+import (
+	"testing"
+)
+
+func TestOptionGroups(t *testing.T) {
+	slice := SeriesSlice{
+		&Series{Country: "Thailand"},
+		&Series{Country: "Diamond Princess"},
+		&Series{Country: "France"},
+		&Series{Country: "France", Province: "Reunion"},
+		&Series{Country: "Italy"},
+	}
+
+	groups := slice.CountryGroups()
+	names := []string{"", Asia, Europe, GroupOther}
+	if len(groups) != len(names) {
+		t.Fatalf("test: country groups wrong wanted:%d got:%d", len(names), len(groups))
+	}
+	for i, name := range names {
+		if groups[i].Name != name {
+			t.Errorf("test: country group wrong wanted:%s got:%s", name, groups[i].Name)
+		}
+	}
+	if len(groups[0].Options) != 1 || groups[0].Options[0].Name != "Global" {
+		t.Errorf("test: global option not first got:%v", groups[0].Options)
+	}
+	if len(groups[2].Options) != 2 || groups[2].Options[0].Value != "france" {
+		t.Errorf("test: europe options wrong got:%v", groups[2].Options)
+	}
+
+	tree := slice.OptionTree()
+	france := tree[2].Options[0]
+	if len(france.Options) != 1 || france.Options[0].Value != "reunion" || france.Options[0].Group != GroupTerritories {
+		t.Errorf("test: nested provinces wrong got:%v", france.Options)
+	}
+	if len(tree[2].Options[1].Options) != 0 {
+		t.Errorf("test: unexpected provinces for italy got:%v", tree[2].Options[1].Options)
+	}
+}
@@ -0,0 +1,56 @@
+package covid
+
+import (
+	"math"
+)
+
+// outlierWindow is the number of days either side used to judge whether a value is an outlier
+const outlierWindow = 7
+
+// outlierMinChange is the smallest difference from the surrounding days which can be an outlier
+// so that small counts are not flagged
+const outlierMinChange = 10
+
+// DefaultOutlierThreshold is the z-score beyond which values are usually treated as outliers
+const DefaultOutlierThreshold = 3.0
+
+// Outliers returns the indexes of values which differ from the surrounding days by more than threshold
+// standard deviations, this catches batch corrections and reporting dumps in daily values
+func Outliers(values []int, threshold float64) (outliers []int) {
+	for i, v := range values {
+		start, end := i-outlierWindow, i+outlierWindow+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(values) {
+			end = len(values)
+		}
+
+		// Calculate the mean and standard deviation of the neighbours, excluding this value
+		var sum, count float64
+		for j := start; j < end; j++ {
+			if j != i {
+				sum += float64(values[j])
+				count++
+			}
+		}
+		if count < 2 {
+			continue
+		}
+		mean := sum / count
+		var variance float64
+		for j := start; j < end; j++ {
+			if j != i {
+				variance += math.Pow(float64(values[j])-mean, 2)
+			}
+		}
+		// Counts vary naturally by around their square root, so don't expect less variation than that
+		stddev := math.Max(math.Sqrt(variance/count), math.Max(math.Sqrt(mean), 1))
+
+		diff := math.Abs(float64(v) - mean)
+		if diff >= outlierMinChange && diff/stddev > threshold {
+			outliers = append(outliers, i)
+		}
+	}
+	return outliers
+}
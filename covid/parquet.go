@@ -0,0 +1,518 @@
+package covid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"time"
+)
+
+// Parquet files are written with a single row group, with one uncompressed, plain encoded page per column
+// all columns are required, so no definition or repetition levels are written
+// The reader only supports files in this form (as written by WriteParquet)
+//
+// Columns are: country (string), province (string), date (date), confirmed (int64), deaths (int64)
+
+// parquetMagic starts and ends every parquet file
+const parquetMagic = "PAR1"
+
+// Parquet physical types, converted types and other enums used
+const (
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetConvertedUTF8 = 0
+	parquetConvertedDate = 6
+
+	parquetRequired     = 0
+	parquetPlain        = 0
+	parquetRLE          = 3
+	parquetUncompressed = 0
+	parquetDataPage     = 0
+)
+
+// parquetColumn describes one column in our schema
+type parquetColumn struct {
+	name      string
+	kind      int32
+	converted int32
+}
+
+// parquetSchema is the schema for our long format dataset
+var parquetSchema = []parquetColumn{
+	{"country", parquetByteArray, parquetConvertedUTF8},
+	{"province", parquetByteArray, parquetConvertedUTF8},
+	{"date", parquetInt32, parquetConvertedDate},
+	{"confirmed", parquetInt64, -1},
+	{"deaths", parquetInt64, -1},
+}
+
+// WriteParquet writes the data in slice to w as a parquet file in long format
+func (slice SeriesSlice) WriteParquet(w io.Writer) error {
+	return writeParquetRows(w, slice.Rows())
+}
+
+// WriteParquet uses our stored data to write a parquet file
+func WriteParquet(w io.Writer) error {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.WriteParquet(w)
+}
+
+// writeParquetRows writes rows to w as a parquet file
+func writeParquetRows(w io.Writer, rows []Row) error {
+	file := &bytes.Buffer{}
+	file.WriteString(parquetMagic)
+
+	// Write each column chunk, recording the metadata for the footer
+	var chunks []*thriftWriter
+	for _, c := range parquetSchema {
+		values := &bytes.Buffer{}
+		for _, r := range rows {
+			switch c.name {
+			case "country":
+				writeParquetString(values, r.Country)
+			case "province":
+				writeParquetString(values, r.Province)
+			case "date":
+				days := DateOf(r.Date.UTC()).epochDays()
+				binary.Write(values, binary.LittleEndian, int32(days))
+			case "confirmed":
+				binary.Write(values, binary.LittleEndian, int64(r.Confirmed))
+			case "deaths":
+				binary.Write(values, binary.LittleEndian, int64(r.Deaths))
+			}
+		}
+
+		// Page header
+		header := &thriftWriter{}
+		header.fieldI32(1, parquetDataPage)
+		header.fieldI32(2, int32(values.Len()))
+		header.fieldI32(3, int32(values.Len()))
+		header.fieldStruct(5)
+		header.fieldI32(1, int32(len(rows)))
+		header.fieldI32(2, parquetPlain)
+		header.fieldI32(3, parquetRLE)
+		header.fieldI32(4, parquetRLE)
+		header.endStruct()
+		header.stop()
+
+		offset := int64(file.Len())
+		size := int64(header.buf.Len() + values.Len())
+		file.Write(header.buf.Bytes())
+		file.Write(values.Bytes())
+
+		// Column chunk metadata for the footer
+		chunk := &thriftWriter{}
+		chunk.fieldI64(2, offset)
+		chunk.fieldStruct(3)
+		chunk.fieldI32(1, c.kind)
+		chunk.fieldList(2, thriftI32, 2)
+		chunk.i32(parquetPlain)
+		chunk.i32(parquetRLE)
+		chunk.endList()
+		chunk.fieldList(3, thriftBinary, 1)
+		chunk.binary([]byte(c.name))
+		chunk.endList()
+		chunk.fieldI32(4, parquetUncompressed)
+		chunk.fieldI64(5, int64(len(rows)))
+		chunk.fieldI64(6, size)
+		chunk.fieldI64(7, size)
+		chunk.fieldI64(9, offset)
+		chunk.endStruct()
+		chunk.stop()
+		chunks = append(chunks, chunk)
+	}
+	dataSize := int64(file.Len() - len(parquetMagic))
+
+	// Footer file metadata
+	meta := &thriftWriter{}
+	meta.fieldI32(1, 1)
+	meta.fieldList(2, thriftStruct, len(parquetSchema)+1)
+	meta.beginStruct()
+	meta.fieldBinary(4, []byte("schema"))
+	meta.fieldI32(5, int32(len(parquetSchema)))
+	meta.endStruct()
+	for _, c := range parquetSchema {
+		meta.beginStruct()
+		meta.fieldI32(1, c.kind)
+		meta.fieldI32(3, parquetRequired)
+		meta.fieldBinary(4, []byte(c.name))
+		if c.converted >= 0 {
+			meta.fieldI32(6, c.converted)
+		}
+		meta.endStruct()
+	}
+	meta.endList()
+	meta.fieldI64(3, int64(len(rows)))
+	meta.fieldList(4, thriftStruct, 1)
+	meta.beginStruct()
+	meta.fieldList(1, thriftStruct, len(chunks))
+	for _, c := range chunks {
+		// Each chunk is a complete struct, so can be copied in directly
+		meta.buf.Write(c.buf.Bytes())
+	}
+	meta.endList()
+	meta.fieldI64(2, dataSize)
+	meta.fieldI64(3, int64(len(rows)))
+	meta.endStruct()
+	meta.endList()
+	meta.fieldBinary(6, []byte("github.com/junlapong/coronavirus"))
+	meta.stop()
+
+	file.Write(meta.buf.Bytes())
+	binary.Write(file, binary.LittleEndian, uint32(meta.buf.Len()))
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// writeParquetString writes a plain encoded byte array
+func writeParquetString(b *bytes.Buffer, s string) {
+	binary.Write(b, binary.LittleEndian, uint32(len(s)))
+	b.WriteString(s)
+}
+
+// ReadParquet reads a parquet file written by WriteParquet and returns the series it contains
+func ReadParquet(r io.Reader) (SeriesSlice, error) {
+	rows, err := readParquetRows(r)
+	if err != nil {
+		return nil, err
+	}
+	return SeriesFromRows(rows), nil
+}
+
+// readParquetRows reads the rows from a parquet file
+func readParquetRows(r io.Reader) ([]Row, error) {
+	file, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check magic at start and end, and read footer
+	n := len(file)
+	if n < 12 || string(file[:4]) != parquetMagic || string(file[n-4:]) != parquetMagic {
+		return nil, fmt.Errorf("parquet: invalid file")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[n-8 : n-4]))
+	if footerLen > n-12 {
+		return nil, fmt.Errorf("parquet: invalid footer length")
+	}
+	meta, err := readThriftStruct(bytes.NewReader(file[n-8-footerLen : n-8]))
+	if err != nil {
+		return nil, fmt.Errorf("parquet: error reading footer:%s", err)
+	}
+
+	// Read each row group in turn
+	var rows []Row
+	groups, _ := meta[4].([]interface{})
+	for _, g := range groups {
+		group, _ := g.(map[int16]interface{})
+		count, _ := group[3].(int64)
+		if count < 0 || count > int64(n) {
+			return nil, fmt.Errorf("parquet: invalid row count")
+		}
+		groupRows := make([]Row, count)
+
+		columns, _ := group[1].([]interface{})
+		for _, c := range columns {
+			chunk, _ := c.(map[int16]interface{})
+			cm, _ := chunk[3].(map[int16]interface{})
+			path, _ := cm[3].([]interface{})
+			if len(path) != 1 {
+				return nil, fmt.Errorf("parquet: unsupported column path")
+			}
+			p, _ := path[0].([]byte)
+			name := string(p)
+			if codec, _ := cm[4].(int32); codec != parquetUncompressed {
+				return nil, fmt.Errorf("parquet: unsupported compression for column:%s", name)
+			}
+			offset, _ := cm[9].(int64)
+			if offset < 4 || offset >= int64(n) {
+				return nil, fmt.Errorf("parquet: invalid page offset for column:%s", name)
+			}
+
+			err = readParquetColumn(file[offset:], name, groupRows)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rows = append(rows, groupRows...)
+	}
+
+	return rows, nil
+}
+
+// readParquetColumn reads the values for the named column from the pages starting at page into rows
+func readParquetColumn(page []byte, name string, rows []Row) error {
+	read := 0
+	for read < len(rows) {
+		r := bytes.NewReader(page)
+		header, err := readThriftStruct(r)
+		if err != nil {
+			return fmt.Errorf("parquet: error reading page header for column:%s error:%s", name, err)
+		}
+		if kind, _ := header[1].(int32); kind != parquetDataPage {
+			return fmt.Errorf("parquet: unsupported page type for column:%s", name)
+		}
+		dph, _ := header[5].(map[int16]interface{})
+		if enc, _ := dph[2].(int32); enc != parquetPlain {
+			return fmt.Errorf("parquet: unsupported encoding for column:%s", name)
+		}
+		count, _ := dph[1].(int32)
+		size, _ := header[3].(int32)
+
+		values := page[len(page)-r.Len():]
+		if int(size) > len(values) || read+int(count) > len(rows) {
+			return fmt.Errorf("parquet: invalid page for column:%s", name)
+		}
+		values = values[:size]
+		page = page[len(page)-r.Len()+int(size):]
+
+		for i := read; i < read+int(count); i++ {
+			switch name {
+			case "country", "province":
+				if len(values) < 4 {
+					return fmt.Errorf("parquet: short page for column:%s", name)
+				}
+				l := int(binary.LittleEndian.Uint32(values))
+				if len(values) < 4+l {
+					return fmt.Errorf("parquet: short page for column:%s", name)
+				}
+				if name == "country" {
+					rows[i].Country = string(values[4 : 4+l])
+				} else {
+					rows[i].Province = string(values[4 : 4+l])
+				}
+				values = values[4+l:]
+			case "date":
+				if len(values) < 4 {
+					return fmt.Errorf("parquet: short page for column:%s", name)
+				}
+				days := int32(binary.LittleEndian.Uint32(values))
+				rows[i].Date = time.Unix(int64(days)*86400, 0).UTC()
+				values = values[4:]
+			case "confirmed", "deaths":
+				if len(values) < 8 {
+					return fmt.Errorf("parquet: short page for column:%s", name)
+				}
+				v := int(int64(binary.LittleEndian.Uint64(values)))
+				if name == "confirmed" {
+					rows[i].Confirmed = v
+				} else {
+					rows[i].Deaths = v
+				}
+				values = values[8:]
+			default:
+				// Ignore columns we don't know about
+			}
+		}
+		read += int(count)
+	}
+	return nil
+}
+
+// Thrift compact protocol types
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// thriftWriter writes the subset of the thrift compact protocol needed for parquet metadata
+type thriftWriter struct {
+	buf   bytes.Buffer
+	last  int16
+	stack []int16
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	t.buf.Write(b[:n])
+}
+
+func (t *thriftWriter) zigzag(v int64) {
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) field(id int16, kind byte) {
+	delta := id - t.last
+	if delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | kind)
+	} else {
+		t.buf.WriteByte(kind)
+		t.zigzag(int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) i32(v int32)     { t.zigzag(int64(v)) }
+func (t *thriftWriter) binary(b []byte) { t.varint(uint64(len(b))); t.buf.Write(b) }
+
+func (t *thriftWriter) fieldI32(id int16, v int32) { t.field(id, thriftI32); t.i32(v) }
+func (t *thriftWriter) fieldI64(id int16, v int64) { t.field(id, thriftI64); t.zigzag(v) }
+func (t *thriftWriter) fieldBinary(id int16, b []byte) {
+	t.field(id, thriftBinary)
+	t.binary(b)
+}
+
+// fieldList writes a list header - elements are written directly after, followed by endList
+// struct elements are written between beginStruct and endStruct
+func (t *thriftWriter) fieldList(id int16, kind byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | kind)
+	} else {
+		t.buf.WriteByte(0xF0 | kind)
+		t.varint(uint64(size))
+	}
+	t.stack = append(t.stack, t.last)
+}
+
+// endList restores the field ids of the struct enclosing a list
+func (t *thriftWriter) endList() {
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// fieldStruct starts a nested struct field, which must be closed with endStruct
+func (t *thriftWriter) fieldStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginStruct()
+}
+
+// beginStruct starts a struct with fresh field ids
+func (t *thriftWriter) beginStruct() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+// endStruct writes the stop field for a struct started with beginStruct
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// stop writes the stop field ending the top level struct
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+// readThriftStruct reads a struct in the thrift compact protocol into a map of field id to value
+// values are bool, int32, int64, float64, []byte, []interface{} or map[int16]interface{}
+func readThriftStruct(r *bytes.Reader) (map[int16]interface{}, error) {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return fields, nil
+		}
+		kind := b & 0x0F
+		delta := int16(b >> 4)
+		id := last + delta
+		if delta == 0 {
+			v, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		last = id
+
+		v, err := readThriftValue(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		fields[id] = v
+	}
+}
+
+// readThriftValue reads one value of the given compact protocol type
+func readThriftValue(r *bytes.Reader, kind byte) (interface{}, error) {
+	switch kind {
+	case thriftTrue:
+		return true, nil
+	case thriftFalse:
+		return false, nil
+	case thriftByte:
+		b, err := r.ReadByte()
+		return int32(int8(b)), err
+	case thriftI16, thriftI32:
+		v, err := binary.ReadVarint(r)
+		return int32(v), err
+	case thriftI64:
+		return binary.ReadVarint(r)
+	case thriftDouble:
+		var b [8]byte
+		_, err := io.ReadFull(r, b[:])
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), err
+	case thriftBinary:
+		l, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if l > uint64(r.Len()) {
+			return nil, fmt.Errorf("thrift: binary length out of range")
+		}
+		b := make([]byte, l)
+		_, err = io.ReadFull(r, b)
+		return b, err
+	case thriftList, thriftSet:
+		h, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(h >> 4)
+		if size == 15 {
+			size, err = binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if size > uint64(r.Len()) {
+			return nil, fmt.Errorf("thrift: list size out of range")
+		}
+		elem := h & 0x0F
+		list := make([]interface{}, 0, size)
+		for i := uint64(0); i < size; i++ {
+			// Booleans in lists are written as a single byte
+			if elem == thriftTrue || elem == thriftFalse {
+				b, err := r.ReadByte()
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, b == thriftTrue)
+				continue
+			}
+			v, err := readThriftValue(r, elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case thriftStruct:
+		return readThriftStruct(r)
+	}
+	return nil, fmt.Errorf("thrift: unsupported type:%d", kind)
+}
@@ -0,0 +1,80 @@
+package covid
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestParquet(t *testing.T) {
+
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3}, Confirmed: []int{2, 5, 10}},
+		{Country: "China", Province: "Hubei", StartsAt: start, Deaths: []int{17, 17, 24}, Confirmed: []int{444, 444, 549}},
+	}
+
+	b := &bytes.Buffer{}
+	err := slice.WriteParquet(b)
+	if err != nil {
+		t.Fatalf("test: failed writing parquet:%s", err)
+	}
+
+	loaded, err := ReadParquet(b)
+	if err != nil {
+		t.Fatalf("test: failed reading parquet:%s", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("test: parquet wrong len wanted:%d got:%d", 2, len(loaded))
+	}
+
+	series, err := loaded.FetchSeries("China", "Hubei")
+	if err != nil {
+		t.Fatalf("test: failed fetching series from parquet:%s", err)
+	}
+	if !series.StartsAt.Equal(start) || series.Deaths[2] != 24 || series.Confirmed[2] != 549 || series.ConfirmedDaily[2] != 105 {
+		t.Errorf("test: parquet wrong data for Hubei got:%v %v", series.Deaths, series.Confirmed)
+	}
+}
+
+func TestReadExports(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3}, Confirmed: []int{2, 5, 10}},
+		{Country: "China", Province: "Hubei", StartsAt: start, Deaths: []int{17, 17, 24}, Confirmed: []int{444, 444, 549}},
+	}
+
+	for _, format := range []string{"csv", "json"} {
+		b := &bytes.Buffer{}
+		var err error
+		var loaded SeriesSlice
+		if format == "csv" {
+			err = slice.WriteCSV(b)
+			if err == nil {
+				loaded, err = ReadCSV(b)
+			}
+		} else {
+			err = slice.WriteJSON(b)
+			if err == nil {
+				loaded, err = ReadJSON(b)
+			}
+		}
+		if err != nil {
+			t.Fatalf("test: failed round trip %s:%s", format, err)
+		}
+		if len(loaded) != 2 {
+			t.Fatalf("test: %s wrong len wanted:%d got:%d", format, 2, len(loaded))
+		}
+		series, err := loaded.FetchSeries("China", "Hubei")
+		if err != nil {
+			t.Fatalf("test: failed fetching series from %s:%s", format, err)
+		}
+		if !series.StartsAt.Equal(start) || series.Deaths[2] != 24 || series.Confirmed[2] != 549 || series.ConfirmedDaily[2] != 105 {
+			t.Errorf("test: %s wrong data for Hubei got:%v %v", format, series.Deaths, series.Confirmed)
+		}
+	}
+
+	if _, err := ReadCSV(bytes.NewBufferString("a,b\n1,2\n")); err == nil {
+		t.Fatalf("test: read csv wanted error for invalid header")
+	}
+}
@@ -0,0 +1,67 @@
+package covid
+
+import (
+	"time"
+)
+
+// Point holds the values of a series for one day
+type Point struct {
+	Date           time.Time `json:"date"`
+	Deaths         int       `json:"deaths"`
+	Confirmed      int       `json:"confirmed"`
+	DeathsDaily    int       `json:"deaths_daily"`
+	ConfirmedDaily int       `json:"confirmed_daily"`
+}
+
+// dayIndex returns the index of date in a series starting at start
+// both are reduced to their UTC calendar dates first, so the time of day and zone of date don't shift the index
+func dayIndex(start, date time.Time) int {
+	return daysBetween(start, date)
+}
+
+// At returns the values of this series on date, or false if the series has no data for that date
+func (s *Series) At(date time.Time) (Point, bool) {
+	i := dayIndex(s.StartsAt, date)
+	if i < 0 || i >= len(s.Deaths) || i >= len(s.Confirmed) {
+		return Point{}, false
+	}
+	return s.point(i), true
+}
+
+// Range returns the values of this series for each day from from to to inclusive,
+// limited to the dates the series has data for
+func (s *Series) Range(from, to time.Time) []Point {
+	first := dayIndex(s.StartsAt, from)
+	last := dayIndex(s.StartsAt, to)
+	if first < 0 {
+		first = 0
+	}
+	n := len(s.Deaths)
+	if len(s.Confirmed) < n {
+		n = len(s.Confirmed)
+	}
+	if last >= n {
+		last = n - 1
+	}
+	points := []Point{}
+	for i := first; i <= last; i++ {
+		points = append(points, s.point(i))
+	}
+	return points
+}
+
+// point returns the values of this series at index i, which must be within the series
+func (s *Series) point(i int) Point {
+	p := Point{
+		Date:      s.StartDate().AddDays(i).Time(),
+		Deaths:    s.Deaths[i],
+		Confirmed: s.Confirmed[i],
+	}
+	if i < len(s.DeathsDaily) {
+		p.DeathsDaily = s.DeathsDaily[i]
+	}
+	if i < len(s.ConfirmedDaily) {
+		p.ConfirmedDaily = s.ConfirmedDaily[i]
+	}
+	return p
+}
@@ -0,0 +1,39 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAt(t *testing.T) {
+	start := time.Date(2020, 3, 27, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "United Kingdom", StartsAt: start, Deaths: []int{1, 3, 6, 10}, Confirmed: []int{10, 20, 40, 80}}
+	s.UpdateDaily()
+
+	// A local time late on the day clocks change still falls on the same date in UTC
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("test: no zone data:%s", err)
+	}
+	p, ok := s.At(time.Date(2020, 3, 29, 23, 30, 0, 0, london))
+	if !ok || p.Deaths != 6 || p.DeathsDaily != 3 || !p.Date.Equal(start.AddDate(0, 0, 2)) {
+		t.Fatalf("test: at wanted deaths:6 got:%+v %t", p, ok)
+	}
+	if _, ok := s.At(start.AddDate(0, 0, -1)); ok {
+		t.Fatalf("test: at returned a point before the start")
+	}
+	if _, ok := s.At(start.AddDate(0, 0, 4)); ok {
+		t.Fatalf("test: at returned a point after the end")
+	}
+	if s.FetchDate(DataConfirmed, start.AddDate(0, 0, 3)) != 80 {
+		t.Fatalf("test: fetch date wanted:80 got:%d", s.FetchDate(DataConfirmed, start.AddDate(0, 0, 3)))
+	}
+
+	points := s.Range(start.AddDate(0, 0, -5), start.AddDate(0, 0, 1))
+	if len(points) != 2 || points[1].Confirmed != 20 {
+		t.Fatalf("test: range wanted 2 points got:%+v", points)
+	}
+	if len(s.Range(start.AddDate(0, 0, 2), start)) != 0 {
+		t.Fatalf("test: range returned points for a reversed range")
+	}
+}
@@ -0,0 +1,69 @@
+package covid
+
+import (
+	"fmt"
+	"math"
+)
+
+// Special results from DaysUntil
+const (
+	// ProjectionNever means the series is flat or declining so will not reach the threshold at the current rate
+	ProjectionNever = -1
+	// ProjectionUnknown means there is not enough data to estimate a growth rate
+	ProjectionUnknown = -2
+)
+
+// projectionDays is the number of recent days used to estimate the growth rate
+const projectionDays = 7
+
+// GrowthRate returns the average daily compound growth rate of the cumulative values for metric over the last week
+// e.g. 0.1 for 10% growth per day, it returns false if there is not enough data
+func (s *Series) GrowthRate(metric string) (float64, bool, error) {
+	values, err := s.cumulativeValues(metric)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(values) <= projectionDays {
+		return 0, false, nil
+	}
+	now, previous := values[len(values)-1], values[len(values)-1-projectionDays]
+	if previous <= 0 || now <= 0 {
+		return 0, false, nil
+	}
+	return math.Pow(float64(now)/float64(previous), 1/float64(projectionDays)) - 1, true, nil
+}
+
+// DaysUntil estimates the number of days until the cumulative metric (deaths or confirmed) reaches threshold
+// at the current growth rate, returning 0 if it has already been reached
+// ProjectionNever is returned for flat or declining series and ProjectionUnknown if there is not enough data
+func (s *Series) DaysUntil(metric string, threshold int) (int, error) {
+	values, err := s.cumulativeValues(metric)
+	if err != nil {
+		return ProjectionUnknown, err
+	}
+	if len(values) > 0 && values[len(values)-1] >= threshold {
+		return 0, nil
+	}
+
+	rate, ok, err := s.GrowthRate(metric)
+	if err != nil || !ok {
+		return ProjectionUnknown, err
+	}
+	if rate <= 0 {
+		return ProjectionNever, nil
+	}
+
+	now := float64(values[len(values)-1])
+	return int(math.Ceil(math.Log(float64(threshold)/now) / math.Log(1+rate))), nil
+}
+
+// cumulativeValues returns the values for a cumulative metric
+func (s *Series) cumulativeValues(metric string) ([]int, error) {
+	switch metric {
+	case MetricDeaths:
+		return s.Deaths, nil
+	case MetricConfirmed:
+		return s.Confirmed, nil
+	}
+	return nil, fmt.Errorf("series: metric is not cumulative:%s", metric)
+}
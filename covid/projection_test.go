@@ -0,0 +1,40 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaysUntil(t *testing.T) {
+
+	// Deaths double every day, confirmed are flat
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start}
+	for i := 0; i < 10; i++ {
+		s.Deaths = append(s.Deaths, 1<<uint(i))
+		s.Confirmed = append(s.Confirmed, 1000)
+	}
+
+	days, err := s.DaysUntil(MetricDeaths, 4096)
+	if err != nil || days != 3 {
+		t.Fatalf("test: days until wanted:%d got:%d %v", 3, days, err)
+	}
+	days, _ = s.DaysUntil(MetricDeaths, 100)
+	if days != 0 {
+		t.Fatalf("test: days until reached wanted:%d got:%d", 0, days)
+	}
+	days, _ = s.DaysUntil(MetricConfirmed, 100000)
+	if days != ProjectionNever {
+		t.Fatalf("test: days until flat wanted:%d got:%d", ProjectionNever, days)
+	}
+	s.Confirmed[1] = 0
+	s.Confirmed = s.Confirmed[:9]
+	days, _ = s.DaysUntil(MetricConfirmed, 100000)
+	if days != ProjectionUnknown {
+		t.Fatalf("test: days until unknown wanted:%d got:%d", ProjectionUnknown, days)
+	}
+	_, err = s.DaysUntil(MetricDeathsDaily, 10)
+	if err == nil {
+		t.Fatalf("test: days until accepted daily metric")
+	}
+}
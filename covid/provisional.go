@@ -0,0 +1,74 @@
+package covid
+
+import (
+	"time"
+)
+
+// Provisional holds the values for a day reported in daily data which is not yet in the official time series
+// they are also the last values of the series, so that totals and charts include today,
+// and are replaced by the official values on the first load of a time series which includes that date
+// days from Since are all provisional, including any missed days carried forward to Date
+type Provisional struct {
+	Date      time.Time `json:"date"`
+	Since     time.Time `json:"since"`
+	Deaths    int       `json:"deaths"`
+	Confirmed int       `json:"confirmed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// officialDays returns the number of days at the start of this series which are not provisional
+func (s *Series) officialDays() int {
+	if s.Provisional == nil {
+		return len(s.Deaths)
+	}
+	days := daysBetween(s.StartsAt, s.Provisional.Since)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// IsProvisional returns true if the values for day i of this series are provisional
+func (s *Series) IsProvisional(i int) bool {
+	return s.Provisional != nil && i >= s.officialDays() && i < len(s.Deaths)
+}
+
+// provisionalDay returns the index of the day with the latest provisional values, or -1 if none
+func (s *Series) provisionalDay() int {
+	if s.Provisional == nil {
+		return -1
+	}
+	return daysBetween(s.StartsAt, s.Provisional.Date)
+}
+
+// AddProvisional sets provisional values for day from daily data, adding the day to the series if required
+// days which already have official values are never replaced, for those false is returned
+func (s *Series) AddProvisional(day int, updated time.Time, confirmed, deaths int) (bool, error) {
+	if day < s.officialDays() {
+		return false, nil
+	}
+	// Days carried forward to reach day are provisional too, as they are not from the time series
+	since := s.StartsAt.AddDate(0, 0, len(s.Deaths))
+	if s.Provisional != nil {
+		since = s.Provisional.Since
+	}
+	err := s.AddDayData(day, updated, confirmed, deaths)
+	if err != nil {
+		return false, err
+	}
+	s.Provisional = &Provisional{
+		Date:      s.StartsAt.AddDate(0, 0, day),
+		Since:     since,
+		Deaths:    deaths,
+		Confirmed: confirmed,
+		UpdatedAt: updated,
+	}
+	return true, nil
+}
+
+// setProvisional sets the provisional values for the day of s.Provisional, keeping the days it covers
+func (s *Series) setProvisional(confirmed, deaths int, updated time.Time) {
+	p := *s.Provisional
+	p.Confirmed, p.Deaths, p.UpdatedAt = confirmed, deaths, updated
+	s.Provisional = &p
+}
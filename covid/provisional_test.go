@@ -0,0 +1,56 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddProvisional(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start, Deaths: []int{1, 2}, Confirmed: []int{10, 20}}
+
+	// Official days are never replaced
+	ok, err := s.AddProvisional(1, time.Now(), 25, 3)
+	if ok || err != nil || s.Deaths[1] != 2 || s.Provisional != nil {
+		t.Fatalf("test: add provisional replaced official day got:%v", s.Deaths)
+	}
+
+	// Today is added and recorded as provisional
+	ok, err = s.AddProvisional(2, time.Now(), 30, 4)
+	if !ok || err != nil || len(s.Deaths) != 3 || s.Deaths[2] != 4 {
+		t.Fatalf("test: add provisional wanted:3 days got:%v error:%v", s.Deaths, err)
+	}
+	if s.Provisional == nil || !s.Provisional.Date.Equal(start.AddDate(0, 0, 2)) || s.Provisional.Confirmed != 30 {
+		t.Fatalf("test: add provisional wanted provisional for day 2 got:%v", s.Provisional)
+	}
+	if !s.IsProvisional(2) || s.IsProvisional(1) {
+		t.Fatalf("test: is provisional wrong for days 1 and 2")
+	}
+
+	// Later provisional values for the same day replace the earlier ones
+	ok, err = s.AddProvisional(2, time.Now(), 35, 5)
+	if !ok || err != nil || s.Deaths[2] != 5 || s.Provisional.Deaths != 5 {
+		t.Fatalf("test: add provisional wanted update got:%v", s.Deaths)
+	}
+
+	// Days missed in daily data are carried forward, and are provisional along with the latest day
+	ok, err = s.AddProvisional(5, time.Now(), 50, 8)
+	if !ok || err != nil || len(s.Deaths) != 6 || s.Deaths[4] != 5 || s.Provisional.Deaths != 8 {
+		t.Fatalf("test: add provisional wanted gap filled got:%v error:%v", s.Deaths, err)
+	}
+	for i := range s.Deaths {
+		if s.IsProvisional(i) != (i >= 2) {
+			t.Fatalf("test: is provisional wrong for day:%d", i)
+		}
+	}
+	if !s.Provisional.Since.Equal(start.AddDate(0, 0, 2)) || s.provisionalDay() != 5 {
+		t.Fatalf("test: add provisional wanted days 2 to 5 got:%v", s.Provisional)
+	}
+
+	// A series with no official days is provisional throughout
+	n := &Series{Country: "Nowhere", StartsAt: start}
+	n.AddProvisional(2, time.Now(), 1, 0)
+	if !n.IsProvisional(0) || !n.IsProvisional(2) || n.IsProvisional(3) {
+		t.Fatalf("test: add provisional wanted all days provisional got:%v", n.Provisional)
+	}
+}
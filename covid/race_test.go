@@ -0,0 +1,103 @@
+package covid
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// raceData returns a small dataset for race tests, with values scaled by n so that reloads change them
+func raceData(n int) SeriesSlice {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	var slice SeriesSlice
+	for _, country := range []string{"Italy", "Spain", "France", "Germany"} {
+		s := &Series{Country: country, StartsAt: start, Deaths: make([]int, 30), Confirmed: make([]int, 30)}
+		for d := range s.Deaths {
+			s.Deaths[d] = d * n
+			s.Confirmed[d] = d * n * 10
+		}
+		// The last day is provisional, as it would be after merging daily data
+		s.Provisional = &Provisional{Date: start.AddDate(0, 0, 29), Since: start.AddDate(0, 0, 29), Deaths: s.Deaths[29], Confirmed: s.Confirmed[29]}
+		s.UpdateDaily()
+		slice = append(slice, s)
+	}
+	return slice
+}
+
+// TestConcurrentAccess reloads and changes our stored data while reading it from many goroutines
+// run with go test -race to check the concurrency contract documented on mutex
+func TestConcurrentAccess(t *testing.T) {
+	mutex.Lock()
+	previousData, previousImports, previousVersion, previousChanges := data, imports, version, changes
+	replaceData(raceData(1))
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data, imports, version, changes = previousData, previousImports, previousVersion, previousChanges
+		mutex.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// Readers keep series after releasing the lock, and read every value
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				for _, country := range []string{"Italy", "Spain", ""} {
+					s, err := FetchSeries(country, "")
+					if err != nil {
+						continue
+					}
+					total := 0
+					for i := range s.Deaths {
+						total += s.Deaths[i] + s.Confirmed[i] + s.DeathsDaily[i] + s.ConfirmedDaily[i]
+					}
+					recent := s.Days(7)
+					for i := range recent.Deaths {
+						total += recent.Deaths[i]
+					}
+				}
+				CountryOptions()
+				ProvinceOptions("Italy")
+				Changes(0)
+			}
+		}()
+	}
+
+	// Writers reload the data, import corrections and merge daily rows
+	for n := 2; n < 20; n++ {
+		mutex.Lock()
+		replaceData(raceData(n))
+		mutex.Unlock()
+
+		// Give readers time to fetch the new series before changing them
+		time.Sleep(time.Millisecond)
+		Import([]Observation{{Country: "Spain", Date: "2020-01-30", Metric: MetricDeaths, Value: float64(n)}})
+		time.Sleep(time.Millisecond)
+
+		mutex.Lock()
+		_, err := data.mergeDailyIncremental([][]string{
+			{"Country_Region", "Last_Update", "Lat", "Long_", "Confirmed", "Deaths"},
+			{"Italy", "2020-02-20 10:00:00", "0", "0", "1000", "100"},
+		}, DataTodayCountry, 29)
+		mutex.Unlock()
+		if err != nil {
+			t.Fatalf("test: concurrent merge error:%s", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	s, err := FetchSeries("Italy", "")
+	if err != nil || s.Deaths[29] != 100 {
+		t.Fatalf("test: concurrent access wanted deaths:100 got:%v", s.Deaths)
+	}
+}
@@ -0,0 +1,153 @@
+package covid
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultTolerance is the fraction by which cumulative values may differ between datasets before they are flagged
+const DefaultTolerance = 0.05
+
+// reconcileMinValue is the minimum value compared, so that small early counts don't flag divergences
+const reconcileMinValue = 100
+
+// Reconciliation compares the series in two datasets for the dates they have in common
+type Reconciliation struct {
+	Dataset   string  `json:"dataset"`
+	Other     string  `json:"other"`
+	Tolerance float64 `json:"tolerance"`
+	// The number of series found in both datasets
+	Compared int `json:"compared"`
+	// Country level series in dataset which are not in other
+	Missing     []string     `json:"missing"`
+	Divergences []Divergence `json:"divergences"`
+}
+
+// Divergence is a series whose cumulative values for a metric differ between datasets by more than the tolerance
+type Divergence struct {
+	Country  string `json:"country"`
+	Province string `json:"province"`
+	Metric   string `json:"metric"`
+	// The first date values diverged, and the number of days they diverged on
+	Since string `json:"since"`
+	Days  int    `json:"days"`
+	// The values on the last date in common
+	Date    string  `json:"date"`
+	Value   int     `json:"value"`
+	Other   int     `json:"other"`
+	Percent float64 `json:"percent"`
+}
+
+// Reconcile compares the series in slice with those in other and returns the series which
+// diverge by more than tolerance (e.g. 0.05 for 5%) on any date in common
+func (slice SeriesSlice) Reconcile(other SeriesSlice, tolerance float64) *Reconciliation {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	r := &Reconciliation{Tolerance: tolerance, Missing: []string{}, Divergences: []Divergence{}}
+	for _, s := range slice {
+		if s.Global() {
+			continue
+		}
+		o, err := other.FetchSlug(s.Slug())
+		if err != nil {
+			if s.Province == "" {
+				r.Missing = append(r.Missing, s.Country)
+			}
+			continue
+		}
+		r.Compared++
+		for _, metric := range []string{MetricConfirmed, MetricDeaths} {
+			if d, ok := s.diverges(o, metric, tolerance); ok {
+				r.Divergences = append(r.Divergences, d)
+			}
+		}
+	}
+	return r
+}
+
+// diverges compares the cumulative values of metric in s with o for the dates they have in common
+func (s *Series) diverges(o *Series, metric string, tolerance float64) (Divergence, bool) {
+	d := Divergence{Country: s.Country, Province: s.Province, Metric: metric}
+	values, err := s.MetricValues(metric)
+	if err != nil {
+		return d, false
+	}
+	others, err := o.MetricValues(metric)
+	if err != nil {
+		return d, false
+	}
+
+	// Offset of the start of o within s, both series are daily from their start
+	offset := daysBetween(s.StartsAt, o.StartsAt)
+	last := -1
+	for i, v := range values {
+		j := i - offset
+		if j < 0 || j >= len(others) {
+			continue
+		}
+		last = i
+		if v < reconcileMinValue && others[j] < reconcileMinValue {
+			continue
+		}
+		if divergence(v, others[j]) > tolerance {
+			if d.Days == 0 {
+				d.Since = s.StartsAt.AddDate(0, 0, i).Format("2006-01-02")
+			}
+			d.Days++
+		}
+	}
+	if d.Days == 0 {
+		return d, false
+	}
+
+	d.Date = s.StartsAt.AddDate(0, 0, last).Format("2006-01-02")
+	d.Value = values[last]
+	d.Other = others[last-offset]
+	d.Percent = math.Round(divergence(d.Value, d.Other)*1000) / 10
+	return d, true
+}
+
+// divergence returns the difference between a and b as a fraction of the larger
+func divergence(a, b int) float64 {
+	larger := math.Max(math.Abs(float64(a)), math.Abs(float64(b)))
+	if larger == 0 {
+		return 0
+	}
+	return math.Abs(float64(a-b)) / larger
+}
+
+// Reconcile compares two datasets by name (DefaultDataset for our stored data)
+func Reconcile(dataset, other string, tolerance float64) (*Reconciliation, error) {
+	a, err := datasetData(dataset)
+	if err != nil {
+		return nil, err
+	}
+	b, err := datasetData(other)
+	if err != nil {
+		return nil, err
+	}
+	r := a.Reconcile(b, tolerance)
+	r.Dataset, r.Other = dataset, other
+	return r, nil
+}
+
+// datasetData returns the series for the dataset with name
+// loads replace the slice rather than altering it, so it is safe to read after the lock is released
+func datasetData(name string) (SeriesSlice, error) {
+	if name == DefaultDataset {
+		mutex.RLock()
+		defer mutex.RUnlock()
+		return data, nil
+	}
+	d, err := FetchDataset(name)
+	if err != nil {
+		return nil, err
+	}
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if d.data == nil {
+		return nil, fmt.Errorf("dataset: not loaded:%s", name)
+	}
+	return d.data, nil
+}
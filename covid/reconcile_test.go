@@ -0,0 +1,35 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconcile(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: []int{100, 200, 300}, Confirmed: []int{1000, 2000, 3000}},
+		{Country: "Spain", StartsAt: start, Deaths: []int{100, 200, 300}, Confirmed: []int{1000, 2000, 3000}},
+		{Country: "France", StartsAt: start, Deaths: []int{1, 2, 3}, Confirmed: []int{10, 20, 30}},
+	}
+	// Other starts a day later, Italy deaths diverge on the last day only
+	other := SeriesSlice{
+		{Country: "Italy", StartsAt: start.AddDate(0, 0, 1), Deaths: []int{200, 400}, Confirmed: []int{2000, 3050}},
+		{Country: "Spain", StartsAt: start, Deaths: []int{101, 201, 301}, Confirmed: []int{1000, 2000, 3000}},
+	}
+	for _, s := range append(slice, other...) {
+		s.slug = Slug(s.Country)
+	}
+
+	r := slice.Reconcile(other, 0.05)
+	if r.Compared != 2 || len(r.Missing) != 1 || r.Missing[0] != "France" {
+		t.Fatalf("test: reconcile wanted compared:2 missing:[France] got:%d %v", r.Compared, r.Missing)
+	}
+	if len(r.Divergences) != 1 {
+		t.Fatalf("test: reconcile wanted 1 divergence got:%v", r.Divergences)
+	}
+	d := r.Divergences[0]
+	if d.Country != "Italy" || d.Metric != MetricDeaths || d.Since != "2020-03-03" || d.Days != 1 || d.Value != 300 || d.Other != 400 || d.Percent != 25 {
+		t.Fatalf("test: reconcile wrong divergence got:%+v", d)
+	}
+}
@@ -0,0 +1,94 @@
+package covid
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Continents returns the names of the continents used for regions
+func Continents() []string {
+	return []string{Africa, Asia, Europe, NorthAmerica, Oceania, SouthAmerica}
+}
+
+// FetchRegion uses our stored data to build the series for a continent
+func FetchRegion(continent string) (*Series, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.Region(continent)
+}
+
+// Region returns a series summing the countries in continent (name or key)
+// only countries with a known population are included, and the population of the series is their total,
+// so that per capita values for the region are population-weighted averages of its countries
+func (slice SeriesSlice) Region(continent string) (*Series, error) {
+	name := ""
+	for _, c := range Continents() {
+		if Slug(c) == Slug(continent) {
+			name = c
+		}
+	}
+	if name == "" {
+		return &Series{}, fmt.Errorf("series: no such region:%s", continent)
+	}
+
+	groups := slice.GroupBy(func(s *Series) string {
+		if s.Province != "" || s.Global() || s.Population() == 0 {
+			return ""
+		}
+		return s.Continent()
+	})
+	for _, g := range groups {
+		if g.Country == name {
+			return g, nil
+		}
+	}
+	return &Series{}, fmt.Errorf("series: no data for region:%s", name)
+}
+
+// GroupBy returns a series for each group of series in slice with the same key, summing their data
+// series with a blank key are left out, and the country of each group series is its key
+// the population of a group is the total for its series, so key should exclude series
+// without a known population where per capita values are needed
+func (slice SeriesSlice) GroupBy(key func(*Series) string) SeriesSlice {
+	members := make(map[string]SeriesSlice)
+	var keys []string
+	for _, s := range slice {
+		k := key(s)
+		if k == "" {
+			continue
+		}
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = append(members[k], s)
+	}
+	sort.Strings(keys)
+
+	var groups SeriesSlice
+	for _, k := range keys {
+		groups = append(groups, aggregate(k, members[k]))
+	}
+	return groups
+}
+
+// aggregate returns a series named name which sums the data of list
+func aggregate(name string, list SeriesSlice) *Series {
+	group := &Series{Country: name}
+	days := 0
+	for _, s := range list {
+		group.population += s.Population()
+		if len(s.Deaths) > days {
+			days = len(s.Deaths)
+			group.StartsAt = s.StartsAt
+		}
+		if s.UpdatedAt.After(group.UpdatedAt) {
+			group.UpdatedAt = s.UpdatedAt
+		}
+	}
+
+	// Series may differ in length, so make space for the longest before summing
+	group.Deaths = make([]int, days)
+	group.Confirmed = make([]int, days)
+	group.sum(list)
+	return group
+}
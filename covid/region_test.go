@@ -0,0 +1,60 @@
+package covid
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRegion(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		&Series{Country: "Italy", StartsAt: start, Deaths: []int{1, 2}, Confirmed: []int{100, 200}},
+		&Series{Country: "Spain", StartsAt: start, Deaths: []int{3, 4}, Confirmed: []int{300, 400}},
+		&Series{Country: "Spain", Province: "Madrid", StartsAt: start, Deaths: []int{1, 1}, Confirmed: []int{10, 10}},
+		&Series{Country: "China", StartsAt: start, Deaths: []int{5, 6}, Confirmed: []int{500, 600}},
+	}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	region, err := slice.Region("europe")
+	if err != nil {
+		t.Fatalf("test: region error:%s", err)
+	}
+	population := slice[0].Population() + slice[1].Population()
+	if region.Title() != Europe || region.TotalConfirmed() != 600 || region.Population() != population {
+		t.Fatalf("test: region wanted:600 got:%d population:%d", region.TotalConfirmed(), region.Population())
+	}
+
+	// Per capita values are weighted by population, not an average of the country rates
+	want := 600 * 1000000 / float64(population)
+	if math.Abs(region.ConfirmedPerMillion()-want) > 0.01 {
+		t.Fatalf("test: region per million wanted:%.2f got:%.2f", want, region.ConfirmedPerMillion())
+	}
+
+	if _, err := slice.Region("atlantis"); err == nil {
+		t.Fatalf("test: region wanted error for unknown region")
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		&Series{Country: "Italy", StartsAt: start, Deaths: []int{1, 2}, Confirmed: []int{100, 200}},
+		&Series{Country: "Spain", StartsAt: start, Deaths: []int{3, 4}, Confirmed: []int{300, 400}},
+		&Series{Country: "China", StartsAt: start, Deaths: []int{5, 6}, Confirmed: []int{500, 600}},
+		&Series{Country: "Atlantis", StartsAt: start, Deaths: []int{7, 8}, Confirmed: []int{700, 800}},
+	}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	groups := slice.GroupBy(func(s *Series) string { return s.Continent() })
+	if len(groups) != 2 || groups[0].Country != Asia || groups[1].Country != Europe {
+		t.Fatalf("test: group by wanted:Asia,Europe got:%d groups", len(groups))
+	}
+	if groups[1].TotalDeaths() != 6 || groups[1].DeathsDaily[1] != 2 {
+		t.Fatalf("test: group by wanted:6 deaths got:%d", groups[1].TotalDeaths())
+	}
+}
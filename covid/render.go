@@ -0,0 +1,290 @@
+package covid
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+)
+
+// Trend directions returned by Series.Trend
+const (
+	TrendFalling = -1
+	TrendFlat    = 0
+	TrendRising  = 1
+)
+
+// trendDays is the window used to compare recent daily figures against the previous period
+const trendDays = 7
+
+// DailyValues returns the daily values for the given datum (DataDeaths or DataConfirmed)
+func (s *Series) DailyValues(datum int) []int {
+	switch datum {
+	case DataDeaths:
+		return s.DeathsDaily
+	case DataConfirmed:
+		return s.ConfirmedDaily
+	}
+	return nil
+}
+
+// Trend compares the last 7 days of daily values with the 7 days before that
+// and returns TrendRising, TrendFalling or TrendFlat
+func (s *Series) Trend(datum int) int {
+	windows := s.Window(datum, trendDays, trendDays)
+	if len(windows) < 2 {
+		return TrendFlat
+	}
+	recent := windows[len(windows)-1].Sum
+	previous := windows[len(windows)-2].Sum
+	switch {
+	case recent > previous:
+		return TrendRising
+	case recent < previous:
+		return TrendFalling
+	}
+	return TrendFlat
+}
+
+// TrendArrow returns an arrow representing the trend for the given datum
+func (s *Series) TrendArrow(datum int) string {
+	switch s.Trend(datum) {
+	case TrendRising:
+		return "▲"
+	case TrendFalling:
+		return "▼"
+	}
+	return "▶"
+}
+
+// DeathsPerMillion returns total deaths per million population, or 0 if population is unknown
+func (s *Series) DeathsPerMillion() float64 {
+	return perCapita(s.TotalDeaths(), s.Population(), 1000000)
+}
+
+// ConfirmedPerMillion returns total confirmed cases per million population, or 0 if population is unknown
+func (s *Series) ConfirmedPerMillion() float64 {
+	return perCapita(s.TotalConfirmed(), s.Population(), 1000000)
+}
+
+// incidenceDays is the window used for incidence, as used by many governments for travel rules
+const incidenceDays = 14
+
+// Incidence14Per100k returns the confirmed cases in the last 14 days per 100k population
+// or 0 if population is unknown
+func (s *Series) Incidence14Per100k() float64 {
+	return perCapita(s.TotalIn(DataConfirmed, incidenceDays), s.Population(), 100000)
+}
+
+// Incidence14Values returns the 14 day incidence per 100k for each day in the series, rounded
+// days before the first 14 use the cases so far, so use the full series rather than a copy from Days
+func (s *Series) Incidence14Values() []int {
+	values := make([]int, len(s.Confirmed))
+	population := s.Population()
+	for i, v := range s.Confirmed {
+		if i >= incidenceDays {
+			v -= s.Confirmed[i-incidenceDays]
+		}
+		values[i] = int(math.Round(perCapita(v, population, 100000)))
+	}
+	return values
+}
+
+// perCapita returns value per unit of population
+func perCapita(value, population, unit int) float64 {
+	if population <= 0 {
+		return 0
+	}
+	return float64(value) / float64(population) * float64(unit)
+}
+
+// Smooth returns the trailing average of values over days for each day, rounded
+// the first days average over the values available so far
+func Smooth(values []int, days int) []int {
+	smoothed := make([]int, len(values))
+	for i, v := range SmoothFloat(values, days) {
+		smoothed[i] = int(math.Round(v))
+	}
+	return smoothed
+}
+
+// SmoothFloat returns the trailing average of values over days for each day
+// the first days average over the values available so far
+func SmoothFloat(values []int, days int) []float64 {
+	smoothed := make([]float64, len(values))
+	for i := range values {
+		start := i - days + 1
+		if start < 0 {
+			start = 0
+		}
+		smoothed[i] = float64(sumInts(values[start:i+1])) / float64(i+1-start)
+	}
+	return smoothed
+}
+
+// AccelerationValues returns the acceleration in daily confirmed cases for each day
+// this is the day over day change in the growth of the 7 day average, so positive values mean growth is speeding up
+func (s *Series) AccelerationValues() []float64 {
+	smoothed := SmoothFloat(s.ConfirmedDaily, trendDays)
+	acceleration := make([]float64, len(smoothed))
+	for i := 2; i < len(smoothed); i++ {
+		growth := smoothed[i] - smoothed[i-1]
+		previous := smoothed[i-1] - smoothed[i-2]
+		acceleration[i] = growth - previous
+	}
+	return acceleration
+}
+
+// Acceleration returns the latest acceleration in daily confirmed cases, in cases per day per day
+func (s *Series) Acceleration() float64 {
+	acceleration := s.AccelerationValues()
+	if len(acceleration) == 0 {
+		return 0
+	}
+	return acceleration[len(acceleration)-1]
+}
+
+// sumInts returns the sum of the values in ints
+func sumInts(ints []int) (sum int) {
+	for _, v := range ints {
+		sum += v
+	}
+	return sum
+}
+
+// Countries returns only the country level series (excluding provinces and the global series)
+func (slice SeriesSlice) Countries() (countries SeriesSlice) {
+	for _, s := range slice {
+		if s.Province == "" && s.Country != "" && !s.Hidden {
+			countries = append(countries, s)
+		}
+	}
+	return countries
+}
+
+// Top returns the first n countries in the slice, which is sorted by deaths
+func (slice SeriesSlice) Top(n int) SeriesSlice {
+	countries := slice.Countries()
+	if n > 0 && n < len(countries) {
+		countries = countries[:n]
+	}
+	return countries
+}
+
+// todayValue returns the last daily value of ints or 0 if empty
+func todayValue(ints []int) int {
+	if len(ints) == 0 {
+		return 0
+	}
+	return ints[len(ints)-1]
+}
+
+// WriteHTMLTable writes a sortable html table of the top n countries to w
+// cells carry a data-value attribute with the raw value for sorting
+func (slice SeriesSlice) WriteHTMLTable(w io.Writer, n int) error {
+	b := &strings.Builder{}
+
+	b.WriteString("<table class=\"covid-table sortable\">\n<thead>\n<tr>")
+	for _, h := range []string{"Country", "Confirmed", "Confirmed Today", "Deaths", "Deaths Today", "Deaths/M", "Acceleration", "Trend", "28 Days"} {
+		fmt.Fprintf(b, "<th>%s</th>", h)
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	for _, s := range slice.Top(n) {
+		b.WriteString("<tr>")
+		fmt.Fprintf(b, "<td data-value=\"%s\">%s</td>", html.EscapeString(s.Country), html.EscapeString(strings.TrimSpace(s.Flag()+" "+s.Country)))
+		writeHTMLCell(b, s.TotalConfirmed(), s.Format(s.TotalConfirmed()))
+		writeHTMLCell(b, todayValue(s.ConfirmedDaily), s.Format(todayValue(s.ConfirmedDaily)))
+		writeHTMLCell(b, s.TotalDeaths(), s.Format(s.TotalDeaths()))
+		writeHTMLCell(b, todayValue(s.DeathsDaily), s.Format(todayValue(s.DeathsDaily)))
+		fmt.Fprintf(b, "<td data-value=\"%.2f\">%.1f</td>", s.DeathsPerMillion(), s.DeathsPerMillion())
+		fmt.Fprintf(b, "<td data-value=\"%.2f\">%+.1f</td>", s.Acceleration(), s.Acceleration())
+		writeHTMLCell(b, s.Trend(DataDeaths), s.TrendArrow(DataDeaths))
+		fmt.Fprintf(b, "<td data-value=\"%d\">%s</td>", s.Trend(DataDeaths), s.Sparkline(DataDeaths, sparklineDays, sparklineWidth, sparklineHeight))
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("</tbody>\n</table>\n")
+	b.WriteString(tableSortScript)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeHTMLCell writes one table cell with a raw sort value and display value
+func writeHTMLCell(b *strings.Builder, value int, display string) {
+	fmt.Fprintf(b, "<td data-value=\"%d\">%s</td>", value, html.EscapeString(display))
+}
+
+// WriteMarkdownTable writes a markdown table of the top n countries to w
+func (slice SeriesSlice) WriteMarkdownTable(w io.Writer, n int) error {
+	b := &strings.Builder{}
+
+	b.WriteString("| Country | Confirmed | Confirmed Today | Deaths | Deaths Today | Deaths/M | Trend |\n")
+	b.WriteString("|:--|--:|--:|--:|--:|--:|:-:|\n")
+
+	for _, s := range slice.Top(n) {
+		// Escape pipes which would break the table
+		name := strings.Replace(strings.TrimSpace(s.Flag()+" "+s.Country), "|", "\\|", -1)
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s | %.1f | %s |\n",
+			name,
+			s.Format(s.TotalConfirmed()),
+			s.Format(todayValue(s.ConfirmedDaily)),
+			s.Format(s.TotalDeaths()),
+			s.Format(todayValue(s.DeathsDaily)),
+			s.DeathsPerMillion(),
+			s.TrendArrow(DataDeaths))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTMLTable uses our stored data to write an html table of the top n countries
+// the table is rendered under the lock and written after releasing it, so that a slow client doesn't hold up loads
+func WriteHTMLTable(w io.Writer, n int) error {
+	b := &bytes.Buffer{}
+	mutex.RLock()
+	err := data.WriteHTMLTable(b, n)
+	mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = b.WriteTo(w)
+	return err
+}
+
+// WriteMarkdownTable uses our stored data to write a markdown table of the top n countries
+// the table is rendered under the lock and written after releasing it, so that a slow client doesn't hold up loads
+func WriteMarkdownTable(w io.Writer, n int) error {
+	b := &bytes.Buffer{}
+	mutex.RLock()
+	err := data.WriteMarkdownTable(b, n)
+	mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = b.WriteTo(w)
+	return err
+}
+
+// tableSortScript sorts tables with class sortable when a header is clicked
+const tableSortScript = `<script>
+document.querySelectorAll("table.sortable th").forEach(function(th, col) {
+    th.addEventListener("click", function() {
+        var tbody = th.closest("table").querySelector("tbody");
+        var rows = Array.from(tbody.querySelectorAll("tr"));
+        var asc = th.dataset.order != "asc";
+        th.dataset.order = asc ? "asc" : "desc";
+        rows.sort(function(a, b) {
+            var x = a.children[col].dataset.value, y = b.children[col].dataset.value;
+            var d = isNaN(x) || isNaN(y) ? x.localeCompare(y) : x - y;
+            return asc ? d : -d;
+        });
+        rows.forEach(function(r) { tbody.appendChild(r); });
+    });
+});
+</script>
+`
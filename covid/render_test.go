@@ -0,0 +1,147 @@
+package covid
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTables(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: []int{1, 3, 6}, Confirmed: []int{10, 20, 40}},
+		{Country: "Pipe|Land", StartsAt: start, Deaths: []int{0, 1, 2}, Confirmed: []int{5, 6, 7}},
+		{Country: "Spain", StartsAt: start, Deaths: []int{0, 0, 1}, Confirmed: []int{1, 2, 3}},
+	}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	b := &bytes.Buffer{}
+	err := slice.WriteMarkdownTable(b, 2)
+	if err != nil {
+		t.Fatalf("test: markdown table error:%s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[2], "Italy | 40 | 20 | 6 | 3 |") || !strings.Contains(lines[3], `Pipe\|Land`) {
+		t.Fatalf("test: markdown table wrong rows got:\n%s", b.String())
+	}
+
+	b.Reset()
+	err = slice.WriteHTMLTable(b, 0)
+	if err != nil {
+		t.Fatalf("test: html table error:%s", err)
+	}
+	table := b.String()
+	if strings.Count(table, "<tr>") != 4 || !strings.Contains(table, `<td data-value="40">40</td>`) || !strings.Contains(table, "Pipe|Land") {
+		t.Fatalf("test: html table wrong rows got:\n%s", table)
+	}
+
+	// The stored tables are written after releasing the lock, so a writer which needs the lock doesn't deadlock
+	mutex.Lock()
+	previous := data
+	data = slice
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data = previous
+		mutex.Unlock()
+	}()
+	w := &lockingWriter{}
+	if err := WriteHTMLTable(w, 0); err != nil || !strings.Contains(w.String(), "Pipe|Land") {
+		t.Fatalf("test: stored html table wrong error:%v got:\n%s", err, w.String())
+	}
+	w.Reset()
+	if err := WriteMarkdownTable(w, 2); err != nil || !strings.Contains(w.String(), "Italy | 40") {
+		t.Fatalf("test: stored markdown table wrong error:%v got:\n%s", err, w.String())
+	}
+}
+
+// lockingWriter takes the data lock on each write, as a load would while a client is slow to read
+type lockingWriter struct {
+	bytes.Buffer
+}
+
+func (w *lockingWriter) Write(b []byte) (int, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return w.Buffer.Write(b)
+}
+
+func TestAcceleration(t *testing.T) {
+	// Once the 7 day average is full, quadratic daily cases have a constant second difference of twice the coefficient
+	quadratic := make([]int, 20)
+	for i := range quadratic {
+		quadratic[i] = 3 * i * i
+	}
+	s := seriesFromDaily("Italy", "", quadratic...)
+	values := s.AccelerationValues()
+	if len(values) != 20 || values[0] != 0 || values[1] != 0 {
+		t.Fatalf("test: acceleration wanted 20 values starting 0,0 got:%v", values)
+	}
+	for i := 8; i < len(values); i++ {
+		if math.Abs(values[i]-6) > 1e-9 {
+			t.Fatalf("test: acceleration day:%d wanted:6 got:%v", i, values[i])
+		}
+	}
+	if math.Abs(s.Acceleration()-6) > 1e-9 {
+		t.Fatalf("test: acceleration wanted:6 got:%v", s.Acceleration())
+	}
+
+	// Linear growth doesn't accelerate, and falling growth decelerates
+	if a := seriesFromDaily("Spain", "", 1, 2, 3, 4, 5, 6, 7, 8, 9, 10).Acceleration(); math.Abs(a) > 1e-9 {
+		t.Fatalf("test: acceleration linear wanted:0 got:%v", a)
+	}
+	if a := seriesFromDaily("Spain", "", 0, 0, 0, 0, 0, 0, 0, 70, 70, 0).Acceleration(); a >= 0 {
+		t.Fatalf("test: acceleration falling wanted negative got:%v", a)
+	}
+
+	// Series too short for a second difference have none, three days have one from the average so far
+	for _, daily := range [][]int{nil, {5}, {5, 10}} {
+		if a := seriesFromDaily("France", "", daily...).Acceleration(); a != 0 {
+			t.Fatalf("test: acceleration for %v wanted:0 got:%v", daily, a)
+		}
+	}
+	if a := seriesFromDaily("France", "", 1, 2, 4).Acceleration(); math.Abs(a-1.0/3) > 1e-9 {
+		t.Fatalf("test: acceleration for 3 days wanted:0.33 got:%v", a)
+	}
+}
+
+func TestIncidence14(t *testing.T) {
+	daily := make([]int, 20)
+	for i := range daily {
+		daily[i] = 10
+	}
+	daily[19] = 80
+	s := seriesFromDaily("Italy", "", daily...)
+	s.population = 1000000
+
+	// The last 14 days have 13*10+80 cases, per 100k of a population of 1m
+	if v := s.Incidence14Per100k(); math.Abs(v-21) > 1e-9 {
+		t.Fatalf("test: incidence wanted:21 got:%v", v)
+	}
+	values := s.Incidence14Values()
+	if len(values) != 20 || values[4] != 5 || values[13] != 14 || values[18] != 14 || values[19] != 21 {
+		t.Fatalf("test: incidence values wrong got:%v", values)
+	}
+
+	// A series shorter than 14 days uses the cases so far
+	short := seriesFromDaily("Italy", "", 10, 20, 30)
+	short.population = 100000
+	if v := short.Incidence14Per100k(); math.Abs(v-60) > 1e-9 {
+		t.Fatalf("test: incidence short series wanted:60 got:%v", v)
+	}
+
+	// Populations come from country metadata, and without one incidence is 0
+	s = seriesFromDaily("Italy", "", daily...)
+	if v, want := s.Incidence14Per100k(), 210/float64(s.Meta().Population)*100000; s.Population() == 0 || math.Abs(v-want) > 1e-9 {
+		t.Fatalf("test: incidence italy wanted:%v got:%v", want, v)
+	}
+	for _, s := range []*Series{seriesFromDaily("Atlantis", "", daily...), seriesFromDaily("Italy", "Lombardy", daily...)} {
+		if v := s.Incidence14Per100k(); v != 0 || s.Incidence14Values()[19] != 0 {
+			t.Fatalf("test: incidence for %s %s without population wanted:0 got:%v", s.Country, s.Province, v)
+		}
+	}
+}
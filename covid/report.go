@@ -0,0 +1,187 @@
+package covid
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Recovery modes for invalid values in time series csv files
+const (
+	// RecoverCell carries forward the previous value for the day in place of an invalid value
+	RecoverCell = iota
+	// RecoverRow skips the series for a row with an invalid value
+	RecoverRow
+	// RecoverNone aborts the load on an invalid value
+	RecoverNone
+)
+
+// recoveryMode is the recovery mode used when loading time series data
+var recoveryMode = RecoverCell
+
+// SetRecoveryMode sets the recovery mode used for invalid values in time series data
+func SetRecoveryMode(mode int) error {
+	switch mode {
+	case RecoverCell, RecoverRow, RecoverNone:
+	default:
+		return fmt.Errorf("load: invalid recovery mode:%d", mode)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	recoveryMode = mode
+	return nil
+}
+
+// LoadReport records the files read and any problems found during the last load of data
+type LoadReport struct {
+	StartedAt time.Time     `json:"started_at"`
+	Files     []string      `json:"files"`
+	Skipped   []SkippedData `json:"skipped"`
+	// Sources which failed to load, whose series or metrics are stale
+	Failed []FailedSource `json:"failed"`
+	// Duplicate series merged on load, or which look like duplicates
+	Duplicates []Duplicate `json:"duplicates"`
+	// Countries which differ from the sum of their provinces
+	Rollups []Rollup `json:"rollups"`
+	// Days missing from the time series which were filled from daily reports, and those with no report yet
+	Backfilled []string `json:"backfilled"`
+	Gaps       []string `json:"gaps"`
+
+	// Statistics for the load, so that changes in upstream data size or parser performance are visible
+	RowsRead      int         `json:"rows_read"`
+	RowsSkipped   int         `json:"rows_skipped"`
+	SeriesCreated int         `json:"series_created"`
+	Duration      float64     `json:"duration_ms"`
+	FileStats     []FileStats `json:"file_stats"`
+	Phases        []LoadPhase `json:"phases"`
+
+	// The file currently being loaded, and when its load started
+	file      string
+	fileStart time.Time
+	// The start of the current phase
+	phaseStart time.Time
+}
+
+// FileStats records the rows read from a file during a load, and the time taken to read and merge them
+type FileStats struct {
+	File     string  `json:"file"`
+	Rows     int     `json:"rows"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// LoadPhase records the time taken by one phase of a load e.g. reading time series files
+type LoadPhase struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// SkippedData records an invalid value or row skipped during a load
+// Column is 0 if the whole row was skipped
+type SkippedData struct {
+	File     string `json:"file"`
+	Row      int    `json:"row"`
+	Column   int    `json:"column"`
+	Country  string `json:"country"`
+	Province string `json:"province"`
+	Value    string `json:"value"`
+	Error    string `json:"error"`
+}
+
+// report is the report for the current or last load, protected by mutex
+var report = &LoadReport{}
+
+// FetchLoadReport returns a copy of the report for the last load of data
+func FetchLoadReport() LoadReport {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	r := *report
+	r.Files = append([]string(nil), report.Files...)
+	r.Skipped = append([]SkippedData(nil), report.Skipped...)
+	r.Failed = append([]FailedSource(nil), report.Failed...)
+	r.Duplicates = append([]Duplicate(nil), report.Duplicates...)
+	r.Rollups = append([]Rollup(nil), report.Rollups...)
+	r.Backfilled = append([]string(nil), report.Backfilled...)
+	r.Gaps = append([]string(nil), report.Gaps...)
+	r.FileStats = append([]FileStats(nil), report.FileStats...)
+	r.Phases = append([]LoadPhase(nil), report.Phases...)
+	return r
+}
+
+// setFile records that file is being loaded
+func (r *LoadReport) setFile(file string) {
+	r.file = file
+	r.fileStart = time.Now()
+	r.Files = append(r.Files, file)
+}
+
+// read records the rows read from the file being loaded, once it has been merged
+func (r *LoadReport) read(rows int) {
+	r.RowsRead += rows
+	r.FileStats = append(r.FileStats, FileStats{File: r.file, Rows: rows, Duration: milliseconds(time.Since(r.fileStart))})
+}
+
+// phase records the end of the phase with name, which started at the end of the previous phase or the load
+func (r *LoadReport) phase(name string) {
+	if r.phaseStart.IsZero() {
+		r.phaseStart = r.StartedAt
+	}
+	now := time.Now()
+	r.Phases = append(r.Phases, LoadPhase{Name: name, Duration: milliseconds(now.Sub(r.phaseStart))})
+	r.phaseStart = now
+}
+
+// finish records the totals for a load which produced slice
+func (r *LoadReport) finish(slice SeriesSlice) {
+	r.SeriesCreated = len(slice)
+	r.RowsSkipped = 0
+	for _, sk := range r.Skipped {
+		if sk.Column == 0 {
+			r.RowsSkipped++
+		}
+	}
+	r.Duration = milliseconds(time.Since(r.StartedAt))
+
+	phases := make([]string, len(r.Phases))
+	for i, p := range r.Phases {
+		phases[i] = fmt.Sprintf("%s:%.0fms", p.Name, p.Duration)
+	}
+	log.Printf("load: read rows:%d skipped rows:%d series:%d phases:%s", r.RowsRead, r.RowsSkipped, r.SeriesCreated, strings.Join(phases, " "))
+}
+
+// milliseconds returns d in milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// skip records that a value or row was skipped in the file being loaded
+func (r *LoadReport) skip(row, column int, country, province, value string, err error) {
+	r.Skipped = append(r.Skipped, SkippedData{
+		File:     r.file,
+		Row:      row,
+		Column:   column,
+		Country:  country,
+		Province: province,
+		Value:    value,
+		Error:    err.Error(),
+	})
+}
+
+// dropSkipped removes series from slice for which a row was skipped
+// a series is dropped rather than kept with only some of its data
+func (slice SeriesSlice) dropSkipped(r *LoadReport) SeriesSlice {
+	var kept SeriesSlice
+	for _, s := range slice {
+		skipped := false
+		for _, sk := range r.Skipped {
+			if sk.Column == 0 && s.Match(sk.Country, sk.Province) {
+				skipped = true
+				break
+			}
+		}
+		if !skipped {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
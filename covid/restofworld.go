@@ -0,0 +1,101 @@
+package covid
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RestOfWorldPrefix is the prefix of keys for rest of world series, e.g. world-excluding-china or world-excluding-top-5
+const RestOfWorldPrefix = "world-excluding-"
+
+// maxRestOfWorldTop limits the number of top countries which may be excluded from a rest of world series
+const maxRestOfWorldTop = 50
+
+// ParseRestOfWorld parses a rest of world key, returning the country excluded (if any) and the number of top countries
+// excluded, ok is false if key is not a rest of world key
+func ParseRestOfWorld(key string) (excluded []string, top int, ok bool) {
+	key = Slug(key)
+	if !strings.HasPrefix(key, RestOfWorldPrefix) || key == RestOfWorldPrefix {
+		return nil, 0, false
+	}
+	key = strings.TrimPrefix(key, RestOfWorldPrefix)
+	if strings.HasPrefix(key, "top-") {
+		n, err := strconv.Atoi(strings.TrimPrefix(key, "top-"))
+		if err == nil {
+			return nil, n, true
+		}
+	}
+	return []string{key}, 0, true
+}
+
+// FetchRestOfWorld uses our stored data to build a rest of world series
+func FetchRestOfWorld(excluded []string, top int) (*Series, []string, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.RestOfWorld(excluded, top)
+}
+
+// RestOfWorld returns a series summing the countries in slice except those in excluded (name or key)
+// and the top countries by deaths, with the names of the countries excluded, e.g. World excluding China
+// only countries with a known population are included, as for regions, so that per capita values are population-weighted
+func (slice SeriesSlice) RestOfWorld(excluded []string, top int) (*Series, []string, error) {
+	if top < 0 || top > maxRestOfWorldTop {
+		return &Series{}, nil, fmt.Errorf("series: invalid top countries:%d", top)
+	}
+	if top == 0 && len(excluded) == 0 {
+		return &Series{}, nil, fmt.Errorf("series: no countries excluded")
+	}
+
+	var countries SeriesSlice
+	for _, s := range slice {
+		if s.Province == "" && !s.Global() && !s.Hidden && s.Population() > 0 {
+			countries = append(countries, s)
+		}
+	}
+
+	// Exclude the top countries first, so that names are listed in order of deaths
+	skip := make(map[*Series]bool)
+	var names []string
+	if top > 0 {
+		ranked := append(SeriesSlice(nil), countries...)
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].TotalDeaths() > ranked[j].TotalDeaths() })
+		if top > len(ranked) {
+			top = len(ranked)
+		}
+		for _, s := range ranked[:top] {
+			skip[s] = true
+			names = append(names, s.Country)
+		}
+	}
+	for _, name := range excluded {
+		s, err := countries.FetchSeries(name, "")
+		if err != nil {
+			return &Series{}, nil, fmt.Errorf("series: no such country:%s", name)
+		}
+		if !skip[s] {
+			skip[s] = true
+			names = append(names, s.Country)
+		}
+	}
+
+	var list SeriesSlice
+	for _, s := range countries {
+		if !skip[s] {
+			list = append(list, s)
+		}
+	}
+	if len(list) == 0 {
+		return &Series{}, nil, fmt.Errorf("series: no countries left in rest of world")
+	}
+
+	title := strings.Join(names, ", ")
+	if top > 0 {
+		title = fmt.Sprintf("top %d", top)
+		if len(names) > top {
+			title += ", " + strings.Join(names[top:], ", ")
+		}
+	}
+	return aggregate("World excluding "+title, list), names, nil
+}
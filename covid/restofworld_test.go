@@ -0,0 +1,57 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestOfWorld(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		&Series{Country: "China", StartsAt: start, Deaths: []int{5, 60}, Confirmed: []int{500, 600}},
+		&Series{Country: "Italy", StartsAt: start, Deaths: []int{1, 20}, Confirmed: []int{100, 200}},
+		&Series{Country: "Spain", StartsAt: start, Deaths: []int{3, 4}, Confirmed: []int{300, 400}},
+		&Series{Country: "France", StartsAt: start, Deaths: []int{0, 0}, Confirmed: []int{0, 10}},
+		&Series{Country: "Spain", Province: "Madrid", StartsAt: start, Deaths: []int{1, 1}, Confirmed: []int{10, 10}},
+		&Series{StartsAt: start, Deaths: []int{9, 84}, Confirmed: []int{900, 1200}},
+	}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	s, names, err := slice.RestOfWorld([]string{"china"}, 0)
+	if err != nil {
+		t.Fatalf("test: rest of world error:%s", err)
+	}
+	population := slice[1].Population() + slice[2].Population() + slice[3].Population()
+	if s.Title() != "World excluding China" || s.TotalDeaths() != 24 || s.Population() != population || len(names) != 1 {
+		t.Fatalf("test: rest of world wanted deaths:24 got:%s %d population:%d", s.Title(), s.TotalDeaths(), s.Population())
+	}
+
+	s, names, err = slice.RestOfWorld([]string{"Spain"}, 2)
+	if err != nil {
+		t.Fatalf("test: rest of world error:%s", err)
+	}
+	if s.Title() != "World excluding top 2, Spain" || len(names) != 3 || names[0] != "China" || names[1] != "Italy" || s.TotalConfirmed() != 10 {
+		t.Fatalf("test: rest of world wrong exclusions got:%s %v", s.Title(), names)
+	}
+
+	if _, _, err := slice.RestOfWorld([]string{"atlantis"}, 0); err == nil {
+		t.Fatalf("test: rest of world wanted error for unknown country")
+	}
+	if _, _, err := slice.RestOfWorld(nil, 0); err == nil {
+		t.Fatalf("test: rest of world wanted error for no exclusions")
+	}
+
+	excluded, top, ok := ParseRestOfWorld("world-excluding-top-5")
+	if !ok || top != 5 || len(excluded) != 0 {
+		t.Fatalf("test: parse rest of world wanted top:5 got:%d %v", top, excluded)
+	}
+	excluded, _, ok = ParseRestOfWorld("world-excluding-united-kingdom")
+	if !ok || len(excluded) != 1 || excluded[0] != "united-kingdom" {
+		t.Fatalf("test: parse rest of world wanted united-kingdom got:%v", excluded)
+	}
+	if _, _, ok := ParseRestOfWorld("europe"); ok {
+		t.Fatalf("test: parse rest of world accepted europe")
+	}
+}
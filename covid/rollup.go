@@ -0,0 +1,125 @@
+package covid
+
+import (
+	"log"
+	"sort"
+)
+
+// rollupRecompute replaces country values with the sum of their provinces when they diverge, set on startup
+var rollupRecompute bool
+
+// SetRollupRecompute sets whether country series which diverge from the sum of their provinces
+// are replaced by that sum on load, this must be called before LoadData
+func SetRollupRecompute(recompute bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	rollupRecompute = recompute
+}
+
+// Rollup compares a country series with the sum of its provinces
+type Rollup struct {
+	Country string `json:"country"`
+	// The number of provinces summed
+	Provinces   int          `json:"provinces"`
+	Divergences []Divergence `json:"divergences"`
+	// True if the country values were replaced by the sum of its provinces
+	Recomputed bool `json:"recomputed"`
+
+	parent *Series
+	sum    *Series
+}
+
+// ValidateRollups returns the countries whose series differ from the sum of their provinces by more than tolerance
+// (e.g. 0.05 for 5%) on any day, overseas territories and hidden series are not summed
+func (slice SeriesSlice) ValidateRollups(tolerance float64) []Rollup {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	children := make(map[string]SeriesSlice)
+	parents := make(map[string]*Series)
+	for _, s := range slice {
+		switch {
+		case s.Global() || s.Hidden:
+		case s.Province == "":
+			parents[s.Country] = s
+		case !IsTerritory(s.Country, s.Province) && len(s.Confirmed) == len(s.Deaths):
+			children[s.Country] = append(children[s.Country], s)
+		}
+	}
+
+	list := []Rollup{}
+	for country, provinces := range children {
+		parent := parents[country]
+		if parent == nil || len(parent.Deaths) == 0 {
+			continue
+		}
+		// Only provinces with the same days as the country can be summed into it
+		provinces = provinces.within(parent)
+		if len(provinces) == 0 {
+			continue
+		}
+		sum := &Series{Country: country, StartsAt: parent.StartsAt}
+		sum.sum(provinces)
+		r := Rollup{Country: country, Provinces: len(provinces), parent: parent, sum: sum}
+		for _, metric := range []string{MetricConfirmed, MetricDeaths} {
+			if d, ok := parent.diverges(sum, metric, tolerance); ok {
+				r.Divergences = append(r.Divergences, d)
+			}
+		}
+		if len(r.Divergences) > 0 {
+			list = append(list, r)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Country < list[j].Country })
+	return list
+}
+
+// within returns the series in slice which start on the same day as parent and have the most days up to its length
+// provinces which lag behind the others are left out, rather than reported as a fall in the sum on their missing days
+func (slice SeriesSlice) within(parent *Series) SeriesSlice {
+	days := 0
+	for _, s := range slice {
+		if s.StartsAt.Equal(parent.StartsAt) && len(s.Deaths) <= len(parent.Deaths) && len(s.Deaths) > days {
+			days = len(s.Deaths)
+		}
+	}
+	var list SeriesSlice
+	for _, s := range slice {
+		if s.StartsAt.Equal(parent.StartsAt) && len(s.Deaths) == days {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// FetchRollups uses our stored data to return the countries whose series differ from the sum of their provinces
+func FetchRollups(tolerance float64) []Rollup {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.ValidateRollups(tolerance)
+}
+
+// recomputeRollups replaces the values of each country in rollups with the sum of its provinces for the days summed,
+// and moves the change into the global series, the caller must hold the lock and the series must not be published yet
+func (slice SeriesSlice) recomputeRollups(rollups []Rollup) {
+	global, err := slice.FetchSeries("", "")
+	if err != nil {
+		global = nil
+	}
+	for i, r := range rollups {
+		included := global != nil && r.parent.AddToGlobal()
+		if included {
+			global.addValues(r.parent, -1)
+		}
+		r.parent.Deaths = append([]int(nil), r.parent.Deaths...)
+		r.parent.Confirmed = append([]int(nil), r.parent.Confirmed...)
+		copy(r.parent.Deaths, r.sum.Deaths)
+		copy(r.parent.Confirmed, r.sum.Confirmed)
+		r.parent.UpdateDaily()
+		if included {
+			global.addValues(r.parent, 1)
+		}
+		rollups[i].Recomputed = true
+		log.Printf("load: recomputed %s from %d provinces", r.Country, r.Provinces)
+	}
+}
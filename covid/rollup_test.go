@@ -0,0 +1,41 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateRollups(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	us := &Series{Country: "US", StartsAt: start, Deaths: []int{100, 200, 300}, Confirmed: []int{1000, 2000, 3000}}
+	slice := SeriesSlice{
+		us,
+		{Country: "US", Province: "New York", StartsAt: start, Deaths: []int{60, 120, 250}, Confirmed: []int{600, 1200, 1800}},
+		{Country: "US", Province: "Washington", StartsAt: start, Deaths: []int{40, 80, 150}, Confirmed: []int{400, 800, 1200}},
+		// Territories are reported separately and not summed
+		{Country: "France", StartsAt: start, Deaths: []int{100, 200, 300}, Confirmed: []int{1000, 2000, 3000}},
+		{Country: "France", Province: "Reunion", StartsAt: start, Deaths: []int{50, 50, 50}, Confirmed: []int{500, 500, 500}},
+		{StartsAt: start, Deaths: []int{200, 400, 600}, Confirmed: []int{2000, 4000, 6000}},
+	}
+
+	rollups := slice.ValidateRollups(0.05)
+	if len(rollups) != 1 || rollups[0].Country != "US" || rollups[0].Provinces != 2 {
+		t.Fatalf("test: rollups wanted US got:%+v", rollups)
+	}
+	d := rollups[0].Divergences
+	if len(d) != 1 || d[0].Metric != MetricDeaths || d[0].Since != "2020-03-03" || d[0].Value != 300 || d[0].Other != 400 {
+		t.Fatalf("test: rollups wrong divergence got:%+v", d)
+	}
+
+	slice.recomputeRollups(rollups)
+	if !rollups[0].Recomputed || us.TotalDeaths() != 400 || us.DeathsDaily[2] != 200 {
+		t.Fatalf("test: recompute wanted deaths:400 got:%d", us.TotalDeaths())
+	}
+	global := slice[len(slice)-1]
+	if global.TotalDeaths() != 700 || global.TotalConfirmed() != 6000 {
+		t.Fatalf("test: recompute global wanted deaths:700 got:%d", global.TotalDeaths())
+	}
+	if len(slice.ValidateRollups(0.05)) != 0 {
+		t.Fatalf("test: rollups diverge after recompute")
+	}
+}
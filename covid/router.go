@@ -0,0 +1,129 @@
+package covid
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRouteAliases maps alternative country keys in urls to the keys we use
+var DefaultRouteAliases = map[string]string{
+	"uk":          "united-kingdom",
+	"usa":         "us",
+	"south-korea": "korea-south",
+}
+
+// Route is the result of routing a country page url
+type Route struct {
+	// The series requested, limited to Period days if set
+	Series *Series
+	// The period in days, 0 for all data
+	Period int
+	// True if the url requested json
+	JSON bool
+	// The canonical path for this series and period, without any .json suffix
+	Canonical string
+	// The first date of the series if leading days with no cases were trimmed with ?trim=1, otherwise zero
+	TrimmedStart time.Time
+}
+
+// Router maps urls of the form /{country}/{province}/{period} to series
+type Router struct {
+	// Aliases maps alternative country keys to our keys
+	Aliases map[string]string
+}
+
+// NewRouter returns a router using the default aliases
+func NewRouter() *Router {
+	return &Router{Aliases: DefaultRouteAliases}
+}
+
+// Route returns the series for a url path, with country, province and period
+// optionally set by query params as submitted by the filter form
+func (r *Router) Route(path string, query url.Values) (*Route, error) {
+	route := &Route{}
+	if strings.HasSuffix(path, ".json") {
+		route.JSON = true
+		path = strings.TrimSuffix(path, ".json")
+	}
+
+	// Parse the path parts, the last part is the period if numeric
+	var country, province string
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) > 1 {
+		if p, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			route.Period = p
+			parts = parts[:len(parts)-1]
+		}
+	}
+	if len(parts) > 0 {
+		country = parts[0]
+	}
+	if len(parts) > 1 {
+		province = parts[1]
+	}
+
+	// Query params override the path
+	if v, ok := query["country"]; ok && len(v) > 0 {
+		country = v[0]
+	}
+	if v, ok := query["province"]; ok && len(v) > 0 {
+		province = v[0]
+	}
+	if v := query.Get("period"); v != "" {
+		period, _ := ParsePeriodFilter(v)
+		route.Period = period.Days
+	}
+	if route.Period < 0 {
+		route.Period = 0
+	}
+
+	// Normalise the country key and resolve aliases
+	country = strings.Replace(strings.ToLower(country), " ", "-", -1)
+	if alias, ok := r.Aliases[country]; ok {
+		country = alias
+	}
+	if country == globalSlug {
+		country = ""
+	}
+
+	// Look up by slug, falling back to keys as used by the filter form
+	s, err := FetchSlug(country + "/" + province)
+	if err != nil {
+		s, err = FetchSeries(country, province)
+		if err != nil {
+			return nil, err
+		}
+	}
+	route.Canonical = s.Path(route.Period)
+	if route.Period > 0 {
+		s = s.Days(route.Period)
+	}
+	if TrimRequested(query.Get("trim")) {
+		if trimmed := s.TrimZeros(); trimmed != s {
+			s = trimmed
+			route.TrimmedStart = s.StartsAt
+		}
+	}
+	route.Series = s
+	return route, nil
+}
+
+// Path returns the canonical path for the page for this series, limited to period days if not 0
+func (s *Series) Path(period int) string {
+	p := ""
+	if !s.Global() {
+		p = "/" + s.Slug()
+	}
+	if period > 0 {
+		if p == "" {
+			p = "/" + globalSlug
+		}
+		p += "/" + strconv.Itoa(period)
+	}
+	if p == "" {
+		p = "/"
+	}
+	return p
+}
@@ -0,0 +1,55 @@
+package covid
+
+import (
+	"testing"
+)
+
+func TestSeriesPath(t *testing.T) {
+	tests := []struct {
+		series *Series
+		period int
+		path   string
+	}{
+		{&Series{}, 0, "/"},
+		{&Series{}, 7, "/global/7"},
+		{&Series{Country: "United Kingdom"}, 0, "/united-kingdom"},
+		{&Series{Country: "Australia", Province: "New South Wales"}, 30, "/australia/new-south-wales/30"},
+	}
+	for _, test := range tests {
+		p := test.series.Path(test.period)
+		if p != test.path {
+			t.Fatalf("test: path wanted:%s got:%s", test.path, p)
+		}
+	}
+}
+
+func TestSlugs(t *testing.T) {
+	if Slug("Korea, South") != "korea-south" || Slug("Taiwan*") != "taiwan" {
+		t.Fatalf("test: slug wanted:korea-south,taiwan got:%s,%s", Slug("Korea, South"), Slug("Taiwan*"))
+	}
+
+	slice := SeriesSlice{
+		&Series{Country: "US", Province: "Georgia"},
+		&Series{Country: "Georgia"},
+		&Series{Country: "Korea South"},
+		&Series{Country: "Korea, South"},
+		&Series{},
+	}
+	slice.updateSlugs()
+	tests := map[string]string{
+		"georgia":       "Georgia",
+		"us/georgia":    "US",
+		"korea-south":   "Korea South",
+		"korea-south-2": "Korea, South",
+		"global":        "",
+	}
+	for slug, country := range tests {
+		s, err := slice.FetchSlug(slug)
+		if err != nil {
+			t.Fatalf("test: slug error:%s", err)
+		}
+		if s.Country != country {
+			t.Fatalf("test: slug %s wanted:%s got:%s", slug, country, s.Country)
+		}
+	}
+}
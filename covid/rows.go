@@ -0,0 +1,230 @@
+package covid
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Row is one day of data for one series in long format
+// this is the format used for exports and imports of the whole dataset
+type Row struct {
+	Country   string
+	Province  string
+	Date      time.Time
+	Confirmed int
+	Deaths    int
+}
+
+// Rows returns the data for all series in slice in long format, one row per series per day
+// the synthetic global series is not included, as it is rebuilt on load
+func (slice SeriesSlice) Rows() (rows []Row) {
+	for _, s := range slice {
+		if s.Global() {
+			continue
+		}
+		rows = append(rows, s.Rows()...)
+	}
+	return rows
+}
+
+// Rows returns the data for this series in long format, one row per day
+func (s *Series) Rows() (rows []Row) {
+	d := s.StartsAt
+	for i := range s.Deaths {
+		if i >= len(s.Confirmed) {
+			break
+		}
+		rows = append(rows, Row{
+			Country:   s.Country,
+			Province:  s.Province,
+			Date:      d,
+			Confirmed: s.Confirmed[i],
+			Deaths:    s.Deaths[i],
+		})
+		d = d.AddDate(0, 0, 1)
+	}
+	return rows
+}
+
+// rowJSON is the json representation of a row used in exports
+type rowJSON struct {
+	Country   string `json:"country"`
+	Province  string `json:"province"`
+	Date      string `json:"date"`
+	Confirmed int    `json:"confirmed"`
+	Deaths    int    `json:"deaths"`
+}
+
+// WriteCSV writes the data in slice to w as csv in long format
+// with columns country,province,date,confirmed,deaths
+func (slice SeriesSlice) WriteCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"country", "province", "date", "confirmed", "deaths"})
+	for _, r := range slice.Rows() {
+		c.Write([]string{r.Country, r.Province, r.Date.Format("2006-01-02"), strconv.Itoa(r.Confirmed), strconv.Itoa(r.Deaths)})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// WriteJSON writes the data in slice to w as a json array of rows in long format
+func (slice SeriesSlice) WriteJSON(w io.Writer) error {
+	rows := slice.Rows()
+	list := make([]rowJSON, len(rows))
+	for i, r := range rows {
+		list[i] = rowJSON{Country: r.Country, Province: r.Province, Date: r.Date.Format("2006-01-02"), Confirmed: r.Confirmed, Deaths: r.Deaths}
+	}
+	return json.NewEncoder(w).Encode(list)
+}
+
+// ReadCSV reads csv in long format written by WriteCSV and returns the series it contains
+func ReadCSV(r io.Reader) (SeriesSlice, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 1 || len(records[0]) != 5 || records[0][0] != "country" {
+		return nil, fmt.Errorf("rows: invalid csv header")
+	}
+
+	rows := make([]Row, 0, len(records)-1)
+	for i, record := range records[1:] {
+		r, err := readRow(rowJSON{Country: record[0], Province: record[1], Date: record[2]}, record[3], record[4])
+		if err != nil {
+			return nil, fmt.Errorf("rows: invalid csv row:%d %s", i+1, err)
+		}
+		rows = append(rows, r)
+	}
+	return SeriesFromRows(rows), nil
+}
+
+// ReadJSON reads a json array of rows written by WriteJSON and returns the series it contains
+func ReadJSON(r io.Reader) (SeriesSlice, error) {
+	var list []rowJSON
+	err := json.NewDecoder(r).Decode(&list)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(list))
+	for i, rj := range list {
+		r, err := readRow(rj, "", "")
+		if err != nil {
+			return nil, fmt.Errorf("rows: invalid json row:%d %s", i, err)
+		}
+		rows = append(rows, r)
+	}
+	return SeriesFromRows(rows), nil
+}
+
+// readRow returns a row from rj, parsing confirmed and deaths from strings if given
+func readRow(rj rowJSON, confirmed, deaths string) (Row, error) {
+	var err error
+	if confirmed != "" {
+		rj.Confirmed, err = strconv.Atoi(confirmed)
+		if err != nil {
+			return Row{}, err
+		}
+	}
+	if deaths != "" {
+		rj.Deaths, err = strconv.Atoi(deaths)
+		if err != nil {
+			return Row{}, err
+		}
+	}
+	date, err := time.Parse("2006-01-02", rj.Date)
+	if err != nil {
+		return Row{}, err
+	}
+	return Row{Country: rj.Country, Province: rj.Province, Date: date, Confirmed: rj.Confirmed, Deaths: rj.Deaths}, nil
+}
+
+// SeriesFromRows builds a SeriesSlice from rows in long format
+// rows may be in any order, missing days are carried forward from the previous day
+func SeriesFromRows(rows []Row) SeriesSlice {
+	var slice SeriesSlice
+
+	// Group rows by series, preserving the order we first see them in
+	groups := make(map[[2]string][]Row)
+	var keys [][2]string
+	for _, r := range rows {
+		k := [2]string{r.Country, r.Province}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], r)
+	}
+
+	for _, k := range keys {
+		group := groups[k]
+		sort.Slice(group, func(i, j int) bool { return group[i].Date.Before(group[j].Date) })
+
+		start := dateOnly(group[0].Date)
+		end := dateOnly(group[len(group)-1].Date)
+		days := daysBetween(start, end) + 1
+
+		s := &Series{
+			Country:   k[0],
+			Province:  k[1],
+			StartsAt:  start,
+			Deaths:    make([]int, days),
+			Confirmed: make([]int, days),
+		}
+
+		// Set values by date, then fill any missing days from the day before
+		set := make([]bool, days)
+		for _, r := range group {
+			i := daysBetween(start, dateOnly(r.Date))
+			s.Deaths[i] = r.Deaths
+			s.Confirmed[i] = r.Confirmed
+			set[i] = true
+		}
+		for i := 1; i < days; i++ {
+			if !set[i] {
+				s.Deaths[i] = s.Deaths[i-1]
+				s.Confirmed[i] = s.Confirmed[i-1]
+			}
+		}
+
+		s.UpdateDaily()
+		slice = append(slice, s)
+	}
+
+	return slice
+}
+
+// addGlobal adds a global series to slice built from the series which make up global totals
+// this is used when loading from exports, which include all series but the global one
+func addGlobal(slice SeriesSlice) SeriesSlice {
+	// Series may differ in length, so make space for the longest before merging
+	global := &Series{}
+	days := 0
+	for _, s := range slice {
+		if len(s.Deaths) > days {
+			days = len(s.Deaths)
+			global.StartsAt = s.StartsAt
+		}
+	}
+	global.Deaths = make([]int, days)
+	global.Confirmed = make([]int, days)
+
+	var list SeriesSlice
+	for _, s := range slice {
+		if s.AddToGlobal() {
+			list = append(list, s)
+		}
+	}
+	global.sum(list)
+	return append(slice, global)
+}
+
+// dateOnly returns the UTC date of t at midnight
+func dateOnly(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,222 @@
+package covid
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Summary columns which may be published, in addition to the country name
+var summaryColumns = map[string]func(s *Series) interface{}{
+	"confirmed":             func(s *Series) interface{} { return s.TotalConfirmed() },
+	"deaths":                func(s *Series) interface{} { return s.TotalDeaths() },
+	"confirmed_today":       func(s *Series) interface{} { return todayValue(s.ConfirmedDaily) },
+	"deaths_today":          func(s *Series) interface{} { return todayValue(s.DeathsDaily) },
+	"confirmed_per_million": func(s *Series) interface{} { return s.ConfirmedPerMillion() },
+	"deaths_per_million":    func(s *Series) interface{} { return s.DeathsPerMillion() },
+	"incidence_14d":         func(s *Series) interface{} { return s.Incidence14Per100k() },
+	"acceleration":          func(s *Series) interface{} { return s.Acceleration() },
+	"population":            func(s *Series) interface{} { return s.Population() },
+	"continent":             func(s *Series) interface{} { return s.Continent() },
+}
+
+// DefaultSummaryColumns are the columns published if none are specified
+var DefaultSummaryColumns = []string{"confirmed", "deaths", "confirmed_today", "deaths_today", "deaths_per_million"}
+
+// SummaryTable returns a header row and one row per country for the top n countries
+// with the columns requested, returning an error if a column is unknown
+func (slice SeriesSlice) SummaryTable(n int, columns []string) ([][]interface{}, error) {
+	header := []interface{}{"country"}
+	for _, c := range columns {
+		if summaryColumns[c] == nil {
+			return nil, fmt.Errorf("summary: unknown column:%s", c)
+		}
+		header = append(header, c)
+	}
+
+	table := [][]interface{}{header}
+	for _, s := range slice.Top(n) {
+		row := []interface{}{s.Country}
+		for _, c := range columns {
+			row = append(row, summaryColumns[c](s))
+		}
+		table = append(table, row)
+	}
+	return table, nil
+}
+
+// SheetsPublisher publishes a summary of the top countries to a Google Sheet
+// using a service account which must have edit access to the sheet
+type SheetsPublisher struct {
+	// The id of the spreadsheet from its url
+	SpreadsheetID string
+	// The name of the sheet to write to, which must exist
+	Sheet string
+	// The number of countries to publish
+	Top int
+	// The summary columns to publish
+	Columns []string
+
+	account serviceAccount
+}
+
+// serviceAccount holds the parts of a Google service account key file we need
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// NewSheetsPublisher returns a publisher for the spreadsheet using the service account key file at credentialsPath
+func NewSheetsPublisher(credentialsPath, spreadsheetID string) (*SheetsPublisher, error) {
+	b, err := ioutil.ReadFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("sheets: error reading credentials:%s", err)
+	}
+	p := &SheetsPublisher{
+		SpreadsheetID: spreadsheetID,
+		Sheet:         "Summary",
+		Top:           50,
+		Columns:       DefaultSummaryColumns,
+	}
+	err = json.Unmarshal(b, &p.account)
+	if err != nil {
+		return nil, fmt.Errorf("sheets: error parsing credentials:%s", err)
+	}
+	if p.account.TokenURI == "" {
+		p.account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return p, nil
+}
+
+// Publish writes the summary from our stored data to the sheet, replacing the values there
+func (p *SheetsPublisher) Publish() error {
+	mutex.RLock()
+	table, err := data.SummaryTable(p.Top, p.Columns)
+	mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	token, err := p.account.accessToken("https://www.googleapis.com/auth/spreadsheets")
+	if err != nil {
+		return err
+	}
+
+	// Clear the sheet first so that old rows don't remain, then write the new values
+	client := &http.Client{Timeout: 60 * time.Second}
+	base := fmt.Sprintf("https://sheets.googleapis.com/v4/spreadsheets/%s/values/%s", url.PathEscape(p.SpreadsheetID), url.PathEscape(p.Sheet))
+	err = sheetsRequest(client, token, http.MethodPost, base+":clear", nil)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"range":          p.Sheet,
+		"majorDimension": "ROWS",
+		"values":         table,
+	})
+	if err != nil {
+		return err
+	}
+	return sheetsRequest(client, token, http.MethodPut, base+"?valueInputOption=RAW", body)
+}
+
+// sheetsRequest makes an authorised request to the sheets api
+func sheetsRequest(client *http.Client, token, method, u string, body []byte) error {
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("sheets: error creating request:%s", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sheets: error in request:%s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sheets: error in request status:%d %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// accessToken exchanges a signed jwt for an oauth access token with the given scope
+func (a serviceAccount) accessToken(scope string) (string, error) {
+	jwt, err := a.signedJWT(scope, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	// Exchange it for an access token
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.PostForm(a.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {jwt},
+	})
+	if err != nil {
+		return "", fmt.Errorf("sheets: error fetching token:%s", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error_description"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("sheets: error decoding token:%s", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("sheets: no access token status:%d %s", resp.StatusCode, token.Error)
+	}
+	return token.AccessToken, nil
+}
+
+// signedJWT returns a jwt for scope issued at t, signed with RS256 using the account's private key
+func (a serviceAccount) signedJWT(scope string, t time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(a.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("sheets: invalid private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("sheets: error parsing private key:%s", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("sheets: private key is not rsa")
+	}
+
+	now := t.Unix()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   a.ClientEmail,
+		"scope": scope,
+		"aud":   a.TokenURI,
+		"iat":   now,
+		"exp":   now + 3600,
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("sheets: error signing token:%s", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,78 @@
+package covid
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("test: error generating key:%s", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("test: error encoding key:%s", err)
+	}
+	a := serviceAccount{
+		ClientEmail: "publisher@example.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    "https://oauth2.googleapis.com/token",
+	}
+
+	issued := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	jwt, err := a.signedJWT("https://www.googleapis.com/auth/spreadsheets", issued)
+	if err != nil {
+		t.Fatalf("test: signed jwt error:%s", err)
+	}
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("test: signed jwt wanted 3 parts got:%d", len(parts))
+	}
+
+	// The header and claims are unpadded base64url json
+	var header map[string]string
+	var claims map[string]interface{}
+	for i, v := range []interface{}{&header, &claims} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			t.Fatalf("test: signed jwt part %d not base64url:%s", i, err)
+		}
+		err = json.Unmarshal(b, v)
+		if err != nil {
+			t.Fatalf("test: signed jwt part %d not json:%s", i, err)
+		}
+	}
+	if header["alg"] != "RS256" || header["typ"] != "JWT" {
+		t.Fatalf("test: signed jwt wrong header got:%v", header)
+	}
+	if claims["iss"] != a.ClientEmail || claims["aud"] != a.TokenURI || claims["scope"] != "https://www.googleapis.com/auth/spreadsheets" ||
+		claims["iat"] != float64(issued.Unix()) || claims["exp"] != float64(issued.Unix()+3600) {
+		t.Fatalf("test: signed jwt wrong claims got:%v", claims)
+	}
+
+	// The signature is RS256 over the encoded header and claims
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("test: signed jwt signature not base64url:%s", err)
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature)
+	if err != nil {
+		t.Fatalf("test: signed jwt signature invalid:%s", err)
+	}
+
+	a.PrivateKey = "invalid"
+	if _, err := a.signedJWT("scope", issued); err == nil {
+		t.Fatalf("test: signed jwt wanted error for invalid key")
+	}
+}
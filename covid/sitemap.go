@@ -0,0 +1,56 @@
+package covid
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// sitemapURLSet is the root element of a sitemap
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is the entry for one page in a sitemap
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq"`
+}
+
+// WriteSitemap writes a sitemap to w with an entry for every series page in our stored data
+// base is the absolute url of the site without a trailing slash
+func WriteSitemap(w io.Writer, base string) error {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.WriteSitemap(w, base)
+}
+
+// WriteSitemap writes a sitemap to w with an entry for the page of every series in slice
+func (slice SeriesSlice) WriteSitemap(w io.Writer, base string) error {
+	set := sitemapURLSet{}
+	for _, s := range slice {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:        base + s.Path(0),
+			LastMod:    s.lastModified(),
+			ChangeFreq: "daily",
+		})
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(set)
+}
+
+// lastModified returns the date this series was last updated, or the date of the last day of data
+func (s *Series) lastModified() string {
+	if !s.UpdatedAt.IsZero() {
+		return isoDate(s.UpdatedAt)
+	}
+	if len(s.Deaths) == 0 {
+		return ""
+	}
+	return isoDate(s.StartsAt.AddDate(0, 0, len(s.Deaths)-1))
+}
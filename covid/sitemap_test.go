@@ -0,0 +1,28 @@
+package covid
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSitemap(t *testing.T) {
+	slice := SeriesSlice{
+		&Series{Country: "Italy", UpdatedAt: time.Date(2020, 3, 23, 12, 0, 0, 0, time.UTC)},
+		&Series{Country: "China", Province: "Hubei", StartsAt: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), Deaths: []int{1, 2}},
+	}
+	var b bytes.Buffer
+	err := slice.WriteSitemap(&b, "https://example.com")
+	if err != nil {
+		t.Fatalf("test: sitemap error:%s", err)
+	}
+	for _, want := range []string{
+		"<loc>https://example.com/italy</loc><lastmod>2020-03-23</lastmod>",
+		"<loc>https://example.com/china/hubei</loc><lastmod>2020-03-02</lastmod>",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("test: sitemap wanted:%s got:%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package covid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SlackNotifier posts a summary for a set of countries to a Slack incoming webhook once for each new day of data
+// and an alert when one of the countries reaches a milestone such as 10,000 deaths
+type SlackNotifier struct {
+	// The incoming webhook url for the channel
+	URL string
+	// The site url used to link share card images e.g. https://coronavirus.projectpage.app
+	SiteURL string
+	// The countries to include, blank for global
+	Countries []string
+
+	// posted is the date of the latest day of data we last posted the daily update for
+	posted string
+	// alerted records the milestones we have posted alerts for
+	alerted map[string]bool
+}
+
+// NewSlackNotifier returns a notifier which posts only for days and milestones after it is created
+func NewSlackNotifier(url, siteURL string, countries []string) *SlackNotifier {
+	if len(countries) == 0 {
+		countries = []string{""}
+	}
+	n := &SlackNotifier{
+		URL:       url,
+		SiteURL:   siteURL,
+		Countries: countries,
+		alerted:   make(map[string]bool),
+	}
+	mutex.RLock()
+	n.posted = n.latestDate(data)
+	for _, a := range n.alerts(data) {
+		n.alerted[a.key] = true
+	}
+	mutex.RUnlock()
+	return n
+}
+
+// Notify posts the daily update to slack if there is a new day of data since we last posted,
+// so that revisions during the day don't post again, then an alert for any milestones reached on the latest day
+func (n *SlackNotifier) Notify() error {
+	mutex.RLock()
+	date := n.latestDate(data)
+	var update map[string]interface{}
+	var err error
+	if date != n.posted {
+		update, err = n.message(data)
+	}
+	alerts := n.alerts(data)
+	mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if update != nil {
+		err = n.post(update)
+		if err != nil {
+			return err
+		}
+		n.posted = date
+	}
+
+	var fresh []slackAlert
+	for _, a := range alerts {
+		if !n.alerted[a.key] {
+			fresh = append(fresh, a)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+	err = n.post(n.alertMessage(fresh))
+	if err != nil {
+		return err
+	}
+	for _, a := range fresh {
+		n.alerted[a.key] = true
+	}
+	return nil
+}
+
+// post sends message to the webhook
+func (n *SlackNotifier) post(message map[string]interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("slack: error posting:%s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack: error posting status:%d", resp.StatusCode)
+	}
+	return nil
+}
+
+// latestDate returns the date of the latest day of data for any of our countries, or "" if we have none
+func (n *SlackNotifier) latestDate(slice SeriesSlice) string {
+	latest := ""
+	for _, country := range n.Countries {
+		s, err := slice.FetchSeries(country, "")
+		if err != nil || len(s.Confirmed) == 0 {
+			continue
+		}
+		if d := isoDate(s.StartsAt.AddDate(0, 0, len(s.Confirmed)-1)); d > latest {
+			latest = d
+		}
+	}
+	return latest
+}
+
+// slackAlert is a milestone reached by one of our countries on its latest day
+type slackAlert struct {
+	key       string
+	series    *Series
+	milestone Milestone
+}
+
+// alerts returns the milestones reached by our countries on the latest day of their data
+// peaks are only known two weeks later, so are never on the latest day
+func (n *SlackNotifier) alerts(slice SeriesSlice) (alerts []slackAlert) {
+	for _, country := range n.Countries {
+		s, err := slice.FetchSeries(country, "")
+		if err != nil || len(s.Confirmed) == 0 {
+			continue
+		}
+		latest := s.StartsAt.AddDate(0, 0, len(s.Confirmed)-1)
+		for _, m := range s.Milestones() {
+			if m.Date.Equal(latest) {
+				key := fmt.Sprintf("%s/%s/%d", s.Key(s.Country), m.Kind, m.Value)
+				alerts = append(alerts, slackAlert{key: key, series: s, milestone: m})
+			}
+		}
+	}
+	return alerts
+}
+
+// alertMessage returns a slack message with a block for each alert
+func (n *SlackNotifier) alertMessage(alerts []slackAlert) map[string]interface{} {
+	blocks := []interface{}{
+		slackText("header", "plain_text", "Coronavirus alert"),
+	}
+	for _, a := range alerts {
+		text := fmt.Sprintf("*%s %s*\n%s on %s", a.series.Flag(), a.series.Title(), a.milestone.Label, a.milestone.Date.Format("Jan 2"))
+		blocks = append(blocks, n.cardBlock(slackText("section", "mrkdwn", text), a.series))
+	}
+	return map[string]interface{}{
+		"text":   "Coronavirus alert",
+		"blocks": blocks,
+	}
+}
+
+// message returns a slack message with blocks for each of our countries
+func (n *SlackNotifier) message(slice SeriesSlice) (map[string]interface{}, error) {
+	blocks := []interface{}{
+		slackText("header", "plain_text", "Coronavirus daily update"),
+	}
+	for _, country := range n.Countries {
+		s, err := slice.FetchSeries(country, "")
+		if err != nil {
+			return nil, fmt.Errorf("slack: unknown country:%s", country)
+		}
+		text := fmt.Sprintf("*%s %s*\nConfirmed: %s (+%s today) %s\nDeaths: %s (+%s today) %s",
+			s.Flag(), s.Title(),
+			s.ConfirmedDisplay(), s.ConfirmedToday(), s.ChangeDisplay(MetricConfirmedDaily, trendDays),
+			s.DeathsDisplay(), s.DeathsToday(), s.ChangeDisplay(MetricDeathsDaily, trendDays))
+		blocks = append(blocks, n.cardBlock(slackText("section", "mrkdwn", text), s))
+	}
+	return map[string]interface{}{
+		"text":   "Coronavirus daily update",
+		"blocks": blocks,
+	}, nil
+}
+
+// cardBlock adds the share card image for s to block if we have a site url
+func (n *SlackNotifier) cardBlock(block map[string]interface{}, s *Series) map[string]interface{} {
+	if n.SiteURL != "" {
+		block["accessory"] = map[string]interface{}{
+			"type":      "image",
+			"image_url": n.SiteURL + "/card" + s.cardPath() + ".png",
+			"alt_text":  s.Title() + " daily cases",
+		}
+	}
+	return block
+}
+
+// slackText returns a slack block of kind containing text of the given type
+func slackText(kind, textType, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": kind,
+		"text": map[string]interface{}{
+			"type": textType,
+			"text": text,
+		},
+	}
+}
+
+// cardPath returns the path for share cards for this series e.g. /spain or /global
+func (s *Series) cardPath() string {
+	if s.Country == "" {
+		return "/global"
+	}
+	p := "/" + s.Key(s.Country)
+	if s.Province != "" {
+		p += "/" + s.Key(s.Province)
+	}
+	return p
+}
@@ -0,0 +1,88 @@
+package covid
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackNotifier(t *testing.T) {
+	var posts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			Text   string `json:"text"`
+			Blocks []struct {
+				Type string `json:"type"`
+				Text struct {
+					Text string `json:"text"`
+				} `json:"text"`
+				Accessory struct {
+					ImageURL string `json:"image_url"`
+				} `json:"accessory"`
+			} `json:"blocks"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&message)
+		if err != nil || len(message.Blocks) != 2 || message.Blocks[0].Type != "header" {
+			t.Errorf("test: slack invalid message:%+v error:%v", message, err)
+			return
+		}
+		section := message.Blocks[1]
+		if !strings.Contains(section.Text.Text, "Italy") || section.Accessory.ImageURL != "https://example.com/card/italy.png" {
+			t.Errorf("test: slack wrong section:%+v", section)
+		}
+		posts = append(posts, message.Text+": "+section.Text.Text)
+	}))
+	defer server.Close()
+
+	italy := &Series{Country: "Italy", StartsAt: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), Deaths: []int{0, 10, 50}, Confirmed: []int{10, 200, 500}}
+	italy.UpdateDaily()
+	mutex.Lock()
+	previous := data
+	data = SeriesSlice{italy}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data = previous
+		mutex.Unlock()
+	}()
+
+	// Nothing is posted for the day of data we already had when created
+	n := NewSlackNotifier(server.URL, "https://example.com", []string{"Italy"})
+	if err := n.Notify(); err != nil || len(posts) != 0 {
+		t.Fatalf("test: slack wanted no posts got:%v error:%v", posts, err)
+	}
+
+	// Revisions during the day don't post again
+	revised := &Series{Country: "Italy", StartsAt: italy.StartsAt, Deaths: []int{0, 10, 60}, Confirmed: []int{10, 200, 550}}
+	revised.UpdateDaily()
+	mutex.Lock()
+	data = SeriesSlice{revised}
+	mutex.Unlock()
+	if err := n.Notify(); err != nil || len(posts) != 0 {
+		t.Fatalf("test: slack revision wanted no posts got:%v error:%v", posts, err)
+	}
+
+	// A new day posts the update, and an alert as deaths pass 100
+	next := &Series{Country: "Italy", StartsAt: italy.StartsAt, Deaths: []int{0, 10, 60, 120}, Confirmed: []int{10, 200, 550, 800}}
+	next.UpdateDaily()
+	mutex.Lock()
+	data = SeriesSlice{next}
+	mutex.Unlock()
+	if err := n.Notify(); err != nil || len(posts) != 2 {
+		t.Fatalf("test: slack new day wanted 2 posts got:%v error:%v", posts, err)
+	}
+	if !strings.HasPrefix(posts[0], "Coronavirus daily update") || !strings.Contains(posts[0], "Deaths: 120 (+60 today)") {
+		t.Fatalf("test: slack wrong update:%s", posts[0])
+	}
+	if !strings.HasPrefix(posts[1], "Coronavirus alert") || !strings.Contains(posts[1], "100 deaths on Mar 4") {
+		t.Fatalf("test: slack wrong alert:%s", posts[1])
+	}
+
+	// Neither is posted again until there is another day
+	if err := n.Notify(); err != nil || len(posts) != 2 {
+		t.Fatalf("test: slack repeat wanted 2 posts got:%v error:%v", posts, err)
+	}
+}
@@ -0,0 +1,109 @@
+package covid
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// globalSlug is the slug used for the global series in urls
+const globalSlug = "global"
+
+// Slug returns a url slug for a country or province name
+// it is lowercase with runs of anything other than letters and digits replaced by -
+// e.g. Korea, South becomes korea-south and Taiwan* becomes taiwan
+func Slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// Slug returns the unique slug for this series, which is the country slug
+// followed by /province slug for provinces, so Georgia the country is georgia
+// and Georgia the state is us/georgia. The global series has a blank slug.
+func (s *Series) Slug() string {
+	if s.slug != "" || s.Global() {
+		return s.slug
+	}
+	if s.Province == "" {
+		return Slug(s.Country)
+	}
+	return Slug(s.Country) + "/" + Slug(s.Province)
+}
+
+// updateSlugs assigns unique slugs to each series in slice
+// where names collide the series sorted first by name gets the plain slug
+// and others get -2, -3 etc, so that slugs are the same on every load
+func (slice SeriesSlice) updateSlugs() {
+	sorted := make(SeriesSlice, len(slice))
+	copy(sorted, slice)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Country != sorted[j].Country {
+			return sorted[i].Country < sorted[j].Country
+		}
+		return sorted[i].Province < sorted[j].Province
+	})
+
+	taken := map[string]bool{globalSlug: true}
+	countries := make(map[string]string)
+	for _, s := range sorted {
+		if s.Global() {
+			s.slug = ""
+			continue
+		}
+		country, ok := countries[s.Country]
+		if !ok {
+			country = uniqueSlug(Slug(s.Country), taken)
+			countries[s.Country] = country
+		}
+		if s.Province == "" {
+			s.slug = country
+			continue
+		}
+		s.slug = uniqueSlug(country+"/"+Slug(s.Province), taken)
+	}
+}
+
+// uniqueSlug returns slug with a numeric suffix if required to make it unique, and marks it as taken
+func uniqueSlug(slug string, taken map[string]bool) string {
+	unique := slug
+	for i := 2; taken[unique]; i++ {
+		unique = slug + "-" + strconv.Itoa(i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// FetchSlug uses our stored data to fetch the series with this slug
+func FetchSlug(slug string) (*Series, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.FetchSlug(slug)
+}
+
+// FetchSlug returns the series in slice with this slug, global or a blank slug returns the global series
+func (slice SeriesSlice) FetchSlug(slug string) (*Series, error) {
+	slug = strings.Trim(strings.ToLower(slug), "/")
+	if slug == globalSlug {
+		slug = ""
+	}
+	for _, s := range slice {
+		if s.Slug() == slug {
+			return s, nil
+		}
+	}
+	return &Series{}, fmt.Errorf("series: not found for slug:%s", slug)
+}
@@ -0,0 +1,132 @@
+package covid
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snapshotExt is the extension of snapshot files, which are stored in our parquet export format
+const snapshotExt = ".parquet"
+
+var (
+	snapshotMutex sync.RWMutex
+	// snapshotDir is where daily snapshots of our data are stored, blank if snapshots are not stored
+	snapshotDir string
+)
+
+// Snapshot describes a stored snapshot of our data as it was at the end of one day
+type Snapshot struct {
+	Date      string    `json:"date"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetSnapshotDir sets the directory daily snapshots of our data are stored in, creating it if required
+func SetSnapshotDir(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating dir:%s", err)
+	}
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+	snapshotDir = dir
+	return nil
+}
+
+// WriteSnapshot stores our data as a snapshot for the last day of data, replacing any snapshot for that day
+// so that the snapshot for each day records the data as it was last reported on that day
+func WriteSnapshot() error {
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+	if snapshotDir == "" {
+		return fmt.Errorf("snapshot: no snapshot dir")
+	}
+
+	mutex.RLock()
+	date := data.lastDate()
+	b := &bytes.Buffer{}
+	err := data.WriteParquet(b)
+	mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("snapshot: error writing snapshot:%s", err)
+	}
+	if date.IsZero() {
+		return fmt.Errorf("snapshot: no data")
+	}
+
+	// Write to a temporary file first so that readers never see a partial snapshot
+	path := filepath.Join(snapshotDir, date.Format("2006-01-02")+snapshotExt)
+	err = ioutil.WriteFile(path+".tmp", b.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("snapshot: error writing snapshot:%s", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Snapshots returns the stored snapshots, oldest first
+func Snapshots() ([]Snapshot, error) {
+	snapshotMutex.RLock()
+	defer snapshotMutex.RUnlock()
+	list := []Snapshot{}
+	if snapshotDir == "" {
+		return list, nil
+	}
+	files, err := ioutil.ReadDir(snapshotDir)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: error listing snapshots:%s", err)
+	}
+	for _, f := range files {
+		date := strings.TrimSuffix(f.Name(), snapshotExt)
+		if f.IsDir() || !strings.HasSuffix(f.Name(), snapshotExt) || !validSnapshotDate(date) {
+			continue
+		}
+		list = append(list, Snapshot{Date: date, Size: f.Size(), CreatedAt: f.ModTime().UTC()})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Date < list[j].Date })
+	return list, nil
+}
+
+// FetchSnapshot returns the series stored in the snapshot for date e.g. 2020-03-24
+func FetchSnapshot(date string) (SeriesSlice, error) {
+	if !validSnapshotDate(date) {
+		return nil, fmt.Errorf("snapshot: invalid date:%s", date)
+	}
+	snapshotMutex.RLock()
+	defer snapshotMutex.RUnlock()
+	if snapshotDir == "" {
+		return nil, fmt.Errorf("snapshot: not found:%s", date)
+	}
+	path := filepath.Join(snapshotDir, date+snapshotExt)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: not found:%s", date)
+	}
+	return readExport(path, b)
+}
+
+// validSnapshotDate returns true if date is a valid date in the format used for snapshot names
+func validSnapshotDate(date string) bool {
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+// lastDate returns the last day of data in slice, or a zero time if there is none
+func (slice SeriesSlice) lastDate() time.Time {
+	var last time.Time
+	for _, s := range slice {
+		if len(s.Deaths) == 0 {
+			continue
+		}
+		if end := s.StartsAt.AddDate(0, 0, len(s.Deaths)-1); end.After(last) {
+			last = end
+		}
+	}
+	return last
+}
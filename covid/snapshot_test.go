@@ -0,0 +1,51 @@
+package covid
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	if err != nil {
+		t.Fatalf("test: temp dir failed:%s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	mutex.Lock()
+	previous := data
+	data = SeriesSlice{
+		&Series{Country: "Italy", StartsAt: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), Deaths: []int{1, 2}, Confirmed: []int{10, 20}},
+	}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		data = previous
+		mutex.Unlock()
+		snapshotDir = ""
+	}()
+
+	err = SetSnapshotDir(dir)
+	if err != nil {
+		t.Fatalf("test: set snapshot dir failed:%s", err)
+	}
+	err = WriteSnapshot()
+	if err != nil {
+		t.Fatalf("test: write snapshot failed:%s", err)
+	}
+
+	list, err := Snapshots()
+	if err != nil || len(list) != 1 || list[0].Date != "2020-03-02" {
+		t.Fatalf("test: snapshots wrong got:%v %v", list, err)
+	}
+	slice, err := FetchSnapshot("2020-03-02")
+	if err != nil || len(slice) != 1 || slice[0].TotalDeaths() != 2 {
+		t.Fatalf("test: snapshot wrong got:%v %v", slice, err)
+	}
+	_, err = FetchSnapshot("../2020-03-02")
+	if err == nil {
+		t.Fatalf("test: fetched snapshot with invalid date")
+	}
+}
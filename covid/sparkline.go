@@ -0,0 +1,61 @@
+package covid
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Default sparkline settings used in options and tables
+const (
+	sparklineDays   = 28
+	sparklineWidth  = 100
+	sparklineHeight = 20
+)
+
+// Sparkline returns a tiny inline svg showing the trend of daily values for metric
+// over the last days (or all days if days is 0), at the given width and height in pixels
+func (s *Series) Sparkline(metric int, days int, width int, height int) template.HTML {
+	values := s.DailyValues(metric)
+	if days > 0 && days < len(values) {
+		values = values[len(values)-days:]
+	}
+	if len(values) < 2 || width <= 0 || height <= 0 {
+		return ""
+	}
+
+	// Find the range of values so that we can scale them to fit
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	spread := float64(max - min)
+	if spread == 0 {
+		spread = 1
+	}
+
+	// Build the points of the line, leaving a pixel margin so the stroke is not clipped
+	h := float64(height - 2)
+	step := float64(width) / float64(len(values)-1)
+	points := make([]string, len(values))
+	for i, v := range values {
+		x := float64(i) * step
+		y := 1 + h - (float64(v-min)/spread)*h
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+
+	color := "#a02020"
+	if metric == DataDeaths {
+		color = "#461e1e"
+	}
+
+	svg := fmt.Sprintf(`<svg class="sparkline" xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d"><polyline fill="none" stroke="%s" stroke-width="1" points="%s"/></svg>`,
+		width, height, width, height, color, strings.Join(points, " "))
+
+	return template.HTML(svg)
+}
@@ -0,0 +1,41 @@
+package covid
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 0, 10, 30, 30}, Confirmed: []int{5, 10, 15, 20, 25}}
+	s.UpdateDaily()
+
+	// The last 4 days of daily deaths are 0,10,20,0, scaled to fit 10 pixels between 1 pixel margins
+	svg := string(s.Sparkline(DataDeaths, 4, 30, 12))
+	if !strings.Contains(svg, `points="0.0,11.0 10.0,6.0 20.0,1.0 30.0,11.0"`) || !strings.Contains(svg, `width="30" height="12"`) {
+		t.Fatalf("test: sparkline wrong svg:%s", svg)
+	}
+
+	// A flat series is drawn along the bottom
+	svg = string(s.Sparkline(DataConfirmed, 4, 30, 12))
+	if !strings.Contains(svg, `points="0.0,11.0 10.0,11.0 20.0,11.0 30.0,11.0"`) {
+		t.Fatalf("test: sparkline flat wrong svg:%s", svg)
+	}
+
+	// An empty series has no sparkline, and none is included in options
+	empty := &Series{Country: "Nowhere", StartsAt: start}
+	if svg := empty.Sparkline(DataDeaths, 4, 30, 12); svg != "" {
+		t.Fatalf("test: sparkline empty wanted none got:%s", svg)
+	}
+	output, err := json.Marshal([]Option{{Name: "Nowhere", Sparkline: empty.Sparkline(DataDeaths, 4, 30, 12)}, {Name: "Italy", Sparkline: s.Sparkline(DataDeaths, 4, 30, 12)}})
+	if err != nil {
+		t.Fatalf("test: sparkline json error:%s", err)
+	}
+	var options []map[string]interface{}
+	json.Unmarshal(output, &options)
+	if _, ok := options[0]["sparkline"]; ok || !strings.HasPrefix(options[1]["sparkline"].(string), "<svg") {
+		t.Fatalf("test: sparkline json wrong:%s", output)
+	}
+}
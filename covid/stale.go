@@ -0,0 +1,165 @@
+package covid
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// FailedSource records a data file which failed to load
+type FailedSource struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// fail records that file failed to load
+func (r *LoadReport) fail(file string, err error) {
+	log.Printf("load: failed to load file:%s error:%s", file, err)
+	r.Failed = append(r.Failed, FailedSource{File: file, Error: err.Error()})
+}
+
+// failed returns true if a file with this prefix failed to load
+func (r *LoadReport) failed(prefix string) bool {
+	for _, f := range r.Failed {
+		if strings.HasPrefix(f.File, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stale returns true if data for this series is stale because a source failed to load
+func (s *Series) Stale() bool {
+	return !s.StaleSince.IsZero()
+}
+
+// DefaultStaleDays is the age in days after which data is shown as stale
+const DefaultStaleDays = 3
+
+// LastReportedAt returns the date of the last day on which confirmed or deaths changed
+// or the zero time if they never have
+func (s *Series) LastReportedAt() time.Time {
+	for i := len(s.Deaths) - 1; i >= 0; i-- {
+		if (i < len(s.ConfirmedDaily) && s.ConfirmedDaily[i] != 0) || (i < len(s.DeathsDaily) && s.DeathsDaily[i] != 0) {
+			return s.StartsAt.AddDate(0, 0, i)
+		}
+	}
+	return time.Time{}
+}
+
+// DataAge returns the age in days of the data for this series, which is the days since
+// it last reported a change up to the last day of data, plus any days it has been stale
+func (s *Series) DataAge() int {
+	if len(s.Deaths) == 0 {
+		return 0
+	}
+	age := len(s.Deaths) - 1
+	if reported := s.LastReportedAt(); !reported.IsZero() {
+		age = daysBetween(reported, s.StartsAt.AddDate(0, 0, len(s.Deaths)-1))
+	}
+	if s.Stale() {
+		age += daysBetween(s.StaleSince.UTC(), time.Now().UTC())
+	}
+	return age
+}
+
+// IsStale returns true if a source for this series failed to load, the data is at least threshold days old
+// or one metric was last updated at least threshold days before another
+func (s *Series) IsStale(threshold int) bool {
+	return s.Stale() || s.DataAge() >= threshold || s.UpdateLag() >= threshold
+}
+
+// staleLabel returns a label for the age of data for options if it is stale, or a blank string
+// series stale because a source failed show when they were last updated, as they may have just been marked
+func (s *Series) staleLabel() string {
+	if s.Stale() {
+		if updated := s.lastUpdate(); !updated.IsZero() {
+			return fmt.Sprintf(" (not updated since %s)", isoDate(updated))
+		}
+		return " (stale)"
+	}
+	if !s.IsStale(DefaultStaleDays) {
+		return ""
+	}
+	return fmt.Sprintf(" (data %d days old)", s.DataAge())
+}
+
+// lastUpdate returns the time of the last successful update of this series,
+// or the date of its last day of data if we have no update times
+func (s *Series) lastUpdate() time.Time {
+	if updated := s.newestUpdate(); !updated.IsZero() {
+		return updated
+	}
+	if len(s.Deaths) == 0 {
+		return time.Time{}
+	}
+	return s.StartsAt.AddDate(0, 0, len(s.Deaths)-1)
+}
+
+// markStale marks this series as stale since the previous series was, or since t if it was not
+func (s *Series) markStale(previous *Series, t time.Time) {
+	if previous != nil && previous.Stale() {
+		s.StaleSince = previous.StaleSince
+		return
+	}
+	s.StaleSince = t
+}
+
+// recoverTimeSeries fills in the datum from a failed time series file using the previous data
+// and marks the series as stale, returning an error if there is no previous data to use
+func (slice SeriesSlice) recoverTimeSeries(previous SeriesSlice, datum int, t time.Time) error {
+	if len(previous) == 0 {
+		return fmt.Errorf("load: no previous data to recover from")
+	}
+	if len(slice) == 0 {
+		return fmt.Errorf("load: no time series data loaded")
+	}
+	for _, s := range slice {
+		// Series which are new since the previous load get zeros rather than mismatched lengths
+		p, err := previous.FetchSeries(s.Country, s.Province)
+		if err != nil {
+			p = &Series{}
+		}
+		switch datum {
+		case DataDeaths:
+			s.Deaths = fitValues(p.Deaths, len(s.Confirmed))
+			s.carryUpdates(p, MetricDeaths)
+		case DataConfirmed:
+			s.Confirmed = fitValues(p.Confirmed, len(s.Deaths))
+			s.carryUpdates(p, MetricConfirmed)
+		}
+		s.UpdateDaily()
+		s.markStale(p, t)
+	}
+	return nil
+}
+
+// markStaleDaily marks series which should have had data from a failed daily file as stale
+// provinces if the state file failed, countries if the country file failed
+func (slice SeriesSlice) markStaleDaily(previous SeriesSlice, provinces bool, t time.Time) {
+	for _, s := range slice {
+		if s.Global() || (s.Province != "") != provinces {
+			continue
+		}
+		p, err := previous.FetchSeries(s.Country, s.Province)
+		if err != nil {
+			p = nil
+		}
+		s.markStale(p, t)
+	}
+}
+
+// fitValues returns a copy of values of length n, truncated or padded with the last value
+// so that values from a previous load match the length of the data which did load
+func fitValues(values []int, n int) []int {
+	fitted := make([]int, n)
+	for i := range fitted {
+		if i < len(values) {
+			fitted[i] = values[i]
+		} else if len(values) > 0 {
+			fitted[i] = values[len(values)-1]
+		}
+	}
+	return fitted
+}
@@ -0,0 +1,82 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecoverTimeSeries(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	since := time.Date(2020, 1, 20, 0, 0, 0, 0, time.UTC)
+	previous := SeriesSlice{
+		&Series{Country: "Italy", StartsAt: start, Deaths: []int{1, 2}, Confirmed: []int{10, 20}},
+		&Series{Country: "Spain", StartsAt: start, Deaths: []int{3, 4}, Confirmed: []int{30, 40}, StaleSince: since},
+	}
+
+	// Deaths failed to load, so confirmed has a day more than the previous deaths
+	slice := SeriesSlice{
+		&Series{Country: "Italy", StartsAt: start, Confirmed: []int{10, 20, 30}},
+		&Series{Country: "Spain", StartsAt: start, Confirmed: []int{30, 40, 50}},
+		&Series{Country: "France", StartsAt: start, Confirmed: []int{1, 2, 3}},
+	}
+	now := time.Now()
+	err := slice.recoverTimeSeries(previous, DataDeaths, now)
+	if err != nil {
+		t.Fatalf("test: recover error:%s", err)
+	}
+	if len(slice[0].Deaths) != 3 || slice[0].Deaths[2] != 2 || slice[0].StaleSince != now {
+		t.Fatalf("test: recover wanted:[1 2 2] got:%v stale:%s", slice[0].Deaths, slice[0].StaleSince)
+	}
+	if slice[1].StaleSince != since {
+		t.Fatalf("test: recover stale wanted:%s got:%s", since, slice[1].StaleSince)
+	}
+	if len(slice[2].Deaths) != 3 || !slice[2].Stale() {
+		t.Fatalf("test: recover new series wanted:[0 0 0] got:%v", slice[2].Deaths)
+	}
+
+	if (SeriesSlice{}).recoverTimeSeries(SeriesSlice{}, DataDeaths, now) == nil {
+		t.Fatalf("test: recover wanted error with no previous data")
+	}
+}
+
+func TestDataAge(t *testing.T) {
+	s := &Series{
+		StartsAt:  time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths:    []int{1, 2, 2, 2, 2},
+		Confirmed: []int{10, 20, 30, 30, 30},
+	}
+	s.UpdateDaily()
+	if s.DataAge() != 2 || !s.IsStale(2) || s.IsStale(3) {
+		t.Fatalf("test: data age wanted:2 got:%d", s.DataAge())
+	}
+	if s.staleLabel() != "" {
+		t.Fatalf("test: stale label wanted blank got:%s", s.staleLabel())
+	}
+	s.Confirmed = []int{10, 20, 20, 20, 20}
+	s.UpdateDaily()
+	if s.staleLabel() != " (data 3 days old)" {
+		t.Fatalf("test: stale label wanted:(data 3 days old) got:%s", s.staleLabel())
+	}
+}
+
+func TestStaleLabelMarked(t *testing.T) {
+	s := &Series{
+		StartsAt:  time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths:    []int{1, 2, 3},
+		Confirmed: []int{10, 20, 30},
+	}
+	s.UpdateDaily()
+
+	// A series just marked stale has data 0 days old, so show when it was last updated instead
+	s.markStale(nil, time.Now())
+	if s.staleLabel() != " (not updated since 2020-03-03)" {
+		t.Fatalf("test: stale label wanted:(not updated since 2020-03-03) got:%s", s.staleLabel())
+	}
+	s.UpdatedAt = time.Date(2020, 3, 4, 10, 0, 0, 0, time.UTC)
+	if s.staleLabel() != " (not updated since 2020-03-04)" {
+		t.Fatalf("test: stale label wanted:(not updated since 2020-03-04) got:%s", s.staleLabel())
+	}
+	if (&Series{StaleSince: time.Now()}).staleLabel() != " (stale)" {
+		t.Fatalf("test: stale label wanted:(stale) for series with no data")
+	}
+}
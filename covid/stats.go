@@ -0,0 +1,84 @@
+package covid
+
+import (
+	"math"
+	"sort"
+)
+
+// Stats are summary statistics for the values of a metric over a period
+// used for scaling chart axes, worst day captions and normalising heatmaps
+type Stats struct {
+	Metric  string  `json:"metric"`
+	Days    int     `json:"days"`
+	Max     int     `json:"max"`
+	MaxDate string  `json:"max_date,omitempty"`
+	Min     int     `json:"min"`
+	MinDate string  `json:"min_date,omitempty"`
+	Mean    float64 `json:"mean"`
+	Median  float64 `json:"median"`
+	P10     float64 `json:"p10"`
+	P25     float64 `json:"p25"`
+	P75     float64 `json:"p75"`
+	P90     float64 `json:"p90"`
+}
+
+// Stats returns statistics for metric over the last period days of this series, or all days if period is 0
+// where the max or min occurs more than once, the first date is returned
+func (s *Series) Stats(metric string, period int) (*Stats, error) {
+	values, err := s.MetricValues(metric)
+	if err != nil {
+		return nil, err
+	}
+	start := 0
+	if period > 0 && period < len(values) {
+		start = len(values) - period
+	}
+	values = values[start:]
+
+	stats := &Stats{Metric: metric, Days: len(values)}
+	if len(values) == 0 {
+		return stats, nil
+	}
+
+	maxIndex, minIndex, total := 0, 0, 0
+	for i, v := range values {
+		if v > values[maxIndex] {
+			maxIndex = i
+		}
+		if v < values[minIndex] {
+			minIndex = i
+		}
+		total += v
+	}
+	stats.Max, stats.MaxDate = values[maxIndex], isoDate(s.StartsAt.AddDate(0, 0, start+maxIndex))
+	stats.Min, stats.MinDate = values[minIndex], isoDate(s.StartsAt.AddDate(0, 0, start+minIndex))
+	stats.Mean = float64(total) / float64(len(values))
+
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+	stats.Median = Percentile(sorted, 50)
+	stats.P10 = Percentile(sorted, 10)
+	stats.P25 = Percentile(sorted, 25)
+	stats.P75 = Percentile(sorted, 75)
+	stats.P90 = Percentile(sorted, 90)
+	return stats, nil
+}
+
+// Percentile returns the pth percentile of sorted values, interpolating between the closest ranks
+func Percentile(sorted []int, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower < 0 {
+		lower = 0
+	}
+	if upper >= len(sorted) {
+		upper = len(sorted) - 1
+	}
+	fraction := rank - float64(lower)
+	return float64(sorted[lower]) + fraction*float64(sorted[upper]-sorted[lower])
+}
@@ -0,0 +1,34 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	s := &Series{
+		StartsAt:  time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deaths:    []int{0, 0, 0, 0, 0, 0},
+		Confirmed: []int{100, 110, 150, 160, 200, 205},
+	}
+	s.UpdateDaily()
+
+	stats, err := s.Stats(MetricConfirmedDaily, 5)
+	if err != nil {
+		t.Fatalf("test: stats error:%s", err)
+	}
+	// daily values are 10,40,10,40,5
+	if stats.Days != 5 || stats.Max != 40 || stats.MaxDate != "2020-03-03" || stats.Min != 5 || stats.MinDate != "2020-03-06" {
+		t.Fatalf("test: stats wanted max:40 2020-03-03 min:5 got:%v", stats)
+	}
+	if stats.Mean != 21 || stats.Median != 10 || stats.P75 != 40 {
+		t.Fatalf("test: stats wanted mean:21 median:10 got:%v", stats)
+	}
+
+	if p := Percentile([]int{1, 2, 3, 4}, 50); p != 2.5 {
+		t.Fatalf("test: percentile wanted:2.5 got:%f", p)
+	}
+	if _, err := s.Stats("unknown", 0); err == nil {
+		t.Fatalf("test: stats wanted error for unknown metric")
+	}
+}
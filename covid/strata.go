@@ -0,0 +1,139 @@
+package covid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stratification dimensions
+const (
+	DimensionAge = "age"
+	DimensionSex = "sex"
+)
+
+// Stratum holds cumulative data for one group within a series e.g. age 60-69 or sex female
+// values are aligned with the dates of the series it belongs to
+type Stratum struct {
+	Dimension string `json:"dimension"`
+	Group     string `json:"group"`
+	Deaths    []int  `json:"deaths"`
+	Confirmed []int  `json:"confirmed"`
+}
+
+// Breakdown returns the strata for dimension in this series, in the order they were loaded
+func (s *Series) Breakdown(dimension string) (strata []*Stratum) {
+	for _, st := range s.Strata {
+		if st.Dimension == dimension {
+			strata = append(strata, st)
+		}
+	}
+	return strata
+}
+
+// Dimensions returns the dimensions for which this series has strata
+func (s *Series) Dimensions() (dimensions []string) {
+	for _, st := range s.Strata {
+		if !containsString(dimensions, st.Dimension) {
+			dimensions = append(dimensions, st.Dimension)
+		}
+	}
+	return dimensions
+}
+
+// AggregateStrata sums the strata for dimension back to totals for each day
+// sources often report fewer deaths by age than in total, so this may differ from the series totals
+func (s *Series) AggregateStrata(dimension string) (deaths, confirmed []int) {
+	deaths = make([]int, len(s.Deaths))
+	confirmed = make([]int, len(s.Confirmed))
+	for _, st := range s.Breakdown(dimension) {
+		for i := range deaths {
+			if i < len(st.Deaths) {
+				deaths[i] += st.Deaths[i]
+			}
+		}
+		for i := range confirmed {
+			if i < len(st.Confirmed) {
+				confirmed[i] += st.Confirmed[i]
+			}
+		}
+	}
+	return deaths, confirmed
+}
+
+// stratum returns the stratum for dimension and group, adding it if necessary
+func (s *Series) stratum(dimension, group string) *Stratum {
+	for _, st := range s.Strata {
+		if st.Dimension == dimension && st.Group == group {
+			return st
+		}
+	}
+	st := &Stratum{
+		Dimension: dimension,
+		Group:     group,
+		Deaths:    make([]int, len(s.Deaths)),
+		Confirmed: make([]int, len(s.Confirmed)),
+	}
+	s.Strata = append(s.Strata, st)
+	return st
+}
+
+// days returns a copy of this stratum starting at day i
+func (st *Stratum) days(i int) *Stratum {
+	c := &Stratum{Dimension: st.Dimension, Group: st.Group}
+	if i < len(st.Deaths) {
+		c.Deaths = copyInts(st.Deaths[i:])
+	}
+	if i < len(st.Confirmed) {
+		c.Confirmed = copyInts(st.Confirmed[i:])
+	}
+	return c
+}
+
+// mergeStrataCSV merges stratified data into existing series
+// the csv has a header row and columns country,province,date,dimension,group,confirmed,deaths with cumulative values
+// missing days are carried forward from the day before
+func (slice SeriesSlice) mergeStrataCSV(records [][]string) (SeriesSlice, error) {
+	for i, row := range records {
+		if i == 0 {
+			if len(row) < 7 || strings.ToLower(row[0]) != "country" {
+				return slice, fmt.Errorf("load: invalid strata csv header:%v", row)
+			}
+			continue
+		}
+		if len(row) < 7 {
+			return slice, fmt.Errorf("load: invalid strata csv row:%d", i)
+		}
+
+		s, err := slice.FetchSeries(row[0], row[1])
+		if err != nil {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", row[2])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid strata date row:%d:%s", i, err)
+		}
+		confirmed, err := strconv.Atoi(row[5])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid strata confirmed row:%d:%s", i, err)
+		}
+		deaths, err := strconv.Atoi(row[6])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid strata deaths row:%d:%s", i, err)
+		}
+
+		day := daysBetween(s.StartsAt, date)
+		if day < 0 || day >= len(s.Deaths) || day >= len(s.Confirmed) {
+			continue
+		}
+
+		// Set this day and carry it forward, later rows overwrite following days
+		st := s.stratum(strings.ToLower(row[3]), row[4])
+		for d := day; d < len(st.Deaths); d++ {
+			st.Deaths[d] = deaths
+			st.Confirmed[d] = confirmed
+		}
+	}
+	return slice, nil
+}
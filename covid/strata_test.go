@@ -0,0 +1,43 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrata(t *testing.T) {
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Deaths: []int{1, 5, 9}, Confirmed: []int{10, 50, 90}},
+	}
+	records := [][]string{
+		{"country", "province", "date", "dimension", "group", "confirmed", "deaths"},
+		{"italy", "", "2020-03-02", "age", "0-59", "30", "1"},
+		{"italy", "", "2020-03-02", "age", "60+", "20", "4"},
+		{"italy", "", "2020-03-03", "age", "60+", "40", "8"},
+		{"italy", "", "2020-03-03", "Sex", "female", "40", "3"},
+	}
+	slice[0].UpdateDaily()
+	slice, err := slice.MergeCSV(records, DataStrata)
+	if err != nil {
+		t.Fatalf("test: merge strata error:%s", err)
+	}
+
+	s := slice[0]
+	if len(s.Breakdown(DimensionAge)) != 2 || len(s.Breakdown(DimensionSex)) != 1 || len(s.Dimensions()) != 2 {
+		t.Fatalf("test: strata wrong:%v", s.Dimensions())
+	}
+
+	// 0-59 is carried forward to the last day
+	deaths, confirmed := s.AggregateStrata(DimensionAge)
+	if deaths[0] != 0 || deaths[1] != 5 || deaths[2] != 9 || confirmed[2] != 70 {
+		t.Fatalf("test: strata totals wrong deaths:%v confirmed:%v", deaths, confirmed)
+	}
+
+	// Copies for a period keep strata aligned
+	d := s.Days(1)
+	if len(d.Strata) != 3 || d.Strata[1].Deaths[0] != 8 {
+		t.Fatalf("test: strata days wrong:%v", d.Strata)
+	}
+}
@@ -0,0 +1,67 @@
+package covid
+
+import (
+	"time"
+)
+
+// summaryMovers is the number of movers included in a summary
+const summaryMovers = 5
+
+// Summary holds the figures shown in the global dashboard header
+type Summary struct {
+	Confirmed      int       `json:"confirmed"`
+	Deaths         int       `json:"deaths"`
+	ConfirmedToday int       `json:"confirmed_today"`
+	DeathsToday    int       `json:"deaths_today"`
+	Countries      int       `json:"countries"`
+	Rising         int       `json:"rising"`
+	Falling        int       `json:"falling"`
+	Movers         *Movers   `json:"movers"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Summary returns global totals, today's figures, the number of countries with rising and falling cases
+// and the countries with the biggest change in daily cases since yesterday
+func (slice SeriesSlice) Summary() *Summary {
+	summary := &Summary{}
+	for _, s := range slice {
+		if s.UpdatedAt.After(summary.UpdatedAt) {
+			summary.UpdatedAt = s.UpdatedAt
+		}
+		if s.Global() {
+			summary.Confirmed = s.TotalConfirmed()
+			summary.Deaths = s.TotalDeaths()
+			summary.ConfirmedToday = todayValue(s.ConfirmedDaily)
+			summary.DeathsToday = todayValue(s.DeathsDaily)
+		}
+	}
+
+	for _, s := range slice.Countries() {
+		summary.Countries++
+		switch s.Trend(DataConfirmed) {
+		case TrendRising:
+			summary.Rising++
+		case TrendFalling:
+			summary.Falling++
+		}
+	}
+
+	// Daily confirmed is always a valid metric so we can ignore the error
+	summary.Movers, _ = slice.Movers(MetricConfirmedDaily, 1, summaryMovers)
+	return summary
+}
+
+// FetchSummary uses our stored data to return a summary for the dashboard
+func FetchSummary() *Summary {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.Summary()
+}
+
+// abs returns the absolute value of i
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,58 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+// seriesFromDaily returns a series with the given daily confirmed cases and no deaths
+func seriesFromDaily(country, province string, daily ...int) *Series {
+	s := &Series{Country: country, Province: province, StartsAt: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)}
+	total := 0
+	for _, d := range daily {
+		total += d
+		s.Confirmed = append(s.Confirmed, total)
+		s.Deaths = append(s.Deaths, 0)
+	}
+	s.UpdateDaily()
+	return s
+}
+
+func TestSummary(t *testing.T) {
+	italy := seriesFromDaily("Italy", "", 1, 1, 1, 1, 1, 1, 1, 5, 5, 5, 5, 5, 5, 20)
+	spain := seriesFromDaily("Spain", "", 5, 5, 5, 5, 5, 5, 5, 1, 1, 1, 1, 1, 1, 0)
+	france := seriesFromDaily("France", "", 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2)
+	madrid := seriesFromDaily("Spain", "Madrid", 0, 0, 0, 0, 0, 0, 0, 9, 9, 9, 9, 9, 9, 9)
+	hidden := seriesFromDaily("Junk", "", 0, 0, 0, 0, 0, 0, 0, 9, 9, 9, 9, 9, 9, 9)
+	hidden.Hidden = true
+	global := seriesFromDaily("", "", 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 22)
+	global.Deaths[13] = 7
+	global.UpdateDaily()
+	spain.UpdatedAt = time.Date(2020, 3, 14, 12, 0, 0, 0, time.UTC)
+	madrid.UpdatedAt = time.Date(2020, 3, 14, 18, 0, 0, 0, time.UTC)
+
+	summary := SeriesSlice{global, italy, spain, france, madrid, hidden}.Summary()
+
+	// Totals and today's figures are from the global series
+	if summary.Confirmed != 126 || summary.ConfirmedToday != 22 || summary.Deaths != 7 || summary.DeathsToday != 7 {
+		t.Fatalf("test: summary wrong totals got:%+v", summary)
+	}
+	// Trends are counted for visible countries only
+	if summary.Countries != 3 || summary.Rising != 1 || summary.Falling != 1 {
+		t.Fatalf("test: summary wanted 3 countries 1 rising 1 falling got:%d %d %d", summary.Countries, summary.Rising, summary.Falling)
+	}
+	if !summary.UpdatedAt.Equal(madrid.UpdatedAt) {
+		t.Fatalf("test: summary wanted latest update got:%s", summary.UpdatedAt)
+	}
+	// Movers compare today with yesterday
+	m := summary.Movers
+	if m == nil || m.Window != 1 || len(m.Increases) != 1 || m.Increases[0].Country != "Italy" || m.Increases[0].Change != 15 || len(m.Decreases) != 1 || m.Decreases[0].Country != "Spain" {
+		t.Fatalf("test: summary wrong movers got:%+v", m)
+	}
+
+	// An empty slice has an empty summary
+	summary = SeriesSlice{}.Summary()
+	if summary.Confirmed != 0 || summary.Countries != 0 || summary.Movers == nil || len(summary.Movers.Increases) != 0 {
+		t.Fatalf("test: summary wanted empty got:%+v", summary)
+	}
+}
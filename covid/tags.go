@@ -0,0 +1,121 @@
+package covid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// DefaultTags are the curated groupings of countries applied to series by tag
+var DefaultTags = map[string][]string{
+	"G7":    {"Canada", "France", "Germany", "Italy", "Japan", "United Kingdom", "US"},
+	"ASEAN": {"Brunei", "Burma", "Cambodia", "Indonesia", "Laos", "Malaysia", "Philippines", "Singapore", "Thailand", "Vietnam"},
+}
+
+// tags are the series for each tag by country name or country/province, applied on load
+var tags = DefaultTags
+
+// LoadTags replaces the tags applied to series with those in a json config at path
+// which is an object of lists of country names or country/province keyed by tag
+func LoadTags(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("tags: error reading config:%s", err)
+	}
+	var config map[string][]string
+	err = json.Unmarshal(b, &config)
+	if err != nil {
+		return fmt.Errorf("tags: error parsing config:%s", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	tags = config
+	data = data.copyAll()
+	data.applyTags()
+	return nil
+}
+
+// Tags returns the names of all tags in use, sorted
+func Tags() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	var list []string
+	for t := range tags {
+		list = append(list, t)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// HasTag returns true if this series has tag (matched by name or key)
+func (s *Series) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if Slug(t) == Slug(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTags sets the tags on each series in slice from our tags config
+func (slice SeriesSlice) applyTags() {
+	members := make(map[string][]string)
+	for tag, list := range tags {
+		for _, name := range list {
+			key := tagKey(name)
+			members[key] = append(members[key], tag)
+		}
+	}
+	for _, s := range slice {
+		s.Tags = nil
+		if s.Global() {
+			continue
+		}
+		s.Tags = append(s.Tags, members[s.Slug()]...)
+		sort.Strings(s.Tags)
+	}
+}
+
+// tagKey returns the slug for a country name or country/province in a tags config
+func tagKey(name string) string {
+	parts := strings.Split(name, "/")
+	for i, p := range parts {
+		parts[i] = Slug(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// WithTag returns the series in slice with tag
+func (slice SeriesSlice) WithTag(tag string) SeriesSlice {
+	var list SeriesSlice
+	for _, s := range slice {
+		if s.HasTag(tag) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// FetchTag uses our stored data to build the series for tag
+func FetchTag(tag string) (*Series, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.TagSeries(tag)
+}
+
+// TagSeries returns a series summing the series in slice with tag (matched by name or key)
+func (slice SeriesSlice) TagSeries(tag string) (*Series, error) {
+	for t := range tags {
+		if Slug(t) == Slug(tag) {
+			list := slice.WithTag(t)
+			if len(list) == 0 {
+				break
+			}
+			return aggregate(t, list), nil
+		}
+	}
+	return &Series{}, fmt.Errorf("series: no data for tag:%s", tag)
+}
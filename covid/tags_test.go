@@ -0,0 +1,41 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTags(t *testing.T) {
+	defer func() { tags = DefaultTags }()
+	tags = map[string][]string{
+		"island-nation": {"Japan", "Iceland"},
+		"G7":            {"Japan", "US/New York"},
+	}
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		&Series{Country: "Japan", StartsAt: start, Deaths: []int{1, 2}, Confirmed: []int{10, 20}},
+		&Series{Country: "Iceland", StartsAt: start, Deaths: []int{0, 1}, Confirmed: []int{5, 6}},
+		&Series{Country: "US", Province: "New York", StartsAt: start, Deaths: []int{3, 4}, Confirmed: []int{30, 40}},
+		&Series{Country: "Spain", StartsAt: start, Deaths: []int{5, 6}, Confirmed: []int{50, 60}},
+	}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+	slice.applyTags()
+
+	if len(slice[0].Tags) != 2 || slice[0].Tags[0] != "G7" || len(slice[3].Tags) != 0 {
+		t.Fatalf("test: tags wanted:[G7 island-nation] got:%v", slice[0].Tags)
+	}
+	if len(slice.WithTag("g7")) != 2 || len(slice.WithTag("island-nation")) != 2 {
+		t.Fatalf("test: with tag wanted:2 got:%d", len(slice.WithTag("g7")))
+	}
+
+	s, err := slice.TagSeries("island-nation")
+	if err != nil {
+		t.Fatalf("test: tag series error:%s", err)
+	}
+	if s.Title() != "island-nation" || s.TotalConfirmed() != 26 {
+		t.Fatalf("test: tag series wanted:26 got:%d", s.TotalConfirmed())
+	}
+}
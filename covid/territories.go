@@ -0,0 +1,34 @@
+package covid
+
+// GroupTerritories is the option group for provinces which are overseas territories
+const GroupTerritories = "Overseas Territories"
+
+// territoryData lists the provinces in the dataset which are overseas territories, by sovereign country
+// these are separate places rather than a breakdown of the country, so are shown apart from other provinces
+var territoryData = map[string][]string{
+	"Australia": {"Christmas Island", "Cocos (Keeling) Islands", "Norfolk Island"},
+	"Denmark":   {"Faroe Islands", "Greenland"},
+	"France": {"French Guiana", "French Polynesia", "Guadeloupe", "Martinique", "Mayotte", "New Caledonia",
+		"Reunion", "Saint Barthelemy", "Saint Pierre and Miquelon", "St Martin", "Wallis and Futuna"},
+	"Netherlands": {"Aruba", "Bonaire, Sint Eustatius and Saba", "Curacao", "Sint Maarten"},
+	"New Zealand": {"Cook Islands", "Niue"},
+	"United Kingdom": {"Anguilla", "Bermuda", "British Virgin Islands", "Cayman Islands", "Channel Islands",
+		"Falkland Islands (Malvinas)", "Falkland Islands (Islas Malvinas)", "Gibraltar", "Guernsey", "Isle of Man", "Jersey",
+		"Montserrat", "Pitcairn Islands", "Saint Helena, Ascension and Tristan da Cunha", "Turks and Caicos Islands"},
+}
+
+// territories is the set of overseas territories indexed by country and province
+var territories = make(map[[2]string]bool)
+
+func init() {
+	for country, provinces := range territoryData {
+		for _, province := range provinces {
+			territories[[2]string{country, province}] = true
+		}
+	}
+}
+
+// IsTerritory returns true if province is an overseas territory of country rather than part of it
+func IsTerritory(country, province string) bool {
+	return territories[[2]string{country, province}]
+}
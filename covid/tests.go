@@ -0,0 +1,87 @@
+package covid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// positivityDays is the window used to smooth positivity rates, as tests are often reported irregularly
+const positivityDays = 7
+
+// mergeTestsCSV merges testing data into existing series
+// the csv has a header row and columns country,date,total_tests with cumulative tests
+// rows for unknown countries or dates outside a series are ignored
+func (slice SeriesSlice) mergeTestsCSV(records [][]string) (SeriesSlice, error) {
+	for i, row := range records {
+		if i == 0 {
+			if len(row) < 3 || strings.ToLower(row[0]) != "country" {
+				return slice, fmt.Errorf("load: invalid testing csv header:%v", row)
+			}
+			continue
+		}
+		if len(row) < 3 {
+			return slice, fmt.Errorf("load: invalid testing csv row:%d", i)
+		}
+
+		date, err := time.Parse("2006-01-02", row[1])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid testing date row:%d:%s", i, err)
+		}
+		tests, err := strconv.Atoi(row[2])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid tests row:%d:%s", i, err)
+		}
+
+		s, err := slice.FetchSeries(row[0], "")
+		if err != nil {
+			continue
+		}
+		day := daysBetween(s.StartsAt, date)
+		if day < 0 || day >= len(s.Confirmed) {
+			continue
+		}
+		if s.Tests == nil {
+			s.Tests = make([]int, len(s.Confirmed))
+		}
+		s.Tests[day] = tests
+	}
+	return slice, nil
+}
+
+// PositivityRate returns the percentage of tests which were positive for each day,
+// smoothed over the previous 7 days, or nil if we have no testing data
+// days where tests are unknown at either end of the window are 0
+func (s *Series) PositivityRate() []float64 {
+	if s.Tests == nil {
+		return nil
+	}
+	rates := make([]float64, len(s.Tests))
+	for i := range s.Tests {
+		if i < positivityDays || i >= len(s.Confirmed) {
+			continue
+		}
+		start := i - positivityDays
+		if s.Tests[i] == 0 || s.Tests[start] == 0 {
+			continue
+		}
+		tests := s.Tests[i] - s.Tests[start]
+		if tests <= 0 {
+			continue
+		}
+		rates[i] = float64(s.Confirmed[i]-s.Confirmed[start]) / float64(tests) * 100
+	}
+	return rates
+}
+
+// ValidateTests returns an error if the testing data for this series is inconsistent
+// a positivity rate over 100% means cases or tests are wrong
+func (s *Series) ValidateTests() error {
+	for i, rate := range s.PositivityRate() {
+		if rate > 100 {
+			return fmt.Errorf("series: %s positivity %.0f%% on %s", s.Title(), rate, s.StartsAt.AddDate(0, 0, i).Format("2006-01-02"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPositivityRate(t *testing.T) {
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Italy", StartsAt: start, Confirmed: []int{0, 10, 20, 30, 40, 50, 60, 70, 80}, Deaths: make([]int, 9)},
+	}
+	records := [][]string{
+		{"country", "date", "total_tests"},
+		{"italy", "2020-03-01", "100"},
+		{"italy", "2020-03-08", "800"},
+		{"italy", "2020-03-09", "1000"},
+		{"spain", "2020-03-09", "1000"},
+	}
+	slice, err := slice.MergeCSV(records, DataTests)
+	if err != nil {
+		t.Fatalf("test: merge tests error:%s", err)
+	}
+
+	// 70 cases from 700 tests over the week to Mar 8, second day has no tests a week before
+	rates := slice[0].PositivityRate()
+	if len(rates) != 9 || rates[7] != 10 || rates[8] != 0 {
+		t.Fatalf("test: positivity wrong:%v", rates)
+	}
+	if slice[0].ValidateTests() != nil {
+		t.Fatalf("test: valid tests rejected")
+	}
+
+	slice[0].Tests[7] = 110
+	if slice[0].ValidateTests() == nil {
+		t.Fatalf("test: positivity over 100%% accepted")
+	}
+}
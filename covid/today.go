@@ -0,0 +1,40 @@
+package covid
+
+import (
+	"fmt"
+	"time"
+)
+
+// The timezone and hour at which today rolls over for daily data
+var (
+	dayLocation   = time.UTC
+	dayCutoffHour = 0
+)
+
+// SetDayCutoff sets the timezone and hour in that timezone at which today rolls over
+// so that a deployment in Bangkok can merge daily data into the local day, defaults to midnight UTC
+func SetDayCutoff(location *time.Location, hour int) error {
+	if location == nil {
+		return fmt.Errorf("today: missing location")
+	}
+	if hour < 0 || hour > 23 {
+		return fmt.Errorf("today: invalid cutoff hour:%d", hour)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	dayLocation = location
+	dayCutoffHour = hour
+	return nil
+}
+
+// Today returns the date of the current day for daily data as midnight UTC
+func Today() time.Time {
+	return dayAt(time.Now())
+}
+
+// dayAt returns the date of the day for daily data which t falls in, as midnight UTC
+// times before the cutoff hour in our timezone belong to the day before
+func dayAt(t time.Time) time.Time {
+	local := t.In(dayLocation).Add(-time.Duration(dayCutoffHour) * time.Hour)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
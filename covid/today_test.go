@@ -0,0 +1,35 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDayAt(t *testing.T) {
+	defer func() { dayLocation, dayCutoffHour = time.UTC, 0 }()
+
+	// 20:00 UTC on 1 March is 03:00 on 2 March in Bangkok
+	at := time.Date(2020, 3, 1, 20, 0, 0, 0, time.UTC)
+	if d := dayAt(at); d.Day() != 1 {
+		t.Fatalf("test: day utc wanted:1 got:%d", d.Day())
+	}
+
+	bangkok := time.FixedZone("ICT", 7*60*60)
+	err := SetDayCutoff(bangkok, 0)
+	if err != nil {
+		t.Fatalf("test: cutoff error:%s", err)
+	}
+	if d := dayAt(at); d.Day() != 2 || d.Location() != time.UTC {
+		t.Fatalf("test: day bangkok wanted:2 got:%s", d)
+	}
+
+	// With a cutoff at 06:00 local time, 03:00 is still the day before
+	SetDayCutoff(bangkok, 6)
+	if d := dayAt(at); d.Day() != 1 {
+		t.Fatalf("test: day bangkok cutoff wanted:1 got:%d", d.Day())
+	}
+
+	if SetDayCutoff(bangkok, 24) == nil {
+		t.Fatalf("test: cutoff wanted error for hour 24")
+	}
+}
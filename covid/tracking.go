@@ -0,0 +1,100 @@
+package covid
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// usStates maps US state and territory codes used by the COVID Tracking Project to province names in our dataset
+var usStates = map[string]string{
+	"AK": "Alaska", "AL": "Alabama", "AR": "Arkansas", "AS": "American Samoa", "AZ": "Arizona",
+	"CA": "California", "CO": "Colorado", "CT": "Connecticut", "DC": "District of Columbia", "DE": "Delaware",
+	"FL": "Florida", "GA": "Georgia", "GU": "Guam", "HI": "Hawaii", "IA": "Iowa",
+	"ID": "Idaho", "IL": "Illinois", "IN": "Indiana", "KS": "Kansas", "KY": "Kentucky",
+	"LA": "Louisiana", "MA": "Massachusetts", "MD": "Maryland", "ME": "Maine", "MI": "Michigan",
+	"MN": "Minnesota", "MO": "Missouri", "MP": "Northern Mariana Islands", "MS": "Mississippi", "MT": "Montana",
+	"NC": "North Carolina", "ND": "North Dakota", "NE": "Nebraska", "NH": "New Hampshire", "NJ": "New Jersey",
+	"NM": "New Mexico", "NV": "Nevada", "NY": "New York", "OH": "Ohio", "OK": "Oklahoma",
+	"OR": "Oregon", "PA": "Pennsylvania", "PR": "Puerto Rico", "RI": "Rhode Island", "SC": "South Carolina",
+	"SD": "South Dakota", "TN": "Tennessee", "TX": "Texas", "UT": "Utah", "VA": "Virginia",
+	"VI": "Virgin Islands", "VT": "Vermont", "WA": "Washington", "WI": "Wisconsin", "WV": "West Virginia",
+	"WY": "Wyoming",
+}
+
+// trackingColumns maps columns in the COVID Tracking Project history to auxiliary series
+var trackingColumns = map[string]string{
+	"hospitalizedCurrently": AuxiliaryHospitalOccupancy,
+	"inIcuCurrently":        AuxiliaryICUOccupancy,
+}
+
+// mergeTrackingCSV merges hospital, ICU and testing history for US states from the archived
+// COVID Tracking Project all-states-history.csv into state series
+// tests are only used for days where we have none from other sources
+func (slice SeriesSlice) mergeTrackingCSV(records [][]string) (SeriesSlice, error) {
+	if len(records) == 0 {
+		return slice, fmt.Errorf("load: empty tracking csv")
+	}
+
+	columns := csvColumns(records[0])
+	date, okDate := columns["date"]
+	state, okState := columns["state"]
+	tests, okTests := columns["totalTestResults"]
+	if !okDate || !okState {
+		return slice, fmt.Errorf("load: invalid tracking csv header")
+	}
+
+	series := make(map[string]*Series)
+	for i, row := range records[1:] {
+		if len(row) != len(records[0]) {
+			return slice, fmt.Errorf("load: invalid tracking csv row:%d", i+1)
+		}
+
+		s, ok := series[row[state]]
+		if !ok {
+			s, _ = slice.FetchSeries("US", usStates[row[state]])
+			if s != nil && s.Province == "" {
+				s = nil
+			}
+			series[row[state]] = s
+		}
+		if s == nil {
+			continue
+		}
+
+		d, err := time.Parse("2006-01-02", row[date])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid tracking date row:%d:%s", i+1, err)
+		}
+		for column, name := range trackingColumns {
+			c, ok := columns[column]
+			if !ok || row[c] == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(row[c], 64)
+			if err != nil {
+				return slice, fmt.Errorf("load: invalid tracking value row:%d:%s", i+1, err)
+			}
+			s.setAuxiliary(name, d, v)
+		}
+
+		if okTests && row[tests] != "" {
+			v, err := strconv.Atoi(row[tests])
+			if err != nil {
+				return slice, fmt.Errorf("load: invalid tracking tests row:%d:%s", i+1, err)
+			}
+			day := daysBetween(s.StartsAt, d)
+			if day < 0 || day >= len(s.Confirmed) {
+				continue
+			}
+			if s.Tests == nil {
+				s.Tests = make([]int, len(s.Confirmed))
+			}
+			if s.Tests[day] == 0 {
+				s.Tests[day] = v
+			}
+		}
+	}
+
+	return slice, nil
+}
@@ -0,0 +1,47 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTracking(t *testing.T) {
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "US", StartsAt: start, Deaths: make([]int, 3), Confirmed: make([]int, 3)},
+		{Country: "US", Province: "New York", StartsAt: start, Deaths: make([]int, 3), Confirmed: make([]int, 3), Tests: []int{0, 0, 999}},
+	}
+	records := [][]string{
+		{"date", "state", "hospitalizedCurrently", "inIcuCurrently", "totalTestResults"},
+		{"2020-03-03", "NY", "120", "30", "500"},
+		{"2020-03-02", "NY", "100", "", "400"},
+		{"2020-03-02", "ZZ", "5", "5", "5"},
+	}
+	slice, err := slice.MergeCSV(records, DataTracking)
+	if err != nil {
+		t.Fatalf("test: merge tracking error:%s", err)
+	}
+	slice.fillAuxiliary()
+
+	ny := slice[1]
+	hospital := ny.HospitalOccupancy()
+	if len(hospital) != 3 || hospital[1] != 100 || hospital[2] != 120 {
+		t.Fatalf("test: tracking wrong hospital occupancy got:%v", hospital)
+	}
+	if icu := ny.ICUOccupancy(); len(icu) != 3 || icu[1] != 0 || icu[2] != 30 {
+		t.Fatalf("test: tracking wrong icu occupancy got:%v", icu)
+	}
+	// Tests are only backfilled where we have none
+	if ny.Tests[1] != 400 || ny.Tests[2] != 999 {
+		t.Fatalf("test: tracking wrong tests got:%v", ny.Tests)
+	}
+	if slice[0].HospitalOccupancy() != nil {
+		t.Fatalf("test: tracking set for country")
+	}
+
+	records[1][0] = "invalid"
+	if _, err := slice.MergeCSV(records, DataTracking); err == nil {
+		t.Fatalf("test: tracking wanted error for invalid date")
+	}
+}
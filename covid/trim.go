@@ -0,0 +1,81 @@
+package covid
+
+import "time"
+
+// trimZeros sets whether leading days with no cases or deaths are trimmed from charts by default, set on startup
+var trimZeros bool
+
+// SetTrimZeros sets whether leading days with no cases or deaths are trimmed from charts and pages by default
+// requests may override this with ?trim=1 or ?trim=0
+func SetTrimZeros(trim bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	trimZeros = trim
+}
+
+// TrimRequested returns true if leading zero days should be trimmed for the query value v (1 or 0),
+// or the default if v is blank or invalid
+func TrimRequested(v string) bool {
+	switch v {
+	case "1", "true":
+		return true
+	case "0", "false":
+		return false
+	}
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return trimZeros
+}
+
+// LeadingZeros returns the number of days at the start of this series with no cases or deaths
+func (s *Series) LeadingZeros() int {
+	for i := range s.Deaths {
+		if s.Deaths[i] != 0 || (i < len(s.Confirmed) && s.Confirmed[i] != 0) {
+			return i
+		}
+	}
+	return len(s.Deaths)
+}
+
+// TrimZeros returns a copy of this series starting at the first day with cases or deaths,
+// or the series itself if it has no leading zero days or no cases at all
+func (s *Series) TrimZeros() *Series {
+	n := s.LeadingZeros()
+	if n == 0 || n == len(s.Deaths) {
+		return s
+	}
+	return s.Days(len(s.Deaths) - n)
+}
+
+// leadingZeroValues returns the number of values at the start of values which are zero
+func leadingZeroValues(values []int) int {
+	for i, v := range values {
+		if v != 0 {
+			return i
+		}
+	}
+	return len(values)
+}
+
+// trimZeros drops the leading days which are zero in every dataset from chart, recording the first date left
+// start is the date of the first label in the chart
+func (c *Chart) trimZeros(start time.Time) {
+	n := len(c.Data.Labels)
+	for _, d := range c.Data.Datasets {
+		if z := leadingZeroValues(d.Data); z < n {
+			n = z
+		}
+	}
+	if n == 0 || n == len(c.Data.Labels) {
+		return
+	}
+	c.Data.Labels = c.Data.Labels[n:]
+	for i, d := range c.Data.Datasets {
+		if n < len(d.Data) {
+			c.Data.Datasets[i].Data = d.Data[n:]
+		} else {
+			c.Data.Datasets[i].Data = []int{}
+		}
+	}
+	c.TrimmedStart = start.AddDate(0, 0, n).Format("2006-01-02")
+}
@@ -0,0 +1,41 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrimZeros(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	italy := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 0, 0, 1, 3}, Confirmed: []int{0, 0, 2, 5, 10}}
+	spain := &Series{Country: "Spain", StartsAt: start, Deaths: []int{0, 0, 0, 0, 2}, Confirmed: []int{0, 0, 0, 1, 8}}
+	for _, s := range []*Series{italy, spain} {
+		s.UpdateDaily()
+	}
+
+	if italy.LeadingZeros() != 2 {
+		t.Fatalf("test: leading zeros wanted:2 got:%d", italy.LeadingZeros())
+	}
+	trimmed := italy.TrimZeros()
+	if !trimmed.StartsAt.Equal(start.AddDate(0, 0, 2)) || len(trimmed.Confirmed) != 3 || trimmed.Confirmed[0] != 2 {
+		t.Fatalf("test: trim wrong start:%s values:%v", trimmed.StartsAt, trimmed.Confirmed)
+	}
+	empty := &Series{StartsAt: start, Deaths: []int{0, 0}, Confirmed: []int{0, 0}}
+	if empty.TrimZeros() != empty {
+		t.Fatalf("test: trim changed series with no cases")
+	}
+
+	// Charts keep the days on which any dataset has values
+	chart := ChartData([]*Series{italy, spain}, ChartSettings{Datum: DataConfirmed, TrimZeros: true})
+	if chart.TrimmedStart != "2020-01-24" || len(chart.Data.Labels) != 3 || len(chart.Data.Datasets[1].Data) != 3 || chart.Data.Datasets[1].Data[0] != 0 {
+		t.Fatalf("test: chart trim wrong start:%s labels:%v", chart.TrimmedStart, chart.Data.Labels)
+	}
+	chart = ChartData([]*Series{italy}, ChartSettings{Datum: DataConfirmed})
+	if chart.TrimmedStart != "" || len(chart.Data.Labels) != 5 {
+		t.Fatalf("test: chart trimmed without trim setting")
+	}
+
+	if !TrimRequested("1") || TrimRequested("0") || TrimRequested("") {
+		t.Fatalf("test: trim requested wrong for default off")
+	}
+}
@@ -0,0 +1,254 @@
+package covid
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TwitterBot posts a daily summary with a chart for each of its countries
+// each country is posted at most once per day, so revisions to the data later that day are not posted again
+type TwitterBot struct {
+	// Countries to post for, blank for global
+	Countries []string
+	// SettleHour is the hour (UTC) after which the day's data is considered settled
+	SettleHour int
+	// StatePath is a file used to record the dates posted, so that restarts don't repost
+	StatePath string
+
+	ConsumerKey    string
+	ConsumerSecret string
+	AccessToken    string
+	AccessSecret   string
+
+	// posted records the last date posted for each country
+	posted map[string]string
+}
+
+// NewTwitterBot returns a bot for countries, loading the record of previous posts from statePath if it exists
+func NewTwitterBot(countries []string, statePath string) (*TwitterBot, error) {
+	if len(countries) == 0 {
+		countries = []string{""}
+	}
+	b := &TwitterBot{
+		Countries:  countries,
+		SettleHour: 22,
+		StatePath:  statePath,
+		posted:     make(map[string]string),
+	}
+	if statePath != "" {
+		state, err := ioutil.ReadFile(statePath)
+		if err == nil {
+			err = json.Unmarshal(state, &b.posted)
+			if err != nil {
+				return nil, fmt.Errorf("twitter: error reading state:%s", err)
+			}
+		}
+	}
+	return b, nil
+}
+
+// Post posts a summary for each country not yet posted today, once the day's data has settled
+func (b *TwitterBot) Post() error {
+	now := time.Now().UTC()
+	if now.Hour() < b.SettleHour {
+		return nil
+	}
+	date := now.Format("2006-01-02")
+
+	for _, country := range b.Countries {
+		if b.posted[country] == date {
+			continue
+		}
+
+		mutex.RLock()
+		text, card, err := b.compose(country)
+		mutex.RUnlock()
+		if err != nil {
+			return err
+		}
+
+		mediaID, err := b.upload(card)
+		if err != nil {
+			return err
+		}
+		err = b.tweet(text, mediaID)
+		if err != nil {
+			return err
+		}
+
+		b.posted[country] = date
+		err = b.save()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compose returns the text and card image for the tweet for country, must be called with mutex locked
+func (b *TwitterBot) compose(country string) (string, []byte, error) {
+	s, err := data.FetchSeries(country, "")
+	if err != nil {
+		return "", nil, fmt.Errorf("twitter: unknown country:%s", country)
+	}
+
+	i := len(s.Confirmed) - 1
+	confirmed, deaths := s.dayChange(s.Confirmed, i), s.dayChange(s.Deaths, i)
+	text := fmt.Sprintf("%s %s update for %s\n\nNew cases: %s (%s vs yesterday) %s\nNew deaths: %s (%s vs yesterday) %s\n\nTotal: %s cases, %s deaths",
+		s.Flag(), s.Title(), time.Now().UTC().Format("Jan 2"),
+		s.Format(confirmed), signed(confirmed-s.dayChange(s.Confirmed, i-1)), s.TrendArrow(DataConfirmed),
+		s.Format(deaths), signed(deaths-s.dayChange(s.Deaths, i-1)), s.TrendArrow(DataDeaths),
+		s.ConfirmedDisplay(), s.DeathsDisplay())
+
+	card := &bytes.Buffer{}
+	err = s.WriteCard(card)
+	if err != nil {
+		return "", nil, err
+	}
+	return text, card.Bytes(), nil
+}
+
+// save writes the record of dates posted to our state file
+func (b *TwitterBot) save() error {
+	if b.StatePath == "" {
+		return nil
+	}
+	state, err := json.Marshal(b.posted)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(b.StatePath, state, 0644)
+}
+
+// upload uploads a png image and returns the media id for use in a tweet
+func (b *TwitterBot) upload(image []byte) (string, error) {
+	body := &bytes.Buffer{}
+	m := multipart.NewWriter(body)
+	f, err := m.CreateFormFile("media", "card.png")
+	if err != nil {
+		return "", err
+	}
+	f.Write(image)
+	m.Close()
+
+	u := "https://upload.twitter.com/1.1/media/upload.json"
+	req, err := http.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return "", fmt.Errorf("twitter: error creating request:%s", err)
+	}
+	req.Header.Set("Content-Type", m.FormDataContentType())
+
+	var media struct {
+		MediaID string `json:"media_id_string"`
+	}
+	err = b.do(req, &media)
+	if err != nil {
+		return "", err
+	}
+	return media.MediaID, nil
+}
+
+// tweet posts text with the media attached
+func (b *TwitterBot) tweet(text, mediaID string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"text":  text,
+		"media": map[string]interface{}{"media_ids": []string{mediaID}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://api.twitter.com/2/tweets", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("twitter: error creating request:%s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return b.do(req, nil)
+}
+
+// do signs and sends req, decoding the json response into v if not nil
+func (b *TwitterBot) do(req *http.Request, v interface{}) error {
+	req.Header.Set("Authorization", b.authorization(req.Method, req.URL))
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twitter: error in request:%s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("twitter: error in request status:%d %s", resp.StatusCode, msg)
+	}
+	if v != nil {
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+	return nil
+}
+
+// authorization returns an oauth 1.0a authorization header for a request
+// request bodies are json or multipart, so only query params are signed
+func (b *TwitterBot) authorization(method string, u *url.URL) string {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	return b.authorizationAt(method, u, nil, hex.EncodeToString(nonce), time.Now().Unix())
+}
+
+// authorizationAt returns an oauth 1.0a authorization header for a request with the given nonce and timestamp
+// form holds any form encoded body params, which are signed with the query params
+func (b *TwitterBot) authorizationAt(method string, u *url.URL, form url.Values, nonce string, timestamp int64) string {
+	oauth := map[string]string{
+		"oauth_consumer_key":     b.ConsumerKey,
+		"oauth_nonce":            nonce,
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(timestamp, 10),
+		"oauth_token":            b.AccessToken,
+		"oauth_version":          "1.0",
+	}
+
+	// The signature base includes oauth params, query params and form params sorted by key
+	var pairs []string
+	for k, v := range oauth {
+		pairs = append(pairs, oauthEscape(k)+"="+oauthEscape(v))
+	}
+	for _, params := range []url.Values{u.Query(), form} {
+		for k, list := range params {
+			for _, v := range list {
+				pairs = append(pairs, oauthEscape(k)+"="+oauthEscape(v))
+			}
+		}
+	}
+	sort.Strings(pairs)
+
+	base := strings.ToUpper(method) + "&" + oauthEscape(u.Scheme+"://"+u.Host+u.Path) + "&" + oauthEscape(strings.Join(pairs, "&"))
+	mac := hmac.New(sha1.New, []byte(oauthEscape(b.ConsumerSecret)+"&"+oauthEscape(b.AccessSecret)))
+	mac.Write([]byte(base))
+	oauth["oauth_signature"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	var header []string
+	for k, v := range oauth {
+		header = append(header, fmt.Sprintf(`%s="%s"`, oauthEscape(k), oauthEscape(v)))
+	}
+	sort.Strings(header)
+	return "OAuth " + strings.Join(header, ", ")
+}
+
+// oauthEscape percent encodes s as required by oauth
+func oauthEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
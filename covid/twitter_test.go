@@ -0,0 +1,42 @@
+package covid
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// oauthParam returns the unescaped value of name in an oauth authorization header
+func oauthParam(t *testing.T, header, name string) string {
+	for _, part := range strings.Split(strings.TrimPrefix(header, "OAuth "), ", ") {
+		if strings.HasPrefix(part, name+"=") {
+			v, err := url.QueryUnescape(strings.Trim(strings.TrimPrefix(part, name+"="), `"`))
+			if err != nil {
+				t.Fatalf("test: invalid oauth param:%s", part)
+			}
+			return v
+		}
+	}
+	t.Fatalf("test: no oauth param:%s in:%s", name, header)
+	return ""
+}
+
+func TestTwitterAuthorization(t *testing.T) {
+	// The example from the Twitter documentation for creating a signature
+	b := &TwitterBot{
+		ConsumerKey:    "xvz1evFS4wEEPTGEFPHBog",
+		ConsumerSecret: "kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw",
+		AccessToken:    "370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb",
+		AccessSecret:   "LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE",
+	}
+	u, _ := url.Parse("https://api.twitter.com/1.1/statuses/update.json?include_entities=true")
+	form := url.Values{"status": {"Hello Ladies + Gentlemen, a signed OAuth request!"}}
+	header := b.authorizationAt("POST", u, form, "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg", 1318622958)
+	if got := oauthParam(t, header, "oauth_signature"); got != "hCtSmYh+iHYCEqBWrE7C7hYmtUk=" {
+		t.Fatalf("test: twitter signature wanted:hCtSmYh+iHYCEqBWrE7C7hYmtUk= got:%s", got)
+	}
+	if !strings.Contains(header, `oauth_signature="hCtSmYh%2BiHYCEqBWrE7C7hYmtUk%3D"`) {
+		t.Fatalf("test: twitter signature not escaped in header:%s", header)
+	}
+
+}
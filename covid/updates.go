@@ -0,0 +1,130 @@
+package covid
+
+import (
+	"fmt"
+	"time"
+)
+
+// Update records when one source last updated one metric of a series
+type Update struct {
+	Metric    string    `json:"metric"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// setUpdate records that source updated metric at t, replacing any earlier update from the same source
+// the list is replaced rather than changed, as it may be shared with copies of this series
+func (s *Series) setUpdate(metric, source string, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	updates := make([]Update, 0, len(s.Updates)+1)
+	for _, u := range s.Updates {
+		if u.Metric != metric || u.Source != source {
+			updates = append(updates, u)
+		}
+	}
+	s.Updates = append(updates, Update{Metric: metric, Source: source, UpdatedAt: t})
+}
+
+// mergeUpdates records the updates of series on s, keeping the latest for each metric and source
+// as UpdatedAt does for aggregates
+func (s *Series) mergeUpdates(series *Series) {
+	for _, u := range series.Updates {
+		if t, ok := s.sourceUpdatedAt(u.Metric, u.Source); !ok || u.UpdatedAt.After(t) {
+			s.setUpdate(u.Metric, u.Source, u.UpdatedAt)
+		}
+	}
+}
+
+// carryUpdates records the updates of previous for metric on s, for values recovered from a previous load
+func (s *Series) carryUpdates(previous *Series, metric string) {
+	for _, u := range previous.Updates {
+		if u.Metric == metric {
+			s.setUpdate(u.Metric, u.Source, u.UpdatedAt)
+		}
+	}
+}
+
+// sourceUpdatedAt returns the time source last updated metric, and false if it never has
+func (s *Series) sourceUpdatedAt(metric, source string) (time.Time, bool) {
+	for _, u := range s.Updates {
+		if u.Metric == metric && u.Source == source {
+			return u.UpdatedAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MetricUpdatedAt returns the time metric was last updated by any source, or a zero time if unknown
+func (s *Series) MetricUpdatedAt(metric string) time.Time {
+	var t time.Time
+	for _, u := range s.Updates {
+		if u.Metric == metric && u.UpdatedAt.After(t) {
+			t = u.UpdatedAt
+		}
+	}
+	return t
+}
+
+// OldestUpdate returns the metric updated least recently and the time it was last updated
+// or a blank metric and UpdatedAt if we have no updates by metric
+func (s *Series) OldestUpdate() (string, time.Time) {
+	metric, oldest := "", s.UpdatedAt
+	for _, m := range []string{MetricDeaths, MetricConfirmed} {
+		t := s.MetricUpdatedAt(m)
+		if !t.IsZero() && (metric == "" || t.Before(oldest)) {
+			metric, oldest = m, t
+		}
+	}
+	return metric, oldest
+}
+
+// newestUpdate returns the latest of UpdatedAt and the updates by metric
+func (s *Series) newestUpdate() time.Time {
+	newest := s.UpdatedAt
+	for _, u := range s.Updates {
+		if u.UpdatedAt.After(newest) {
+			newest = u.UpdatedAt
+		}
+	}
+	return newest
+}
+
+// UpdateLag returns the days by which the metric updated least recently lags the latest update,
+// so that one metric left behind by a stale source is seen even though another is up to date
+func (s *Series) UpdateLag() int {
+	metric, oldest := s.OldestUpdate()
+	if metric == "" {
+		return 0
+	}
+	return daysBetween(oldest, s.newestUpdate())
+}
+
+// laggingDisplay returns a string to display when the metric updated least recently lags the others, or a blank string
+func (s *Series) laggingDisplay() string {
+	metric, oldest := s.OldestUpdate()
+	if metric == "" || s.UpdateLag() < 1 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s last updated at %s)", metric, oldest.In(dayLocation).Format("2006-01-02 15:04 MST"))
+}
+
+// setDailyUpdate records that source updated deaths and confirmed at t, as daily sources report both
+func (s *Series) setDailyUpdate(source string, t time.Time) {
+	s.setUpdate(MetricDeaths, source, t)
+	s.setUpdate(MetricConfirmed, source, t)
+}
+
+// setUpdates records that source updated metric at t for every series in slice
+func (slice SeriesSlice) setUpdates(metric, source string, t time.Time) {
+	for _, s := range slice {
+		s.setUpdate(metric, source, t)
+	}
+}
+
+// sourceNames are the names used for daily sources in updates, by data type
+var sourceNames = map[int]string{
+	DataTodayCountry: "cases_country.csv",
+	DataTodayState:   "cases_state.csv",
+}
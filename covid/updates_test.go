@@ -0,0 +1,46 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdates(t *testing.T) {
+	now := time.Date(2020, 3, 10, 12, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", UpdatedAt: now, Deaths: []int{1, 2}, Confirmed: []int{10, 20}}
+	s.UpdateDaily()
+
+	// Without updates by metric, UpdatedAt is used and nothing lags
+	if m, t0 := s.OldestUpdate(); m != "" || !t0.Equal(now) || s.UpdateLag() != 0 {
+		t.Fatalf("test: oldest update wanted:%s got:%s %s", now, m, t0)
+	}
+
+	// A stale confirmed file shows as the oldest update, and marks the series stale
+	s.setUpdate(MetricDeaths, "deaths.csv", now)
+	s.setUpdate(MetricConfirmed, "confirmed.csv", now.AddDate(0, 0, -4))
+	s.setDailyUpdate("cases_country.csv", now.AddDate(0, 0, -5))
+	if m, t0 := s.OldestUpdate(); m != MetricConfirmed || !t0.Equal(now.AddDate(0, 0, -4)) {
+		t.Fatalf("test: oldest update wanted confirmed got:%s %s", m, t0)
+	}
+	if s.UpdateLag() != 4 || !s.IsStale(DefaultStaleDays) {
+		t.Fatalf("test: update lag wanted:4 got:%d", s.UpdateLag())
+	}
+	if got := s.UpdatedAtDisplay(); got == "" || s.laggingDisplay() == "" {
+		t.Fatalf("test: updated at display wanted confirmed lag got:%s", got)
+	}
+
+	// Updates from the same source replace earlier ones, without changing the list shared with copies
+	c := s.Copy()
+	s.setUpdate(MetricConfirmed, "confirmed.csv", now)
+	if s.UpdateLag() != 0 || len(s.Updates) != 4 || c.UpdateLag() != 4 {
+		t.Fatalf("test: set update wanted lag:0 got:%d copy:%d", s.UpdateLag(), c.UpdateLag())
+	}
+
+	// Aggregates keep the latest update for each metric and source
+	global := &Series{}
+	global.mergeUpdates(c)
+	global.mergeUpdates(s)
+	if tc, _ := global.sourceUpdatedAt(MetricConfirmed, "confirmed.csv"); !tc.Equal(now) || len(global.Updates) != 4 {
+		t.Fatalf("test: merge updates wanted:%s got:%s", now, tc)
+	}
+}
@@ -0,0 +1,119 @@
+package covid
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// variantPrefix is the prefix for auxiliary series which hold the percentage share of sequences for a variant
+const variantPrefix = "variant_"
+
+// VariantShares returns the percentage of sequenced cases for each variant by day, keyed by variant name
+// or nil if we have no variant data
+func (s *Series) VariantShares() map[string][]float64 {
+	var shares map[string][]float64
+	for name, values := range s.Auxiliary {
+		if strings.HasPrefix(name, variantPrefix) {
+			if shares == nil {
+				shares = make(map[string][]float64)
+			}
+			shares[strings.TrimPrefix(name, variantPrefix)] = values
+		}
+	}
+	return shares
+}
+
+// Variants returns the names of variants we have data for, sorted by name
+func (s *Series) Variants() (variants []string) {
+	for name := range s.VariantShares() {
+		variants = append(variants, name)
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+// VariantCases returns estimated daily confirmed cases for each variant, by applying the variant shares to daily cases
+func (s *Series) VariantCases() map[string][]int {
+	shares := s.VariantShares()
+	if shares == nil {
+		return nil
+	}
+	cases := make(map[string][]int, len(shares))
+	for name, values := range shares {
+		v := make([]int, len(s.ConfirmedDaily))
+		for i := range v {
+			if i < len(values) {
+				v[i] = int(float64(s.ConfirmedDaily[i]) * values[i] / 100)
+			}
+		}
+		cases[name] = v
+	}
+	return cases
+}
+
+// DominantVariants returns the variant with the largest share on each day, blank where unknown
+func (s *Series) DominantVariants() []string {
+	dominant := make([]string, len(s.Deaths))
+	best := make([]float64, len(s.Deaths))
+	for _, name := range s.Variants() {
+		for i, v := range s.Auxiliary[variantPrefix+name] {
+			if i < len(dominant) && v > best[i] {
+				dominant[i] = name
+				best[i] = v
+			}
+		}
+	}
+	return dominant
+}
+
+// mergeVariantsCSV merges variant shares into country series
+// the csv has a header row with columns location,date,variant,perc_sequences as in the OWID variants dataset
+// which is derived from CoVariants and GISAID
+func (slice SeriesSlice) mergeVariantsCSV(records [][]string) (SeriesSlice, error) {
+	if len(records) == 0 {
+		return slice, fmt.Errorf("load: empty variants csv")
+	}
+
+	columns := csvColumns(records[0])
+	location, okLocation := columns["location"]
+	date, okDate := columns["date"]
+	variant, okVariant := columns["variant"]
+	share, okShare := columns["perc_sequences"]
+	if !okLocation || !okDate || !okVariant || !okShare {
+		return slice, fmt.Errorf("load: invalid variants csv header")
+	}
+
+	series := make(map[string]*Series)
+	for i, row := range records[1:] {
+		if len(row) != len(records[0]) {
+			return slice, fmt.Errorf("load: invalid variants csv row:%d", i+1)
+		}
+		if row[share] == "" {
+			continue
+		}
+
+		s, ok := series[row[location]]
+		if !ok {
+			s = slice.seriesForName(row[location])
+			series[row[location]] = s
+		}
+		if s == nil {
+			continue
+		}
+
+		d, err := time.Parse("2006-01-02", row[date])
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid variants date row:%d:%s", i+1, err)
+		}
+		v, err := strconv.ParseFloat(row[share], 64)
+		if err != nil {
+			return slice, fmt.Errorf("load: invalid variants share row:%d:%s", i+1, err)
+		}
+		s.setAuxiliary(variantPrefix+row[variant], d, v)
+	}
+
+	return slice, nil
+}
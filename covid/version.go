@@ -0,0 +1,101 @@
+package covid
+
+import (
+	"time"
+)
+
+// maxChanges is the number of dataset versions we keep changes for
+// clients asking for changes since an older version should fetch the full dataset again
+const maxChanges = 100
+
+// version is incremented each time a load changes the data, protected by mutex
+// it starts at the time we started in seconds, so that versions keep increasing across restarts
+// as we change the data far less often than once a second
+var version = int(time.Now().Unix())
+
+// changes stores a record of changes for recent versions, protected by mutex
+var changes []Change
+
+// Change records the dates changed in one series for a dataset version
+type Change struct {
+	Version  int       `json:"version"`
+	Country  string    `json:"country"`
+	Province string    `json:"province"`
+	Dates    []string  `json:"dates"`
+	At       time.Time `json:"at"`
+}
+
+// Version returns the current dataset version, which increases every time the data changes
+func Version() int {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return version
+}
+
+// Changes returns the changes to the data since the given version
+// if the changes since that version are no longer available, or since is a version we never had
+// (e.g. from before a restart), complete is false and clients should fetch all data again
+func Changes(since int) (list []Change, current int, complete bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	// If the first version we hold is after since+1 we have lost some changes
+	complete = since == version || (since < version && len(changes) > 0 && changes[0].Version <= since+1)
+
+	for _, c := range changes {
+		if c.Version > since {
+			list = append(list, c)
+		}
+	}
+	return list, version, complete
+}
+
+// updateVersion compares the newly loaded data with the previous data and if anything changed
+// increments the version and records the changes, must be called with mutex locked
+func updateVersion(previous, current SeriesSlice) {
+	now := time.Now().UTC()
+	var updated []Change
+
+	for _, s := range current {
+		p, _ := previous.FetchSeries(s.Country, s.Province)
+		dates := s.changedDates(p)
+		if len(dates) > 0 {
+			updated = append(updated, Change{Country: s.Country, Province: s.Province, Dates: dates, At: now})
+		}
+	}
+
+	addChanges(updated)
+}
+
+// addChanges increments the version and records the changes if there are any, must be called with mutex locked
+func addChanges(updated []Change) {
+	if len(updated) == 0 {
+		return
+	}
+
+	version++
+	for i := range updated {
+		updated[i].Version = version
+	}
+	changes = append(changes, updated...)
+
+	// Drop changes for versions we no longer keep
+	for len(changes) > 0 && changes[0].Version <= version-maxChanges {
+		changes = changes[1:]
+	}
+}
+
+// changedDates returns the dates for which the data in s differs from p
+// if p is empty (a new series) all dates are returned
+func (s *Series) changedDates(p *Series) (dates []string) {
+	d := s.StartsAt
+	for i := range s.Deaths {
+		if p == nil || !p.Valid() || !p.StartsAt.Equal(s.StartsAt) ||
+			i >= len(p.Deaths) || i >= len(p.Confirmed) || i >= len(s.Confirmed) ||
+			p.Deaths[i] != s.Deaths[i] || p.Confirmed[i] != s.Confirmed[i] {
+			dates = append(dates, d.Format("2006-01-02"))
+		}
+		d = d.AddDate(0, 0, 1)
+	}
+	return dates
+}
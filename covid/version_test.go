@@ -0,0 +1,45 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChanges(t *testing.T) {
+	mutex.Lock()
+	previousVersion, previousChanges := version, changes
+	version, changes = 10, nil
+	addChanges([]Change{{Country: "Italy", Dates: []string{"2020-03-01"}, At: time.Now()}})
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		version, changes = previousVersion, previousChanges
+		mutex.Unlock()
+	}()
+
+	list, current, complete := Changes(10)
+	if current != 11 || !complete || len(list) != 1 {
+		t.Fatalf("test: changes wanted version:11 complete got:%d %v %d", current, complete, len(list))
+	}
+	if _, _, complete = Changes(11); !complete {
+		t.Fatalf("test: changes wanted complete for current version")
+	}
+
+	// Versions we never had, e.g. from before a restart, must be fetched again in full
+	list, _, complete = Changes(57)
+	if complete || len(list) != 0 {
+		t.Fatalf("test: changes wanted incomplete for future version got:%v", complete)
+	}
+
+	// Versions before the changes we hold are incomplete too
+	if _, _, complete = Changes(5); complete {
+		t.Fatalf("test: changes wanted incomplete for lost versions")
+	}
+}
+
+func TestVersionSeed(t *testing.T) {
+	// Versions start from the time we started, so they keep increasing across restarts
+	if Version() < int(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Unix()) {
+		t.Fatalf("test: version wanted seeded from start time got:%d", Version())
+	}
+}
@@ -0,0 +1,290 @@
+package covid
+
+import (
+	"fmt"
+	"time"
+)
+
+// SeriesView is a read only view of a series for templates and api handlers
+// values are returned as copies, so a buggy template or handler can't change our stored data
+type SeriesView struct {
+	s *Series
+}
+
+// View returns a read only view of s
+func (s *Series) View() SeriesView {
+	return SeriesView{s: s}
+}
+
+// copyFloats returns a copy of values, or nil if values is nil
+func copyFloats(values []float64) []float64 {
+	if values == nil {
+		return nil
+	}
+	return append([]float64(nil), values...)
+}
+
+// Country returns the country of the series
+func (v SeriesView) Country() string { return v.s.Country }
+
+// Province returns the province of the series, blank for countries
+func (v SeriesView) Province() string { return v.s.Province }
+
+// Key returns the key used in urls for a country or province name
+func (v SeriesView) Key(name string) string { return v.s.Key(name) }
+
+// Slug returns the unique slug of the series
+func (v SeriesView) Slug() string { return v.s.Slug() }
+
+// Path returns the url path of the series for period
+func (v SeriesView) Path(period int) string { return v.s.Path(period) }
+
+// Title returns a display title for the series
+func (v SeriesView) Title() string { return v.s.Title() }
+
+// Flag returns the flag emoji for the country of the series
+func (v SeriesView) Flag() string { return v.s.Flag() }
+
+// Global returns true if this is the global series
+func (v SeriesView) Global() bool { return v.s.Global() }
+
+// Population returns the population of the series, 0 if unknown
+func (v SeriesView) Population() int { return v.s.Population() }
+
+// Tags returns a copy of the tags of the series
+func (v SeriesView) Tags() []string { return copyStrings(v.s.Tags) }
+
+// IncomeGroup returns the World Bank income group of the country
+func (v SeriesView) IncomeGroup() string { return v.s.IncomeGroup() }
+
+// WHORegion returns the WHO region of the country
+func (v SeriesView) WHORegion() string { return v.s.WHORegion() }
+
+// StartsAt returns the date the series starts
+func (v SeriesView) StartsAt() time.Time { return v.s.StartsAt }
+
+// UpdatedAt returns the time the series was last updated
+func (v SeriesView) UpdatedAt() time.Time { return v.s.UpdatedAt }
+
+// UpdatedAtDisplay returns a string to display the updated at date
+func (v SeriesView) UpdatedAtDisplay() string { return v.s.UpdatedAtDisplay() }
+
+// StaleSince returns the time from which the series is stale, zero if not stale
+func (v SeriesView) StaleSince() time.Time { return v.s.StaleSince }
+
+// Stale returns true if the series is stale because a source failed to load
+func (v SeriesView) Stale() bool { return v.s.Stale() }
+
+// DataAge returns the days since the series last changed
+func (v SeriesView) DataAge() int { return v.s.DataAge() }
+
+// Cadence returns the reporting cadence of the series
+func (v SeriesView) Cadence() string { return v.s.Cadence() }
+
+// CadenceNote returns a note on the reporting cadence for display
+func (v SeriesView) CadenceNote() string { return v.s.CadenceNote() }
+
+// Dates returns a copy of the date labels of the series
+func (v SeriesView) Dates() []string { return copyStrings(v.s.Dates()) }
+
+// Deaths returns a copy of the cumulative deaths by day
+func (v SeriesView) Deaths() []int { return copyInts(v.s.Deaths) }
+
+// Confirmed returns a copy of the cumulative confirmed cases by day
+func (v SeriesView) Confirmed() []int { return copyInts(v.s.Confirmed) }
+
+// DeathsDaily returns a copy of the daily deaths
+func (v SeriesView) DeathsDaily() []int { return copyInts(v.s.DeathsDaily) }
+
+// ConfirmedDaily returns a copy of the daily confirmed cases
+func (v SeriesView) ConfirmedDaily() []int { return copyInts(v.s.ConfirmedDaily) }
+
+// Tests returns a copy of the cumulative tests by day, nil if unknown
+func (v SeriesView) Tests() []int { return copyInts(v.s.Tests) }
+
+// MetricValues returns a copy of the values for the named metric
+func (v SeriesView) MetricValues(metric string) ([]int, error) {
+	values, err := v.s.MetricValues(metric)
+	return copyInts(values), err
+}
+
+// Auxiliary returns a copy of the auxiliary values of the series by name
+func (v SeriesView) Auxiliary() map[string][]float64 {
+	if v.s.Auxiliary == nil {
+		return nil
+	}
+	aux := make(map[string][]float64, len(v.s.Auxiliary))
+	for name, values := range v.s.Auxiliary {
+		aux[name] = copyFloats(values)
+	}
+	return aux
+}
+
+// TotalDeaths returns the deaths on the last day
+func (v SeriesView) TotalDeaths() int { return v.s.TotalDeaths() }
+
+// TotalConfirmed returns the confirmed cases on the last day
+func (v SeriesView) TotalConfirmed() int { return v.s.TotalConfirmed() }
+
+// DeathsDisplay returns the total deaths formatted for display
+func (v SeriesView) DeathsDisplay() string { return v.s.DeathsDisplay() }
+
+// ConfirmedDisplay returns the total confirmed cases formatted for display
+func (v SeriesView) ConfirmedDisplay() string { return v.s.ConfirmedDisplay() }
+
+// DeathsToday returns the deaths today formatted for display
+func (v SeriesView) DeathsToday() string { return v.s.DeathsToday() }
+
+// ConfirmedToday returns the confirmed cases today formatted for display
+func (v SeriesView) ConfirmedToday() string { return v.s.ConfirmedToday() }
+
+// Incidence14Per100k returns the cases in the last 14 days per 100k population
+func (v SeriesView) Incidence14Per100k() float64 { return v.s.Incidence14Per100k() }
+
+// Acceleration returns the change in the growth of daily cases
+func (v SeriesView) Acceleration() float64 { return v.s.Acceleration() }
+
+// PositivityRate returns a copy of the test positivity rate by day
+func (v SeriesView) PositivityRate() []float64 { return copyFloats(v.s.PositivityRate()) }
+
+// HospitalOccupancy returns a copy of the hospital occupancy by day
+func (v SeriesView) HospitalOccupancy() []float64 { return copyFloats(v.s.HospitalOccupancy()) }
+
+// ICUOccupancy returns a copy of the ICU occupancy by day
+func (v SeriesView) ICUOccupancy() []float64 { return copyFloats(v.s.ICUOccupancy()) }
+
+// VariantShares returns a copy of the share of each variant by day
+func (v SeriesView) VariantShares() map[string][]float64 {
+	shares := v.s.VariantShares()
+	if shares == nil {
+		return nil
+	}
+	c := make(map[string][]float64, len(shares))
+	for name, values := range shares {
+		c[name] = copyFloats(values)
+	}
+	return c
+}
+
+// DominantVariants returns the dominant variant by day
+func (v SeriesView) DominantVariants() []string { return copyStrings(v.s.DominantVariants()) }
+
+// View is a series prepared for one request, limited to a period with the requested metrics
+// calculated, smoothed and formatted, so that handlers don't repeat the same steps
+type View struct {
+	Country  string `json:"country"`
+	Province string `json:"province"`
+	Title    string `json:"title"`
+	Path     string `json:"path"`
+	// The number of days the view is limited to, 0 for all days
+	Period  int          `json:"period"`
+	Dates   []string     `json:"dates"`
+	Metrics []MetricView `json:"metrics"`
+
+	// The complete series, as windowed values like smoothed averages are calculated before limiting to the period
+	series *Series
+}
+
+// MetricView is the values of one metric for a view
+type MetricView struct {
+	Metric string `json:"metric"`
+	Values []int  `json:"values"`
+	// The trailing average of values over trendDays
+	Smoothed []int `json:"smoothed"`
+	// The last value, and the last value formatted for display e.g. 12.3k
+	Latest  int    `json:"latest"`
+	Display string `json:"display"`
+}
+
+// FetchView uses our stored data to prepare a view of a series for the last period days (0 for all days)
+// with the values of metrics, or the cumulative and daily deaths and confirmed if no metrics are given
+func FetchView(country, province string, period int, metrics ...string) (*View, error) {
+	s, err := FetchSeries(country, province)
+	if err != nil {
+		return nil, err
+	}
+	return s.PrepareView(period, metrics...)
+}
+
+// PrepareView returns a view of s for the last period days (0 for all days) with the values of metrics
+func (s *Series) PrepareView(period int, metrics ...string) (*View, error) {
+	if period < 0 {
+		return nil, fmt.Errorf("series: invalid period:%d", period)
+	}
+	if len(metrics) == 0 {
+		metrics = []string{MetricDeaths, MetricConfirmed, MetricDeathsDaily, MetricConfirmedDaily}
+	}
+
+	v := &View{
+		Country:  s.Country,
+		Province: s.Province,
+		Title:    s.Title(),
+		Path:     s.Path(period),
+		Period:   period,
+		Dates:    copyStrings(lastStrings(s.Dates(), period)),
+		series:   s,
+	}
+	for _, metric := range metrics {
+		values, err := s.MetricValues(metric)
+		if err != nil {
+			return nil, err
+		}
+		m := MetricView{
+			Metric:   metric,
+			Values:   copyInts(lastInts(values, period)),
+			Smoothed: lastInts(Smooth(values, trendDays), period),
+		}
+		if len(m.Values) > 0 {
+			m.Latest = m.Values[len(m.Values)-1]
+		}
+		m.Display = formatNumber(m.Latest)
+		v.Metrics = append(v.Metrics, m)
+	}
+	return v, nil
+}
+
+// Metric returns the values for metric in the view, or nil if it was not requested
+func (v *View) Metric(metric string) *MetricView {
+	for i := range v.Metrics {
+		if v.Metrics[i].Metric == metric {
+			return &v.Metrics[i]
+		}
+	}
+	return nil
+}
+
+// Series returns a read only view of the series limited to the period of the view
+func (v *View) Series() SeriesView {
+	if v.Period > 0 {
+		return v.series.Days(v.Period).View()
+	}
+	return v.series.View()
+}
+
+// Chart returns a chart of metric for the period of the view, daily metrics are drawn as bars with a smoothed line
+func (v *View) Chart(metric string) (*Chart, error) {
+	settings := ChartSettings{Days: v.Period}
+	switch metric {
+	case MetricDeaths, MetricDeathsDaily:
+		settings.Datum = DataDeaths
+	case MetricConfirmed, MetricConfirmedDaily:
+		settings.Datum = DataConfirmed
+	case MetricIncidence14:
+		settings.Datum = DataIncidence14
+	default:
+		return nil, fmt.Errorf("series: no chart for metric:%s", metric)
+	}
+	if metric == MetricDeathsDaily || metric == MetricConfirmedDaily {
+		settings.Daily = true
+		settings.Smoothed = true
+	}
+	return ChartData([]*Series{v.series}, settings), nil
+}
+
+// copyStrings returns a copy of values, or nil if values is nil
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string(nil), values...)
+}
@@ -0,0 +1,58 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeriesView(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3}, Confirmed: []int{1, 5, 10}, Tags: []string{"g7"}}
+	s.UpdateDaily()
+	s.SetAuxiliaryValues("stringency", []float64{10, 20, 30})
+	v := s.View()
+
+	if v.Country() != "Italy" || v.TotalDeaths() != 3 || len(v.Dates()) != 3 {
+		t.Fatalf("test: view wanted Italy deaths:3 got:%s %d", v.Country(), v.TotalDeaths())
+	}
+
+	// Changing values from a view must not change the series
+	v.Deaths()[2] = 100
+	v.DeathsDaily()[2] = 100
+	v.Tags()[0] = "changed"
+	v.Auxiliary()["stringency"][0] = 100
+	v.Dates()[0] = "changed"
+	if s.Deaths[2] != 3 || s.DeathsDaily[2] != 2 || s.Tags[0] != "g7" || s.AuxiliaryValues("stringency")[0] != 10 || s.Dates()[0] != "Jan 22" {
+		t.Fatalf("test: view changed series:%v %v %v", s.Deaths, s.Tags, s.Dates())
+	}
+}
+
+func TestPrepareView(t *testing.T) {
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	s := &Series{Country: "Italy", StartsAt: start, Deaths: make([]int, 10), Confirmed: []int{7, 14, 21, 28, 35, 42, 49, 56, 63, 1070}}
+	s.UpdateDaily()
+
+	v, err := s.PrepareView(3, MetricConfirmedDaily)
+	if err != nil {
+		t.Fatalf("test: prepare view error:%s", err)
+	}
+	m := v.Metric(MetricConfirmedDaily)
+	if len(v.Dates) != 3 || v.Dates[0] != "Jan 29" || m == nil || len(m.Values) != 3 || m.Latest != 1007 || m.Display != "1007" {
+		t.Fatalf("test: prepare view wanted 3 days latest:1007 got:%v %v", v.Dates, m)
+	}
+	// Smoothed values are averaged over the days before the period too
+	if m.Smoothed[0] != 7 || m.Smoothed[2] != 150 {
+		t.Fatalf("test: prepare view wanted smoothed [7 7 150] got:%v", m.Smoothed)
+	}
+	if v.Metric(MetricDeaths) != nil || len(v.Series().Deaths()) != 3 {
+		t.Fatalf("test: prepare view wanted only confirmed daily for 3 days")
+	}
+	chart, err := v.Chart(MetricConfirmedDaily)
+	if err != nil || chart.Type != "bar" || len(chart.Data.Labels) != 3 {
+		t.Fatalf("test: prepare view wanted bar chart of 3 days got:%v", err)
+	}
+
+	if _, err = s.PrepareView(0, "unknown"); err == nil {
+		t.Fatalf("test: prepare view wanted error for unknown metric")
+	}
+}
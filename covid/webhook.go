@@ -0,0 +1,157 @@
+package covid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// webhookAttempts is the number of times we try to deliver a webhook before giving up
+const webhookAttempts = 3
+
+// Webhook posts a json summary to a url whenever a load changes the data in its scope
+type Webhook struct {
+	// The url to post to
+	URL string `json:"url"`
+	// If set, the body is signed with this secret in the X-Covid-Signature header
+	Secret string `json:"secret"`
+	// The series to watch as country or country/province, blank for any change
+	Scope string `json:"scope"`
+
+	// version is the dataset version we last notified for
+	version int
+}
+
+// WebhookPayload is the json body posted to webhooks
+type WebhookPayload struct {
+	Version        int       `json:"version"`
+	Scope          string    `json:"scope"`
+	Title          string    `json:"title"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Confirmed      int       `json:"confirmed"`
+	Deaths         int       `json:"deaths"`
+	ConfirmedToday int       `json:"confirmed_today"`
+	DeathsToday    int       `json:"deaths_today"`
+	Changes        []Change  `json:"changes"`
+}
+
+// LoadWebhooks reads a json list of webhooks from the file at path
+// webhooks only fire for changes after they are loaded
+func LoadWebhooks(path string) ([]*Webhook, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: error reading config:%s", err)
+	}
+	var hooks []*Webhook
+	err = json.Unmarshal(b, &hooks)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: error parsing config:%s", err)
+	}
+	current := Version()
+	for _, h := range hooks {
+		if h.URL == "" {
+			return nil, fmt.Errorf("webhook: missing url")
+		}
+		h.version = current
+	}
+	return hooks, nil
+}
+
+// Notify posts a summary to the webhook if data in scope has changed since the last notification
+func (h *Webhook) Notify() error {
+	country, province := h.scope()
+
+	mutex.RLock()
+	series, err := data.FetchSeries(country, province)
+	mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("webhook: invalid scope:%s", h.Scope)
+	}
+
+	list, current, _ := Changes(h.version)
+	if current == h.version {
+		return nil
+	}
+	h.version = current
+
+	// Only notify if something in our scope changed
+	var changed []Change
+	for _, c := range list {
+		if h.Scope == "" || series.Match(c.Country, c.Province) ||
+			(province == "" && series.Key(c.Country) == series.Key(country)) {
+			changed = append(changed, c)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		Version:        current,
+		Scope:          h.Scope,
+		Title:          series.Title(),
+		UpdatedAt:      series.UpdatedAt,
+		Confirmed:      series.TotalConfirmed(),
+		Deaths:         series.TotalDeaths(),
+		ConfirmedToday: todayValue(series.ConfirmedDaily),
+		DeathsToday:    todayValue(series.DeathsDaily),
+		Changes:        changed,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Retry failed deliveries with increasing delays
+	for attempt := 1; ; attempt++ {
+		err = h.post(body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt*attempt) * 5 * time.Second)
+	}
+}
+
+// scope returns the country and province for the scope of this webhook
+func (h *Webhook) scope() (country, province string) {
+	parts := strings.SplitN(h.Scope, "/", 2)
+	country = parts[0]
+	if len(parts) > 1 {
+		province = parts[1]
+	}
+	return country, province
+}
+
+// post sends body to the webhook url, signed with our secret if we have one
+func (h *Webhook) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: error creating request:%s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Secret != "" {
+		req.Header.Set("X-Covid-Signature", "sha256="+hex.EncodeToString(h.Sign(body)))
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: error posting to %s:%s", h.URL, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: error posting to %s status:%d", h.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hmac sha256 of body using our secret
+// receivers should compare this with the X-Covid-Signature header
+func (h *Webhook) Sign(body []byte) []byte {
+	return hmacSHA256([]byte(h.Secret), string(body))
+}
@@ -0,0 +1,35 @@
+package covid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	// RFC 4231 test case 2 for hmac sha256
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	body := []byte("what do ya want for nothing?")
+
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Covid-Signature"))
+	}))
+	defer server.Close()
+
+	h := &Webhook{URL: server.URL, Secret: "Jefe"}
+	err := h.post(body)
+	if err != nil {
+		t.Fatalf("test: webhook post error:%s", err)
+	}
+
+	// Webhooks without a secret are not signed
+	h.Secret = ""
+	err = h.post(body)
+	if err != nil {
+		t.Fatalf("test: webhook post error:%s", err)
+	}
+	if len(got) != 2 || got[0] != want || got[1] != "" {
+		t.Fatalf("test: webhook signature wanted:%s got:%v", want, got)
+	}
+}
@@ -0,0 +1,93 @@
+package covid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats for dataset sources other than our own exports
+const (
+	// FormatWHO is the WHO global daily data csv from https://covid19.who.int/WHO-COVID-19-global-data.csv
+	FormatWHO = "who"
+)
+
+// countryForISO returns the name used in our dataset for an ISO2 or ISO3 code, or "" if unknown
+func countryForISO(code string) string {
+	for _, c := range countryData {
+		if c.ISO2 == code || c.ISO3 == code {
+			return c.Name
+		}
+	}
+	return ""
+}
+
+// readWHOCSV reads the WHO global daily data csv and returns a series per country
+// countries are matched by ISO code, as WHO names differ from those in our dataset
+func readWHOCSV(records [][]string) (SeriesSlice, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("load: empty who csv")
+	}
+
+	// The file starts with a byte order mark
+	header := records[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	columns := csvColumns(header)
+	date, okDate := columns["Date_reported"]
+	code, okCode := columns["Country_code"]
+	country, okCountry := columns["Country"]
+	confirmed, okConfirmed := columns["Cumulative_cases"]
+	deaths, okDeaths := columns["Cumulative_deaths"]
+	if !okDate || !okCode || !okCountry || !okConfirmed || !okDeaths {
+		return nil, fmt.Errorf("load: invalid who csv header")
+	}
+
+	// Cache names by code as the file has one row per country per day
+	names := make(map[string]string)
+	var rows []Row
+	for i, row := range records[1:] {
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("load: invalid who csv row:%d", i+1)
+		}
+
+		name, ok := names[row[code]+row[country]]
+		if !ok {
+			name = countryForISO(strings.TrimSpace(row[code]))
+			if name == "" {
+				name = row[country]
+				if n, ok := countryAliases[name]; ok {
+					name = n
+				}
+			}
+			names[row[code]+row[country]] = name
+		}
+
+		d, err := time.Parse("2006-01-02", row[date])
+		if err != nil {
+			return nil, fmt.Errorf("load: invalid who date row:%d:%s", i+1, err)
+		}
+		r := Row{Country: name, Date: d}
+		r.Confirmed, err = parseWHOValue(row[confirmed])
+		if err != nil {
+			return nil, fmt.Errorf("load: invalid who cases row:%d:%s", i+1, err)
+		}
+		r.Deaths, err = parseWHOValue(row[deaths])
+		if err != nil {
+			return nil, fmt.Errorf("load: invalid who deaths row:%d:%s", i+1, err)
+		}
+		rows = append(rows, r)
+	}
+
+	return SeriesFromRows(rows), nil
+}
+
+// parseWHOValue parses a cumulative value, which is blank in later files where none have been reported
+func parseWHOValue(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,37 @@
+package covid
+
+import (
+	"testing"
+)
+
+func TestReadWHOCSV(t *testing.T) {
+	records := [][]string{
+		{"\ufeffDate_reported", "Country_code", "Country", "WHO_region", "New_cases", "Cumulative_cases", "New_deaths", "Cumulative_deaths"},
+		{"2020-03-01", "US", "United States of America", "AMRO", "10", "10", "1", "1"},
+		{"2020-03-02", "US", "United States of America", "AMRO", "5", "15", "0", "1"},
+		{"2020-03-01", "GB", "The United Kingdom", "EURO", "3", "3", "0", "0"},
+		{"2020-03-01", " ", "Other", "Other", "0", "700", "0", ""},
+	}
+	slice, err := readWHOCSV(records)
+	if err != nil {
+		t.Fatalf("test: who error:%s", err)
+	}
+	if len(slice) != 3 {
+		t.Fatalf("test: who wanted len:3 got:%d", len(slice))
+	}
+	s, err := slice.FetchSeries("US", "")
+	if err != nil {
+		t.Fatalf("test: who missing US:%s", err)
+	}
+	if s.TotalConfirmed() != 15 || s.TotalDeaths() != 1 || s.ConfirmedDaily[1] != 5 {
+		t.Fatalf("test: who wrong data for US got:%v %v", s.Confirmed, s.Deaths)
+	}
+	if _, err := slice.FetchSeries("United Kingdom", ""); err != nil {
+		t.Fatalf("test: who missing United Kingdom:%s", err)
+	}
+
+	records[1][5] = "x"
+	if _, err := readWHOCSV(records); err == nil {
+		t.Fatalf("test: who wanted error for invalid value")
+	}
+}
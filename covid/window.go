@@ -0,0 +1,60 @@
+package covid
+
+import (
+	"time"
+)
+
+// Window holds aggregated values over a window of days
+type Window struct {
+	// The index of the first and last values in the window
+	Start int `json:"-"`
+	End   int `json:"-"`
+	// The dates of the first and last days in the window, set for windows of a series
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+
+	Sum  int     `json:"sum"`
+	Mean float64 `json:"mean"`
+	Max  int     `json:"max"`
+	Min  int     `json:"min"`
+}
+
+// Windows returns aggregates over sliding windows of n values, moving step values each time
+// windows are aligned to end on the last value and returned in order, incomplete windows at the start are omitted
+func Windows(values []int, n, step int) []Window {
+	if n < 1 || step < 1 || len(values) < n {
+		return nil
+	}
+
+	// Count the windows so that we can fill them in order
+	count := (len(values)-n)/step + 1
+	windows := make([]Window, count)
+	for w := range windows {
+		end := len(values) - 1 - (count-1-w)*step
+		start := end - n + 1
+		window := Window{Start: start, End: end, Max: values[start], Min: values[start]}
+		for _, v := range values[start : end+1] {
+			window.Sum += v
+			if v > window.Max {
+				window.Max = v
+			}
+			if v < window.Min {
+				window.Min = v
+			}
+		}
+		window.Mean = float64(window.Sum) / float64(n)
+		windows[w] = window
+	}
+	return windows
+}
+
+// Window returns aggregates of the daily values for datum over sliding windows of n days, moving step days each time
+// e.g. Window(DataConfirmed, 7, 7) returns weekly totals ending on the last day
+func (s *Series) Window(datum, n, step int) []Window {
+	windows := Windows(s.DailyValues(datum), n, step)
+	for i := range windows {
+		windows[i].StartsAt = s.StartsAt.AddDate(0, 0, windows[i].Start)
+		windows[i].EndsAt = s.StartsAt.AddDate(0, 0, windows[i].End)
+	}
+	return windows
+}
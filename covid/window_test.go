@@ -0,0 +1,25 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+
+	windows := Windows([]int{1, 2, 3, 4, 5, 6, 7}, 3, 2)
+	if len(windows) != 3 || windows[0].Start != 0 || windows[2].End != 6 {
+		t.Fatalf("test: windows wrong:%v", windows)
+	}
+	if windows[2].Sum != 18 || windows[2].Mean != 6 || windows[2].Max != 7 || windows[1].Min != 3 {
+		t.Fatalf("test: windows wrong aggregates:%v", windows[2])
+	}
+
+	start := time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC)
+	series := &Series{Country: "Italy", StartsAt: start, Deaths: []int{0, 1, 3, 6}, Confirmed: []int{1, 5, 10, 20}}
+	series.UpdateDaily()
+	weekly := series.Window(DataConfirmed, 2, 2)
+	if len(weekly) != 2 || weekly[1].Sum != 15 || !weekly[1].StartsAt.Equal(start.AddDate(0, 0, 2)) {
+		t.Fatalf("test: series window wrong:%v", weekly)
+	}
+}
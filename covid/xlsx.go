@@ -0,0 +1,243 @@
+package covid
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteXLSX writes an Excel workbook of the data in slice to w, with sheets:
+// Summary - totals per series
+// Confirmed, Deaths - one row per series with one column per day
+// Detail - one row per country per day
+func (slice SeriesSlice) WriteXLSX(w io.Writer) error {
+	z := zip.NewWriter(w)
+
+	sheets := []struct {
+		name  string
+		write func(*sheetWriter)
+	}{
+		{"Summary", slice.writeSummarySheet},
+		{"Confirmed", func(sw *sheetWriter) { slice.writeWideSheet(sw, DataConfirmed) }},
+		{"Deaths", func(sw *sheetWriter) { slice.writeWideSheet(sw, DataDeaths) }},
+		{"Detail", slice.writeDetailSheet},
+	}
+
+	// Write the package parts which describe the workbook
+	var names []string
+	for _, s := range sheets {
+		names = append(names, s.name)
+	}
+	err := writeXLSXParts(z, names)
+	if err != nil {
+		return err
+	}
+
+	// Write each sheet
+	for i, s := range sheets {
+		f, err := z.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		sw := &sheetWriter{w: bufio.NewWriter(f)}
+		sw.start()
+		s.write(sw)
+		sw.end()
+		if sw.err != nil {
+			return sw.err
+		}
+	}
+
+	return z.Close()
+}
+
+// WriteXLSX uses our stored data to write an Excel workbook
+func WriteXLSX(w io.Writer) error {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return data.WriteXLSX(w)
+}
+
+// writeSummarySheet writes one row of totals per series
+func (slice SeriesSlice) writeSummarySheet(sw *sheetWriter) {
+	sw.row("Country", "Province", "Confirmed", "Deaths", "Confirmed Today", "Deaths Today", "Population", "Updated At")
+	for _, s := range slice {
+		if s.Global() {
+			continue
+		}
+		updated := ""
+		if !s.UpdatedAt.IsZero() {
+			updated = s.UpdatedAt.Format("2006-01-02 15:04")
+		}
+		sw.row(s.Country, s.Province, s.TotalConfirmed(), s.TotalDeaths(),
+			todayValue(s.ConfirmedDaily), todayValue(s.DeathsDaily), s.Population(), updated)
+	}
+}
+
+// writeWideSheet writes one row per series with a column of cumulative values per day
+func (slice SeriesSlice) writeWideSheet(sw *sheetWriter, datum int) {
+	if len(slice) == 0 {
+		return
+	}
+	// Series may differ in length if daily data is missing, so use the longest for dates
+	longest := slice[0]
+	for _, s := range slice {
+		if len(s.Deaths) > len(longest.Deaths) {
+			longest = s
+		}
+	}
+	header := []interface{}{"Country", "Province"}
+	for _, d := range longest.isoDates() {
+		header = append(header, d)
+	}
+	sw.row(header...)
+
+	for _, s := range slice {
+		if s.Global() {
+			continue
+		}
+		row := []interface{}{s.Country, s.Province}
+		for _, v := range s.chartValues(datum, false) {
+			row = append(row, v)
+		}
+		sw.row(row...)
+	}
+}
+
+// writeDetailSheet writes one row per country per day
+func (slice SeriesSlice) writeDetailSheet(sw *sheetWriter) {
+	sw.row("Country", "Date", "Confirmed", "Deaths", "Confirmed Daily", "Deaths Daily")
+	for _, s := range slice.Countries() {
+		for i, d := range s.isoDates() {
+			if i >= len(s.Confirmed) || i >= len(s.ConfirmedDaily) || i >= len(s.DeathsDaily) {
+				break
+			}
+			sw.row(s.Country, d, s.Confirmed[i], s.Deaths[i], s.ConfirmedDaily[i], s.DeathsDaily[i])
+		}
+	}
+}
+
+// isoDates returns dates for every datapoint in this series in the format 2006-01-02
+func (s *Series) isoDates() (dates []string) {
+	d := s.StartsAt
+	for range s.Deaths {
+		dates = append(dates, d.Format("2006-01-02"))
+		d = d.AddDate(0, 0, 1)
+	}
+	return dates
+}
+
+// sheetWriter writes the xml for one worksheet, recording the first error
+type sheetWriter struct {
+	w   *bufio.Writer
+	r   int
+	err error
+}
+
+func (sw *sheetWriter) write(s string) {
+	if sw.err == nil {
+		_, sw.err = sw.w.WriteString(s)
+	}
+}
+
+func (sw *sheetWriter) start() {
+	sw.write(xml.Header)
+	sw.write(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+}
+
+func (sw *sheetWriter) end() {
+	sw.write(`</sheetData></worksheet>`)
+	if sw.err == nil {
+		sw.err = sw.w.Flush()
+	}
+}
+
+// row writes a row of cells - ints are written as numbers, anything else as an inline string
+func (sw *sheetWriter) row(cells ...interface{}) {
+	sw.r++
+	sw.write(fmt.Sprintf(`<row r="%d">`, sw.r))
+	for i, c := range cells {
+		ref := columnName(i) + strconv.Itoa(sw.r)
+		switch v := c.(type) {
+		case int:
+			sw.write(fmt.Sprintf(`<c r="%s"><v>%d</v></c>`, ref, v))
+		default:
+			sw.write(fmt.Sprintf(`<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escapeXML(fmt.Sprint(v))))
+		}
+	}
+	sw.write(`</row>`)
+}
+
+// columnName returns the spreadsheet column name for index i (0 = A, 26 = AA)
+func columnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// escapeXML escapes s for use in xml text
+func escapeXML(s string) string {
+	b := &strings.Builder{}
+	xml.EscapeText(b, []byte(s))
+	return b.String()
+}
+
+// writeXLSXParts writes the content types, relationships, workbook and styles for a workbook with the sheets named
+func writeXLSXParts(z *zip.Writer, sheets []string) error {
+	contentTypes := `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+	workbook := `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`
+	workbookRels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+
+	for i, name := range sheets {
+		n := i + 1
+		contentTypes += fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		workbook += fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(name), n, n)
+		workbookRels += fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	contentTypes += `</Types>`
+	workbook += `</sheets></workbook>`
+	workbookRels += fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+	workbookRels += `</Relationships>`
+
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	styles := `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+		`<borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/></cellXfs>` +
+		`</styleSheet>`
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/styles.xml", styles},
+	}
+	for _, p := range parts {
+		f, err := z.Create(p.name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, xml.Header+p.content)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package covid
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Korea, South", StartsAt: start, UpdatedAt: time.Date(2020, 3, 3, 9, 30, 0, 0, time.UTC), Deaths: []int{1, 2, 5}, Confirmed: []int{100, 150, 400}},
+		{Country: "Trinidad & <Tobago>", StartsAt: start, Deaths: []int{0, 0}, Confirmed: []int{1, 3}},
+		{Country: "Australia", Province: "New South Wales", StartsAt: start, Deaths: []int{0, 1, 1}, Confirmed: []int{4, 6, 9}},
+		{StartsAt: start, Deaths: []int{1, 3, 6}, Confirmed: []int{105, 159, 409}},
+	}
+	for _, s := range slice {
+		s.UpdateDaily()
+	}
+
+	b := &bytes.Buffer{}
+	err := slice.WriteXLSX(b)
+	if err != nil {
+		t.Fatalf("test: write xlsx error:%s", err)
+	}
+
+	// Read every sheet back, checking the values and that names are escaped, the global series is left out
+	// and provinces are only in the sheets of all series
+	tests := map[string][]string{
+		"Summary": {
+			"Country,Province,Confirmed,Deaths,Confirmed Today,Deaths Today,Population,Updated At",
+			fmt.Sprintf("Korea, South,,400,5,250,3,%d,2020-03-03 09:30", slice[0].Population()),
+			"Trinidad & <Tobago>,,3,0,2,0,0,",
+			"Australia,New South Wales,9,1,3,0,0,",
+		},
+		"Confirmed": {
+			"Country,Province,2020-03-01,2020-03-02,2020-03-03",
+			"Korea, South,,100,150,400",
+			"Trinidad & <Tobago>,,1,3,",
+			"Australia,New South Wales,4,6,9",
+		},
+		"Deaths": {
+			"Country,Province,2020-03-01,2020-03-02,2020-03-03",
+			"Korea, South,,1,2,5",
+			"Trinidad & <Tobago>,,0,0,",
+			"Australia,New South Wales,0,1,1",
+		},
+		"Detail": {
+			"Country,Date,Confirmed,Deaths,Confirmed Daily,Deaths Daily",
+			"Korea, South,2020-03-01,100,1,100,1",
+			"Korea, South,2020-03-02,150,2,50,1",
+			"Korea, South,2020-03-03,400,5,250,3",
+			"Trinidad & <Tobago>,2020-03-01,1,0,1,0",
+			"Trinidad & <Tobago>,2020-03-02,3,0,2,0",
+		},
+	}
+	for sheet, want := range tests {
+		records, err := readXLSX(b.Bytes(), sheet)
+		if err != nil {
+			t.Fatalf("test: read xlsx sheet:%s error:%s", sheet, err)
+		}
+		var got []string
+		for _, r := range records {
+			got = append(got, strings.Join(r, ","))
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("test: xlsx sheet:%s wanted:\n%s\ngot:\n%s", sheet, strings.Join(want, "\n"), strings.Join(got, "\n"))
+		}
+	}
+
+	// An empty slice still writes a valid workbook
+	b.Reset()
+	if err := (SeriesSlice{}).WriteXLSX(b); err != nil {
+		t.Fatalf("test: write empty xlsx error:%s", err)
+	}
+	if records, err := readXLSX(b.Bytes(), "Confirmed"); err != nil || len(records) != 0 {
+		t.Fatalf("test: read empty xlsx wanted no rows got:%v error:%v", records, err)
+	}
+}
@@ -0,0 +1,204 @@
+package covid
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatXLSX is an Excel workbook read with the columns declared in the dataset config, e.g. a ministry spreadsheet
+const FormatXLSX = "xlsx"
+
+// xlsxWorkbook is the list of sheets in xl/workbook.xml
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships are the targets of relationships in xl/_rels/workbook.xml.rels
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxSharedStrings are the strings in xl/sharedStrings.xml, rich text is split into runs
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+// xlsxSheet is the cell data in a worksheet
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX reads the sheet with name from an Excel workbook as rows of strings, like a csv
+// the first sheet is used if name is blank, numbers are returned as written so dates are Excel serial numbers
+func readXLSX(b []byte, name string) ([][]string, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: invalid workbook:%s", err)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range z.File {
+		files[f.Name] = f
+	}
+
+	var workbook xlsxWorkbook
+	err = readXLSXPart(files, "xl/workbook.xml", &workbook)
+	if err != nil {
+		return nil, err
+	}
+	var rels xlsxRelationships
+	err = readXLSXPart(files, "xl/_rels/workbook.xml.rels", &rels)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the worksheet for the sheet name
+	id := ""
+	for _, s := range workbook.Sheets {
+		if name == "" || s.Name == name {
+			id = s.ID
+			break
+		}
+	}
+	target := ""
+	for _, r := range rels.Relationships {
+		if id != "" && r.ID == id {
+			target = r.Target
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("xlsx: sheet not found:%s", name)
+	}
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+
+	// Shared strings are optional, workbooks with only inline strings don't have them
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		err = readXLSXPart(files, "xl/sharedStrings.xml", &shared)
+		if err != nil {
+			return nil, err
+		}
+	}
+	strs := make([]string, len(shared.Items))
+	for i, item := range shared.Items {
+		strs[i] = item.Text
+		for _, r := range item.Runs {
+			strs[i] += r.Text
+		}
+	}
+
+	var sheet xlsxSheet
+	err = readXLSXPart(files, target, &sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cells may be missing, so place each by its reference and pad rows to the width of the header
+	var records [][]string
+	width := 0
+	for _, row := range sheet.Rows {
+		var record []string
+		for i, c := range row.Cells {
+			column := i
+			if c.Ref != "" {
+				column = columnIndex(c.Ref)
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(strs) {
+					return nil, fmt.Errorf("xlsx: invalid shared string:%s", c.Value)
+				}
+				record[column] = strs[n]
+			case "inlineStr":
+				record[column] = c.Inline
+			default:
+				record[column] = c.Value
+			}
+		}
+		if len(records) == 0 {
+			width = len(record)
+		}
+		for len(record) < width {
+			record = append(record, "")
+		}
+		records = append(records, record[:width])
+	}
+	return records, nil
+}
+
+// readXLSXPart reads and decodes the xml part of a workbook with name into v
+func readXLSXPart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("xlsx: missing part:%s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("xlsx: error reading %s:%s", name, err)
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("xlsx: error reading %s:%s", name, err)
+	}
+	err = xml.Unmarshal(b, v)
+	if err != nil {
+		return fmt.Errorf("xlsx: error parsing %s:%s", name, err)
+	}
+	return nil
+}
+
+// columnIndex returns the index of the column in a cell reference e.g. 0 for A1, 26 for AA3
+// it is the inverse of columnName
+func columnIndex(ref string) int {
+	i := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		i = i*26 + int(r-'A') + 1
+	}
+	return i - 1
+}
+
+// excelDate returns the date for an Excel serial date number e.g. 43891 for 2020-03-01
+func excelDate(v string) (time.Time, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(math.Floor(f))), nil
+}
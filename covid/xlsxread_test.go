@@ -0,0 +1,44 @@
+package covid
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestReadXLSX(t *testing.T) {
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	slice := SeriesSlice{
+		{Country: "Thailand", StartsAt: start, Deaths: []int{0, 1}, Confirmed: []int{42, 43}},
+	}
+	slice[0].UpdateDaily()
+
+	b := &bytes.Buffer{}
+	err := slice.WriteXLSX(b)
+	if err != nil {
+		t.Fatalf("test: write xlsx error:%s", err)
+	}
+
+	records, err := readXLSX(b.Bytes(), "Detail")
+	if err != nil {
+		t.Fatalf("test: read xlsx error:%s", err)
+	}
+	if len(records) != 3 || records[0][0] != "Country" || records[2][1] != "2020-03-02" || records[2][2] != "43" {
+		t.Fatalf("test: read xlsx wrong records got:%v", records)
+	}
+
+	loaded, err := readMappedCSV(records, &ColumnMapping{Country: "Country", Date: "Date", Confirmed: "Confirmed", Deaths: "Deaths"})
+	if err != nil || len(loaded) != 1 || loaded[0].TotalConfirmed() != 43 {
+		t.Fatalf("test: read xlsx mapped wrong got:%v err:%v", loaded, err)
+	}
+
+	if _, err := readXLSX(b.Bytes(), "Missing"); err == nil {
+		t.Fatalf("test: read xlsx wanted error for missing sheet")
+	}
+	if d, err := excelDate("43891"); err != nil || !d.Equal(start) {
+		t.Fatalf("test: excel date wanted:%s got:%s", start, d)
+	}
+	if columnIndex("AA3") != 26 || columnIndex("B2") != 1 {
+		t.Fatalf("test: column index wrong")
+	}
+}
@@ -0,0 +1,74 @@
+package covid
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// daysInYear is the number of days in the calendar used to align years, which leaves out Feb 29
+const daysInYear = 365
+
+// YearOverYear returns a line chart of metric with one dataset per calendar year, aligned by day of year
+// so that seasonal patterns can be compared, Feb 29 is left out so that leap years line up with others
+func (s *Series) YearOverYear(metric string) (*Chart, error) {
+	values, err := s.MetricValues(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	// Label days with a calendar for a year which is not a leap year
+	chart := &Chart{Type: "line"}
+	chart.Data.Labels = make([]string, daysInYear)
+	day := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range chart.Data.Labels {
+		chart.Data.Labels[i] = day.AddDate(0, 0, i).Format("Jan 2")
+	}
+
+	// Place values by year and day of year, recording the last day we have for each year
+	years := make(map[int][]int)
+	last := make(map[int]int)
+	for i, v := range values {
+		date := s.StartsAt.AddDate(0, 0, i)
+		if date.Month() == time.February && date.Day() == 29 {
+			continue
+		}
+		if years[date.Year()] == nil {
+			years[date.Year()] = make([]int, daysInYear)
+		}
+		d := dayOfYear(date)
+		years[date.Year()][d] = v
+		last[date.Year()] = d
+	}
+
+	var keys []int
+	for y := range years {
+		keys = append(keys, y)
+	}
+	sort.Ints(keys)
+
+	chart.Data.Datasets = []ChartDataset{}
+	for i, y := range keys {
+		color := chartColors[i%len(chartColors)]
+		chart.Data.Datasets = append(chart.Data.Datasets, ChartDataset{
+			Label:       strconv.Itoa(y),
+			Data:        years[y][:last[y]+1],
+			BorderWidth: 2,
+			BorderColor: color,
+			LineTension: 0.1,
+		})
+	}
+
+	chart.Options = chartOptions(ChartSettings{}, chart.Data.Labels)
+	return chart, nil
+}
+
+// dayOfYear returns the 0 based index of date in a year without Feb 29
+func dayOfYear(date time.Time) int {
+	d := date.YearDay() - 1
+	leap := time.Date(date.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366
+	if leap && date.Month() > time.February {
+		d--
+	}
+	return d
+}
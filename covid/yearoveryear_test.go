@@ -0,0 +1,34 @@
+package covid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestYearOverYear(t *testing.T) {
+	// Daily values from Dec 30 2019 to Mar 2 2020, then a year later
+	s := &Series{StartsAt: time.Date(2019, 12, 30, 0, 0, 0, 0, time.UTC)}
+	days := int(time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC).Sub(s.StartsAt).Hours() / 24)
+	for i := 0; i < days; i++ {
+		s.Confirmed = append(s.Confirmed, i)
+		s.Deaths = append(s.Deaths, 0)
+	}
+	s.UpdateDaily()
+
+	chart, err := s.YearOverYear(MetricConfirmed)
+	if err != nil {
+		t.Fatalf("test: year over year error:%s", err)
+	}
+	if len(chart.Data.Labels) != 365 || len(chart.Data.Datasets) != 3 {
+		t.Fatalf("test: year over year wanted:365 labels 3 datasets got:%d %d", len(chart.Data.Labels), len(chart.Data.Datasets))
+	}
+
+	// 2020 is a leap year, so Mar 1 is index 59 and has the value for day 62 of the series
+	y2020 := chart.Data.Datasets[1]
+	if y2020.Label != "2020" || chart.Data.Labels[59] != "Mar 1" || y2020.Data[59] != 62 || len(y2020.Data) != 365 {
+		t.Fatalf("test: year over year wanted:62 got:%d", y2020.Data[59])
+	}
+	if len(chart.Data.Datasets[2].Data) != 2 {
+		t.Fatalf("test: year over year wanted 2 days in 2021 got:%d", len(chart.Data.Datasets[2].Data))
+	}
+}
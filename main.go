@@ -2,6 +2,7 @@ package main
 
 import (
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
@@ -21,6 +22,10 @@ var development = true
 // Store our templates globally, don't touch it after server start
 var htmlTemplate *template.Template
 var jsonTemplate *template.Template
+var embedTemplate *template.Template
+
+// Route country page urls to series
+var router = covid.NewRouter()
 
 // Main loads data, sets up a periodic fetch, and starts a web server to serve that data
 func main() {
@@ -35,8 +40,118 @@ func main() {
 		log.Printf("server: restarting")
 	}
 
+	// Set the timezone and hour at which today rolls over for daily data, e.g. Asia/Bangkok
+	if tz := os.Getenv("COVID_TIMEZONE"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Fatalf("server: invalid timezone:%s", err)
+		}
+		hour, _ := strconv.Atoi(os.Getenv("COVID_DAY_CUTOFF"))
+		err = covid.SetDayCutoff(location, hour)
+		if err != nil {
+			log.Fatalf("server: invalid day cutoff:%s", err)
+		}
+	}
+
+	// Set how invalid values in time series data are handled, by default they are skipped
+	switch os.Getenv("COVID_RECOVERY") {
+	case "row":
+		covid.SetRecoveryMode(covid.RecoverRow)
+	case "none":
+		covid.SetRecoveryMode(covid.RecoverNone)
+	}
+
+	// Set the number of attempts for fetches from data sources, and the wait before the first retry e.g. 5s
+	if attempts := os.Getenv("COVID_FETCH_ATTEMPTS"); attempts != "" {
+		policy := covid.DefaultRetryPolicy
+		policy.Attempts, _ = strconv.Atoi(attempts)
+		if backoff, err := time.ParseDuration(os.Getenv("COVID_FETCH_BACKOFF")); err == nil {
+			policy.Backoff = backoff
+		}
+		covid.SetRetryPolicy(policy)
+	}
+
+	// Cache downloads in a dir so that interrupted downloads resume where they stopped,
+	// recent downloads within the cache ttl e.g. 1h are reused, and offline mode loads from the cache only
+	if dir := os.Getenv("COVID_CACHE_DIR"); dir != "" {
+		err := covid.SetCacheDir(dir)
+		if err != nil {
+			log.Fatalf("server: invalid cache dir:%s", err)
+		}
+		if ttl, err := time.ParseDuration(os.Getenv("COVID_CACHE_TTL")); err == nil {
+			covid.SetCacheTTL(ttl)
+		}
+		covid.SetOffline(os.Getenv("COVID_OFFLINE") == "true")
+	}
+
+	// Check downloads against checksums or minimum row counts by file name
+	if path := os.Getenv("COVID_FILE_CHECKS"); path != "" {
+		err := covid.LoadFileChecks(path)
+		if err != nil {
+			log.Fatalf("server: failed to load file checks:%s", err)
+		}
+	}
+
+	// Replace the default tags for curated groups of countries
+	if path := os.Getenv("COVID_TAGS"); path != "" {
+		err := covid.LoadTags(path)
+		if err != nil {
+			log.Fatalf("server: failed to load tags:%s", err)
+		}
+	}
+
+	// Trim leading days with no cases from charts and pages unless requests set ?trim=0
+	if os.Getenv("COVID_TRIM_ZEROS") != "" {
+		covid.SetTrimZeros(true)
+	}
+
+	// Replace countries which differ from the sum of their provinces with that sum on load
+	if os.Getenv("COVID_ROLLUP_RECOMPUTE") != "" {
+		covid.SetRollupRecompute(true)
+	}
+
+	// Hide series by slug e.g. COVID_HIDDEN=china/unknown,others before loading
+	if list := os.Getenv("COVID_HIDDEN"); list != "" {
+		for _, slug := range strings.Split(list, ",") {
+			err := covid.SetHidden(slug, true)
+			if err != nil {
+				log.Fatalf("server: failed to hide series:%s", err)
+			}
+		}
+	}
+
+	// Freeze the data at an archive date e.g. 2021-03-01, which disables fetching
+	if date := os.Getenv("COVID_ARCHIVE_DATE"); date != "" {
+		archiveDate, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			log.Fatalf("server: invalid archive date:%s", err)
+		}
+		covid.SetArchiveDate(archiveDate)
+	}
+
+	// Load additional datasets from other providers to compare with our data
+	if path := os.Getenv("COVID_DATASETS"); path != "" {
+		err := covid.LoadDatasets(path)
+		if err != nil {
+			log.Fatalf("server: failed to load datasets:%s", err)
+		}
+	}
+
+	// Admin commands: backup <path> writes a backup of the loaded data and exits
+	// restore <path> serves the data and config from a backup instead of loading data
+	// reconcile <dataset> prints a comparison of our data with another dataset and exits
+	var command, commandPath string
+	if len(os.Args) > 1 {
+		if len(os.Args) != 3 || (os.Args[1] != "backup" && os.Args[1] != "restore" && os.Args[1] != "reconcile") {
+			log.Fatalf("usage: covid [backup|restore path] [reconcile dataset]")
+		}
+		command, commandPath = os.Args[1], os.Args[2]
+	}
+
 	// Schedule a regular fetch of data at a specified time daily
-	covid.ScheduleDataFetch()
+	if command == "" || command == "restore" {
+		covid.ScheduleDataFetch()
+	}
 
 	/*
 		// For testing, test a fetch instead
@@ -46,18 +161,91 @@ func main() {
 		}
 	*/
 
-	// Load the data
-	err := covid.LoadData()
+	// Load the data, or bootstrap from one of our own exports (parquet, csv or json) if one is given
+	var err error
+	if command == "restore" {
+		err = restoreBackup(commandPath)
+	} else if snapshot := os.Getenv("COVID_SNAPSHOT"); snapshot != "" {
+		err = covid.LoadExport(snapshot)
+	} else {
+		err = covid.LoadData()
+	}
 	if err != nil {
 		log.Fatalf("server: failed to load data:%s", err)
 	}
 
+	if command == "backup" {
+		err = writeBackup(commandPath)
+		if err != nil {
+			log.Fatalf("server: failed to write backup:%s", err)
+		}
+		log.Printf("server: wrote backup to %s", commandPath)
+		return
+	}
+
+	if command == "reconcile" {
+		report, err := covid.Reconcile(covid.DefaultDataset, commandPath, covid.DefaultTolerance)
+		if err != nil {
+			log.Fatalf("server: failed to reconcile:%s", err)
+		}
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("server: failed to reconcile:%s", err)
+		}
+		fmt.Println(string(b))
+		return
+	}
+
+	// Apply corrections or local data from a json file of observations
+	if path := os.Getenv("COVID_IMPORT"); path != "" {
+		result, err := covid.ImportFile(path)
+		if err != nil {
+			log.Fatalf("server: failed to import data:%s", err)
+		}
+		log.Printf("server: imported data applied:%d skipped:%d", result.Applied, result.Skipped)
+	}
+
 	// Load our template files into memory
 	loadTemplates()
 
+	// Set up optional publishing of data after each scheduled load
+	setupPublishers()
+
 	// Set up the https server with the handler attached to serve this data in a template
 	http.HandleFunc("/favicon.ico", handleFile)
-	http.HandleFunc("/", handleHome)
+
+	// Responses are compressed with brotli or gzip if the client accepts it
+	http.HandleFunc("/card/", compressHandler(handleCard))
+	http.HandleFunc("/embed/", compressHandler(handleEmbed))
+	http.HandleFunc("/export.xlsx", compressHandler(handleExportXLSX))
+	http.HandleFunc("/export.parquet", compressHandler(handleExportParquet))
+	http.HandleFunc("/top.html", compressHandler(handleTable))
+	http.HandleFunc("/top.md", compressHandler(handleTable))
+	http.HandleFunc("/chart/", compressHandler(handleChart))
+	http.HandleFunc("/feed.xml", compressHandler(handleFeed))
+	http.HandleFunc("/sitemap.xml", compressHandler(handleSitemap))
+	http.HandleFunc("/api/changes", compressHandler(handleChanges))
+	http.HandleFunc("/api/batch", compressHandler(handleBatch))
+	http.HandleFunc("/api/series", compressHandler(handleSeriesList))
+	http.HandleFunc("/api/series/", compressHandler(handleSeriesDownload))
+	http.HandleFunc("/api/datasets", compressHandler(handleDatasets))
+	http.HandleFunc("/api/datasets/", compressHandler(handleDatasets))
+	http.HandleFunc("/api/reconcile", compressHandler(handleReconcile))
+	http.HandleFunc("/api/rollups", compressHandler(handleRollups))
+	http.HandleFunc("/api/rest_of_world", compressHandler(handleRestOfWorld))
+	http.HandleFunc("/admin/import", handleImport)
+	http.HandleFunc("/admin/hidden", handleHidden)
+	http.HandleFunc("/api/leaderboard", compressHandler(handleLeaderboard))
+	http.HandleFunc("/api/breakdown", compressHandler(handleBreakdown))
+	http.HandleFunc("/api/country/", compressHandler(handleCountry))
+	http.HandleFunc("/api/summary", compressHandler(handleSummary))
+	http.HandleFunc("/api/load_report", compressHandler(handleLoadReport))
+	http.HandleFunc("/api/movers", compressHandler(handleMovers))
+	http.HandleFunc("/api/options", compressHandler(handleOptions))
+	http.HandleFunc("/api/stats", compressHandler(handleStats))
+	http.HandleFunc("/api/snapshots", compressHandler(handleSnapshots))
+	http.HandleFunc("/api/snapshots/", compressHandler(handleSnapshots))
+	http.HandleFunc("/", compressHandler(handleHome))
 
 	// Start a server on port 443 (or another port if dev specified)
 	if development {
@@ -73,21 +261,48 @@ func main() {
 
 }
 
+// writeBackup writes a backup of our data and config to a file at path
+func writeBackup(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = covid.WriteBackup(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// restoreBackup restores our data and config from a backup file at path
+func restoreBackup(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return covid.RestoreBackup(f)
+}
+
 func loadTemplates() {
 	var err error
-	htmlTemplate, err = template.ParseFiles("index.html.got")
+	htmlTemplate, err = template.New("index.html.got").Funcs(covid.FuncMap()).ParseFiles("index.html.got")
 	if err != nil {
 		log.Fatalf("template error:%s", err)
 	}
-	funcMap := map[string]interface{}{
-		"e":  escapeJSON,
-		"l":  outputList,
-		"ls": outputStringList,
-	}
+	funcMap := covid.FuncMap()
+	funcMap["e"] = escapeJSON
+	funcMap["l"] = outputList
+	funcMap["ls"] = outputStringList
 	jsonTemplate, err = template.New("index.json.got").Funcs(funcMap).ParseFiles("index.json.got")
 	if err != nil {
 		log.Fatalf("template error:%s", err)
 	}
+	embedTemplate, err = template.New("embed.html.got").Funcs(covid.FuncMap()).ParseFiles("embed.html.got")
+	if err != nil {
+		log.Fatalf("template error:%s", err)
+	}
 }
 
 // handleHome shows our website
@@ -95,35 +310,73 @@ func handleHome(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("request:%s", r.URL)
 
-	// Get the parameters from the url
-	country, province, period := parseParams(r)
-
-	// Fetch the series concerned - if both are blank we'll get the global series
-	series, err := covid.FetchSeries(country, province)
+	// Route the url to a series, limited by period if necessary
+	route, err := router.Route(r.URL.Path, r.URL.Query())
 	if err != nil {
 		http.NotFound(w, r)
 		return
 	}
+	series, period := route.Series, route.Period
+
+	// Redirect pages to the canonical url for aliases, case differences and form submissions
+	query := r.URL.Query()
+	if !route.JSON && (r.URL.Path != route.Canonical || query["country"] != nil || query["province"] != nil || query["period"] != nil) {
+		query.Del("country")
+		query.Del("province")
+		query.Del("period")
+		u := route.Canonical
+		if len(query) > 0 {
+			u += "?" + query.Encode()
+		}
+		http.Redirect(w, r, u, http.StatusMovedPermanently)
+		return
+	}
 
-	// Limit by period if necessary
-	if period > 0 {
-		series = series.Days(period)
+	jsonURL := fmt.Sprintf("%s.json?period=%d", route.Canonical, period)
+	if v := query.Get("trim"); v == "1" || v == "0" {
+		jsonURL += "&trim=" + v
 	}
 
-	//log.Printf("request:%s country:%s province:%s period:%d", r.URL, country, province, period)
+	// The first date shown if leading days with no cases were trimmed
+	trimmedStart := ""
+	if !route.TrimmedStart.IsZero() {
+		trimmedStart = route.TrimmedStart.Format("2006-01-02")
+	}
+
+	// Share card image for og:image - this must be an absolute url
+	cardPath := series.Path(0)
+	if cardPath == "/" {
+		cardPath = "/global"
+	}
+	scheme := "https"
+	if development {
+		scheme = "http"
+	}
+	cardURL := fmt.Sprintf("%s://%s/card%s.png", scheme, r.Host, cardPath)
+
+	// Structured data describing the series for search engines
+	jsonLD, err := series.JSONLD(fmt.Sprintf("%s://%s", scheme, r.Host))
+	if err != nil {
+		log.Printf("json-ld error:%s", err)
+	}
 
-	jsonURL := fmt.Sprintf("%s.json?period=%d", r.URL.Path, period)
+	provinceOptions := covid.ProvinceOptions(series.Country)
 
 	// Set up context with data
 	context := map[string]interface{}{
 		"period":          strconv.Itoa(period),
 		"country":         series.Key(series.Country),
 		"province":        series.Key(series.Province),
-		"series":          series,
+		"series":          series.View(),
 		"periodOptions":   covid.PeriodOptions(),
-		"countryOptions":  covid.CountryOptions(),
-		"provinceOptions": covid.ProvinceOptions(series.Country),
+		"countryGroups":   covid.CountryGroups(),
+		"provinceOptions": provinceOptions,
+		"provinceGroups":  covid.GroupOptions(provinceOptions),
 		"jsonURL":         jsonURL,
+		"cardURL":         cardURL,
+		"jsonLD":          jsonLD,
+		"trimmedStart":    trimmedStart,
+		"summary":         covid.FetchSummary(),
 	}
 
 	// If in development reload templates each time
@@ -150,54 +403,276 @@ func handleHome(w http.ResponseWriter, r *http.Request) {
 
 }
 
-// parseParams parses the parts of the url path (if any) and params
-func parseParams(r *http.Request) (country, province string, period int) {
+// handleTable shows a table of the top countries as html or markdown
+func handleTable(w http.ResponseWriter, r *http.Request) {
 
-	// Parse the path
-	p := r.URL.Path
+	log.Printf("request:%s", r.URL)
 
-	// First remove .json if it exists
-	p = strings.Replace(p, ".json", "", 1)
+	// Default to the top 20 countries, allow ?n=50 etc
+	n := 20
+	if v, err := strconv.Atoi(r.URL.Query().Get("n")); err == nil && v > 0 {
+		n = v
+	}
 
-	// Now parse parts
-	parts := strings.Split(strings.Trim(p, "/"), "/")
+	var err error
+	if strings.HasSuffix(r.URL.Path, ".md") {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		err = covid.WriteMarkdownTable(w, n)
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = covid.WriteHTMLTable(w, n)
+	}
 
-	if len(parts) > 0 {
-		country = parts[0]
+	if err != nil {
+		log.Printf("table render error:%s", err)
 	}
+}
+
+// handleCard serves a png share card for a series at /card/country/province.png
+func handleCard(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	// Parse the path after /card as we would for a country page
+	p := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/card"), ".png")
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	country, province := parts[0], ""
 	if len(parts) > 1 {
 		province = parts[1]
 	}
+	if country == "global" {
+		country = ""
+	}
 
-	// Add query string params from request  - accept all params this way
-	queryParams := r.URL.Query()
-	if len(queryParams["period"]) > 0 {
-		var err error
-		periodString := queryParams["period"][0]
-		period, err = strconv.Atoi(periodString)
-		if err != nil {
-			period = 0
-		}
+	series, err := covid.FetchSeries(country, province)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	err = series.WriteCard(w)
+	if err != nil {
+		log.Printf("card render error:%s", err)
+	}
+}
+
+// handleEmbed serves a small self-contained page with headline numbers and a chart of daily values
+// for iframes in other sites e.g. /embed/italy?metric=deaths&days=28
+func handleEmbed(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	route, err := router.Route(strings.TrimPrefix(r.URL.Path, "/embed"), nil)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	series, err := covid.FetchSeries(route.Series.Country, route.Series.Province)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	metric, err := covid.ParseMetricFilter(query.Get("metric"), covid.MetricConfirmed, covid.MetricConfirmed, covid.MetricDeaths)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	days, err := covid.ParsePeriodFilter(query.Get("days"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if days.AllTime() {
+		days.Days = covid.EmbedDays
+	}
+	datum := covid.DataConfirmed
+	if metric.Metric == covid.MetricDeaths {
+		datum = covid.DataDeaths
+	}
+	embed, err := series.Embed(datum, days.Days)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	if len(queryParams["country"]) > 0 {
-		country = queryParams["country"][0]
+
+	scheme := "https"
+	if development {
+		scheme = "http"
+	}
+	context := map[string]interface{}{
+		"embed":   embed,
+		"pageURL": fmt.Sprintf("%s://%s%s", scheme, r.Host, embed.Path),
 	}
 
-	if len(queryParams["province"]) > 0 {
-		province = queryParams["province"][0]
+	if development {
+		loadTemplates()
 	}
 
-	// Allow some abreviations for urls
-	if country == "uk" {
-		country = "United Kingdom"
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	err = embedTemplate.Execute(w, context)
+	if err != nil {
+		log.Printf("template render error:%s", err)
 	}
+}
 
-	// Allow global for top level (for global.json)
-	if country == "global" {
-		country = ""
+// handleFeed serves an atom feed of daily updates for the global series
+// or for a country or province with /feed.xml?country=spain
+func handleFeed(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	query := r.URL.Query()
+	series, err := covid.FetchSeries(query.Get("country"), query.Get("province"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	scheme := "https"
+	if development {
+		scheme = "http"
+	}
+	link := fmt.Sprintf("%s://%s%s", scheme, r.Host, series.Path(0))
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	err = series.WriteAtom(w, link)
+	if err != nil {
+		log.Printf("feed render error:%s", err)
+	}
+}
+
+// handleSitemap serves a sitemap with every country and province page
+func handleSitemap(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	scheme := "https"
+	if development {
+		scheme = "http"
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	err := covid.WriteSitemap(w, fmt.Sprintf("%s://%s", scheme, r.Host))
+	if err != nil {
+		log.Printf("sitemap render error:%s", err)
+	}
+}
+
+// handleChart serves a Chart.js config for a series at /chart/country/province.json
+// other countries, continents, tags or classifications may be added for comparison with ?with=spain,europe,g7,low-income
+// or the rest of the world with ?with=world-excluding-china or ?with=world-excluding-top-5
+// and auxiliary data overlaid with ?overlay=stringency, or daily cases split by variant with ?variants=1
+// outliers in each dataset are flagged with ?outliers=1 and daily charts get a 7 day average line with ?smoothed=1
+// calendar years are compared for the series with ?yoy=1
+// daily confirmed and deaths are charted on two axes with ?dual=1, with deaths shifted earlier by ?lag=14
+// leading days with no cases are trimmed with ?trim=1, and the first date charted is returned as trimmed_start
+func handleChart(w http.ResponseWriter, r *http.Request) {
+
+	log.Printf("request:%s", r.URL)
+
+	// Route the path after /chart as we would for a country page
+	// the period is applied in the chart, so fetch the full series
+	route, err := router.Route(strings.TrimPrefix(r.URL.Path, "/chart"), r.URL.Query())
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	series, err := covid.FetchSeries(route.Series.Country, route.Series.Province)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	list := []*covid.Series{series}
+
+	// Add any comparison countries
+	with := r.URL.Query().Get("with")
+	if with != "" {
+		for _, c := range strings.Split(with, ",") {
+			s, err := covid.FetchSeries(c, "")
+			if err != nil {
+				// Allow comparison with continents, tags or classifications, e.g. with=europe,g7,low-income
+				// or the rest of the world, e.g. with=world-excluding-china or world-excluding-top-5
+				s, err = covid.FetchRegion(c)
+				if excluded, top, ok := covid.ParseRestOfWorld(c); ok {
+					s, _, err = covid.FetchRestOfWorld(excluded, top)
+				}
+				if err != nil {
+					s, err = covid.FetchTag(c)
+				}
+				if err != nil {
+					s, err = covid.FetchClassification(c)
+				}
+				if err != nil {
+					http.NotFound(w, r)
+					return
+				}
+			}
+			list = append(list, s)
+		}
+	}
+
+	// Limit by period in the chart, so that windowed values are calculated with all data
+	query := r.URL.Query()
+	settings := covid.ChartSettings{
+		Days:        route.Period,
+		Datum:       covid.DataConfirmed,
+		Daily:       query.Get("daily") == "1",
+		Logarithmic: query.Get("log") == "1",
+		Overlay:     query.Get("overlay"),
+		Variants:    query.Get("variants") == "1",
+		Smoothed:    query.Get("smoothed") == "1",
+		Dual:        query.Get("dual") == "1",
+		TrimZeros:   covid.TrimRequested(query.Get("trim")),
+	}
+	settings.DeathsLag, _ = strconv.Atoi(query.Get("lag"))
+	if settings.Dual {
+		settings.Daily = true
+	}
+	if query.Get("outliers") == "1" {
+		settings.OutlierThreshold = covid.DefaultOutlierThreshold
+	}
+	switch query.Get("metric") {
+	case "deaths":
+		settings.Datum = covid.DataDeaths
+	case "incidence":
+		settings.Datum = covid.DataIncidence14
+	}
+
+	chart := covid.ChartData(list, settings)
+
+	// Compare calendar years for the first series instead
+	if query.Get("yoy") == "1" {
+		metric := covid.MetricConfirmed
+		switch settings.Datum {
+		case covid.DataDeaths:
+			metric = covid.MetricDeaths
+		case covid.DataIncidence14:
+			metric = covid.MetricIncidence14
+		}
+		if settings.Daily && settings.Datum != covid.DataIncidence14 {
+			metric += "_daily"
+		}
+		chart, err = series.YearOverYear(metric)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	output, err := chart.JSON()
+	if err != nil {
+		log.Printf("chart render error:%s", err)
+		http.Error(w, err.Error(), 500)
+		return
 	}
 
-	return country, province, period
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(output)
 }
 
 // handleFile shows a file (if it exists)
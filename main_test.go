@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleTable(t *testing.T) {
+	tests := map[string]string{
+		"/top.html":   "text/html; charset=utf-8",
+		"/top.md?n=5": "text/markdown; charset=utf-8",
+	}
+	for path, contentType := range tests {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		handleTable(w, r)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != contentType {
+			t.Fatalf("test: table %s wanted:%d %s got:%d %s", path, http.StatusOK, contentType, w.Code, w.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(w.Body.String(), "Deaths Today") {
+			t.Fatalf("test: table %s wanted header row got:%s", path, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/junlapong/coronavirus/covid"
+)
+
+// setupPublishers registers publishers configured with env variables to run after each data load
+func setupPublishers() {
+
+	// Export InfluxDB line protocol to a file
+	if path := os.Getenv("COVID_INFLUX_FILE"); path != "" {
+		log.Printf("server: exporting influx line protocol to %s", path)
+		covid.OnLoad(func() error {
+			return covid.ExportLineProtocol(path)
+		})
+	}
+
+	// Store a daily snapshot of the data, served at /api/snapshots
+	if dir := os.Getenv("COVID_SNAPSHOT_DIR"); dir != "" {
+		err := covid.SetSnapshotDir(dir)
+		if err != nil {
+			log.Fatalf("server: failed to set up snapshots:%s", err)
+		}
+		log.Printf("server: storing snapshots in %s", dir)
+		covid.OnLoad(covid.WriteSnapshot)
+	}
+
+	// Push InfluxDB line protocol to an endpoint
+	if url := os.Getenv("COVID_INFLUX_URL"); url != "" {
+		log.Printf("server: pushing data to influx")
+		token := os.Getenv("COVID_INFLUX_TOKEN")
+		covid.OnLoad(func() error {
+			return covid.PushInflux(url, token)
+		})
+	}
+
+	// Publish a summary to a Google Sheet
+	if id := os.Getenv("COVID_SHEETS_ID"); id != "" {
+		sheets, err := covid.NewSheetsPublisher(os.Getenv("COVID_SHEETS_CREDENTIALS"), id)
+		if err != nil {
+			log.Fatalf("server: failed to set up sheets publisher:%s", err)
+		}
+		if columns := os.Getenv("COVID_SHEETS_COLUMNS"); columns != "" {
+			sheets.Columns = strings.Split(columns, ",")
+		}
+		if top, err := strconv.Atoi(os.Getenv("COVID_SHEETS_TOP")); err == nil && top > 0 {
+			sheets.Top = top
+		}
+		log.Printf("server: publishing summary to google sheet %s", id)
+		covid.OnLoad(sheets.Publish)
+	}
+
+	// Upload exports to an S3 compatible bucket (including GCS)
+	if bucket := os.Getenv("COVID_BUCKET"); bucket != "" {
+		publisher := &covid.BucketPublisher{
+			Endpoint:  os.Getenv("COVID_BUCKET_ENDPOINT"),
+			Region:    os.Getenv("COVID_BUCKET_REGION"),
+			Bucket:    bucket,
+			Prefix:    os.Getenv("COVID_BUCKET_PREFIX"),
+			AccessKey: os.Getenv("COVID_BUCKET_ACCESS_KEY"),
+			SecretKey: os.Getenv("COVID_BUCKET_SECRET_KEY"),
+		}
+		if publisher.Endpoint == "" {
+			publisher.Endpoint = "https://s3.amazonaws.com"
+		}
+		if publisher.Region == "" {
+			publisher.Region = "us-east-1"
+		}
+		log.Printf("server: uploading exports to bucket %s", bucket)
+		covid.OnLoad(publisher.Publish)
+	}
+
+	// Post to webhooks when data changes
+	if path := os.Getenv("COVID_WEBHOOKS"); path != "" {
+		hooks, err := covid.LoadWebhooks(path)
+		if err != nil {
+			log.Fatalf("server: failed to set up webhooks:%s", err)
+		}
+		for _, h := range hooks {
+			log.Printf("server: posting changes to webhook %s", h.URL)
+			covid.OnLoad(h.Notify)
+		}
+	}
+
+	// Post a daily update and milestone alerts to a slack channel
+	if url := os.Getenv("COVID_SLACK_WEBHOOK"); url != "" {
+		var countries []string
+		if c := os.Getenv("COVID_SLACK_COUNTRIES"); c != "" {
+			countries = strings.Split(c, ",")
+		}
+		log.Printf("server: posting updates to slack")
+		covid.OnLoad(covid.NewSlackNotifier(url, os.Getenv("COVID_SITE_URL"), countries).Notify)
+	}
+
+	// Post a daily summary tweet for each country
+	if key := os.Getenv("COVID_TWITTER_CONSUMER_KEY"); key != "" {
+		var countries []string
+		if c := os.Getenv("COVID_TWITTER_COUNTRIES"); c != "" {
+			countries = strings.Split(c, ",")
+		}
+		bot, err := covid.NewTwitterBot(countries, os.Getenv("COVID_TWITTER_STATE"))
+		if err != nil {
+			log.Fatalf("server: failed to set up twitter bot:%s", err)
+		}
+		bot.ConsumerKey = key
+		bot.ConsumerSecret = os.Getenv("COVID_TWITTER_CONSUMER_SECRET")
+		bot.AccessToken = os.Getenv("COVID_TWITTER_ACCESS_TOKEN")
+		bot.AccessSecret = os.Getenv("COVID_TWITTER_ACCESS_SECRET")
+		if hour, err := strconv.Atoi(os.Getenv("COVID_TWITTER_SETTLE_HOUR")); err == nil {
+			bot.SettleHour = hour
+		}
+		log.Printf("server: posting daily summaries to twitter")
+		covid.OnLoad(bot.Post)
+	}
+}